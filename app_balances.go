@@ -0,0 +1,43 @@
+package main
+
+import "github.com/default-anton/cashmop/internal/database"
+
+// SetBalanceCheckpoint records the balance of the named account at the end of
+// cp.Date, replacing one already set for that date.
+func (a *App) SetBalanceCheckpoint(account string, cp database.BalanceCheckpoint) (database.BalanceCheckpoint, error) {
+	accountID, err := a.svc.AccountID(account)
+	if err != nil {
+		return database.BalanceCheckpoint{}, err
+	}
+	cp.AccountID = accountID
+	saved, err := a.svc.SetBalanceCheckpoint(cp)
+	if err != nil {
+		return database.BalanceCheckpoint{}, err
+	}
+	a.emit(EventBalancesUpdated)
+	return saved, nil
+}
+
+func (a *App) DeleteBalanceCheckpoint(id int64) error {
+	if err := a.svc.DeleteBalanceCheckpoint(id); err != nil {
+		return err
+	}
+	a.emit(EventBalancesUpdated)
+	return nil
+}
+
+// GetBalanceCheckpoints returns the checkpoints of one account, or of all
+// accounts when accountID is nil.
+func (a *App) GetBalanceCheckpoints(accountID *int64) ([]database.BalanceCheckpoint, error) {
+	return a.svc.GetBalanceCheckpoints(accountID)
+}
+
+func (a *App) ReconcileAccounts(accountID *int64) ([]database.AccountReconciliation, error) {
+	return a.svc.ReconcileAccounts(accountID)
+}
+
+// GetNetWorth sums account balances at the end of each month from startMonth
+// to endMonth (YYYY-MM) in the main currency.
+func (a *App) GetNetWorth(startMonth, endMonth string) (database.NetWorth, error) {
+	return a.svc.GetNetWorth(startMonth, endMonth)
+}
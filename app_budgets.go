@@ -0,0 +1,39 @@
+package main
+
+import "github.com/default-anton/cashmop/internal/database"
+
+func (a *App) GetBudgets() ([]database.Budget, error) {
+	return a.svc.GetBudgets()
+}
+
+func (a *App) CreateBudget(b database.Budget) (database.Budget, error) {
+	created, err := a.svc.CreateBudget(b)
+	if err != nil {
+		return database.Budget{}, err
+	}
+	a.emit(EventBudgetsUpdated)
+	return created, nil
+}
+
+func (a *App) UpdateBudget(id int64, u database.BudgetUpdate) (database.Budget, error) {
+	updated, err := a.svc.UpdateBudget(id, u)
+	if err != nil {
+		return database.Budget{}, err
+	}
+	a.emit(EventBudgetsUpdated)
+	return updated, nil
+}
+
+func (a *App) DeleteBudget(id int64) error {
+	if err := a.svc.DeleteBudget(id); err != nil {
+		return err
+	}
+	a.emit(EventBudgetsUpdated)
+	return nil
+}
+
+// GetBudgetReport compares budgets with spending for each month from
+// startMonth to endMonth (YYYY-MM).
+func (a *App) GetBudgetReport(startMonth, endMonth string) (database.BudgetReport, error) {
+	return a.svc.GetBudgetReport(startMonth, endMonth)
+}
@@ -6,6 +6,11 @@ const (
 	EventTransactionsUpdated = "transactions-updated"
 	EventCategoriesUpdated   = "categories-updated"
 	EventOwnersUpdated       = "owners-updated"
+	EventBudgetsUpdated      = "budgets-updated"
+	EventBalancesUpdated     = "balances-updated"
+	// EventAnomaliesDetected carries the []cashmop.AnomalyReport of the months
+	// an import covered that have anomalies.
+	EventAnomaliesDetected = "anomalies-detected"
 )
 
 func (a *App) emit(event string, data ...any) {
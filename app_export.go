@@ -9,7 +9,7 @@ import (
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-func (a *App) ExportTransactionsWithDialog(startDate, endDate string, categoryIDs []int64, ownerIDs []int64, format string) (int, error) {
+func (a *App) ExportTransactionsWithDialog(startDate, endDate string, categoryIDs []int64, ownerIDs []int64, tagIDs []int64, merchantIDs []int64, format string) (int, error) {
 	defaultFilename := generateDefaultFilename(startDate, endDate, format)
 
 	var destinationPath string
@@ -33,7 +33,7 @@ func (a *App) ExportTransactionsWithDialog(startDate, endDate string, categoryID
 		return 0, fmt.Errorf("no destination selected")
 	}
 
-	return a.ExportTransactions(startDate, endDate, categoryIDs, ownerIDs, format, destinationPath)
+	return a.ExportTransactions(startDate, endDate, categoryIDs, ownerIDs, tagIDs, merchantIDs, format, destinationPath)
 }
 
 func generateDefaultFilename(startDate, endDate, format string) string {
@@ -49,12 +49,14 @@ func generateDefaultFilename(startDate, endDate, format string) string {
 	return fmt.Sprintf("cashmop_%s.%s", datePart, format)
 }
 
-func (a *App) ExportTransactions(startDate, endDate string, categoryIDs []int64, ownerIDs []int64, format, destinationPath string) (int, error) {
+func (a *App) ExportTransactions(startDate, endDate string, categoryIDs []int64, ownerIDs []int64, tagIDs []int64, merchantIDs []int64, format, destinationPath string) (int, error) {
 	return a.svc.ExportTransactions(cashmop.ExportParams{
 		StartDate:       startDate,
 		EndDate:         endDate,
 		CategoryIDs:     categoryIDs,
 		OwnerIDs:        ownerIDs,
+		TagIDs:          tagIDs,
+		MerchantIDs:     merchantIDs,
 		Format:          format,
 		DestinationPath: destinationPath,
 	})
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"log"
+	"sort"
 
 	"github.com/default-anton/cashmop/internal/cashmop"
 	"github.com/default-anton/cashmop/internal/fx"
@@ -21,6 +22,7 @@ func (a *App) ImportTransactions(transactions []TransactionInput) error {
 			Account:     t.Account,
 			Owner:       t.Owner,
 			Currency:    t.Currency,
+			Note:        t.Note,
 		})
 	}
 
@@ -50,5 +52,39 @@ func (a *App) ImportTransactions(transactions []TransactionInput) error {
 		}
 	}
 
+	// After the FX sync, so that foreign spending counts.
+	if reports := a.importAnomalies(transactions); len(reports) > 0 {
+		a.emit(EventAnomaliesDetected, reports)
+	}
+
 	return nil
 }
+
+// importAnomalies runs anomaly detection for each month the imported
+// transactions fall in and returns the reports that found something. Errors
+// are only logged: the import itself succeeded.
+func (a *App) importAnomalies(transactions []TransactionInput) []cashmop.AnomalyReport {
+	seen := map[string]bool{}
+	months := []string{}
+	for _, t := range transactions {
+		if len(t.Date) < 7 || seen[t.Date[:7]] {
+			continue
+		}
+		seen[t.Date[:7]] = true
+		months = append(months, t.Date[:7])
+	}
+	sort.Strings(months)
+
+	reports := []cashmop.AnomalyReport{}
+	for _, month := range months {
+		report, err := a.svc.DetectAnomalies(month)
+		if err != nil {
+			log.Printf("Anomaly detection failed for %s after import: %v", month, err)
+			continue
+		}
+		if len(report.Anomalies) > 0 {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func (a *App) GetMerchants() ([]database.Merchant, error) {
+	return a.svc.GetMerchants()
+}
+
+func (a *App) CreateMerchant(name string, aliases []database.MerchantAlias) (database.Merchant, error) {
+	created, err := a.svc.CreateMerchant(name, aliases)
+	if err != nil {
+		return database.Merchant{}, err
+	}
+	a.emit(EventTransactionsUpdated)
+	return created, nil
+}
+
+func (a *App) RenameMerchant(id int64, name string) (database.Merchant, error) {
+	renamed, err := a.svc.RenameMerchant(id, name)
+	if err != nil {
+		return database.Merchant{}, err
+	}
+	a.emit(EventTransactionsUpdated)
+	return renamed, nil
+}
+
+func (a *App) DeleteMerchant(id int64) error {
+	if err := a.svc.DeleteMerchant(id); err != nil {
+		return err
+	}
+	a.emit(EventTransactionsUpdated)
+	return nil
+}
+
+func (a *App) AddMerchantAlias(merchantID int64, alias database.MerchantAlias) (database.Merchant, error) {
+	updated, err := a.svc.AddMerchantAlias(merchantID, alias)
+	if err != nil {
+		return database.Merchant{}, err
+	}
+	a.emit(EventTransactionsUpdated)
+	return updated, nil
+}
+
+func (a *App) DeleteMerchantAlias(id int64) (database.Merchant, error) {
+	updated, err := a.svc.DeleteMerchantAlias(id)
+	if err != nil {
+		return database.Merchant{}, err
+	}
+	a.emit(EventTransactionsUpdated)
+	return updated, nil
+}
+
+// SuggestMerchants clusters descriptions of transactions without a merchant
+// and proposes aliases for them.
+func (a *App) SuggestMerchants(opts cashmop.MerchantSuggestionOptions) ([]cashmop.MerchantSuggestion, error) {
+	return a.svc.SuggestMerchants(opts)
+}
+
+// GetMerchantReport ranks merchants by spending, visits or average ticket.
+func (a *App) GetMerchantReport(opts cashmop.MerchantReportOptions) (cashmop.MerchantReport, error) {
+	return a.svc.GetMerchantReport(opts)
+}
@@ -0,0 +1,11 @@
+package main
+
+import "github.com/default-anton/cashmop/internal/cashmop"
+
+func (a *App) GetRecurringSeries(includeEnded bool) ([]cashmop.RecurringSeries, error) {
+	return a.svc.GetRecurringSeries(includeEnded)
+}
+
+func (a *App) SetKnownSubscription(key string, known bool) error {
+	return a.svc.SetKnownSubscription(key, known)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+// GetSummary totals transactions per month, quarter or year and per category,
+// owner, account, tag or merchant without sending the transactions themselves.
+func (a *App) GetSummary(opts database.SummaryOptions) (database.Summary, error) {
+	return a.svc.GetSummary(opts)
+}
+
+// GetForecast projects cash flow and account balances for the next months.
+func (a *App) GetForecast(months int) (cashmop.Forecast, error) {
+	return a.svc.Forecast(months)
+}
+
+// GetSettlement splits shared spending between owners and returns who owes
+// whom.
+func (a *App) GetSettlement(opts cashmop.SettlementOptions) (cashmop.Settlement, error) {
+	return a.svc.Settle(opts)
+}
+
+// GetAnomalies flags categories and merchants with unusual spending in month
+// (YYYY-MM), e.g. the months an import just covered.
+func (a *App) GetAnomalies(month string) (cashmop.AnomalyReport, error) {
+	return a.svc.DetectAnomalies(month)
+}
+
+// GetComparison compares two date ranges per category, owner, account, tag or
+// merchant and lists merchants that appeared or disappeared between them.
+func (a *App) GetComparison(opts cashmop.ComparisonOptions) (cashmop.Comparison, error) {
+	return a.svc.Compare(opts)
+}
@@ -1,6 +1,9 @@
 package main
 
-import "github.com/default-anton/cashmop/internal/database"
+import (
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
 
 func (a *App) SaveCategorizationRule(rule database.CategorizationRule) (*RuleResult, error) {
 	id, affectedIds, err := a.svc.SaveCategorizationRule(rule)
@@ -64,3 +67,29 @@ func (a *App) UndoCategorizationRule(ruleId int64, transactionIds []int64) error
 func (a *App) GetCategorizationRulesCount() (int, error) {
 	return a.svc.GetCategorizationRulesCount()
 }
+
+func (a *App) SuggestCategorizationRules(opts cashmop.RuleSuggestionOptions) ([]cashmop.RuleSuggestion, error) {
+	return a.svc.SuggestCategorizationRules(opts)
+}
+
+func (a *App) ReapplyRules(ruleIDs []int64, overwrite string, dryRun bool) (database.RuleApplyResult, error) {
+	result, err := a.svc.ReapplyRules(ruleIDs, overwrite, dryRun)
+	if err != nil {
+		return result, err
+	}
+	if !dryRun && len(result.Changes) > 0 {
+		a.emit(EventTransactionsUpdated)
+	}
+	return result, nil
+}
+
+func (a *App) UndoRuleApply(batchID int64) (database.RuleApplyUndoResult, error) {
+	result, err := a.svc.UndoRuleApply(batchID)
+	if err != nil {
+		return result, err
+	}
+	if result.Restored > 0 {
+		a.emit(EventTransactionsUpdated)
+	}
+	return result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/base64"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
@@ -239,7 +240,7 @@ func TestImportTransactions_ForeignCurrency(t *testing.T) {
 	}
 
 	// Verify the transaction was imported with correct currency
-	txs, err := store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil)
+	txs, err := store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("GetAnalysisTransactions failed: %v", err)
 	}
@@ -253,6 +254,43 @@ func TestImportTransactions_ForeignCurrency(t *testing.T) {
 	}
 }
 
+func TestImportTransactions_Anomalies(t *testing.T) {
+	store := setupTestDB(t)
+
+	app := newTestApp(t, store)
+
+	var history []TransactionInput
+	for month := 1; month <= 12; month++ {
+		history = append(history, TransactionInput{
+			Date:        fmt.Sprintf("2023-%02d-10", month),
+			Description: "Weekly shop",
+			Amount:      -10000 - int64(month)*100,
+			Category:    "Groceries",
+			Account:     "Checking",
+		})
+	}
+	if err := app.ImportTransactions(history); err != nil {
+		t.Fatalf("ImportTransactions failed: %v", err)
+	}
+
+	transactions := []TransactionInput{
+		{Date: "2024-01-10", Description: "Weekly shop", Amount: -60000, Category: "Groceries", Account: "Checking"},
+	}
+	if err := app.ImportTransactions(transactions); err != nil {
+		t.Fatalf("ImportTransactions failed: %v", err)
+	}
+
+	reports := app.importAnomalies(transactions)
+	if len(reports) != 1 || reports[0].Month != "2024-01" || len(reports[0].Anomalies) != 2 {
+		t.Fatalf("expected a category and a merchant anomaly for 2024-01, got %+v", reports)
+	}
+	for _, a := range reports[0].Anomalies {
+		if a.CategoryName != "Groceries" || a.Direction != "high" {
+			t.Errorf("unexpected anomaly: %+v", a)
+		}
+	}
+}
+
 func TestGetUncategorizedTransactions(t *testing.T) {
 	store := setupTestDB(t)
 
@@ -460,13 +498,16 @@ func TestCreateCategory(t *testing.T) {
 	store := setupTestDB(t)
 	app := newTestApp(t, store)
 
-	id, err := app.CreateCategory("Utilities")
+	id, err := app.CreateCategory("Utilities", "")
 	if err != nil {
 		t.Fatalf("CreateCategory failed: %v", err)
 	}
 	if id <= 0 {
 		t.Fatalf("Expected positive ID, got %d", id)
 	}
+	if _, err := app.CreateCategory("Salary", "bonus"); err == nil {
+		t.Fatal("Expected an invalid kind to be rejected")
+	}
 
 	categories, err := app.GetCategories()
 	if err != nil {
@@ -475,8 +516,18 @@ func TestCreateCategory(t *testing.T) {
 	if len(categories) != 1 {
 		t.Fatalf("Expected 1 category, got %d", len(categories))
 	}
-	if categories[0].Name != "Utilities" {
-		t.Fatalf("Expected category name Utilities, got %s", categories[0].Name)
+	if categories[0].Name != "Utilities" || categories[0].Kind != database.CategoryKindExpense {
+		t.Fatalf("Expected expense category Utilities, got %+v", categories[0])
+	}
+
+	if _, err := app.CreateCategory("Salary", "income"); err != nil {
+		t.Fatalf("CreateCategory failed: %v", err)
+	}
+	categories, _ = app.GetCategories()
+	for _, c := range categories {
+		if c.Name == "Salary" && c.Kind != database.CategoryKindIncome {
+			t.Errorf("Expected Salary to be income, got %q", c.Kind)
+		}
 	}
 }
 
@@ -551,7 +602,7 @@ func TestDeleteCategory(t *testing.T) {
 		CategoryName: "Subscriptions",
 	})
 
-	res, err := app.DeleteCategory(categoryID)
+	res, err := app.DeleteCategory(categoryID, nil)
 	if err != nil {
 		t.Fatalf("DeleteCategory failed: %v", err)
 	}
@@ -592,6 +643,53 @@ func TestDeleteCategory(t *testing.T) {
 	}
 }
 
+func TestDeleteCategoryReassign(t *testing.T) {
+	store := setupTestDB(t)
+	app := newTestApp(t, store)
+
+	accountID := createTestAccount(t, store, "Checking")
+	oldID := createTestCategory(t, store, "Streaming")
+	targetID := createTestCategory(t, store, "Subscriptions")
+
+	tx := createTestTransaction(t, store, accountID, nil, "2024-01-01", "Netflix", 1599, &oldID)
+	createTestRule(t, store, database.CategorizationRule{
+		MatchType:  "contains",
+		MatchValue: "netflix",
+		CategoryID: oldID,
+	})
+
+	res, err := app.DeleteCategory(oldID, &targetID)
+	if err != nil {
+		t.Fatalf("DeleteCategory failed: %v", err)
+	}
+	if res.ReassignedToID == nil || *res.ReassignedToID != targetID || res.ReassignedToName != "Subscriptions" {
+		t.Fatalf("Expected reassignment to Subscriptions, got %+v", res)
+	}
+	if res.ReassignedCount != 1 || res.MovedRuleCount != 1 || res.UncategorizedCount != 0 || res.DeletedRuleCount != 0 {
+		t.Fatalf("Unexpected counts: %+v", res)
+	}
+
+	var category sql.NullInt64
+	if err := store.DB().QueryRow("SELECT category_id FROM transactions WHERE id = ?", tx.ID).Scan(&category); err != nil {
+		t.Fatalf("query transaction category: %v", err)
+	}
+	if !category.Valid || category.Int64 != targetID {
+		t.Fatalf("Expected transaction to move to Subscriptions, got %v", category)
+	}
+
+	var rulesCount int
+	if err := store.DB().QueryRow("SELECT COUNT(*) FROM categorization_rules WHERE category_id = ?", targetID).Scan(&rulesCount); err != nil {
+		t.Fatalf("count rules: %v", err)
+	}
+	if rulesCount != 1 {
+		t.Fatalf("Expected rule to move to Subscriptions, got %d", rulesCount)
+	}
+
+	if _, err := app.DeleteCategory(targetID, &targetID); err == nil {
+		t.Fatalf("Expected reassigning a category to itself to fail")
+	}
+}
+
 // ============================================================================
 // 4. Account & Owner Management
 // ============================================================================
@@ -1130,7 +1228,7 @@ func TestGetAnalysisTransactions(t *testing.T) {
 	createTestTransaction(t, store, accountID, nil, "2024-03-05", "Mar 1", 25.00, nil)
 
 	t.Run("date range only", func(t *testing.T) {
-		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil)
+		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("GetAnalysisTransactions failed: %v", err)
 		}
@@ -1144,7 +1242,7 @@ func TestGetAnalysisTransactions(t *testing.T) {
 		catID := createTestCategory(t, store, "Groceries")
 		tx := createTestTransaction(t, store, accountID, nil, "2024-01-15", "Categorized", 50.00, &catID)
 
-		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", []int64{catID}, nil)
+		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", []int64{catID}, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("GetAnalysisTransactions failed: %v", err)
 		}
@@ -1159,7 +1257,7 @@ func TestGetAnalysisTransactions(t *testing.T) {
 	})
 
 	t.Run("filter by uncategorized (category ID 0)", func(t *testing.T) {
-		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", []int64{0}, nil)
+		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", []int64{0}, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("GetAnalysisTransactions failed: %v", err)
 		}
@@ -1180,7 +1278,7 @@ func TestGetAnalysisTransactions(t *testing.T) {
 		_ = createTestTransaction(t, store, accountID, &ownerID, "2024-01-15", "John's purchase", 75.00, nil)
 
 		// Get transactions filtered by owner
-		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, []int64{ownerID})
+		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, []int64{ownerID}, nil, nil)
 		if err != nil {
 			t.Fatalf("GetAnalysisTransactions failed: %v", err)
 		}
@@ -1196,7 +1294,7 @@ func TestGetAnalysisTransactions(t *testing.T) {
 
 	t.Run("filter by no owner (owner ID 0)", func(t *testing.T) {
 		// Get transactions without owner (owner_id IS NULL)
-		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, []int64{0})
+		results, err := app.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, []int64{0}, nil, nil)
 		if err != nil {
 			t.Fatalf("GetAnalysisTransactions failed: %v", err)
 		}
@@ -1486,7 +1584,7 @@ func TestExportTransactions(t *testing.T) {
 
 	t.Run("export to CSV", func(t *testing.T) {
 		csvPath := tempDir + "/test_export.csv"
-		count, err := app.ExportTransactions("2024-01-01", "2024-01-31", nil, nil, "csv", csvPath)
+		count, err := app.ExportTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil, "csv", csvPath)
 		if err != nil {
 			t.Fatalf("ExportTransactions to CSV failed: %v", err)
 		}
@@ -1516,7 +1614,7 @@ func TestExportTransactions(t *testing.T) {
 
 	t.Run("export to XLSX", func(t *testing.T) {
 		xlsxPath := tempDir + "/test_export.xlsx"
-		count, err := app.ExportTransactions("2024-01-01", "2024-01-31", nil, nil, "xlsx", xlsxPath)
+		count, err := app.ExportTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil, "xlsx", xlsxPath)
 		if err != nil {
 			t.Fatalf("ExportTransactions to XLSX failed: %v", err)
 		}
@@ -1532,7 +1630,7 @@ func TestExportTransactions(t *testing.T) {
 
 	t.Run("no transactions error", func(t *testing.T) {
 		path := tempDir + "/no_transactions.csv"
-		_, err := app.ExportTransactions("2025-01-01", "2025-01-31", nil, nil, "csv", path)
+		_, err := app.ExportTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil, "csv", path)
 		if err == nil {
 			t.Error("Expected error when no transactions in date range")
 		}
@@ -1540,7 +1638,7 @@ func TestExportTransactions(t *testing.T) {
 
 	t.Run("invalid format error", func(t *testing.T) {
 		path := tempDir + "/invalid.txt"
-		_, err := app.ExportTransactions("2024-01-01", "2024-01-31", nil, nil, "txt", path)
+		_, err := app.ExportTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil, "txt", path)
 		if err == nil {
 			t.Error("Expected error for invalid format")
 		}
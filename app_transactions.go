@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/default-anton/cashmop/internal/cashmop"
 	"github.com/default-anton/cashmop/internal/database"
 	"github.com/default-anton/cashmop/internal/fuzzy"
 )
@@ -34,6 +36,113 @@ func (a *App) DeleteTransactions(ids []int64) (int, error) {
 	return count, nil
 }
 
+func (a *App) GetTransactionSplits(transactionID int64) ([]database.SplitLine, error) {
+	return a.svc.GetTransactionSplits(transactionID)
+}
+
+func (a *App) CreateTransaction(input TransactionInput) (database.TransactionModel, error) {
+	tx, err := a.svc.CreateTransaction(cashmop.TransactionImportInput{
+		Date:        input.Date,
+		Description: input.Description,
+		Amount:      input.Amount,
+		Category:    input.Category,
+		Account:     input.Account,
+		Owner:       input.Owner,
+		Currency:    input.Currency,
+		Note:        input.Note,
+	})
+	if err != nil {
+		return tx, err
+	}
+	// Entering a transaction may create accounts, owners and categories.
+	a.emit(EventTransactionsUpdated)
+	a.emit(EventCategoriesUpdated)
+	a.emit(EventOwnersUpdated)
+	return tx, nil
+}
+
+func (a *App) UpdateTransaction(transactionID int64, update database.TransactionUpdate) (database.TransactionModel, error) {
+	tx, err := a.svc.UpdateTransaction(transactionID, update)
+	if err != nil {
+		return tx, err
+	}
+	a.emit(EventTransactionsUpdated)
+	return tx, nil
+}
+
+func (a *App) SetTransactionNote(transactionID int64, note string) error {
+	if err := a.svc.SetTransactionNote(transactionID, note); err != nil {
+		return err
+	}
+	a.emit(EventTransactionsUpdated)
+	return nil
+}
+
+func (a *App) AttachFile(transactionID int64, path string) (database.Attachment, error) {
+	if strings.TrimSpace(path) == "" {
+		return database.Attachment{}, fmt.Errorf("No file selected.")
+	}
+	res, err := a.svc.AttachFile(transactionID, path)
+	if err != nil {
+		return res, err
+	}
+	a.emit(EventTransactionsUpdated)
+	return res, nil
+}
+
+func (a *App) GetTransactionAttachments(transactionID int64) ([]database.Attachment, error) {
+	return a.svc.GetTransactionAttachments(transactionID)
+}
+
+func (a *App) SplitTransaction(transactionID int64, lines []database.SplitLine) ([]database.SplitLine, error) {
+	res, err := a.svc.SplitTransaction(transactionID, lines)
+	if err != nil {
+		return nil, err
+	}
+	// Category summaries count split lines.
+	a.emit(EventTransactionsUpdated)
+	a.emit(EventCategoriesUpdated)
+	return res, nil
+}
+
+func (a *App) UnsplitTransaction(transactionID int64) (int64, error) {
+	removed, err := a.svc.UnsplitTransaction(transactionID)
+	if err != nil {
+		return 0, err
+	}
+	if removed > 0 {
+		a.emit(EventTransactionsUpdated)
+		a.emit(EventCategoriesUpdated)
+	}
+	return removed, nil
+}
+
+func (a *App) GetTags() ([]database.Tag, error) {
+	return a.svc.GetTags()
+}
+
+func (a *App) AddTransactionTags(transactionIDs []int64, names []string) (database.TagChangeResult, error) {
+	res, err := a.svc.AddTransactionTags(transactionIDs, names)
+	if err != nil {
+		return res, err
+	}
+	if res.Changed > 0 {
+		a.emit(EventTransactionsUpdated)
+	}
+	return res, nil
+}
+
+func (a *App) RemoveTransactionTags(transactionIDs []int64, names []string) (database.TagChangeResult, error) {
+	res, err := a.svc.RemoveTransactionTags(transactionIDs, names)
+	if err != nil {
+		return res, err
+	}
+	if res.Changed > 0 {
+		a.emit(EventTransactionsUpdated)
+	}
+	return res, nil
+}
+
 func (a *App) RenameCategory(id int64, newName string) error {
 	if err := a.svc.RenameCategory(id, newName); err != nil {
 		return err
@@ -58,21 +167,60 @@ func (a *App) GetCategorySummaries() ([]database.CategorySummary, error) {
 	return a.svc.GetCategorySummaries()
 }
 
-func (a *App) CreateCategory(name string) (int64, error) {
+// CreateCategory creates a category of the given kind; an empty kind keeps the
+// default (expense).
+func (a *App) CreateCategory(name string, kind string) (int64, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind != "" && !database.IsValidCategoryKind(kind) {
+		return 0, database.ErrInvalidCategoryKind
+	}
 	id, err := a.svc.CreateCategory(name)
 	if err != nil {
 		return 0, err
 	}
+	if kind != "" {
+		if err := a.svc.SetCategoryKind(id, kind); err != nil {
+			return 0, err
+		}
+	}
 	a.emit(EventCategoriesUpdated)
 	return id, nil
 }
 
-func (a *App) DeleteCategory(id int64) (*CategoryDeleteResult, error) {
-	res, err := a.svc.DeleteCategory(id)
+func (a *App) MoveCategory(id int64, parentID *int64) error {
+	if err := a.svc.MoveCategory(id, parentID); err != nil {
+		return err
+	}
+	a.emit(EventCategoriesUpdated)
+	return nil
+}
+
+func (a *App) SetCategoryKind(id int64, kind string) error {
+	if err := a.svc.SetCategoryKind(id, kind); err != nil {
+		return err
+	}
+	a.emit(EventCategoriesUpdated)
+	return nil
+}
+
+func (a *App) MergeCategories(sourceIDs []int64, targetID int64) (database.CategoryMergeResult, error) {
+	res, err := a.svc.MergeCategories(sourceIDs, targetID)
+	if err != nil {
+		return res, err
+	}
+	if res.MovedTransactions > 0 {
+		a.emit(EventTransactionsUpdated)
+	}
+	a.emit(EventCategoriesUpdated)
+	return res, nil
+}
+
+func (a *App) DeleteCategory(id int64, reassignTo *int64) (*CategoryDeleteResult, error) {
+	res, err := a.svc.DeleteCategory(id, reassignTo)
 	if err != nil {
 		return nil, err
 	}
-	if res.UncategorizedCount > 0 {
+	if res.UncategorizedCount > 0 || res.ReassignedCount > 0 {
 		a.emit(EventTransactionsUpdated)
 	}
 	a.emit(EventCategoriesUpdated)
@@ -81,6 +229,10 @@ func (a *App) DeleteCategory(id int64) (*CategoryDeleteResult, error) {
 		CategoryName:       res.CategoryName,
 		UncategorizedCount: res.UncategorizedCount,
 		DeletedRuleCount:   res.DeletedRuleCount,
+		ReassignedToID:     res.ReassignedToID,
+		ReassignedToName:   res.ReassignedToName,
+		ReassignedCount:    res.ReassignedCount,
+		MovedRuleCount:     res.MovedRuleCount,
 	}, nil
 }
 
@@ -120,12 +272,12 @@ func (a *App) GetMonthList() ([]string, error) {
 	return a.svc.GetMonthList()
 }
 
-func (a *App) GetAnalysisTransactions(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64) ([]database.TransactionModel, error) {
-	return a.svc.GetAnalysisTransactions(startDate, endDate, categoryIDs, ownerIDs)
+func (a *App) GetAnalysisTransactions(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64, tagIDs []int64, merchantIDs []int64) ([]database.TransactionModel, error) {
+	return a.svc.GetAnalysisTransactions(startDate, endDate, categoryIDs, ownerIDs, tagIDs, merchantIDs)
 }
 
-func (a *App) GetAnalysisView(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64) (database.AnalysisView, error) {
-	transactions, err := a.svc.GetAnalysisTransactions(startDate, endDate, categoryIDs, ownerIDs)
+func (a *App) GetAnalysisView(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64, tagIDs []int64, merchantIDs []int64) (database.AnalysisView, error) {
+	transactions, err := a.svc.GetAnalysisTransactions(startDate, endDate, categoryIDs, ownerIDs, tagIDs, merchantIDs)
 	if err != nil {
 		return database.AnalysisView{}, err
 	}
@@ -133,5 +285,13 @@ func (a *App) GetAnalysisView(startDate string, endDate string, categoryIDs []in
 	if err != nil {
 		return database.AnalysisView{}, err
 	}
-	return database.AnalysisView{Transactions: transactions, Facets: facets}, nil
+	rollups, err := a.svc.GetCategoryRollups(transactions)
+	if err != nil {
+		return database.AnalysisView{}, err
+	}
+	totals, err := a.svc.GetKindTotals(transactions)
+	if err != nil {
+		return database.AnalysisView{}, err
+	}
+	return database.AnalysisView{Transactions: transactions, Facets: facets, Rollups: rollups, Totals: totals}, nil
 }
@@ -0,0 +1,30 @@
+package main
+
+import "github.com/default-anton/cashmop/internal/database"
+
+// DetectTransfers pairs transactions moving the same money between two
+// accounts and links them unless opts.DryRun is set.
+func (a *App) DetectTransfers(opts database.TransferDetectOptions) ([]database.TransferLink, error) {
+	links, err := a.svc.DetectTransfers(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.DryRun && len(links) > 0 {
+		a.emit(EventTransactionsUpdated)
+	}
+	return links, nil
+}
+
+func (a *App) GetTransferLinks() ([]database.TransferLink, error) {
+	return a.svc.GetTransferLinks()
+}
+
+// UnlinkTransfer removes a transfer link; detection leaves the pair alone
+// afterwards.
+func (a *App) UnlinkTransfer(id int64) error {
+	if err := a.svc.UnlinkTransfer(id); err != nil {
+		return err
+	}
+	a.emit(EventTransactionsUpdated)
+	return nil
+}
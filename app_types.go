@@ -14,6 +14,7 @@ type TransactionInput struct {
 	Account     string `json:"account"`
 	Owner       string `json:"owner"`
 	Currency    string `json:"currency"`
+	Note        string `json:"note"`
 }
 
 type CategorizeResult struct {
@@ -42,6 +43,10 @@ type CategoryDeleteResult struct {
 	CategoryName       string `json:"category_name"`
 	UncategorizedCount int64  `json:"uncategorized_count"`
 	DeletedRuleCount   int64  `json:"deleted_rule_count"`
+	ReassignedToID     *int64 `json:"reassigned_to_id"`
+	ReassignedToName   string `json:"reassigned_to_name,omitempty"`
+	ReassignedCount    int64  `json:"reassigned_count"`
+	MovedRuleCount     int64  `json:"moved_rule_count"`
 }
 
 type ExcelData struct {
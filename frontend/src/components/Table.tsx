@@ -30,6 +30,8 @@ interface TableProps<T> {
   sortOrder?: "asc" | "desc";
   onSort?: (key: string) => void;
   rowKey?: (row: T) => string | number;
+  // Defaults to the row key; rows sharing a selection key select together.
+  selectionKey?: (row: T) => string | number;
   selectedIds?: Set<string | number>;
   onSelectionChange?: (id: string | number, selected: boolean) => void;
 }
@@ -44,6 +46,7 @@ const Table = <T,>({
   sortOrder,
   onSort,
   rowKey,
+  selectionKey,
   selectedIds,
   onSelectionChange,
 }: TableProps<T>) => {
@@ -52,6 +55,7 @@ const Table = <T,>({
     if ((row as any).id !== undefined) return (row as any).id;
     return index;
   };
+  const getSelectionKey = (row: T, index: number) => (selectionKey ? selectionKey(row) : getRowKey(row, index));
 
   return (
     <div className={`overflow-x-auto rounded-3xl border border-canvas-200/80 bg-canvas-50/90 ${className}`}>
@@ -158,12 +162,12 @@ const Table = <T,>({
               <tr
                 key={getRowKey(row, rowIndex)}
                 className={`group transition-all duration-150 hover:bg-brand/[0.05] even:bg-canvas-100/35 ${
-                  selectedIds?.has(getRowKey(row, rowIndex)) ? "bg-brand/[0.08]" : ""
+                  selectedIds?.has(getSelectionKey(row, rowIndex)) ? "bg-brand/[0.08]" : ""
                 }`}
               >
                 {columns.map((column) => {
                   if (column.isCheckbox) {
-                    const rowKeyVal = getRowKey(row, rowIndex);
+                    const rowKeyVal = getSelectionKey(row, rowIndex);
                     return (
                       <td key="checkbox" className="w-10 px-6 py-4 text-center">
                         <input
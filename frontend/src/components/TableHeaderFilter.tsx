@@ -432,6 +432,9 @@ export const OwnerFilterContent: React.FC<{
   onSearchChange: (term: string) => void;
   inputRef?: React.RefObject<HTMLInputElement | null>;
   includeNoOwner?: boolean;
+  // The owner list also backs the tag filter; these relabel it.
+  label?: string;
+  missingLabel?: string;
 }> = ({
   owners,
   selectedIds,
@@ -443,11 +446,14 @@ export const OwnerFilterContent: React.FC<{
   onSearchChange,
   inputRef,
   includeNoOwner = true,
+  label = "Owner",
+  missingLabel = "No Owner",
 }) => {
   const [filteredOwners, setFilteredOwners] = useState(owners);
+  const plural = `${label.toLowerCase()}s`;
 
   useEffect(() => {
-    const noOwnerOption: { id: number; name: string } = { id: MISSING_FILTER_ID, name: "No Owner" };
+    const noOwnerOption: { id: number; name: string } = { id: MISSING_FILTER_ID, name: missingLabel };
 
     const prefix = includeNoOwner ? [noOwnerOption] : [];
 
@@ -463,7 +469,7 @@ export const OwnerFilterContent: React.FC<{
         .filter((o): o is { id: number; name: string } => !!o);
       setFilteredOwners([...prefix, ...ranked]);
     });
-  }, [owners, searchTerm, includeNoOwner]);
+  }, [owners, searchTerm, includeNoOwner, missingLabel]);
 
   const selectedCount = selectedIds.length;
   const totalCount = owners.length + (includeNoOwner ? 1 : 0);
@@ -474,7 +480,7 @@ export const OwnerFilterContent: React.FC<{
       <div className="p-4 border-b border-canvas-200/70 space-y-3">
         <div className="flex justify-between items-center px-1">
           <span className="text-[10px] font-extrabold text-canvas-600 uppercase tracking-[0.14em] select-none">
-            Filter by Owner
+            Filter by {label}
           </span>
           <div className="flex gap-3">
             <button
@@ -505,8 +511,8 @@ export const OwnerFilterContent: React.FC<{
           <input
             ref={inputRef}
             type="text"
-            placeholder="Search owners..."
-            aria-label="Search owners"
+            placeholder={`Search ${plural}...`}
+            aria-label={`Search ${plural}`}
             value={searchTerm}
             onChange={(e) => onSearchChange(e.target.value)}
             className="w-full bg-canvas-100 border border-canvas-200/80 rounded-xl py-2.5 pl-9 pr-4 text-sm focus:ring-2 focus:ring-brand/20 placeholder:text-canvas-500 outline-none"
@@ -548,7 +554,7 @@ export const OwnerFilterContent: React.FC<{
 
         {filteredOwners.length === 0 && (
           <div className="px-4 py-8 text-center text-canvas-500 text-sm italic select-none">
-            {searchTerm ? "No matches found" : "No owners found"}
+            {searchTerm ? "No matches found" : `No ${plural} found`}
           </div>
         )}
       </div>
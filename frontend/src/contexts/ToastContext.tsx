@@ -1,5 +1,7 @@
 import React, { createContext, type ReactNode, useCallback, useContext, useEffect, useState } from "react";
+import type { cashmop } from "../../wailsjs/go/models";
 import { EventsOn } from "../../wailsjs/runtime/runtime";
+import { EVENT_ANOMALIES_DETECTED } from "../utils/events";
 
 export type ToastType = "success" | "error" | "warning" | "info";
 
@@ -50,6 +52,19 @@ export const ToastProvider: React.FC<ToastProviderProps> = ({ children }) => {
     return () => off?.();
   }, [showToast]);
 
+  useEffect(() => {
+    const off = EventsOn(EVENT_ANOMALIES_DETECTED, (reports: cashmop.AnomalyReport[]) => {
+      const names = (reports ?? []).flatMap((r) =>
+        r.anomalies.map((a) => (a.kind === "merchant" ? a.merchant || a.description : a.category_name)),
+      );
+      if (names.length === 0) return;
+      const shown = Array.from(new Set(names));
+      const more = shown.length > 3 ? ` and ${shown.length - 3} more` : "";
+      showToast(`Unusual spending after import: ${shown.slice(0, 3).join(", ")}${more}.`, "warning", 8000);
+    });
+    return () => off?.();
+  }, [showToast]);
+
   return (
     <ToastContext.Provider value={{ showToast, removeToast }}>
       {children}
@@ -27,6 +27,8 @@ const Analysis: React.FC = () => {
   const [selectedCategoryIds, setSelectedCategoryIds] = useState<number[]>([]);
   const [owners, setOwners] = useState<database.User[]>([]);
   const [selectedOwnerIds, setSelectedOwnerIds] = useState<number[]>([]);
+  const [selectedTagIds, setSelectedTagIds] = useState<number[]>([]);
+  const [selectedMerchantIds, setSelectedMerchantIds] = useState<number[]>([]);
   const [groupBy, setGroupBy] = useState<GroupBy>("All");
   const [transactions, setTransactions] = useState<database.TransactionModel[]>([]);
   const [analysisFacets, setAnalysisFacets] = useState<database.AnalysisFacets | null>(null);
@@ -85,6 +87,8 @@ const Analysis: React.FC = () => {
           endDate,
           selectedCategoryIds,
           selectedOwnerIds,
+          selectedTagIds,
+          selectedMerchantIds,
         );
         const view = database.AnalysisView.createFrom(raw || {});
         setTransactions(view.transactions || []);
@@ -93,8 +97,12 @@ const Analysis: React.FC = () => {
             database.AnalysisFacets.createFrom({
               categories: [],
               owners: [],
+              tags: [],
+              merchants: [],
               has_uncategorized: false,
               has_no_owner: false,
+              has_untagged: false,
+              has_no_merchant: false,
             }),
         );
       } catch (e) {
@@ -104,15 +112,19 @@ const Analysis: React.FC = () => {
           database.AnalysisFacets.createFrom({
             categories: [],
             owners: [],
+            tags: [],
+            merchants: [],
             has_uncategorized: false,
             has_no_owner: false,
+            has_untagged: false,
+            has_no_merchant: false,
           }),
         );
       } finally {
         if (!silent) setLoading(false);
       }
     },
-    [selectedMonth, selectedCategoryIds, selectedOwnerIds],
+    [selectedMonth, selectedCategoryIds, selectedOwnerIds, selectedTagIds, selectedMerchantIds],
   );
 
   useEffect(() => {
@@ -221,6 +233,8 @@ const Analysis: React.FC = () => {
         endDate,
         selectedCategoryIds,
         selectedOwnerIds,
+        selectedTagIds,
+        selectedMerchantIds,
         targetFormat,
       );
 
@@ -289,6 +303,9 @@ const Analysis: React.FC = () => {
         tx.date,
         formatCentsDecimal(tx.amount),
         tx.currency || mainCurrency,
+        ...(tx.tags || []),
+        tx.note || "",
+        tx.split_note || "",
       ];
       return `${parts.join(" | ")} ::${tx.id}`;
     },
@@ -326,8 +343,14 @@ const Analysis: React.FC = () => {
 
   const filterOwners = useMemo(() => analysisFacets?.owners || [], [analysisFacets]);
 
+  const filterTags = useMemo(() => analysisFacets?.tags || [], [analysisFacets]);
+
+  const filterMerchants = useMemo(() => analysisFacets?.merchants || [], [analysisFacets]);
+
   const includeUncategorizedInFilter = analysisFacets?.has_uncategorized || false;
   const includeNoOwnerInFilter = analysisFacets?.has_no_owner || false;
+  const includeUntaggedInFilter = analysisFacets?.has_untagged || false;
+  const includeNoMerchantInFilter = analysisFacets?.has_no_merchant || false;
 
   useEffect(() => {
     if (!analysisFacets) return;
@@ -353,6 +376,30 @@ const Analysis: React.FC = () => {
     }
   }, [analysisFacets, filterOwners, includeNoOwnerInFilter, selectedOwnerIds]);
 
+  useEffect(() => {
+    if (!analysisFacets) return;
+
+    const allowed = new Set<number>(filterTags.map((t) => t.id));
+    if (includeUntaggedInFilter) allowed.add(MISSING_FILTER_ID);
+
+    const next = selectedTagIds.filter((id) => allowed.has(id));
+    if (next.length !== selectedTagIds.length) {
+      setSelectedTagIds(next);
+    }
+  }, [analysisFacets, filterTags, includeUntaggedInFilter, selectedTagIds]);
+
+  useEffect(() => {
+    if (!analysisFacets) return;
+
+    const allowed = new Set<number>(filterMerchants.map((m) => m.id));
+    if (includeNoMerchantInFilter) allowed.add(MISSING_FILTER_ID);
+
+    const next = selectedMerchantIds.filter((id) => allowed.has(id));
+    if (next.length !== selectedMerchantIds.length) {
+      setSelectedMerchantIds(next);
+    }
+  }, [analysisFacets, filterMerchants, includeNoMerchantInFilter, selectedMerchantIds]);
+
   const groupingOptions: GroupBy[] = ["All", "Category", "Owner", "Account"];
 
   return (
@@ -497,8 +544,12 @@ const Analysis: React.FC = () => {
             owners={owners}
             filterCategories={filterCategories}
             filterOwners={filterOwners}
+            filterTags={filterTags}
+            filterMerchants={filterMerchants}
             includeUncategorizedInFilter={includeUncategorizedInFilter}
             includeNoOwnerInFilter={includeNoOwnerInFilter}
+            includeUntaggedInFilter={includeUntaggedInFilter}
+            includeNoMerchantInFilter={includeNoMerchantInFilter}
             groupBy={groupBy}
             showSummary={false}
             groupSortField={groupSortField}
@@ -511,6 +562,10 @@ const Analysis: React.FC = () => {
             onCategoryFilterChange={setSelectedCategoryIds}
             selectedOwnerIds={selectedOwnerIds}
             onOwnerFilterChange={setSelectedOwnerIds}
+            selectedTagIds={selectedTagIds}
+            onTagFilterChange={setSelectedTagIds}
+            selectedMerchantIds={selectedMerchantIds}
+            onMerchantFilterChange={setSelectedMerchantIds}
             monthOptions={monthOptions}
             selectedMonth={selectedMonth}
             onMonthChange={setSelectedMonth}
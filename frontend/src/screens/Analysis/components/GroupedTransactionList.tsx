@@ -32,8 +32,12 @@ interface GroupedTransactionListProps {
   // Filter lists (used only for filter dropdown options)
   filterCategories?: { id: number; name: string }[];
   filterOwners?: { id: number; name: string }[];
+  filterTags?: { id: number; name: string }[];
+  filterMerchants?: { id: number; name: string }[];
   includeUncategorizedInFilter?: boolean;
   includeNoOwnerInFilter?: boolean;
+  includeUntaggedInFilter?: boolean;
+  includeNoMerchantInFilter?: boolean;
 
   groupBy: GroupBy;
   showSummary?: boolean;
@@ -47,6 +51,10 @@ interface GroupedTransactionListProps {
   onCategoryFilterChange?: (ids: number[]) => void;
   selectedOwnerIds?: number[];
   onOwnerFilterChange?: (ids: number[]) => void;
+  selectedTagIds?: number[];
+  onTagFilterChange?: (ids: number[]) => void;
+  selectedMerchantIds?: number[];
+  onMerchantFilterChange?: (ids: number[]) => void;
   monthOptions: { value: string; label: string }[];
   selectedMonth: string;
   onMonthChange: (month: string) => void;
@@ -55,6 +63,10 @@ interface GroupedTransactionListProps {
   onDeleteSelected?: () => void;
 }
 
+// Split transactions come back as one row per split line, all sharing the
+// transaction ID.
+const transactionRowKey = (tx: database.TransactionModel) => (tx.split_id ? `${tx.id}:${tx.split_id}` : tx.id);
+
 const EditableCategoryCell: React.FC<{
   transaction: database.TransactionModel;
   categories: database.Category[];
@@ -70,6 +82,18 @@ const EditableCategoryCell: React.FC<{
     }
   }, [showSuccess]);
 
+  if (transaction.split_id) {
+    return (
+      <span
+        className="inline-flex items-center gap-1.5 px-2.5 py-1 rounded-lg text-[15px] font-medium tracking-tight text-canvas-700 select-none"
+        title="Split line: use tx split to change"
+      >
+        {transaction.category_name || "Uncategorized"}
+        <span className="text-[10px] font-bold uppercase tracking-[0.08em] text-canvas-500">split</span>
+      </span>
+    );
+  }
+
   if (isEditing) {
     return (
       <CategoryGhostInput
@@ -113,8 +137,12 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
   owners,
   filterCategories,
   filterOwners,
+  filterTags = [],
+  filterMerchants = [],
   includeUncategorizedInFilter = true,
   includeNoOwnerInFilter = true,
+  includeUntaggedInFilter = true,
+  includeNoMerchantInFilter = true,
   groupBy,
   showSummary = true,
   groupSortField,
@@ -127,6 +155,10 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
   onCategoryFilterChange,
   selectedOwnerIds = [],
   onOwnerFilterChange,
+  selectedTagIds = [],
+  onTagFilterChange,
+  selectedMerchantIds = [],
+  onMerchantFilterChange,
   monthOptions,
   selectedMonth,
   onMonthChange,
@@ -138,9 +170,13 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
   const categoryFilterInputRef = useRef<HTMLInputElement>(null);
   const [ownerFilterSearch, setOwnerFilterSearch] = useState("");
   const ownerFilterInputRef = useRef<HTMLInputElement>(null);
+  const [tagFilterSearch, setTagFilterSearch] = useState("");
+  const tagFilterInputRef = useRef<HTMLInputElement>(null);
+  const [merchantFilterSearch, setMerchantFilterSearch] = useState("");
+  const merchantFilterInputRef = useRef<HTMLInputElement>(null);
   const [monthFilterSearch, setMonthFilterSearch] = useState("");
   const [filteredMonthOptions, setFilteredMonthOptions] = useState(monthOptions);
-  const [activeFilter, setActiveFilter] = useState<"category" | "owner" | "date" | null>(null);
+  const [activeFilter, setActiveFilter] = useState<"category" | "owner" | "tag" | "merchant" | "date" | null>(null);
   const [monthHighlightedIndex, setMonthHighlightedIndex] = useState(0);
   const { mainCurrency } = useCurrency();
 
@@ -164,6 +200,22 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
     }
   }, [activeFilter]);
 
+  useEffect(() => {
+    if (activeFilter === "tag" && tagFilterInputRef.current) {
+      setTimeout(() => {
+        tagFilterInputRef.current?.focus();
+      }, 50);
+    }
+  }, [activeFilter]);
+
+  useEffect(() => {
+    if (activeFilter === "merchant" && merchantFilterInputRef.current) {
+      setTimeout(() => {
+        merchantFilterInputRef.current?.focus();
+      }, 50);
+    }
+  }, [activeFilter]);
+
   useEffect(() => {
     if (activeFilter === "date") {
       setMonthFilterSearch("");
@@ -268,6 +320,21 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
       {
         key: "description",
         header: "Description",
+        render: (val: string, tx: database.TransactionModel) => (
+          <div className="flex flex-wrap items-center gap-1.5">
+            <span>{val}</span>
+            {tx.split_note && <span className="text-canvas-500">· {tx.split_note}</span>}
+            {(tx.tags || []).map((tag) => (
+              <span
+                key={tag}
+                className="rounded-md bg-canvas-100 px-1.5 py-0.5 text-[11px] font-semibold text-canvas-600 select-none"
+              >
+                #{tag}
+              </span>
+            ))}
+            {tx.note && <span className="basis-full text-xs italic text-canvas-500">{tx.note}</span>}
+          </div>
+        ),
       },
     ];
 
@@ -368,6 +435,18 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
     label: selectedOwnerIds.length > 0 ? `${selectedOwnerIds.length} selected` : "All",
   };
 
+  const tagFilterConfig: FilterConfig = {
+    type: "category",
+    isActive: selectedTagIds.length > 0,
+    label: selectedTagIds.length > 0 ? `${selectedTagIds.length} selected` : "All",
+  };
+
+  const merchantFilterConfig: FilterConfig = {
+    type: "category",
+    isActive: selectedMerchantIds.length > 0,
+    label: selectedMerchantIds.length > 0 ? `${selectedMerchantIds.length} selected` : "All",
+  };
+
   const getIcon = () => {
     if (groupBy === "Owner") return <User className="w-4 h-4" />;
     if (groupBy === "Category") return <Tag className="w-4 h-4" />;
@@ -375,7 +454,19 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
     return <Landmark className="w-4 h-4" />;
   };
 
-  const netTotal = transactions.reduce((sum, tx) => sum + (tx.main_amount ?? 0), 0);
+  // Mirrors database.KindTotals: transfer and excluded categories stay out of the
+  // totals, uncategorized rows count by sign.
+  const kindTotals = transactions.reduce(
+    (totals, tx) => {
+      const amount = tx.main_amount ?? 0;
+      const kind = tx.category_kind;
+      if (kind === "income" || (!kind && amount > 0)) totals.income += amount;
+      else if (kind === "expense" || (!kind && amount < 0)) totals.expenses += amount;
+      return totals;
+    },
+    { income: 0, expenses: 0 },
+  );
+  const netTotal = kindTotals.income + kindTotals.expenses;
   const selectedCount = selectedTxIds.size;
 
   return (
@@ -387,10 +478,7 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
               Total Income
             </div>
             <div className="text-2xl font-mono font-bold text-finance-income">
-              {formatCents(
-                transactions.filter((t) => (t.main_amount ?? 0) > 0).reduce((s, t) => s + (t.main_amount ?? 0), 0),
-                mainCurrency,
-              )}
+              {formatCents(kindTotals.income, mainCurrency)}
             </div>
           </Card>
           <Card variant="elevated" className="p-6 bg-gradient-to-br from-finance-expense/10 to-canvas-50">
@@ -398,10 +486,7 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
               Total Expenses
             </div>
             <div className="text-2xl font-mono font-bold text-finance-expense">
-              {formatCents(
-                transactions.filter((t) => (t.main_amount ?? 0) < 0).reduce((s, t) => s + (t.main_amount ?? 0), 0),
-                mainCurrency,
-              )}
+              {formatCents(kindTotals.expenses, mainCurrency)}
             </div>
           </Card>
           <Card
@@ -634,6 +719,94 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
               />
             </TableHeaderFilter>
           )}
+
+          {onTagFilterChange && (filterTags.length > 0 || selectedTagIds.length > 0) && (
+            <TableHeaderFilter
+              variant="bar"
+              titleLabel="Tag"
+              config={tagFilterConfig}
+              ariaLabel="Tag filter"
+              onClear={() => {
+                onTagFilterChange([]);
+                setActiveFilter(null);
+              }}
+              isOpen={activeFilter === "tag"}
+              onOpenChange={(open) => setActiveFilter(open ? "tag" : null)}
+              positionKey={`analysis-filter-tag-${groupBy}`}
+            >
+              <OwnerFilterContent
+                label="Tag"
+                missingLabel="No Tags"
+                owners={filterTags}
+                selectedIds={selectedTagIds}
+                includeNoOwner={includeUntaggedInFilter}
+                onSelect={(id) => {
+                  const newSelection = selectedTagIds.includes(id)
+                    ? selectedTagIds.filter((sid) => sid !== id)
+                    : [...selectedTagIds, id];
+                  onTagFilterChange(newSelection);
+                }}
+                onSelectOnly={(id) => {
+                  onTagFilterChange([id]);
+                }}
+                onSelectAll={() => {
+                  const ids = filterTags.map((t) => t.id);
+                  onTagFilterChange(includeUntaggedInFilter ? [MISSING_FILTER_ID, ...ids] : ids);
+                }}
+                onClear={() => {
+                  onTagFilterChange([]);
+                  setActiveFilter(null);
+                }}
+                searchTerm={tagFilterSearch}
+                onSearchChange={setTagFilterSearch}
+                inputRef={tagFilterInputRef}
+              />
+            </TableHeaderFilter>
+          )}
+
+          {onMerchantFilterChange && (filterMerchants.length > 0 || selectedMerchantIds.length > 0) && (
+            <TableHeaderFilter
+              variant="bar"
+              titleLabel="Merchant"
+              config={merchantFilterConfig}
+              ariaLabel="Merchant filter"
+              onClear={() => {
+                onMerchantFilterChange([]);
+                setActiveFilter(null);
+              }}
+              isOpen={activeFilter === "merchant"}
+              onOpenChange={(open) => setActiveFilter(open ? "merchant" : null)}
+              positionKey={`analysis-filter-merchant-${groupBy}`}
+            >
+              <OwnerFilterContent
+                label="Merchant"
+                missingLabel="No Merchant"
+                owners={filterMerchants}
+                selectedIds={selectedMerchantIds}
+                includeNoOwner={includeNoMerchantInFilter}
+                onSelect={(id) => {
+                  const newSelection = selectedMerchantIds.includes(id)
+                    ? selectedMerchantIds.filter((sid) => sid !== id)
+                    : [...selectedMerchantIds, id];
+                  onMerchantFilterChange(newSelection);
+                }}
+                onSelectOnly={(id) => {
+                  onMerchantFilterChange([id]);
+                }}
+                onSelectAll={() => {
+                  const ids = filterMerchants.map((m) => m.id);
+                  onMerchantFilterChange(includeNoMerchantInFilter ? [MISSING_FILTER_ID, ...ids] : ids);
+                }}
+                onClear={() => {
+                  onMerchantFilterChange([]);
+                  setActiveFilter(null);
+                }}
+                searchTerm={merchantFilterSearch}
+                onSearchChange={setMerchantFilterSearch}
+                inputRef={merchantFilterInputRef}
+              />
+            </TableHeaderFilter>
+          )}
         </div>
 
         {selectedCount > 0 && onDeleteSelected && (
@@ -694,6 +867,8 @@ const GroupedTransactionList: React.FC<GroupedTransactionListProps> = ({
                   sortField={transactionSortField}
                   sortOrder={transactionSortOrder}
                   onSort={(field) => onSortTransaction(field as TransactionSortField)}
+                  rowKey={transactionRowKey}
+                  selectionKey={(tx) => tx.id}
                   selectedIds={selectedTxIds}
                   onSelectionChange={onSelectionChange}
                 />
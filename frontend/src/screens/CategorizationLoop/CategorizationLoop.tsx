@@ -46,6 +46,7 @@ interface UndoState {
 interface Category {
   id: number;
   name: string;
+  full_name?: string;
 }
 
 interface CategorizationLoopProps {
@@ -6,6 +6,7 @@ import { Button, Card } from "../../../components";
 interface Category {
   id: number;
   name: string;
+  full_name?: string;
 }
 
 interface CategoryInputProps {
@@ -109,7 +110,7 @@ export const CategoryInput: React.FC<CategoryInputProps> = ({
           }}
           className="group flex w-full items-center justify-between border-b border-canvas-100 px-4 py-2.5 text-left text-sm font-semibold text-canvas-700 transition-colors hover:bg-brand/5 hover:text-brand last:border-0"
         >
-          {s.name}
+          {s.full_name || s.name}
           <ArrowRight className="h-4 w-4 text-canvas-400 transition-colors group-hover:text-brand" />
         </button>
       ))}
@@ -64,9 +64,11 @@ const CategoryManager: React.FC<CategoryManagerProps> = ({ onViewRules }) => {
 
   const [createModalOpen, setCreateModalOpen] = useState(false);
   const [newCategoryName, setNewCategoryName] = useState("");
+  const [newCategoryKind, setNewCategoryKind] = useState("expense");
   const [creating, setCreating] = useState(false);
 
   const [deleteCategory, setDeleteCategory] = useState<CategorySummary | null>(null);
+  const [reassignTo, setReassignTo] = useState<number | null>(null);
   const [deletingCategory, setDeletingCategory] = useState(false);
 
   const [rulesCategory, setRulesCategory] = useState<CategorySummary | null>(null);
@@ -264,9 +266,10 @@ const CategoryManager: React.FC<CategoryManagerProps> = ({ onViewRules }) => {
 
     setCreating(true);
     try {
-      await (window as any).go.main.App.CreateCategory(name);
+      await (window as any).go.main.App.CreateCategory(name, newCategoryKind);
       setCreateModalOpen(false);
       setNewCategoryName("");
+      setNewCategoryKind("expense");
       showToast(`Category ${name} is ready`, "success");
       await fetchData(true);
     } catch (error) {
@@ -282,20 +285,29 @@ const CategoryManager: React.FC<CategoryManagerProps> = ({ onViewRules }) => {
 
     setDeletingCategory(true);
     try {
-      const res = await (window as any).go.main.App.DeleteCategory(deleteCategory.id);
-      const uncategorizedCount = res?.uncategorized_count || 0;
+      const res = await (window as any).go.main.App.DeleteCategory(deleteCategory.id, reassignTo);
       const deletedRuleCount = res?.deleted_rule_count || 0;
 
-      showToast(
-        `Deleted ${deleteCategory.name}: ${uncategorizedCount} uncategorized, ${deletedRuleCount} rule${deletedRuleCount !== 1 ? "s" : ""} removed`,
-        "success",
-      );
+      if (res?.reassigned_to_id) {
+        const movedRuleCount = res?.moved_rule_count || 0;
+        showToast(
+          `Deleted ${deleteCategory.name}: ${res.reassigned_count || 0} moved to ${res.reassigned_to_name}, ${movedRuleCount} rule${movedRuleCount !== 1 ? "s" : ""} moved`,
+          "success",
+        );
+      } else {
+        const uncategorizedCount = res?.uncategorized_count || 0;
+        showToast(
+          `Deleted ${deleteCategory.name}: ${uncategorizedCount} uncategorized, ${deletedRuleCount} rule${deletedRuleCount !== 1 ? "s" : ""} removed`,
+          "success",
+        );
+      }
 
       if (rulesCategory?.id === deleteCategory.id) {
         setRulesCategory(null);
       }
 
       setDeleteCategory(null);
+      setReassignTo(null);
       await fetchData(true);
     } catch (error) {
       console.error("Failed to delete category", error);
@@ -354,7 +366,10 @@ const CategoryManager: React.FC<CategoryManagerProps> = ({ onViewRules }) => {
       setEditName(category.name);
     },
     onOpenRules: setRulesCategory,
-    onDeleteCategory: setDeleteCategory,
+    onDeleteCategory: (category: CategorySummary) => {
+      setReassignTo(null);
+      setDeleteCategory(category);
+    },
     formatDate,
   });
 
@@ -401,14 +416,19 @@ const CategoryManager: React.FC<CategoryManagerProps> = ({ onViewRules }) => {
       <CreateCategoryModal
         isOpen={createModalOpen}
         value={newCategoryName}
+        kind={newCategoryKind}
         creating={creating}
         onChange={setNewCategoryName}
+        onKindChange={setNewCategoryKind}
         onCreate={handleCreateCategory}
         onClose={() => setCreateModalOpen(false)}
       />
 
       <DeleteCategoryModal
         category={deleteCategory}
+        categories={categories}
+        reassignTo={reassignTo}
+        onReassignToChange={setReassignTo}
         deleting={deletingCategory}
         onClose={() => setDeleteCategory(null)}
         onDelete={handleDeleteCategory}
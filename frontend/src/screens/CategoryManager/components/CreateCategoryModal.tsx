@@ -1,12 +1,21 @@
 import type React from "react";
 import { useEffect } from "react";
-import { Button, Input, Modal } from "../../../components";
+import { Button, Input, Modal, Select } from "../../../components";
+
+const KIND_OPTIONS = [
+  { value: "expense", label: "Expense" },
+  { value: "income", label: "Income" },
+  { value: "transfer", label: "Transfer" },
+  { value: "excluded", label: "Excluded" },
+];
 
 interface CreateCategoryModalProps {
   isOpen: boolean;
   value: string;
+  kind: string;
   creating: boolean;
   onChange: (value: string) => void;
+  onKindChange: (kind: string) => void;
   onCreate: () => void;
   onClose: () => void;
 }
@@ -14,8 +23,10 @@ interface CreateCategoryModalProps {
 const CreateCategoryModal: React.FC<CreateCategoryModalProps> = ({
   isOpen,
   value,
+  kind,
   creating,
   onChange,
+  onKindChange,
   onCreate,
   onClose,
 }) => {
@@ -55,6 +66,13 @@ const CreateCategoryModal: React.FC<CreateCategoryModalProps> = ({
           className="w-full"
           autoFocus
         />
+        <Select
+          value={kind}
+          onChange={(event) => onKindChange(event.target.value)}
+          options={KIND_OPTIONS}
+          aria-label="New category kind"
+          className="w-full"
+        />
         <div className="flex justify-end gap-2">
           <Button variant="secondary" onClick={onClose}>
             Cancel
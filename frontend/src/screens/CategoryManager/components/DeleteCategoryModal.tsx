@@ -1,16 +1,27 @@
 import type React from "react";
 import { useEffect } from "react";
-import { Button, Modal } from "../../../components";
+import { Button, Modal, Select } from "../../../components";
 import type { CategorySummary } from "../types";
 
 interface DeleteCategoryModalProps {
   category: CategorySummary | null;
+  categories: CategorySummary[];
+  reassignTo: number | null;
   deleting: boolean;
+  onReassignToChange: (id: number | null) => void;
   onClose: () => void;
   onDelete: () => void;
 }
 
-const DeleteCategoryModal: React.FC<DeleteCategoryModalProps> = ({ category, deleting, onClose, onDelete }) => {
+const DeleteCategoryModal: React.FC<DeleteCategoryModalProps> = ({
+  category,
+  categories,
+  reassignTo,
+  deleting,
+  onReassignToChange,
+  onClose,
+  onDelete,
+}) => {
   useEffect(() => {
     if (!category) return;
 
@@ -33,21 +44,53 @@ const DeleteCategoryModal: React.FC<DeleteCategoryModalProps> = ({ category, del
     };
   }, [category, deleting, onClose, onDelete]);
 
+  const transactionCount = category?.transaction_count || 0;
+  const ruleCount = category?.rule_count || 0;
+  const target = categories.find((c) => c.id === reassignTo);
+  const targetOptions = categories
+    .filter((c) => c.id !== category?.id)
+    .map((c) => ({ value: String(c.id), label: c.full_name || c.name }));
+
   return (
     <Modal isOpen={!!category} onClose={onClose} title="Delete Category" size="sm">
       <div className="space-y-4">
         <p className="text-sm text-canvas-600 select-none">
-          Delete <span className="font-semibold text-canvas-800">{category?.name}</span>? This will remove all linked
-          rules and uncategorize its transactions.
+          Delete <span className="font-semibold text-canvas-800">{category?.name}</span>?{" "}
+          {target
+            ? "Its transactions and rules will move to another category."
+            : "This will remove all linked rules and uncategorize its transactions."}
         </p>
+        <label className="block space-y-1 text-sm text-canvas-600 select-none">
+          <span>Move transactions and rules to</span>
+          <Select
+            className="w-full"
+            value={reassignTo === null ? "" : String(reassignTo)}
+            placeholder="Nothing (uncategorize)"
+            options={targetOptions}
+            onChange={(event) => onReassignToChange(event.target.value ? Number(event.target.value) : null)}
+          />
+        </label>
         <div className="rounded-xl border border-canvas-200 bg-canvas-100/60 p-3 text-sm text-canvas-600 select-none">
-          <p>
-            {category?.transaction_count || 0} transaction{(category?.transaction_count || 0) !== 1 ? "s" : ""} will
-            become uncategorized.
-          </p>
-          <p>
-            {category?.rule_count || 0} rule{(category?.rule_count || 0) !== 1 ? "s" : ""} will be deleted.
-          </p>
+          {target ? (
+            <>
+              <p>
+                {transactionCount} transaction{transactionCount !== 1 ? "s" : ""} will move to{" "}
+                {target.full_name || target.name}.
+              </p>
+              <p>
+                {ruleCount} rule{ruleCount !== 1 ? "s" : ""} will move with them.
+              </p>
+            </>
+          ) : (
+            <>
+              <p>
+                {transactionCount} transaction{transactionCount !== 1 ? "s" : ""} will become uncategorized.
+              </p>
+              <p>
+                {ruleCount} rule{ruleCount !== 1 ? "s" : ""} will be deleted.
+              </p>
+            </>
+          )}
         </div>
         <div className="flex justify-end gap-2">
           <Button variant="secondary" onClick={onClose}>
@@ -58,7 +101,7 @@ const DeleteCategoryModal: React.FC<DeleteCategoryModalProps> = ({ category, del
             disabled={deleting}
             className="!bg-finance-expense hover:!bg-finance-expense/90 !text-white disabled:opacity-60"
           >
-            {deleting ? "Deleting..." : "Delete + Uncategorize"}
+            {deleting ? "Deleting..." : target ? "Delete + Reassign" : "Delete + Uncategorize"}
           </Button>
         </div>
       </div>
@@ -1,6 +1,9 @@
 export type CategorySummary = {
   id: number;
   name: string;
+  parent_id?: number | null;
+  full_name?: string;
+  kind?: string;
   transaction_count: number;
   rule_count: number;
   last_used_date: string;
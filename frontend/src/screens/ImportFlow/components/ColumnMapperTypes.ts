@@ -5,6 +5,7 @@ export type CsvFieldKey =
   | "owner"
   | "account"
   | "currency"
+  | "memo"
   | "debit"
   | "credit"
   | "amountColumn"
@@ -32,6 +33,7 @@ export type ImportMapping = {
     amountMapping: AmountMapping;
     account?: string;
     currency?: string;
+    memo?: string; // Imported as the transaction note
   };
   account: string;
   owner?: string;
@@ -25,6 +25,7 @@ export const useColumnMapping = (initialMapping?: ImportMapping, defaultCurrency
     if (mapping.csv.date) used.add(mapping.csv.date);
     if (mapping.csv.account) used.add(mapping.csv.account);
     if (mapping.csv.currency) used.add(mapping.csv.currency);
+    if (mapping.csv.memo) used.add(mapping.csv.memo);
     mapping.csv.description.forEach((h) => {
       used.add(h);
     });
@@ -85,6 +86,7 @@ export const useColumnMapping = (initialMapping?: ImportMapping, defaultCurrency
       if (next.csv.date === header) next.csv.date = "";
       if (next.csv.account === header) delete next.csv.account;
       if (next.csv.currency === header) delete next.csv.currency;
+      if (next.csv.memo === header) delete next.csv.memo;
 
       // Clear from amountMapping
       const am = next.csv.amountMapping;
@@ -130,6 +132,7 @@ export const useColumnMapping = (initialMapping?: ImportMapping, defaultCurrency
         if (next.csv.date === h) next.csv.date = "";
         if (next.csv.account === h) delete next.csv.account;
         if (next.csv.currency === h) delete next.csv.currency;
+        if (next.csv.memo === h) delete next.csv.memo;
         next.csv.description = next.csv.description.filter((x: string) => x !== h);
         // Clear from amountMapping
         const am = next.csv.amountMapping;
@@ -50,6 +50,7 @@ export const getMappedHeaders = (mapping: ImportMapping) => {
   if (csv.date) set.add(csv.date);
   if (csv.account) set.add(csv.account);
   if (csv.currency) set.add(csv.currency);
+  if (csv.memo) set.add(csv.memo);
   for (const h of csv.description) {
     set.add(h);
   }
@@ -107,6 +108,7 @@ export const getHeaderRole = (mapping: ImportMapping, header: string): ColumnRol
   if (mapping.csv.description.includes(header)) return "description";
   if (mapping.csv.account === header) return "account";
   if (mapping.csv.currency === header) return "currency";
+  if (mapping.csv.memo === header) return "memo";
 
   const am = mapping.csv.amountMapping;
   if (am.type === "single" && am.column === header) return "money";
@@ -130,6 +132,7 @@ export const buildRoleOptions = (hasDirection: boolean) => {
     { value: "description", label: "Description" },
     { value: "account", label: "Account" },
     { value: "currency", label: "Currency" },
+    { value: "memo", label: "Memo (notes)" },
     { value: "money", label: moneyLabel },
     { value: "moneyOut", label: "Money out" },
     { value: "moneyIn", label: "Money in" },
@@ -171,6 +174,7 @@ export const applyPresetToHeaders = (mapping: ImportMapping, headers: string[])
       description: (csv.description || []).filter((d) => headerSet.has(d)),
       account: csv.account && headerSet.has(csv.account) ? csv.account : undefined,
       currency: csv.currency && headerSet.has(csv.currency) ? csv.currency : undefined,
+      memo: csv.memo && headerSet.has(csv.memo) ? csv.memo : undefined,
       amountMapping: nextAmountMapping,
     },
   };
@@ -236,6 +240,7 @@ export const applyRoleChange = (prev: ImportMapping, header: string, role: Colum
   if (next.csv.date === header) next.csv.date = "";
   if (next.csv.account === header) delete next.csv.account;
   if (next.csv.currency === header) delete next.csv.currency;
+  if (next.csv.memo === header) delete next.csv.memo;
 
   const am = next.csv.amountMapping;
   if (am.type === "single" && am.column === header) {
@@ -285,6 +290,11 @@ export const applyRoleChange = (prev: ImportMapping, header: string, role: Colum
     return normalizeAmountMapping(next);
   }
 
+  if (role === "memo") {
+    next.csv.memo = header;
+    return normalizeAmountMapping(next);
+  }
+
   if (role === "money") {
     if (directionMapped) {
       const existing = prevAm.type === "amountWithType" ? prevAm : null;
@@ -357,6 +367,7 @@ export const normalizeTransactions = (pf: ParsedFile, m: ImportMapping, months:
 
   const accountIdx = m.csv.account ? headers.indexOf(m.csv.account) : -1;
   const currencyIdx = m.csv.currency ? headers.indexOf(m.csv.currency) : -1;
+  const memoIdx = m.csv.memo ? headers.indexOf(m.csv.memo) : -1;
 
   for (const row of pf.rows) {
     const dStr = row[dateIdx];
@@ -390,6 +401,7 @@ export const normalizeTransactions = (pf: ParsedFile, m: ImportMapping, months:
       account: accountIdx !== -1 ? row[accountIdx] : m.account,
       owner: m.owner || "Unassigned",
       currency: currency || m.currencyDefault,
+      note: memoIdx !== -1 ? (row[memoIdx] ?? "").trim() : "",
     });
   }
 
@@ -68,6 +68,7 @@ export const rebindMappingToHeaders = (m: ImportMapping, fileHeaders: string[])
       description: (csv.description || []).map((d) => resolve(d) || d),
       account: csv.account ? resolve(csv.account) : undefined,
       currency: csv.currency ? resolve(csv.currency) : undefined,
+      memo: csv.memo ? resolve(csv.memo) : undefined,
       amountMapping,
     },
   };
@@ -118,6 +119,7 @@ export const heuristicPrefillMapping = (headers: string[], baseMapping: ImportMa
   next.csv.description = [];
   next.csv.account = undefined;
   next.csv.currency = undefined;
+  next.csv.memo = undefined;
   next.csv.amountMapping = { type: "single", column: "", invertSign: next.csv.amountMapping.invertSign ?? false };
 
   const used = new Set<string>();
@@ -25,7 +25,8 @@ export type ColumnRole =
   | "moneyIn"
   | "direction"
   | "account"
-  | "currency";
+  | "currency"
+  | "memo";
 
 export type MonthOption = {
   key: string;
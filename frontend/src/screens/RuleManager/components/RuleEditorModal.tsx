@@ -432,7 +432,7 @@ const RuleEditorModal: React.FC<RuleEditorModalProps> = ({
                   setCategoryId(id);
                 }
               }}
-              options={categorySuggestions.map((cat) => ({ value: String(cat.id), label: cat.name }))}
+              options={categorySuggestions.map((cat) => ({ value: String(cat.id), label: cat.full_name || cat.name }))}
               placeholder="Search categories..."
               aria-label="Category for rule"
               filterMode="none"
@@ -1,3 +1,4 @@
 export const EVENT_TRANSACTIONS_UPDATED = "transactions-updated";
 export const EVENT_CATEGORIES_UPDATED = "categories-updated";
 export const EVENT_OWNERS_UPDATED = "owners-updated";
+export const EVENT_ANOMALIES_DETECTED = "anomalies-detected";
@@ -1,29 +1,52 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
-import {main} from '../models';
 import {database} from '../models';
+import {main} from '../models';
+import {cashmop} from '../models';
+
+export function AddMerchantAlias(arg1:number,arg2:database.MerchantAlias):Promise<database.Merchant>;
+
+export function AddTransactionTags(arg1:Array<number>,arg2:Array<string>):Promise<database.TagChangeResult>;
+
+export function AttachFile(arg1:number,arg2:string):Promise<database.Attachment>;
 
 export function CategorizeTransaction(arg1:number,arg2:string):Promise<main.CategorizeResult>;
 
 export function CreateAccount(arg1:string):Promise<number>;
 
-export function CreateCategory(arg1:string):Promise<number>;
+export function CreateBudget(arg1:database.Budget):Promise<database.Budget>;
+
+export function CreateCategory(arg1:string,arg2:string):Promise<number>;
 
 export function CreateManualBackup():Promise<string>;
 
+export function CreateMerchant(arg1:string,arg2:Array<database.MerchantAlias>):Promise<database.Merchant>;
+
 export function CreateOwner(arg1:string):Promise<number>;
 
+export function CreateTransaction(arg1:main.TransactionInput):Promise<database.TransactionModel>;
+
+export function DeleteBalanceCheckpoint(arg1:number):Promise<void>;
+
+export function DeleteBudget(arg1:number):Promise<void>;
+
 export function DeleteCategorizationRule(arg1:number,arg2:boolean):Promise<main.RuleDeleteResult>;
 
-export function DeleteCategory(arg1:number):Promise<main.CategoryDeleteResult>;
+export function DeleteCategory(arg1:number,arg2:any):Promise<main.CategoryDeleteResult>;
 
 export function DeleteColumnMapping(arg1:number):Promise<void>;
 
+export function DeleteMerchant(arg1:number):Promise<void>;
+
+export function DeleteMerchantAlias(arg1:number):Promise<database.Merchant>;
+
 export function DeleteTransactions(arg1:Array<number>):Promise<number>;
 
-export function ExportTransactions(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>,arg5:string,arg6:string):Promise<number>;
+export function DetectTransfers(arg1:database.TransferDetectOptions):Promise<Array<database.TransferLink>>;
 
-export function ExportTransactionsWithDialog(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>,arg5:string):Promise<number>;
+export function ExportTransactions(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>,arg5:Array<number>,arg6:Array<number>,arg7:string,arg8:string):Promise<number>;
+
+export function ExportTransactionsWithDialog(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>,arg5:Array<number>,arg6:Array<number>,arg7:string):Promise<number>;
 
 export function FuzzySearch(arg1:string,arg2:Array<string>):Promise<Array<string>>;
 
@@ -31,9 +54,17 @@ export function GetAccounts():Promise<Array<string>>;
 
 export function GetAllUsers():Promise<Array<database.User>>;
 
-export function GetAnalysisTransactions(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>):Promise<Array<database.TransactionModel>>;
+export function GetAnalysisTransactions(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>,arg5:Array<number>,arg6:Array<number>):Promise<Array<database.TransactionModel>>;
+
+export function GetAnalysisView(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>,arg5:Array<number>,arg6:Array<number>):Promise<database.AnalysisView>;
+
+export function GetAnomalies(arg1:string):Promise<cashmop.AnomalyReport>;
 
-export function GetAnalysisView(arg1:string,arg2:string,arg3:Array<number>,arg4:Array<number>):Promise<database.AnalysisView>;
+export function GetBalanceCheckpoints(arg1:any):Promise<Array<database.BalanceCheckpoint>>;
+
+export function GetBudgetReport(arg1:string,arg2:string):Promise<database.BudgetReport>;
+
+export function GetBudgets():Promise<Array<database.Budget>>;
 
 export function GetCategories():Promise<Array<database.Category>>;
 
@@ -45,22 +76,46 @@ export function GetCategorySummaries():Promise<Array<database.CategorySummary>>;
 
 export function GetColumnMappings():Promise<Array<database.ColumnMappingModel>>;
 
+export function GetComparison(arg1:cashmop.ComparisonOptions):Promise<cashmop.Comparison>;
+
 export function GetCurrencySettings():Promise<database.CurrencySettings>;
 
+export function GetForecast(arg1:number):Promise<cashmop.Forecast>;
+
 export function GetFxRate(arg1:string,arg2:string,arg3:string):Promise<database.FxRateLookup>;
 
 export function GetFxRateStatus():Promise<database.FxRateStatus>;
 
 export function GetLastBackupInfo():Promise<Record<string, any>>;
 
+export function GetMerchantReport(arg1:cashmop.MerchantReportOptions):Promise<cashmop.MerchantReport>;
+
+export function GetMerchants():Promise<Array<database.Merchant>>;
+
 export function GetMonthList():Promise<Array<string>>;
 
+export function GetNetWorth(arg1:string,arg2:string):Promise<database.NetWorth>;
+
 export function GetOwners():Promise<Array<string>>;
 
+export function GetRecurringSeries(arg1:boolean):Promise<Array<cashmop.RecurringSeries>>;
+
 export function GetRuleAmountRange(arg1:string,arg2:string):Promise<database.AmountRange>;
 
 export function GetRuleMatchCount(arg1:number):Promise<number>;
 
+export function GetSettlement(arg1:cashmop.SettlementOptions):Promise<cashmop.Settlement>;
+
+export function GetSummary(arg1:database.SummaryOptions):Promise<database.Summary>;
+
+export function GetTags():Promise<Array<database.Tag>>;
+
+export function GetTransactionAttachments(arg1:number):Promise<Array<database.Attachment>>;
+
+export function GetTransactionSplits(arg1:number):Promise<Array<database.SplitLine>>;
+
+export function GetTransferLinks():Promise<Array<database.TransferLink>>;
+
 export function GetUncategorizedTransactions():Promise<Array<database.TransactionModel>>;
 
 export function GetVersion():Promise<string>;
@@ -71,14 +126,26 @@ export function ImportTransactions(arg1:Array<main.TransactionInput>):Promise<vo
 
 export function IsTestEnv():Promise<boolean>;
 
+export function MergeCategories(arg1:Array<number>,arg2:number):Promise<database.CategoryMergeResult>;
+
+export function MoveCategory(arg1:number,arg2:any):Promise<void>;
+
 export function OpenBackupFolder():Promise<string>;
 
 export function ParseExcel(arg1:string):Promise<main.ExcelData>;
 
 export function PreviewRuleMatches(arg1:string,arg2:string,arg3:any,arg4:any):Promise<database.RuleMatchPreview>;
 
+export function ReapplyRules(arg1:Array<number>,arg2:string,arg3:boolean):Promise<database.RuleApplyResult>;
+
+export function ReconcileAccounts(arg1:any):Promise<Array<database.AccountReconciliation>>;
+
+export function RemoveTransactionTags(arg1:Array<number>,arg2:Array<string>):Promise<database.TagChangeResult>;
+
 export function RenameCategory(arg1:number,arg2:string):Promise<void>;
 
+export function RenameMerchant(arg1:number,arg2:string):Promise<database.Merchant>;
+
 export function RestoreBackup(arg1:string):Promise<void>;
 
 export function RestoreBackupFromDialog():Promise<string>;
@@ -95,10 +162,24 @@ export function SearchWeb(arg1:string):Promise<Array<main.WebSearchResult>>;
 
 export function SelectBackupFile():Promise<database.BackupMetadata>;
 
+export function SetBalanceCheckpoint(arg1:string,arg2:database.BalanceCheckpoint):Promise<database.BalanceCheckpoint>;
+
+export function SetCategoryKind(arg1:number,arg2:string):Promise<void>;
+
+export function SetKnownSubscription(arg1:string,arg2:boolean):Promise<void>;
+
 export function SetTestDialogPaths(arg1:main.TestDialogPaths):Promise<void>;
 
+export function SetTransactionNote(arg1:number,arg2:string):Promise<void>;
+
 export function ShowAbout():Promise<void>;
 
+export function SplitTransaction(arg1:number,arg2:Array<database.SplitLine>):Promise<Array<database.SplitLine>>;
+
+export function SuggestCategorizationRules(arg1:cashmop.RuleSuggestionOptions):Promise<Array<cashmop.RuleSuggestion>>;
+
+export function SuggestMerchants(arg1:cashmop.MerchantSuggestionOptions):Promise<Array<cashmop.MerchantSuggestion>>;
+
 export function SyncFxRates():Promise<void>;
 
 export function SyncFxRatesNow():Promise<void>;
@@ -107,8 +188,18 @@ export function TriggerAutoBackup():Promise<string>;
 
 export function UndoCategorizationRule(arg1:number,arg2:Array<number>):Promise<void>;
 
+export function UndoRuleApply(arg1:number):Promise<database.RuleApplyUndoResult>;
+
+export function UnlinkTransfer(arg1:number):Promise<void>;
+
+export function UnsplitTransaction(arg1:number):Promise<number>;
+
+export function UpdateBudget(arg1:number,arg2:database.BudgetUpdate):Promise<database.Budget>;
+
 export function UpdateCategorizationRule(arg1:database.CategorizationRule,arg2:boolean):Promise<main.RuleUpdateResult>;
 
 export function UpdateCurrencySettings(arg1:database.CurrencySettings):Promise<database.CurrencySettings>;
 
+export function UpdateTransaction(arg1:number,arg2:database.TransactionUpdate):Promise<database.TransactionModel>;
+
 export function ValidateBackupFile(arg1:string):Promise<database.BackupMetadata>;
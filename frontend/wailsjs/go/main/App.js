@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddMerchantAlias(arg1, arg2) {
+  return window['go']['main']['App']['AddMerchantAlias'](arg1, arg2);
+}
+
+export function AddTransactionTags(arg1, arg2) {
+  return window['go']['main']['App']['AddTransactionTags'](arg1, arg2);
+}
+
+export function AttachFile(arg1, arg2) {
+  return window['go']['main']['App']['AttachFile'](arg1, arg2);
+}
+
 export function CategorizeTransaction(arg1, arg2) {
   return window['go']['main']['App']['CategorizeTransaction'](arg1, arg2);
 }
@@ -10,40 +22,72 @@ export function CreateAccount(arg1) {
   return window['go']['main']['App']['CreateAccount'](arg1);
 }
 
-export function CreateCategory(arg1) {
-  return window['go']['main']['App']['CreateCategory'](arg1);
+export function CreateBudget(arg1) {
+  return window['go']['main']['App']['CreateBudget'](arg1);
+}
+
+export function CreateCategory(arg1, arg2) {
+  return window['go']['main']['App']['CreateCategory'](arg1, arg2);
 }
 
 export function CreateManualBackup() {
   return window['go']['main']['App']['CreateManualBackup']();
 }
 
+export function CreateMerchant(arg1, arg2) {
+  return window['go']['main']['App']['CreateMerchant'](arg1, arg2);
+}
+
 export function CreateOwner(arg1) {
   return window['go']['main']['App']['CreateOwner'](arg1);
 }
 
+export function CreateTransaction(arg1) {
+  return window['go']['main']['App']['CreateTransaction'](arg1);
+}
+
+export function DeleteBalanceCheckpoint(arg1) {
+  return window['go']['main']['App']['DeleteBalanceCheckpoint'](arg1);
+}
+
+export function DeleteBudget(arg1) {
+  return window['go']['main']['App']['DeleteBudget'](arg1);
+}
+
 export function DeleteCategorizationRule(arg1, arg2) {
   return window['go']['main']['App']['DeleteCategorizationRule'](arg1, arg2);
 }
 
-export function DeleteCategory(arg1) {
-  return window['go']['main']['App']['DeleteCategory'](arg1);
+export function DeleteCategory(arg1, arg2) {
+  return window['go']['main']['App']['DeleteCategory'](arg1, arg2);
 }
 
 export function DeleteColumnMapping(arg1) {
   return window['go']['main']['App']['DeleteColumnMapping'](arg1);
 }
 
+export function DeleteMerchant(arg1) {
+  return window['go']['main']['App']['DeleteMerchant'](arg1);
+}
+
+export function DeleteMerchantAlias(arg1) {
+  return window['go']['main']['App']['DeleteMerchantAlias'](arg1);
+}
+
 export function DeleteTransactions(arg1) {
   return window['go']['main']['App']['DeleteTransactions'](arg1);
 }
 
-export function ExportTransactions(arg1, arg2, arg3, arg4, arg5, arg6) {
-  return window['go']['main']['App']['ExportTransactions'](arg1, arg2, arg3, arg4, arg5, arg6);
+export function DetectTransfers(arg1) {
+  return window['go']['main']['App']['DetectTransfers'](arg1);
 }
 
-export function ExportTransactionsWithDialog(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['main']['App']['ExportTransactionsWithDialog'](arg1, arg2, arg3, arg4, arg5);
+export function ExportTransactions(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['ExportTransactions'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function ExportTransactionsWithDialog(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['ExportTransactionsWithDialog'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
 
 export function FuzzySearch(arg1, arg2) {
@@ -58,12 +102,28 @@ export function GetAllUsers() {
   return window['go']['main']['App']['GetAllUsers']();
 }
 
-export function GetAnalysisTransactions(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['GetAnalysisTransactions'](arg1, arg2, arg3, arg4);
+export function GetAnalysisTransactions(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['GetAnalysisTransactions'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
-export function GetAnalysisView(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['GetAnalysisView'](arg1, arg2, arg3, arg4);
+export function GetAnalysisView(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['GetAnalysisView'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function GetAnomalies(arg1) {
+  return window['go']['main']['App']['GetAnomalies'](arg1);
+}
+
+export function GetBalanceCheckpoints(arg1) {
+  return window['go']['main']['App']['GetBalanceCheckpoints'](arg1);
+}
+
+export function GetBudgetReport(arg1, arg2) {
+  return window['go']['main']['App']['GetBudgetReport'](arg1, arg2);
+}
+
+export function GetBudgets() {
+  return window['go']['main']['App']['GetBudgets']();
 }
 
 export function GetCategories() {
@@ -86,10 +146,18 @@ export function GetColumnMappings() {
   return window['go']['main']['App']['GetColumnMappings']();
 }
 
+export function GetComparison(arg1) {
+  return window['go']['main']['App']['GetComparison'](arg1);
+}
+
 export function GetCurrencySettings() {
   return window['go']['main']['App']['GetCurrencySettings']();
 }
 
+export function GetForecast(arg1) {
+  return window['go']['main']['App']['GetForecast'](arg1);
+}
+
 export function GetFxRate(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetFxRate'](arg1, arg2, arg3);
 }
@@ -102,14 +170,30 @@ export function GetLastBackupInfo() {
   return window['go']['main']['App']['GetLastBackupInfo']();
 }
 
+export function GetMerchantReport(arg1) {
+  return window['go']['main']['App']['GetMerchantReport'](arg1);
+}
+
+export function GetMerchants() {
+  return window['go']['main']['App']['GetMerchants']();
+}
+
 export function GetMonthList() {
   return window['go']['main']['App']['GetMonthList']();
 }
 
+export function GetNetWorth(arg1, arg2) {
+  return window['go']['main']['App']['GetNetWorth'](arg1, arg2);
+}
+
 export function GetOwners() {
   return window['go']['main']['App']['GetOwners']();
 }
 
+export function GetRecurringSeries(arg1) {
+  return window['go']['main']['App']['GetRecurringSeries'](arg1);
+}
+
 export function GetRuleAmountRange(arg1, arg2) {
   return window['go']['main']['App']['GetRuleAmountRange'](arg1, arg2);
 }
@@ -118,6 +202,30 @@ export function GetRuleMatchCount(arg1) {
   return window['go']['main']['App']['GetRuleMatchCount'](arg1);
 }
 
+export function GetSettlement(arg1) {
+  return window['go']['main']['App']['GetSettlement'](arg1);
+}
+
+export function GetSummary(arg1) {
+  return window['go']['main']['App']['GetSummary'](arg1);
+}
+
+export function GetTags() {
+  return window['go']['main']['App']['GetTags']();
+}
+
+export function GetTransactionAttachments(arg1) {
+  return window['go']['main']['App']['GetTransactionAttachments'](arg1);
+}
+
+export function GetTransactionSplits(arg1) {
+  return window['go']['main']['App']['GetTransactionSplits'](arg1);
+}
+
+export function GetTransferLinks() {
+  return window['go']['main']['App']['GetTransferLinks']();
+}
+
 export function GetUncategorizedTransactions() {
   return window['go']['main']['App']['GetUncategorizedTransactions']();
 }
@@ -138,6 +246,14 @@ export function IsTestEnv() {
   return window['go']['main']['App']['IsTestEnv']();
 }
 
+export function MergeCategories(arg1, arg2) {
+  return window['go']['main']['App']['MergeCategories'](arg1, arg2);
+}
+
+export function MoveCategory(arg1, arg2) {
+  return window['go']['main']['App']['MoveCategory'](arg1, arg2);
+}
+
 export function OpenBackupFolder() {
   return window['go']['main']['App']['OpenBackupFolder']();
 }
@@ -150,10 +266,26 @@ export function PreviewRuleMatches(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['PreviewRuleMatches'](arg1, arg2, arg3, arg4);
 }
 
+export function ReapplyRules(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ReapplyRules'](arg1, arg2, arg3);
+}
+
+export function ReconcileAccounts(arg1) {
+  return window['go']['main']['App']['ReconcileAccounts'](arg1);
+}
+
+export function RemoveTransactionTags(arg1, arg2) {
+  return window['go']['main']['App']['RemoveTransactionTags'](arg1, arg2);
+}
+
 export function RenameCategory(arg1, arg2) {
   return window['go']['main']['App']['RenameCategory'](arg1, arg2);
 }
 
+export function RenameMerchant(arg1, arg2) {
+  return window['go']['main']['App']['RenameMerchant'](arg1, arg2);
+}
+
 export function RestoreBackup(arg1) {
   return window['go']['main']['App']['RestoreBackup'](arg1);
 }
@@ -186,14 +318,42 @@ export function SelectBackupFile() {
   return window['go']['main']['App']['SelectBackupFile']();
 }
 
+export function SetBalanceCheckpoint(arg1, arg2) {
+  return window['go']['main']['App']['SetBalanceCheckpoint'](arg1, arg2);
+}
+
+export function SetCategoryKind(arg1, arg2) {
+  return window['go']['main']['App']['SetCategoryKind'](arg1, arg2);
+}
+
+export function SetKnownSubscription(arg1, arg2) {
+  return window['go']['main']['App']['SetKnownSubscription'](arg1, arg2);
+}
+
 export function SetTestDialogPaths(arg1) {
   return window['go']['main']['App']['SetTestDialogPaths'](arg1);
 }
 
+export function SetTransactionNote(arg1, arg2) {
+  return window['go']['main']['App']['SetTransactionNote'](arg1, arg2);
+}
+
 export function ShowAbout() {
   return window['go']['main']['App']['ShowAbout']();
 }
 
+export function SplitTransaction(arg1, arg2) {
+  return window['go']['main']['App']['SplitTransaction'](arg1, arg2);
+}
+
+export function SuggestCategorizationRules(arg1) {
+  return window['go']['main']['App']['SuggestCategorizationRules'](arg1);
+}
+
+export function SuggestMerchants(arg1) {
+  return window['go']['main']['App']['SuggestMerchants'](arg1);
+}
+
 export function SyncFxRates() {
   return window['go']['main']['App']['SyncFxRates']();
 }
@@ -210,6 +370,22 @@ export function UndoCategorizationRule(arg1, arg2) {
   return window['go']['main']['App']['UndoCategorizationRule'](arg1, arg2);
 }
 
+export function UndoRuleApply(arg1) {
+  return window['go']['main']['App']['UndoRuleApply'](arg1);
+}
+
+export function UnlinkTransfer(arg1) {
+  return window['go']['main']['App']['UnlinkTransfer'](arg1);
+}
+
+export function UnsplitTransaction(arg1) {
+  return window['go']['main']['App']['UnsplitTransaction'](arg1);
+}
+
+export function UpdateBudget(arg1, arg2) {
+  return window['go']['main']['App']['UpdateBudget'](arg1, arg2);
+}
+
 export function UpdateCategorizationRule(arg1, arg2) {
   return window['go']['main']['App']['UpdateCategorizationRule'](arg1, arg2);
 }
@@ -218,6 +394,10 @@ export function UpdateCurrencySettings(arg1) {
   return window['go']['main']['App']['UpdateCurrencySettings'](arg1);
 }
 
+export function UpdateTransaction(arg1, arg2) {
+  return window['go']['main']['App']['UpdateTransaction'](arg1, arg2);
+}
+
 export function ValidateBackupFile(arg1) {
   return window['go']['main']['App']['ValidateBackupFile'](arg1);
 }
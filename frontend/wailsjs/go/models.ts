@@ -1,49 +1,1740 @@
+export namespace cashmop {
+	
+	export class Anomaly {
+	    kind: string;
+	    direction: string;
+	    category_id?: number;
+	    category_name: string;
+	    merchant: string;
+	    description: string;
+	    transaction_id?: number;
+	    date: string;
+	    amount: number;
+	    median: number;
+	    mad: number;
+	    score: number;
+	    history_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Anomaly(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.direction = source["direction"];
+	        this.category_id = source["category_id"];
+	        this.category_name = source["category_name"];
+	        this.merchant = source["merchant"];
+	        this.description = source["description"];
+	        this.transaction_id = source["transaction_id"];
+	        this.date = source["date"];
+	        this.amount = source["amount"];
+	        this.median = source["median"];
+	        this.mad = source["mad"];
+	        this.score = source["score"];
+	        this.history_count = source["history_count"];
+	    }
+	}
+	export class AnomalyReport {
+	    month: string;
+	    main_currency: string;
+	    anomalies: Anomaly[];
+	    unconverted: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnomalyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.main_currency = source["main_currency"];
+	        this.anomalies = this.convertValues(source["anomalies"], Anomaly);
+	        this.unconverted = source["unconverted"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OwnerShare {
+	    owner_id: number;
+	    percent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OwnerShare(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.owner_id = source["owner_id"];
+	        this.percent = source["percent"];
+	    }
+	}
+	export class CategoryShares {
+	    category_id: number;
+	    shares: OwnerShare[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryShares(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category_id = source["category_id"];
+	        this.shares = this.convertValues(source["shares"], OwnerShare);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ComparisonMerchant {
+	    merchant: string;
+	    description: string;
+	    count: number;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ComparisonMerchant(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.merchant = source["merchant"];
+	        this.description = source["description"];
+	        this.count = source["count"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class ComparisonRow {
+	    group_id?: number;
+	    group: string;
+	    current: number;
+	    previous: number;
+	    delta: number;
+	    current_count: number;
+	    previous_count: number;
+	    delta_percent?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ComparisonRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.group_id = source["group_id"];
+	        this.group = source["group"];
+	        this.current = source["current"];
+	        this.previous = source["previous"];
+	        this.delta = source["delta"];
+	        this.current_count = source["current_count"];
+	        this.previous_count = source["previous_count"];
+	        this.delta_percent = source["delta_percent"];
+	    }
+	}
+	export class ComparisonRange {
+	    start_date: string;
+	    end_date: string;
+	    totals: database.KindTotals;
+	
+	    static createFrom(source: any = {}) {
+	        return new ComparisonRange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.totals = this.convertValues(source["totals"], database.KindTotals);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Comparison {
+	    main_currency: string;
+	    group_by: string;
+	    current: ComparisonRange;
+	    previous: ComparisonRange;
+	    rows: ComparisonRow[];
+	    new_merchants: ComparisonMerchant[];
+	    disappeared_merchants: ComparisonMerchant[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Comparison(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.main_currency = source["main_currency"];
+	        this.group_by = source["group_by"];
+	        this.current = this.convertValues(source["current"], ComparisonRange);
+	        this.previous = this.convertValues(source["previous"], ComparisonRange);
+	        this.rows = this.convertValues(source["rows"], ComparisonRow);
+	        this.new_merchants = this.convertValues(source["new_merchants"], ComparisonMerchant);
+	        this.disappeared_merchants = this.convertValues(source["disappeared_merchants"], ComparisonMerchant);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ComparisonOptions {
+	    current_start: string;
+	    current_end: string;
+	    previous_start: string;
+	    previous_end: string;
+	    group_by: string;
+	    category_ids: number[];
+	    owner_ids: number[];
+	    tag_ids: number[];
+	    merchant_ids: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ComparisonOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current_start = source["current_start"];
+	        this.current_end = source["current_end"];
+	        this.previous_start = source["previous_start"];
+	        this.previous_end = source["previous_end"];
+	        this.group_by = source["group_by"];
+	        this.category_ids = source["category_ids"];
+	        this.owner_ids = source["owner_ids"];
+	        this.tag_ids = source["tag_ids"];
+	        this.merchant_ids = source["merchant_ids"];
+	    }
+	}
+	
+	
+	export class RecurringPriceChange {
+	    date: string;
+	    from: number;
+	    to: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurringPriceChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	    }
+	}
+	export class RecurringSeries {
+	    key: string;
+	    description: string;
+	    account_id: number;
+	    account_name: string;
+	    category_id?: number;
+	    category_name: string;
+	    category_kind: string;
+	    currency: string;
+	    cadence: string;
+	    amount: number;
+	    average_amount: number;
+	    monthly_amount: number;
+	    count: number;
+	    first_date: string;
+	    last_date: string;
+	    next_date: string;
+	    price_changes: RecurringPriceChange[];
+	    missed_dates: string[];
+	    active: boolean;
+	    known: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurringSeries(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.description = source["description"];
+	        this.account_id = source["account_id"];
+	        this.account_name = source["account_name"];
+	        this.category_id = source["category_id"];
+	        this.category_name = source["category_name"];
+	        this.category_kind = source["category_kind"];
+	        this.currency = source["currency"];
+	        this.cadence = source["cadence"];
+	        this.amount = source["amount"];
+	        this.average_amount = source["average_amount"];
+	        this.monthly_amount = source["monthly_amount"];
+	        this.count = source["count"];
+	        this.first_date = source["first_date"];
+	        this.last_date = source["last_date"];
+	        this.next_date = source["next_date"];
+	        this.price_changes = this.convertValues(source["price_changes"], RecurringPriceChange);
+	        this.missed_dates = source["missed_dates"];
+	        this.active = source["active"];
+	        this.known = source["known"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ForecastPoint {
+	    month: string;
+	    amount: number;
+	    low: number;
+	    high: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ForecastPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.amount = source["amount"];
+	        this.low = source["low"];
+	        this.high = source["high"];
+	    }
+	}
+	export class ForecastSeries {
+	    id?: number;
+	    name: string;
+	    points: ForecastPoint[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ForecastSeries(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.points = this.convertValues(source["points"], ForecastPoint);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ForecastMonth {
+	    month: string;
+	    income: number;
+	    expenses: number;
+	    net: number;
+	    low: number;
+	    high: number;
+	    balance: number;
+	    balance_low: number;
+	    balance_high: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ForecastMonth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.income = source["income"];
+	        this.expenses = source["expenses"];
+	        this.net = source["net"];
+	        this.low = source["low"];
+	        this.high = source["high"];
+	        this.balance = source["balance"];
+	        this.balance_low = source["balance_low"];
+	        this.balance_high = source["balance_high"];
+	    }
+	}
+	export class Forecast {
+	    main_currency: string;
+	    history_start: string;
+	    history_end: string;
+	    opening_balance: number;
+	    months: ForecastMonth[];
+	    categories: ForecastSeries[];
+	    accounts: ForecastSeries[];
+	    balances: ForecastSeries[];
+	    recurring: RecurringSeries[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Forecast(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.main_currency = source["main_currency"];
+	        this.history_start = source["history_start"];
+	        this.history_end = source["history_end"];
+	        this.opening_balance = source["opening_balance"];
+	        this.months = this.convertValues(source["months"], ForecastMonth);
+	        this.categories = this.convertValues(source["categories"], ForecastSeries);
+	        this.accounts = this.convertValues(source["accounts"], ForecastSeries);
+	        this.balances = this.convertValues(source["balances"], ForecastSeries);
+	        this.recurring = this.convertValues(source["recurring"], RecurringSeries);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class MerchantStats {
+	    merchant_id: number;
+	    name: string;
+	    spent: number;
+	    visits: number;
+	    visits_per_month: number;
+	    average_ticket: number;
+	    first_date: string;
+	    last_date: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MerchantStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.merchant_id = source["merchant_id"];
+	        this.name = source["name"];
+	        this.spent = source["spent"];
+	        this.visits = source["visits"];
+	        this.visits_per_month = source["visits_per_month"];
+	        this.average_ticket = source["average_ticket"];
+	        this.first_date = source["first_date"];
+	        this.last_date = source["last_date"];
+	    }
+	}
+	export class MerchantReport {
+	    start_date: string;
+	    end_date: string;
+	    main_currency: string;
+	    sort_by: string;
+	    merchants: MerchantStats[];
+	    unassigned_count: number;
+	    unassigned_spent: number;
+	    unconverted: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MerchantReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.main_currency = source["main_currency"];
+	        this.sort_by = source["sort_by"];
+	        this.merchants = this.convertValues(source["merchants"], MerchantStats);
+	        this.unassigned_count = source["unassigned_count"];
+	        this.unassigned_spent = source["unassigned_spent"];
+	        this.unconverted = source["unconverted"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MerchantReportOptions {
+	    start_date: string;
+	    end_date: string;
+	    sort_by: string;
+	    limit: number;
+	    category_ids: number[];
+	    owner_ids: number[];
+	    tag_ids: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MerchantReportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.sort_by = source["sort_by"];
+	        this.limit = source["limit"];
+	        this.category_ids = source["category_ids"];
+	        this.owner_ids = source["owner_ids"];
+	        this.tag_ids = source["tag_ids"];
+	    }
+	}
+	
+	export class MerchantSuggestion {
+	    name: string;
+	    merchant_id?: number;
+	    aliases: database.MerchantAlias[];
+	    descriptions: string[];
+	    transaction_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MerchantSuggestion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.merchant_id = source["merchant_id"];
+	        this.aliases = this.convertValues(source["aliases"], database.MerchantAlias);
+	        this.descriptions = source["descriptions"];
+	        this.transaction_count = source["transaction_count"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MerchantSuggestionOptions {
+	    min_transactions: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MerchantSuggestionOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.min_transactions = source["min_transactions"];
+	        this.limit = source["limit"];
+	    }
+	}
+	
+	
+	
+	export class RuleSuggestion {
+	    match_type: string;
+	    match_value: string;
+	    category_id: number;
+	    category_name: string;
+	    support: number;
+	    matches: number;
+	    precision: number;
+	    preview: database.RuleMatchPreview;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleSuggestion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.match_type = source["match_type"];
+	        this.match_value = source["match_value"];
+	        this.category_id = source["category_id"];
+	        this.category_name = source["category_name"];
+	        this.support = source["support"];
+	        this.matches = source["matches"];
+	        this.precision = source["precision"];
+	        this.preview = this.convertValues(source["preview"], database.RuleMatchPreview);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleSuggestionOptions {
+	    min_support: number;
+	    min_precision: number;
+	    limit: number;
+	    preview_limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleSuggestionOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.min_support = source["min_support"];
+	        this.min_precision = source["min_precision"];
+	        this.limit = source["limit"];
+	        this.preview_limit = source["preview_limit"];
+	    }
+	}
+	export class SettlementPayment {
+	    from_owner_id: number;
+	    from: string;
+	    to_owner_id: number;
+	    to: string;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SettlementPayment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from_owner_id = source["from_owner_id"];
+	        this.from = source["from"];
+	        this.to_owner_id = source["to_owner_id"];
+	        this.to = source["to"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class SettlementOwner {
+	    owner_id: number;
+	    name: string;
+	    paid: number;
+	    share: number;
+	    balance: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SettlementOwner(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.owner_id = source["owner_id"];
+	        this.name = source["name"];
+	        this.paid = source["paid"];
+	        this.share = source["share"];
+	        this.balance = source["balance"];
+	    }
+	}
+	export class Settlement {
+	    start_date: string;
+	    end_date: string;
+	    main_currency: string;
+	    total: number;
+	    owners: SettlementOwner[];
+	    payments: SettlementPayment[];
+	    unassigned_count: number;
+	    unassigned_total: number;
+	    unconverted: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settlement(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.main_currency = source["main_currency"];
+	        this.total = source["total"];
+	        this.owners = this.convertValues(source["owners"], SettlementOwner);
+	        this.payments = this.convertValues(source["payments"], SettlementPayment);
+	        this.unassigned_count = source["unassigned_count"];
+	        this.unassigned_total = source["unassigned_total"];
+	        this.unconverted = source["unconverted"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SettlementOptions {
+	    start_date: string;
+	    end_date: string;
+	    category_ids: number[];
+	    tag_ids: number[];
+	    merchant_ids: number[];
+	    shares: OwnerShare[];
+	    category_shares: CategoryShares[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SettlementOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.category_ids = source["category_ids"];
+	        this.tag_ids = source["tag_ids"];
+	        this.merchant_ids = source["merchant_ids"];
+	        this.shares = this.convertValues(source["shares"], OwnerShare);
+	        this.category_shares = this.convertValues(source["category_shares"], CategoryShares);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+
+}
+
 export namespace database {
 	
-	export class AmountRange {
-	    min?: number;
-	    max?: number;
+	export class ReconciliationEntry {
+	    id: number;
+	    account_id: number;
+	    account_name: string;
+	    date: string;
+	    balance: number;
+	    currency: string;
+	    source: string;
+	    note: string;
+	    created_at: string;
+	    transaction_count: number;
+	    expected?: number;
+	    gap?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReconciliationEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.account_id = source["account_id"];
+	        this.account_name = source["account_name"];
+	        this.date = source["date"];
+	        this.balance = source["balance"];
+	        this.currency = source["currency"];
+	        this.source = source["source"];
+	        this.note = source["note"];
+	        this.created_at = source["created_at"];
+	        this.transaction_count = source["transaction_count"];
+	        this.expected = source["expected"];
+	        this.gap = source["gap"];
+	    }
+	}
+	export class AccountReconciliation {
+	    account_id: number;
+	    account_name: string;
+	    currency: string;
+	    entries: ReconciliationEntry[];
+	    reconciled: boolean;
+	    opening_balance: number;
+	    current_balance: number;
+	    other_currency_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AccountReconciliation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.account_id = source["account_id"];
+	        this.account_name = source["account_name"];
+	        this.currency = source["currency"];
+	        this.entries = this.convertValues(source["entries"], ReconciliationEntry);
+	        this.reconciled = source["reconciled"];
+	        this.opening_balance = source["opening_balance"];
+	        this.current_balance = source["current_balance"];
+	        this.other_currency_count = source["other_currency_count"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AmountRange {
+	    min?: number;
+	    max?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AmountRange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.min = source["min"];
+	        this.max = source["max"];
+	    }
+	}
+	export class AnalysisFilterOption {
+	    id: number;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnalysisFilterOption(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	    }
+	}
+	export class AnalysisFacets {
+	    categories: AnalysisFilterOption[];
+	    owners: AnalysisFilterOption[];
+	    tags: AnalysisFilterOption[];
+	    merchants: AnalysisFilterOption[];
+	    has_uncategorized: boolean;
+	    has_no_owner: boolean;
+	    has_untagged: boolean;
+	    has_no_merchant: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnalysisFacets(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.categories = this.convertValues(source["categories"], AnalysisFilterOption);
+	        this.owners = this.convertValues(source["owners"], AnalysisFilterOption);
+	        this.tags = this.convertValues(source["tags"], AnalysisFilterOption);
+	        this.merchants = this.convertValues(source["merchants"], AnalysisFilterOption);
+	        this.has_uncategorized = source["has_uncategorized"];
+	        this.has_no_owner = source["has_no_owner"];
+	        this.has_untagged = source["has_untagged"];
+	        this.has_no_merchant = source["has_no_merchant"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class KindTotals {
+	    income: number;
+	    expenses: number;
+	    net: number;
+	    transfers: number;
+	    excluded: number;
+	    unconverted: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new KindTotals(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.income = source["income"];
+	        this.expenses = source["expenses"];
+	        this.net = source["net"];
+	        this.transfers = source["transfers"];
+	        this.excluded = source["excluded"];
+	        this.unconverted = source["unconverted"];
+	    }
+	}
+	export class CategoryRollup {
+	    category_id: number;
+	    name: string;
+	    full_name: string;
+	    parent_id?: number;
+	    kind: string;
+	    depth: number;
+	    own_count: number;
+	    own_total: number;
+	    count: number;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryRollup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category_id = source["category_id"];
+	        this.name = source["name"];
+	        this.full_name = source["full_name"];
+	        this.parent_id = source["parent_id"];
+	        this.kind = source["kind"];
+	        this.depth = source["depth"];
+	        this.own_count = source["own_count"];
+	        this.own_total = source["own_total"];
+	        this.count = source["count"];
+	        this.total = source["total"];
+	    }
+	}
+	export class CategoryPrediction {
+	    category_id: number;
+	    category_name: string;
+	    confidence: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryPrediction(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category_id = source["category_id"];
+	        this.category_name = source["category_name"];
+	        this.confidence = source["confidence"];
+	    }
+	}
+	export class TransactionModel {
+	    id: number;
+	    account_id: number;
+	    account_name: string;
+	    owner_id?: number;
+	    owner_name: string;
+	    date: string;
+	    description: string;
+	    note: string;
+	    amount: number;
+	    category_id?: number;
+	    category_name: string;
+	    category_full_name?: string;
+	    top_category_id?: number;
+	    top_category_name?: string;
+	    category_kind?: string;
+	    category_source?: string;
+	    currency: string;
+	    raw_metadata: string;
+	    amount_in_main_currency?: number;
+	    main_currency: string;
+	    tags?: string[];
+	    merchant_id?: number;
+	    merchant_name?: string;
+	    split_id?: number;
+	    split_note?: string;
+	    transfer_link_id?: number;
+	    predictions?: CategoryPrediction[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TransactionModel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.account_id = source["account_id"];
+	        this.account_name = source["account_name"];
+	        this.owner_id = source["owner_id"];
+	        this.owner_name = source["owner_name"];
+	        this.date = source["date"];
+	        this.description = source["description"];
+	        this.note = source["note"];
+	        this.amount = source["amount"];
+	        this.category_id = source["category_id"];
+	        this.category_name = source["category_name"];
+	        this.category_full_name = source["category_full_name"];
+	        this.top_category_id = source["top_category_id"];
+	        this.top_category_name = source["top_category_name"];
+	        this.category_kind = source["category_kind"];
+	        this.category_source = source["category_source"];
+	        this.currency = source["currency"];
+	        this.raw_metadata = source["raw_metadata"];
+	        this.amount_in_main_currency = source["amount_in_main_currency"];
+	        this.main_currency = source["main_currency"];
+	        this.tags = source["tags"];
+	        this.merchant_id = source["merchant_id"];
+	        this.merchant_name = source["merchant_name"];
+	        this.split_id = source["split_id"];
+	        this.split_note = source["split_note"];
+	        this.transfer_link_id = source["transfer_link_id"];
+	        this.predictions = this.convertValues(source["predictions"], CategoryPrediction);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AnalysisView {
+	    transactions: TransactionModel[];
+	    facets: AnalysisFacets;
+	    rollups: CategoryRollup[];
+	    totals: KindTotals;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnalysisView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.transactions = this.convertValues(source["transactions"], TransactionModel);
+	        this.facets = this.convertValues(source["facets"], AnalysisFacets);
+	        this.rollups = this.convertValues(source["rollups"], CategoryRollup);
+	        this.totals = this.convertValues(source["totals"], KindTotals);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Attachment {
+	    id: number;
+	    transaction_id: number;
+	    sha256: string;
+	    file_name: string;
+	    mime_type: string;
+	    size: number;
+	    created_at: string;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Attachment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.transaction_id = source["transaction_id"];
+	        this.sha256 = source["sha256"];
+	        this.file_name = source["file_name"];
+	        this.mime_type = source["mime_type"];
+	        this.size = source["size"];
+	        this.created_at = source["created_at"];
+	        this.path = source["path"];
+	    }
+	}
+	export class BackupMetadata {
+	    path: string;
+	    size: number;
+	    transaction_count: number;
+	    // Go type: time
+	    created_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupMetadata(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.transaction_count = source["transaction_count"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BalanceCheckpoint {
+	    id: number;
+	    account_id: number;
+	    account_name: string;
+	    date: string;
+	    balance: number;
+	    currency: string;
+	    source: string;
+	    note: string;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BalanceCheckpoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.account_id = source["account_id"];
+	        this.account_name = source["account_name"];
+	        this.date = source["date"];
+	        this.balance = source["balance"];
+	        this.currency = source["currency"];
+	        this.source = source["source"];
+	        this.note = source["note"];
+	        this.created_at = source["created_at"];
+	    }
+	}
+	export class Budget {
+	    id: number;
+	    category_id: number;
+	    category_name: string;
+	    period: string;
+	    amount: number;
+	    rollover: boolean;
+	    start_month: string;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Budget(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.category_id = source["category_id"];
+	        this.category_name = source["category_name"];
+	        this.period = source["period"];
+	        this.amount = source["amount"];
+	        this.rollover = source["rollover"];
+	        this.start_month = source["start_month"];
+	        this.created_at = source["created_at"];
+	    }
+	}
+	export class BudgetMonthTotal {
+	    month: string;
+	    budget: number;
+	    carry: number;
+	    available: number;
+	    actual: number;
+	    remaining: number;
+	    overspent: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BudgetMonthTotal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.budget = source["budget"];
+	        this.carry = source["carry"];
+	        this.available = source["available"];
+	        this.actual = source["actual"];
+	        this.remaining = source["remaining"];
+	        this.overspent = source["overspent"];
+	    }
+	}
+	export class BudgetReportRow {
+	    month: string;
+	    category_id: number;
+	    name: string;
+	    full_name: string;
+	    parent_id?: number;
+	    depth: number;
+	    budget_id?: number;
+	    period: string;
+	    period_start: string;
+	    budget: number;
+	    carry: number;
+	    available: number;
+	    actual: number;
+	    remaining: number;
+	    overspent: boolean;
+	    unconverted: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BudgetReportRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.category_id = source["category_id"];
+	        this.name = source["name"];
+	        this.full_name = source["full_name"];
+	        this.parent_id = source["parent_id"];
+	        this.depth = source["depth"];
+	        this.budget_id = source["budget_id"];
+	        this.period = source["period"];
+	        this.period_start = source["period_start"];
+	        this.budget = source["budget"];
+	        this.carry = source["carry"];
+	        this.available = source["available"];
+	        this.actual = source["actual"];
+	        this.remaining = source["remaining"];
+	        this.overspent = source["overspent"];
+	        this.unconverted = source["unconverted"];
+	    }
+	}
+	export class BudgetReport {
+	    main_currency: string;
+	    start_month: string;
+	    end_month: string;
+	    rows: BudgetReportRow[];
+	    totals: BudgetMonthTotal[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BudgetReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.main_currency = source["main_currency"];
+	        this.start_month = source["start_month"];
+	        this.end_month = source["end_month"];
+	        this.rows = this.convertValues(source["rows"], BudgetReportRow);
+	        this.totals = this.convertValues(source["totals"], BudgetMonthTotal);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class BudgetUpdate {
+	    period?: string;
+	    amount?: number;
+	    rollover?: boolean;
+	    start_month?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new AmountRange(source);
+	        return new BudgetUpdate(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.min = source["min"];
-	        this.max = source["max"];
+	        this.period = source["period"];
+	        this.amount = source["amount"];
+	        this.rollover = source["rollover"];
+	        this.start_month = source["start_month"];
 	    }
 	}
-	export class AnalysisFilterOption {
+	export class CategorizationRule {
+	    id: number;
+	    match_type: string;
+	    match_value: string;
+	    category_id: number;
+	    category_name: string;
+	    amount_min?: number;
+	    amount_max?: number;
+	    created_at: string;
+	    hit_count: number;
+	    last_matched_date: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategorizationRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.match_type = source["match_type"];
+	        this.match_value = source["match_value"];
+	        this.category_id = source["category_id"];
+	        this.category_name = source["category_name"];
+	        this.amount_min = source["amount_min"];
+	        this.amount_max = source["amount_max"];
+	        this.created_at = source["created_at"];
+	        this.hit_count = source["hit_count"];
+	        this.last_matched_date = source["last_matched_date"];
+	    }
+	}
+	export class Category {
 	    id: number;
 	    name: string;
+	    parent_id?: number;
+	    full_name: string;
+	    kind: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new AnalysisFilterOption(source);
+	        return new Category(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.parent_id = source["parent_id"];
+	        this.full_name = source["full_name"];
+	        this.kind = source["kind"];
+	    }
+	}
+	export class CategoryMergeResult {
+	    target_id: number;
+	    target_name: string;
+	    merged_names: string[];
+	    moved_transactions: number;
+	    moved_rules: number;
+	    removed_rules: number;
+	    moved_subcategories: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryMergeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.target_id = source["target_id"];
+	        this.target_name = source["target_name"];
+	        this.merged_names = source["merged_names"];
+	        this.moved_transactions = source["moved_transactions"];
+	        this.moved_rules = source["moved_rules"];
+	        this.removed_rules = source["removed_rules"];
+	        this.moved_subcategories = source["moved_subcategories"];
+	    }
+	}
+	
+	
+	export class CategorySummary {
+	    id: number;
+	    name: string;
+	    parent_id?: number;
+	    full_name: string;
+	    kind: string;
+	    transaction_count: number;
+	    rule_count: number;
+	    last_used_date: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategorySummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.parent_id = source["parent_id"];
+	        this.full_name = source["full_name"];
+	        this.kind = source["kind"];
+	        this.transaction_count = source["transaction_count"];
+	        this.rule_count = source["rule_count"];
+	        this.last_used_date = source["last_used_date"];
+	    }
+	}
+	export class ColumnMappingModel {
+	    id: number;
+	    name: string;
+	    mapping_json: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnMappingModel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.mapping_json = source["mapping_json"];
+	    }
+	}
+	export class CurrencySettings {
+	    main_currency: string;
+	    fx_last_sync: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CurrencySettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.main_currency = source["main_currency"];
+	        this.fx_last_sync = source["fx_last_sync"];
+	    }
+	}
+	export class FxRateLookup {
+	    rate_date: string;
+	    rate: number;
+	    source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FxRateLookup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rate_date = source["rate_date"];
+	        this.rate = source["rate"];
+	        this.source = source["source"];
+	    }
+	}
+	export class FxRatePairStatus {
+	    quote_currency: string;
+	    latest_rate_date: string;
+	    max_tx_date?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FxRatePairStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.quote_currency = source["quote_currency"];
+	        this.latest_rate_date = source["latest_rate_date"];
+	        this.max_tx_date = source["max_tx_date"];
+	    }
+	}
+	export class FxRateStatus {
+	    base_currency: string;
+	    last_sync: string;
+	    pairs: FxRatePairStatus[];
+	    max_tx_date?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FxRateStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.base_currency = source["base_currency"];
+	        this.last_sync = source["last_sync"];
+	        this.pairs = this.convertValues(source["pairs"], FxRatePairStatus);
+	        this.max_tx_date = source["max_tx_date"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class MerchantAlias {
+	    id: number;
+	    merchant_id: number;
+	    match_type: string;
+	    pattern: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MerchantAlias(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.merchant_id = source["merchant_id"];
+	        this.match_type = source["match_type"];
+	        this.pattern = source["pattern"];
+	    }
+	}
+	export class Merchant {
+	    id: number;
+	    name: string;
+	    aliases: MerchantAlias[];
+	    transaction_count: number;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Merchant(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.name = source["name"];
+	        this.aliases = this.convertValues(source["aliases"], MerchantAlias);
+	        this.transaction_count = source["transaction_count"];
+	        this.created_at = source["created_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class NetWorthAccount {
+	    account_id: number;
+	    name: string;
+	    currency: string;
+	    balance: number;
+	    balance_in_main_currency?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetWorthAccount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.account_id = source["account_id"];
+	        this.name = source["name"];
+	        this.currency = source["currency"];
+	        this.balance = source["balance"];
+	        this.balance_in_main_currency = source["balance_in_main_currency"];
+	    }
+	}
+	export class NetWorthPoint {
+	    month: string;
+	    date: string;
+	    total: number;
+	    accounts: NetWorthAccount[];
+	    unconverted: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NetWorthPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.month = source["month"];
+	        this.date = source["date"];
+	        this.total = source["total"];
+	        this.accounts = this.convertValues(source["accounts"], NetWorthAccount);
+	        this.unconverted = source["unconverted"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class AnalysisFacets {
-	    categories: AnalysisFilterOption[];
-	    owners: AnalysisFilterOption[];
-	    has_uncategorized: boolean;
-	    has_no_owner: boolean;
+	export class NetWorth {
+	    main_currency: string;
+	    points: NetWorthPoint[];
+	    missing_accounts: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new AnalysisFacets(source);
+	        return new NetWorth(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.categories = this.convertValues(source["categories"], AnalysisFilterOption);
-	        this.owners = this.convertValues(source["owners"], AnalysisFilterOption);
-	        this.has_uncategorized = source["has_uncategorized"];
-	        this.has_no_owner = source["has_no_owner"];
+	        this.main_currency = source["main_currency"];
+	        this.points = this.convertValues(source["points"], NetWorthPoint);
+	        this.missing_accounts = source["missing_accounts"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -65,56 +1756,52 @@ export namespace database {
 		}
 	}
 	
-	export class TransactionModel {
-	    id: number;
-	    account_id: number;
-	    account_name: string;
-	    owner_id?: number;
-	    owner_name: string;
+	
+	
+	export class RuleApplyChange {
+	    transaction_id: number;
 	    date: string;
 	    description: string;
-	    amount: number;
-	    category_id?: number;
-	    category_name: string;
-	    currency: string;
-	    raw_metadata: string;
-	    amount_in_main_currency?: number;
-	    main_currency: string;
+	    old_category_id?: number;
+	    old_category_name: string;
+	    old_source: string;
+	    new_category_id: number;
+	    new_category_name: string;
+	    rule_id: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new TransactionModel(source);
+	        return new RuleApplyChange(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.account_id = source["account_id"];
-	        this.account_name = source["account_name"];
-	        this.owner_id = source["owner_id"];
-	        this.owner_name = source["owner_name"];
+	        this.transaction_id = source["transaction_id"];
 	        this.date = source["date"];
 	        this.description = source["description"];
-	        this.amount = source["amount"];
-	        this.category_id = source["category_id"];
-	        this.category_name = source["category_name"];
-	        this.currency = source["currency"];
-	        this.raw_metadata = source["raw_metadata"];
-	        this.amount_in_main_currency = source["amount_in_main_currency"];
-	        this.main_currency = source["main_currency"];
+	        this.old_category_id = source["old_category_id"];
+	        this.old_category_name = source["old_category_name"];
+	        this.old_source = source["old_source"];
+	        this.new_category_id = source["new_category_id"];
+	        this.new_category_name = source["new_category_name"];
+	        this.rule_id = source["rule_id"];
 	    }
 	}
-	export class AnalysisView {
-	    transactions: TransactionModel[];
-	    facets: AnalysisFacets;
+	export class RuleApplyResult {
+	    batch_id?: number;
+	    overwrite: string;
+	    dry_run: boolean;
+	    changes: RuleApplyChange[];
 	
 	    static createFrom(source: any = {}) {
-	        return new AnalysisView(source);
+	        return new RuleApplyResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.transactions = this.convertValues(source["transactions"], TransactionModel);
-	        this.facets = this.convertValues(source["facets"], AnalysisFacets);
+	        this.batch_id = source["batch_id"];
+	        this.overwrite = source["overwrite"];
+	        this.dry_run = source["dry_run"];
+	        this.changes = this.convertValues(source["changes"], RuleApplyChange);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -135,23 +1822,38 @@ export namespace database {
 		    return a;
 		}
 	}
-	export class BackupMetadata {
-	    path: string;
-	    size: number;
-	    transaction_count: number;
-	    // Go type: time
-	    created_at: any;
+	export class RuleApplyUndoResult {
+	    batch_id: number;
+	    restored: number;
+	    skipped: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new BackupMetadata(source);
+	        return new RuleApplyUndoResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.path = source["path"];
-	        this.size = source["size"];
-	        this.transaction_count = source["transaction_count"];
-	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.batch_id = source["batch_id"];
+	        this.restored = source["restored"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+	export class RuleMatchPreview {
+	    count: number;
+	    min_amount?: number;
+	    max_amount?: number;
+	    transactions: TransactionModel[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleMatchPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.count = source["count"];
+	        this.min_amount = source["min_amount"];
+	        this.max_amount = source["max_amount"];
+	        this.transactions = this.convertValues(source["transactions"], TransactionModel);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -172,180 +1874,246 @@ export namespace database {
 		    return a;
 		}
 	}
-	export class CategorizationRule {
+	export class SplitLine {
 	    id: number;
-	    match_type: string;
-	    match_value: string;
-	    category_id: number;
+	    transaction_id: number;
+	    amount: number;
+	    category_id?: number;
 	    category_name: string;
-	    amount_min?: number;
-	    amount_max?: number;
-	    created_at: string;
+	    owner_id?: number;
+	    owner_name: string;
+	    note: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new CategorizationRule(source);
+	        return new SplitLine(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.match_type = source["match_type"];
-	        this.match_value = source["match_value"];
+	        this.transaction_id = source["transaction_id"];
+	        this.amount = source["amount"];
 	        this.category_id = source["category_id"];
 	        this.category_name = source["category_name"];
-	        this.amount_min = source["amount_min"];
-	        this.amount_max = source["amount_max"];
-	        this.created_at = source["created_at"];
+	        this.owner_id = source["owner_id"];
+	        this.owner_name = source["owner_name"];
+	        this.note = source["note"];
 	    }
 	}
-	export class Category {
-	    id: number;
-	    name: string;
+	export class SummaryRow {
+	    period: string;
+	    group_id?: number;
+	    group: string;
+	    count: number;
+	    income: number;
+	    expenses: number;
+	    net: number;
+	    transfers: number;
+	    excluded: number;
+	    unconverted: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new Category(source);
+	        return new SummaryRow(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
+	        this.period = source["period"];
+	        this.group_id = source["group_id"];
+	        this.group = source["group"];
+	        this.count = source["count"];
+	        this.income = source["income"];
+	        this.expenses = source["expenses"];
+	        this.net = source["net"];
+	        this.transfers = source["transfers"];
+	        this.excluded = source["excluded"];
+	        this.unconverted = source["unconverted"];
 	    }
 	}
-	export class CategorySummary {
-	    id: number;
-	    name: string;
-	    transaction_count: number;
-	    rule_count: number;
-	    last_used_date: string;
+	export class Summary {
+	    main_currency: string;
+	    period: string;
+	    group_by: string;
+	    rows: SummaryRow[];
+	    totals: KindTotals;
 	
 	    static createFrom(source: any = {}) {
-	        return new CategorySummary(source);
+	        return new Summary(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.transaction_count = source["transaction_count"];
-	        this.rule_count = source["rule_count"];
-	        this.last_used_date = source["last_used_date"];
+	        this.main_currency = source["main_currency"];
+	        this.period = source["period"];
+	        this.group_by = source["group_by"];
+	        this.rows = this.convertValues(source["rows"], SummaryRow);
+	        this.totals = this.convertValues(source["totals"], KindTotals);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class ColumnMappingModel {
+	export class SummaryOptions {
+	    start_date: string;
+	    end_date: string;
+	    period: string;
+	    group_by: string;
+	    category_ids: number[];
+	    owner_ids: number[];
+	    tag_ids: number[];
+	    merchant_ids: number[];
+	    include_transfers: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SummaryOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.period = source["period"];
+	        this.group_by = source["group_by"];
+	        this.category_ids = source["category_ids"];
+	        this.owner_ids = source["owner_ids"];
+	        this.tag_ids = source["tag_ids"];
+	        this.merchant_ids = source["merchant_ids"];
+	        this.include_transfers = source["include_transfers"];
+	    }
+	}
+	
+	export class Tag {
 	    id: number;
 	    name: string;
-	    mapping_json: string;
+	    transaction_count: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ColumnMappingModel(source);
+	        return new Tag(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.name = source["name"];
-	        this.mapping_json = source["mapping_json"];
+	        this.transaction_count = source["transaction_count"];
 	    }
 	}
-	export class CurrencySettings {
-	    main_currency: string;
-	    fx_last_sync: string;
+	export class TagChangeResult {
+	    transaction_ids: number[];
+	    tags: string[];
+	    changed: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new CurrencySettings(source);
+	        return new TagChangeResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.main_currency = source["main_currency"];
-	        this.fx_last_sync = source["fx_last_sync"];
+	        this.transaction_ids = source["transaction_ids"];
+	        this.tags = source["tags"];
+	        this.changed = source["changed"];
 	    }
 	}
-	export class FxRateLookup {
-	    rate_date: string;
-	    rate: number;
-	    source: string;
+	
+	export class TransactionUpdate {
+	    account_id?: number;
+	    date?: string;
+	    description?: string;
+	    amount?: number;
+	    currency?: string;
+	    owner_id?: number;
+	    clear_owner: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new FxRateLookup(source);
+	        return new TransactionUpdate(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.rate_date = source["rate_date"];
-	        this.rate = source["rate"];
-	        this.source = source["source"];
+	        this.account_id = source["account_id"];
+	        this.date = source["date"];
+	        this.description = source["description"];
+	        this.amount = source["amount"];
+	        this.currency = source["currency"];
+	        this.owner_id = source["owner_id"];
+	        this.clear_owner = source["clear_owner"];
 	    }
 	}
-	export class FxRatePairStatus {
-	    quote_currency: string;
-	    latest_rate_date: string;
-	    max_tx_date?: string;
+	export class TransferDetectOptions {
+	    StartDate: string;
+	    EndDate: string;
+	    WindowDays: number;
+	    DryRun: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new FxRatePairStatus(source);
+	        return new TransferDetectOptions(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.quote_currency = source["quote_currency"];
-	        this.latest_rate_date = source["latest_rate_date"];
-	        this.max_tx_date = source["max_tx_date"];
+	        this.StartDate = source["StartDate"];
+	        this.EndDate = source["EndDate"];
+	        this.WindowDays = source["WindowDays"];
+	        this.DryRun = source["DryRun"];
 	    }
 	}
-	export class FxRateStatus {
-	    base_currency: string;
-	    last_sync: string;
-	    pairs: FxRatePairStatus[];
-	    max_tx_date?: string;
+	export class TransferSide {
+	    transaction_id: number;
+	    account_id: number;
+	    account_name: string;
+	    date: string;
+	    description: string;
+	    amount: number;
+	    currency: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new FxRateStatus(source);
+	        return new TransferSide(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.base_currency = source["base_currency"];
-	        this.last_sync = source["last_sync"];
-	        this.pairs = this.convertValues(source["pairs"], FxRatePairStatus);
-	        this.max_tx_date = source["max_tx_date"];
+	        this.transaction_id = source["transaction_id"];
+	        this.account_id = source["account_id"];
+	        this.account_name = source["account_name"];
+	        this.date = source["date"];
+	        this.description = source["description"];
+	        this.amount = source["amount"];
+	        this.currency = source["currency"];
 	    }
-	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
-		    if (!a) {
-		        return a;
-		    }
-		    if (a.slice && a.map) {
-		        return (a as any[]).map(elem => this.convertValues(elem, classs));
-		    } else if ("object" === typeof a) {
-		        if (asMap) {
-		            for (const key of Object.keys(a)) {
-		                a[key] = new classs(a[key]);
-		            }
-		            return a;
-		        }
-		        return new classs(a);
-		    }
-		    return a;
-		}
 	}
-	export class RuleMatchPreview {
-	    count: number;
-	    min_amount?: number;
-	    max_amount?: number;
-	    transactions: TransactionModel[];
+	export class TransferLink {
+	    id: number;
+	    out: TransferSide;
+	    in: TransferSide;
+	    days_apart: number;
+	    created_at: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new RuleMatchPreview(source);
+	        return new TransferLink(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.count = source["count"];
-	        this.min_amount = source["min_amount"];
-	        this.max_amount = source["max_amount"];
-	        this.transactions = this.convertValues(source["transactions"], TransactionModel);
+	        this.id = source["id"];
+	        this.out = this.convertValues(source["out"], TransferSide);
+	        this.in = this.convertValues(source["in"], TransferSide);
+	        this.days_apart = source["days_apart"];
+	        this.created_at = source["created_at"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -405,6 +2173,10 @@ export namespace main {
 	    category_name: string;
 	    uncategorized_count: number;
 	    deleted_rule_count: number;
+	    reassigned_to_id?: number;
+	    reassigned_to_name?: string;
+	    reassigned_count: number;
+	    moved_rule_count: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new CategoryDeleteResult(source);
@@ -416,6 +2188,10 @@ export namespace main {
 	        this.category_name = source["category_name"];
 	        this.uncategorized_count = source["uncategorized_count"];
 	        this.deleted_rule_count = source["deleted_rule_count"];
+	        this.reassigned_to_id = source["reassigned_to_id"];
+	        this.reassigned_to_name = source["reassigned_to_name"];
+	        this.reassigned_count = source["reassigned_count"];
+	        this.moved_rule_count = source["moved_rule_count"];
 	    }
 	}
 	export class ExcelData {
@@ -502,6 +2278,7 @@ export namespace main {
 	    account: string;
 	    owner: string;
 	    currency: string;
+	    note: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new TransactionInput(source);
@@ -516,6 +2293,7 @@ export namespace main {
 	        this.account = source["account"];
 	        this.owner = source["owner"];
 	        this.currency = source["currency"];
+	        this.note = source["note"];
 	    }
 	}
 	export class WebSearchResult {
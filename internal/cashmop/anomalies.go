@@ -0,0 +1,248 @@
+package cashmop
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+const (
+	AnomalyKindCategory = "category"
+	AnomalyKindMerchant = "merchant"
+
+	AnomalyDirectionHigh = "high"
+	AnomalyDirectionLow  = "low"
+
+	// anomalyHistoryMonths is how many months before the checked one make up
+	// the baseline.
+	anomalyHistoryMonths = 12
+	// anomalyMinHistory is how many months with spending (for a category) or
+	// earlier charges (for a merchant) a baseline needs.
+	anomalyMinHistory = 3
+	// anomalyThreshold is the robust z-score from which a value is flagged.
+	anomalyThreshold = 3.5
+	// madScale makes the median absolute deviation comparable to a standard
+	// deviation for normally distributed values.
+	madScale = 1.4826
+	// anomalyMinSpreadShare keeps steady amounts, whose MAD is zero, from
+	// flagging every small change: the spread is at least this share of the
+	// median.
+	anomalyMinSpreadShare = 0.1
+	// anomalyMinDelta is the smallest difference from the median, in cents,
+	// that is reported.
+	anomalyMinDelta = 500
+)
+
+var ErrInvalidAnomalyMonth = errors.New("anomaly month must be YYYY-MM")
+
+// Anomaly is a monthly category total or a single merchant charge far from
+// its trailing median. Amounts are spending in the main currency: positive
+// for money out.
+type Anomaly struct {
+	// Kind is AnomalyKindCategory or AnomalyKindMerchant.
+	Kind string `json:"kind"`
+	// Direction is AnomalyDirectionHigh, or AnomalyDirectionLow for a
+	// merchant charging less than usual.
+	Direction    string `json:"direction"`
+	CategoryID   *int64 `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	// Merchant, Description, TransactionID and Date are only set for
+	// merchant anomalies.
+	Merchant      string `json:"merchant"`
+	Description   string `json:"description"`
+	TransactionID *int64 `json:"transaction_id"`
+	Date          string `json:"date"`
+	Amount        int64  `json:"amount"`
+	Median        int64  `json:"median"`
+	// MAD is the median absolute deviation of the baseline.
+	MAD int64 `json:"mad"`
+	// Score is the robust z-score: the distance from the median in scaled
+	// MADs.
+	Score float64 `json:"score"`
+	// HistoryCount is how many months or charges the baseline has.
+	HistoryCount int `json:"history_count"`
+}
+
+// AnomalyReport lists the anomalies of one month, highest score first.
+type AnomalyReport struct {
+	Month        string    `json:"month"`
+	MainCurrency string    `json:"main_currency"`
+	Anomalies    []Anomaly `json:"anomalies"`
+	// Unconverted counts transactions left out for lack of an FX rate.
+	Unconverted int `json:"unconverted"`
+}
+
+// robustScore returns the median and MAD of history and the robust z-score of
+// value against them.
+func robustScore(value int64, history []int64) (int64, int64, float64) {
+	median := medianInt64(history)
+	deviations := make([]int64, len(history))
+	for i, v := range history {
+		deviations[i] = absInt64(v - median)
+	}
+	mad := medianInt64(deviations)
+	spread := math.Max(madScale*float64(mad), anomalyMinSpreadShare*math.Abs(float64(median)))
+	if spread == 0 {
+		spread = 1
+	}
+	score := float64(value-median) / spread
+	return median, mad, math.Round(score*100) / 100
+}
+
+func isAnomalous(value, median int64, score float64) bool {
+	return math.Abs(score) >= anomalyThreshold && absInt64(value-median) >= anomalyMinDelta
+}
+
+// DetectAnomalies flags expense categories whose total in month (YYYY-MM) is
+// far above their monthly totals over the previous 12 months, and merchant
+// charges in month far from the merchant's earlier charges. Income,
+// transfers and excluded categories are left out.
+func (s *Service) DetectAnomalies(month string) (AnomalyReport, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return AnomalyReport{}, ErrInvalidAnomalyMonth
+	}
+	historyStart := start.AddDate(0, -anomalyHistoryMonths, 0)
+	end := start.AddDate(0, 1, -1)
+
+	settings, err := s.store.GetCurrencySettings()
+	if err != nil {
+		return AnomalyReport{}, err
+	}
+	txs, err := s.store.GetAnalysisTransactions(historyStart.Format("2006-01-02"), end.Format("2006-01-02"), nil, nil, nil, nil)
+	if err != nil {
+		return AnomalyReport{}, err
+	}
+
+	report := AnomalyReport{Month: month, MainCurrency: settings.MainCurrency, Anomalies: []Anomaly{}}
+	type categoryMonths struct {
+		name   string
+		months map[string]int64
+	}
+	categories := map[int64]*categoryMonths{}
+	type charge struct {
+		tx     database.TransactionModel
+		amount int64
+	}
+	merchants := map[string][]charge{}
+	for _, tx := range txs {
+		if !isSpending(tx) {
+			continue
+		}
+		if tx.AmountInMainCurrency == nil {
+			if tx.Date >= start.Format("2006-01-02") {
+				report.Unconverted++
+			}
+			continue
+		}
+		spent := -*tx.AmountInMainCurrency
+		if tx.CategoryID != nil {
+			c, ok := categories[*tx.CategoryID]
+			if !ok {
+				c = &categoryMonths{name: tx.CategoryFullName, months: map[string]int64{}}
+				categories[*tx.CategoryID] = c
+			}
+			c.months[tx.Date[:7]] += spent
+		}
+		if tx.SplitID == nil && spent > 0 {
+			if key, _ := merchantOf(tx); key != "" {
+				merchants[key] = append(merchants[key], charge{tx: tx, amount: spent})
+			}
+		}
+	}
+
+	for id, c := range categories {
+		current, ok := c.months[month]
+		if !ok {
+			continue
+		}
+		var history []int64
+		active := 0
+		for m := historyStart; m.Before(start); m = m.AddDate(0, 1, 0) {
+			v := c.months[m.Format("2006-01")]
+			if v != 0 {
+				active++
+			}
+			history = append(history, v)
+		}
+		if active < anomalyMinHistory {
+			continue
+		}
+		median, mad, score := robustScore(current, history)
+		if score <= 0 || !isAnomalous(current, median, score) {
+			continue
+		}
+		categoryID := id
+		report.Anomalies = append(report.Anomalies, Anomaly{
+			Kind:         AnomalyKindCategory,
+			Direction:    AnomalyDirectionHigh,
+			CategoryID:   &categoryID,
+			CategoryName: c.name,
+			Amount:       current,
+			Median:       median,
+			MAD:          mad,
+			Score:        score,
+			HistoryCount: active,
+		})
+	}
+
+	for _, charges := range merchants {
+		_, name := merchantOf(charges[0].tx)
+		var history []int64
+		for _, ch := range charges {
+			if ch.tx.Date[:7] != month {
+				history = append(history, ch.amount)
+			}
+		}
+		if len(history) < anomalyMinHistory {
+			continue
+		}
+		for _, ch := range charges {
+			if ch.tx.Date[:7] != month {
+				continue
+			}
+			median, mad, score := robustScore(ch.amount, history)
+			if !isAnomalous(ch.amount, median, score) {
+				continue
+			}
+			direction := AnomalyDirectionHigh
+			if score < 0 {
+				direction = AnomalyDirectionLow
+			}
+			id := ch.tx.ID
+			report.Anomalies = append(report.Anomalies, Anomaly{
+				Kind:          AnomalyKindMerchant,
+				Direction:     direction,
+				CategoryID:    ch.tx.CategoryID,
+				CategoryName:  ch.tx.CategoryFullName,
+				Merchant:      name,
+				Description:   ch.tx.Description,
+				TransactionID: &id,
+				Date:          ch.tx.Date,
+				Amount:        ch.amount,
+				Median:        median,
+				MAD:           mad,
+				Score:         score,
+				HistoryCount:  len(history),
+			})
+		}
+	}
+
+	sort.Slice(report.Anomalies, func(i, j int) bool {
+		a, b := report.Anomalies[i], report.Anomalies[j]
+		if math.Abs(a.Score) != math.Abs(b.Score) {
+			return math.Abs(a.Score) > math.Abs(b.Score)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.CategoryName != b.CategoryName {
+			return a.CategoryName < b.CategoryName
+		}
+		return a.Date < b.Date
+	})
+	return report, nil
+}
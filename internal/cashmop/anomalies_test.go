@@ -0,0 +1,63 @@
+package cashmop
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	svc := newTestService(t)
+
+	var txs []TransactionImportInput
+	for m := 1; m <= 12; m++ {
+		month := fmt.Sprintf("2024-%02d", m)
+		txs = append(txs,
+			TransactionImportInput{Date: month + "-03", Description: fmt.Sprintf("GROCER #%d", m), Amount: int64(-40000 - 1000*(m%3)), Category: "Groceries", Account: "Visa"},
+			TransactionImportInput{Date: month + "-05", Description: "NETFLIX.COM", Amount: -1599, Category: "Subscriptions", Account: "Visa"},
+			TransactionImportInput{Date: month + "-01", Description: "RENT", Amount: -150000, Category: "Rent", Account: "Chequing"},
+		)
+	}
+	txs = append(txs,
+		TransactionImportInput{Date: "2024-11-10", Description: "BISTRO", Amount: -8000, Category: "Dining", Account: "Visa"},
+		TransactionImportInput{Date: "2025-01-03", Description: "GROCER #13", Amount: -120000, Category: "Groceries", Account: "Visa"},
+		TransactionImportInput{Date: "2025-01-05", Description: "NETFLIX.COM", Amount: -2299, Category: "Subscriptions", Account: "Visa"},
+		TransactionImportInput{Date: "2025-01-01", Description: "RENT", Amount: -150000, Category: "Rent", Account: "Chequing"},
+		TransactionImportInput{Date: "2025-01-12", Description: "BISTRO", Amount: -50000, Category: "Dining", Account: "Visa"},
+	)
+	seedTransactions(t, svc, txs)
+
+	if _, err := svc.DetectAnomalies("2025-13"); !errors.Is(err, ErrInvalidAnomalyMonth) {
+		t.Errorf("expected ErrInvalidAnomalyMonth, got %v", err)
+	}
+
+	report, err := svc.DetectAnomalies("2025-01")
+	if err != nil {
+		t.Fatalf("DetectAnomalies failed: %v", err)
+	}
+	// Groceries and subscriptions, each as a monthly total and as a charge.
+	if len(report.Anomalies) != 4 {
+		t.Fatalf("expected 4 anomalies, got %+v", report.Anomalies)
+	}
+	found := map[string]Anomaly{}
+	for _, a := range report.Anomalies {
+		found[a.Kind+":"+a.CategoryName] = a
+	}
+	if report.Anomalies[0].CategoryName != "Groceries" || report.Anomalies[3].CategoryName != "Subscriptions" {
+		t.Errorf("expected anomalies sorted by score: %+v", report.Anomalies)
+	}
+	groceries, ok := found[AnomalyKindCategory+":Groceries"]
+	if !ok || groceries.Amount != 120000 || groceries.Median != 41000 || groceries.Direction != AnomalyDirectionHigh || groceries.HistoryCount != 12 {
+		t.Errorf("unexpected groceries anomaly: %+v", groceries)
+	}
+	netflix, ok := found[AnomalyKindMerchant+":Subscriptions"]
+	if !ok || netflix.Merchant != "netflix com" || netflix.Amount != 2299 || netflix.Median != 1599 || netflix.TransactionID == nil {
+		t.Errorf("unexpected merchant anomaly: %+v", netflix)
+	}
+	if _, ok := found[AnomalyKindCategory+":Subscriptions"]; !ok {
+		t.Errorf("expected the subscriptions total to be flagged too: %+v", report.Anomalies)
+	}
+	if _, ok := found[AnomalyKindCategory+":Dining"]; ok {
+		t.Errorf("dining has too little history to be flagged: %+v", report.Anomalies)
+	}
+}
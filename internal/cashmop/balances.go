@@ -0,0 +1,41 @@
+package cashmop
+
+import (
+	"strings"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func (s *Service) SetBalanceCheckpoint(cp database.BalanceCheckpoint) (database.BalanceCheckpoint, error) {
+	return s.store.SetBalanceCheckpoint(cp)
+}
+
+// AccountID returns the ID of the account with the given name, or
+// database.ErrUnknownAccount.
+func (s *Service) AccountID(name string) (int64, error) {
+	accounts, err := s.store.GetAccountMap()
+	if err != nil {
+		return 0, err
+	}
+	id, ok := accounts[strings.TrimSpace(name)]
+	if !ok {
+		return 0, database.ErrUnknownAccount
+	}
+	return id, nil
+}
+
+func (s *Service) DeleteBalanceCheckpoint(id int64) error {
+	return s.store.DeleteBalanceCheckpoint(id)
+}
+
+func (s *Service) GetBalanceCheckpoints(accountID *int64) ([]database.BalanceCheckpoint, error) {
+	return s.store.GetBalanceCheckpoints(accountID)
+}
+
+func (s *Service) ReconcileAccounts(accountID *int64) ([]database.AccountReconciliation, error) {
+	return s.store.ReconcileAccounts(accountID)
+}
+
+func (s *Service) GetNetWorth(startMonth, endMonth string) (database.NetWorth, error) {
+	return s.store.GetNetWorth(startMonth, endMonth)
+}
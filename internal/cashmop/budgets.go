@@ -0,0 +1,27 @@
+package cashmop
+
+import "github.com/default-anton/cashmop/internal/database"
+
+func (s *Service) GetBudgets() ([]database.Budget, error) {
+	return s.store.GetBudgets()
+}
+
+func (s *Service) GetBudget(id int64) (database.Budget, error) {
+	return s.store.GetBudget(id)
+}
+
+func (s *Service) CreateBudget(b database.Budget) (database.Budget, error) {
+	return s.store.CreateBudget(b)
+}
+
+func (s *Service) UpdateBudget(id int64, u database.BudgetUpdate) (database.Budget, error) {
+	return s.store.UpdateBudget(id, u)
+}
+
+func (s *Service) DeleteBudget(id int64) error {
+	return s.store.DeleteBudget(id)
+}
+
+func (s *Service) GetBudgetReport(startMonth, endMonth string) (database.BudgetReport, error) {
+	return s.store.GetBudgetReport(startMonth, endMonth)
+}
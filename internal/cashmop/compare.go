@@ -0,0 +1,242 @@
+package cashmop
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+var ErrInvalidComparisonRange = errors.New("comparison ranges must be valid YYYY-MM-DD dates with the end not before the start")
+
+// ComparisonOptions selects two date ranges to compare and how to group them.
+// GroupBy and the filters work like those of database.SummaryOptions.
+type ComparisonOptions struct {
+	CurrentStart  string  `json:"current_start"`
+	CurrentEnd    string  `json:"current_end"`
+	PreviousStart string  `json:"previous_start"`
+	PreviousEnd   string  `json:"previous_end"`
+	GroupBy       string  `json:"group_by"`
+	CategoryIDs   []int64 `json:"category_ids"`
+	OwnerIDs      []int64 `json:"owner_ids"`
+	TagIDs        []int64 `json:"tag_ids"`
+	MerchantIDs   []int64 `json:"merchant_ids"`
+}
+
+type ComparisonRange struct {
+	StartDate string              `json:"start_date"`
+	EndDate   string              `json:"end_date"`
+	Totals    database.KindTotals `json:"totals"`
+}
+
+// ComparisonRow compares the net amount (income plus expenses) of one group
+// in the main currency.
+type ComparisonRow struct {
+	// GroupID is nil for the uncategorized, no-owner and untagged groups.
+	GroupID       *int64 `json:"group_id"`
+	Group         string `json:"group"`
+	Current       int64  `json:"current"`
+	Previous      int64  `json:"previous"`
+	Delta         int64  `json:"delta"`
+	CurrentCount  int    `json:"current_count"`
+	PreviousCount int    `json:"previous_count"`
+	// DeltaPercent is Delta as a percentage of the previous amount's size,
+	// nil when the previous amount is zero.
+	DeltaPercent *float64 `json:"delta_percent"`
+}
+
+// ComparisonMerchant is spending at a merchant within one of the ranges.
+// Amount is positive for money out, in the main currency.
+type ComparisonMerchant struct {
+	Merchant string `json:"merchant"`
+	// Description is the latest description seen for the merchant.
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+	Amount      int64  `json:"amount"`
+}
+
+// Comparison compares two date ranges. Rows are ordered by the size of their
+// delta, largest first.
+type Comparison struct {
+	MainCurrency string          `json:"main_currency"`
+	GroupBy      string          `json:"group_by"`
+	Current      ComparisonRange `json:"current"`
+	Previous     ComparisonRange `json:"previous"`
+	Rows         []ComparisonRow `json:"rows"`
+	// NewMerchants had spending in the current range only, and
+	// DisappearedMerchants in the previous range only; both by amount.
+	NewMerchants         []ComparisonMerchant `json:"new_merchants"`
+	DisappearedMerchants []ComparisonMerchant `json:"disappeared_merchants"`
+}
+
+func validComparisonRange(start, end string) bool {
+	s, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return false
+	}
+	e, err := time.Parse("2006-01-02", end)
+	return err == nil && !e.Before(s)
+}
+
+// comparisonPercent returns delta as a percentage of |previous|, rounded to
+// one decimal.
+func comparisonPercent(delta, previous int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	pct := math.Round(float64(delta)/math.Abs(float64(previous))*1000) / 10
+	return &pct
+}
+
+// Compare totals each group in both ranges and lists merchants that appeared
+// or disappeared between them.
+func (s *Service) Compare(opts ComparisonOptions) (Comparison, error) {
+	if !validComparisonRange(opts.CurrentStart, opts.CurrentEnd) || !validComparisonRange(opts.PreviousStart, opts.PreviousEnd) {
+		return Comparison{}, ErrInvalidComparisonRange
+	}
+
+	summaryFor := func(start, end string) (database.Summary, error) {
+		return s.store.GetSummary(database.SummaryOptions{
+			StartDate:   start,
+			EndDate:     end,
+			Period:      database.SummaryPeriodYear,
+			GroupBy:     opts.GroupBy,
+			CategoryIDs: opts.CategoryIDs,
+			OwnerIDs:    opts.OwnerIDs,
+			TagIDs:      opts.TagIDs,
+			MerchantIDs: opts.MerchantIDs,
+		})
+	}
+	current, err := summaryFor(opts.CurrentStart, opts.CurrentEnd)
+	if err != nil {
+		return Comparison{}, err
+	}
+	previous, err := summaryFor(opts.PreviousStart, opts.PreviousEnd)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	comparison := Comparison{
+		MainCurrency: current.MainCurrency,
+		GroupBy:      current.GroupBy,
+		Current:      ComparisonRange{StartDate: opts.CurrentStart, EndDate: opts.CurrentEnd, Totals: current.Totals},
+		Previous:     ComparisonRange{StartDate: opts.PreviousStart, EndDate: opts.PreviousEnd, Totals: previous.Totals},
+	}
+
+	// Rows of a summary are per year; a range spanning years has several
+	// rows per group.
+	type groupKey struct {
+		id   int64
+		name string
+	}
+	keyOf := func(row database.SummaryRow) groupKey {
+		if row.GroupID == nil {
+			return groupKey{name: row.Group}
+		}
+		return groupKey{id: *row.GroupID}
+	}
+	rows := map[groupKey]*ComparisonRow{}
+	add := func(summary database.Summary, isCurrent bool) {
+		for _, r := range summary.Rows {
+			key := keyOf(r)
+			row, ok := rows[key]
+			if !ok {
+				row = &ComparisonRow{GroupID: r.GroupID, Group: r.Group}
+				rows[key] = row
+			}
+			if isCurrent {
+				row.Current += r.Net
+				row.CurrentCount += r.Count
+			} else {
+				row.Previous += r.Net
+				row.PreviousCount += r.Count
+			}
+		}
+	}
+	add(current, true)
+	add(previous, false)
+
+	comparison.Rows = make([]ComparisonRow, 0, len(rows))
+	for _, row := range rows {
+		// Groups with only transfers or excluded amounts have nothing to
+		// compare.
+		if row.Current == 0 && row.Previous == 0 {
+			continue
+		}
+		row.Delta = row.Current - row.Previous
+		row.DeltaPercent = comparisonPercent(row.Delta, row.Previous)
+		comparison.Rows = append(comparison.Rows, *row)
+	}
+	sort.Slice(comparison.Rows, func(i, j int) bool {
+		a, b := comparison.Rows[i], comparison.Rows[j]
+		if absInt64(a.Delta) != absInt64(b.Delta) {
+			return absInt64(a.Delta) > absInt64(b.Delta)
+		}
+		return a.Group < b.Group
+	})
+
+	currentMerchants, err := s.merchantSpending(opts.CurrentStart, opts.CurrentEnd, opts)
+	if err != nil {
+		return Comparison{}, err
+	}
+	previousMerchants, err := s.merchantSpending(opts.PreviousStart, opts.PreviousEnd, opts)
+	if err != nil {
+		return Comparison{}, err
+	}
+	comparison.NewMerchants = merchantsMissingFrom(currentMerchants, previousMerchants)
+	comparison.DisappearedMerchants = merchantsMissingFrom(previousMerchants, currentMerchants)
+	return comparison, nil
+}
+
+// merchantSpending totals spending per merchant in the range; see merchantOf.
+func (s *Service) merchantSpending(start, end string, opts ComparisonOptions) (map[string]*ComparisonMerchant, error) {
+	txs, err := s.store.GetAnalysisTransactions(start, end, opts.CategoryIDs, opts.OwnerIDs, opts.TagIDs, opts.MerchantIDs)
+	if err != nil {
+		return nil, err
+	}
+	merchants := map[string]*ComparisonMerchant{}
+	latest := map[string]string{}
+	for _, tx := range txs {
+		if !isSpending(tx) || tx.Amount >= 0 {
+			continue
+		}
+		key, name := merchantOf(tx)
+		if key == "" {
+			continue
+		}
+		m, ok := merchants[key]
+		if !ok {
+			m = &ComparisonMerchant{Merchant: name}
+			merchants[key] = m
+		}
+		m.Count++
+		if tx.AmountInMainCurrency != nil {
+			m.Amount -= *tx.AmountInMainCurrency
+		}
+		if tx.Date >= latest[key] {
+			latest[key] = tx.Date
+			m.Description = tx.Description
+		}
+	}
+	return merchants, nil
+}
+
+// merchantsMissingFrom returns the merchants of from that other lacks, by
+// amount, largest first.
+func merchantsMissingFrom(from, other map[string]*ComparisonMerchant) []ComparisonMerchant {
+	out := []ComparisonMerchant{}
+	for key, m := range from {
+		if _, ok := other[key]; !ok {
+			out = append(out, *m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Amount != out[j].Amount {
+			return out[i].Amount > out[j].Amount
+		}
+		return out[i].Merchant < out[j].Merchant
+	})
+	return out
+}
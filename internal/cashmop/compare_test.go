@@ -0,0 +1,81 @@
+package cashmop
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/default-anton/cashmop/internal/database"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCompare(t *testing.T) {
+	svc := newTestService(t)
+
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-01-03", Description: "GROCER #1", Amount: -40000, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-10", Description: "GYM CLUB", Amount: -5000, Category: "Fitness", Account: "Visa"},
+		{Date: "2025-01-15", Description: "PAYROLL", Amount: 300000, Category: "Salary", Account: "Chequing"},
+		{Date: "2025-02-03", Description: "GROCER #2", Amount: -50000, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-02-08", Description: "STREAMING PLUS", Amount: -1500, Category: "Subscriptions", Account: "Visa"},
+		{Date: "2025-02-15", Description: "PAYROLL", Amount: 300000, Category: "Salary", Account: "Chequing"},
+	})
+	salary, _ := svc.store.GetOrCreateCategory("Salary")
+	if err := svc.store.SetCategoryKind(salary, database.CategoryKindIncome); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.Compare(ComparisonOptions{CurrentStart: "2025-02-01", CurrentEnd: "2025-01-01", PreviousStart: "2025-01-01", PreviousEnd: "2025-01-31"}); !errors.Is(err, ErrInvalidComparisonRange) {
+		t.Errorf("expected ErrInvalidComparisonRange, got %v", err)
+	}
+
+	opts := ComparisonOptions{CurrentStart: "2025-02-01", CurrentEnd: "2025-02-28", PreviousStart: "2025-01-01", PreviousEnd: "2025-01-31"}
+	c, err := svc.Compare(opts)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if c.Current.Totals.Expenses != -51500 || c.Previous.Totals.Expenses != -45000 {
+		t.Errorf("unexpected totals: %+v %+v", c.Current.Totals, c.Previous.Totals)
+	}
+	if len(c.Rows) != 4 {
+		t.Fatalf("expected 4 rows, got %+v", c.Rows)
+	}
+	groceries := c.Rows[0]
+	if groceries.Group != "Groceries" || groceries.Delta != -10000 || groceries.DeltaPercent == nil || *groceries.DeltaPercent != -25 {
+		t.Errorf("unexpected groceries row: %+v", groceries)
+	}
+	for _, r := range c.Rows {
+		if r.Group == "Subscriptions" && r.DeltaPercent != nil {
+			t.Errorf("expected no percentage without a previous amount: %+v", r)
+		}
+	}
+	if len(c.NewMerchants) != 1 || c.NewMerchants[0].Merchant != "streaming plus" || c.NewMerchants[0].Amount != 1500 {
+		t.Errorf("unexpected new merchants: %+v", c.NewMerchants)
+	}
+	if len(c.DisappearedMerchants) != 1 || c.DisappearedMerchants[0].Description != "GYM CLUB" {
+		t.Errorf("unexpected disappeared merchants: %+v", c.DisappearedMerchants)
+	}
+
+	path := filepath.Join(t.TempDir(), "compare.xlsx")
+	if _, err := svc.ExportTransactions(ExportParams{
+		StartDate:         opts.CurrentStart,
+		EndDate:           opts.CurrentEnd,
+		Format:            "xlsx",
+		DestinationPath:   path,
+		PreviousStartDate: opts.PreviousStart,
+		PreviousEndDate:   opts.PreviousEnd,
+	}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if v, _ := f.GetCellValue("Comparison", "A6"); v != "Groceries" {
+		t.Errorf("expected Groceries first on the Comparison sheet, got %q", v)
+	}
+	if v, _ := f.GetCellValue("Merchant Changes", "A2"); v != "New" {
+		t.Errorf("expected a new merchant first, got %q", v)
+	}
+}
@@ -17,13 +17,19 @@ type ExportParams struct {
 	EndDate          string
 	CategoryIDs      []int64
 	OwnerIDs         []int64
+	TagIDs           []int64
+	MerchantIDs      []int64
 	Format           string
 	DestinationPath  string
 	MainCurrencyHint string
+	// PreviousStartDate and PreviousEndDate, when set, add sheets comparing
+	// the export range with this one (XLSX only).
+	PreviousStartDate string
+	PreviousEndDate   string
 }
 
 func (s *Service) ExportTransactions(params ExportParams) (int, error) {
-	transactions, err := s.store.GetAnalysisTransactions(params.StartDate, params.EndDate, params.CategoryIDs, params.OwnerIDs)
+	transactions, err := s.store.GetAnalysisTransactions(params.StartDate, params.EndDate, params.CategoryIDs, params.OwnerIDs, params.TagIDs, params.MerchantIDs)
 	if err != nil {
 		return 0, fmt.Errorf("Unable to load transactions. Please try again.")
 	}
@@ -44,11 +50,33 @@ func (s *Service) ExportTransactions(params ExportParams) (int, error) {
 		mainCurrency = database.DefaultCurrency()
 	}
 
+	var comparison *Comparison
+	if params.PreviousStartDate != "" || params.PreviousEndDate != "" {
+		if strings.ToLower(params.Format) != "xlsx" {
+			return 0, fmt.Errorf("Comparison sheets are only available in Excel exports.")
+		}
+		c, err := s.Compare(ComparisonOptions{
+			CurrentStart:  params.StartDate,
+			CurrentEnd:    params.EndDate,
+			PreviousStart: params.PreviousStartDate,
+			PreviousEnd:   params.PreviousEndDate,
+			GroupBy:       database.SummaryGroupCategory,
+			CategoryIDs:   params.CategoryIDs,
+			OwnerIDs:      params.OwnerIDs,
+			TagIDs:        params.TagIDs,
+			MerchantIDs:   params.MerchantIDs,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("Unable to compare the date ranges. Please check them and try again.")
+		}
+		comparison = &c
+	}
+
 	switch strings.ToLower(params.Format) {
 	case "csv":
 		return exportToCSV(s.store, transactions, params.DestinationPath, mainCurrency)
 	case "xlsx":
-		return exportToXLSX(s.store, transactions, params.DestinationPath, mainCurrency)
+		return exportToXLSX(s.store, transactions, params.DestinationPath, mainCurrency, comparison)
 	default:
 		return 0, fmt.Errorf("Unsupported format. Please choose CSV or Excel.")
 	}
@@ -87,17 +115,28 @@ type exportRow struct {
 	MainAmount     *int64
 	OriginalAmount int64
 	Currency       string
+	CategoryID     *int64
+	TransferLinkID *int64
 	Category       string
+	TopCategory    string
 	Account        string
 	Owner          string
+	Tags           string
+	Notes          string
+	Merchant       string
 }
 
 func buildExportRows(store *database.Store, transactions []database.TransactionModel, mainCurrency string) ([]exportRow, error) {
 	rows := make([]exportRow, 0, len(transactions))
 	for _, tx := range transactions {
-		category := tx.CategoryName
+		category := tx.CategoryFullName
+		if category == "" {
+			category = tx.CategoryName
+		}
+		topCategory := tx.TopCategoryName
 		if tx.CategoryID == nil {
 			category = ""
+			topCategory = ""
 		}
 
 		var mainAmount *int64
@@ -113,14 +152,32 @@ func buildExportRows(store *database.Store, transactions []database.TransactionM
 			MainAmount:     mainAmount,
 			OriginalAmount: tx.Amount,
 			Currency:       tx.Currency,
+			CategoryID:     tx.CategoryID,
+			TransferLinkID: tx.TransferLinkID,
 			Category:       category,
+			TopCategory:    topCategory,
 			Account:        tx.AccountName,
 			Owner:          tx.OwnerName,
+			Tags:           strings.Join(tx.Tags, database.TagSeparator),
+			Notes:          exportNotes(tx),
+			Merchant:       tx.MerchantName,
 		})
 	}
 	return rows, nil
 }
 
+// exportNotes returns the transaction note, followed by the line's own note
+// for split lines.
+func exportNotes(tx database.TransactionModel) string {
+	notes := make([]string, 0, 2)
+	for _, note := range []string{tx.Note, tx.SplitNote} {
+		if note != "" {
+			notes = append(notes, note)
+		}
+	}
+	return strings.Join(notes, "; ")
+}
+
 func exportToCSV(store *database.Store, transactions []database.TransactionModel, destinationPath string, mainCurrency string) (int, error) {
 	rows, err := buildExportRows(store, transactions, mainCurrency)
 	if err != nil {
@@ -141,7 +198,7 @@ func exportToCSV(store *database.Store, transactions []database.TransactionModel
 	writer.UseCRLF = true
 	defer writer.Flush()
 
-	header := []string{"Date", "Description", "Amount (Main)", "Amount (Original)", "Currency (Original)", "Category", "Account", "Owner"}
+	header := []string{"Date", "Description", "Amount (Main)", "Amount (Original)", "Currency (Original)", "Category", "Top Category", "Account", "Owner", "Tags", "Notes", "Merchant"}
 	if err := writer.Write(header); err != nil {
 		return 0, fmt.Errorf("Unable to write the export file. Please check disk space and permissions.")
 	}
@@ -158,8 +215,12 @@ func exportToCSV(store *database.Store, transactions []database.TransactionModel
 			formatCents(r.OriginalAmount),
 			SanitizeCSVField(r.Currency),
 			SanitizeCSVField(r.Category),
+			SanitizeCSVField(r.TopCategory),
 			SanitizeCSVField(r.Account),
 			SanitizeCSVField(r.Owner),
+			SanitizeCSVField(r.Tags),
+			SanitizeCSVField(r.Notes),
+			SanitizeCSVField(r.Merchant),
 		}
 		if err := writer.Write(row); err != nil {
 			return 0, fmt.Errorf("Unable to write the export file. Please check disk space and permissions.")
@@ -169,7 +230,7 @@ func exportToCSV(store *database.Store, transactions []database.TransactionModel
 	return len(rows), nil
 }
 
-func exportToXLSX(store *database.Store, transactions []database.TransactionModel, destinationPath string, mainCurrency string) (int, error) {
+func exportToXLSX(store *database.Store, transactions []database.TransactionModel, destinationPath string, mainCurrency string, comparison *Comparison) (int, error) {
 	rows, err := buildExportRows(store, transactions, mainCurrency)
 	if err != nil {
 		return 0, err
@@ -179,8 +240,8 @@ func exportToXLSX(store *database.Store, transactions []database.TransactionMode
 	sheetName := "Transactions"
 	f.SetSheetName("Sheet1", sheetName)
 
-	headers := []string{"Date", "Description", "Amount (Main)", "Amount (Original)", "Currency (Original)", "Category", "Account", "Owner"}
-	cols := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	headers := []string{"Date", "Description", "Amount (Main)", "Amount (Original)", "Currency (Original)", "Category", "Top Category", "Account", "Owner", "Tags", "Notes", "Merchant"}
+	cols := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"}
 
 	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
 	if err != nil {
@@ -217,8 +278,12 @@ func exportToXLSX(store *database.Store, transactions []database.TransactionMode
 			centsToFloat64(r.OriginalAmount),
 			r.Currency,
 			r.Category,
+			r.TopCategory,
 			r.Account,
 			r.Owner,
+			r.Tags,
+			r.Notes,
+			r.Merchant,
 		}
 
 		for j, val := range values {
@@ -253,9 +318,17 @@ func exportToXLSX(store *database.Store, transactions []database.TransactionMode
 			case 5:
 				value = r.Category
 			case 6:
-				value = r.Account
+				value = r.TopCategory
 			case 7:
+				value = r.Account
+			case 8:
 				value = r.Owner
+			case 9:
+				value = r.Tags
+			case 10:
+				value = r.Notes
+			case 11:
+				value = r.Merchant
 			}
 			width := float64(len(value))
 			if width > maxWidth {
@@ -274,9 +347,243 @@ func exportToXLSX(store *database.Store, transactions []database.TransactionMode
 		}
 	}
 
+	if err := writeSummarySheets(f, store, rows, headerStyle, amountStyle); err != nil {
+		return 0, fmt.Errorf("Unable to create the Excel file. Please try again.")
+	}
+	if comparison != nil {
+		if err := writeComparisonSheets(f, *comparison, headerStyle, amountStyle); err != nil {
+			return 0, fmt.Errorf("Unable to create the Excel file. Please try again.")
+		}
+	}
+
 	if err := f.SaveAs(destinationPath); err != nil {
 		return 0, fmt.Errorf("Unable to save the Excel file. Please check disk space and permissions.")
 	}
 
 	return len(rows), nil
 }
+
+// writeSummarySheets adds a Summary sheet with totals by category kind and a
+// Category Totals sheet with per-category totals, all in the main currency.
+func writeSummarySheets(f *excelize.File, store *database.Store, rows []exportRow, headerStyle, amountStyle int) error {
+	categories, err := store.GetAllCategories()
+	if err != nil {
+		return err
+	}
+	txs := make([]database.TransactionModel, len(rows))
+	for i, r := range rows {
+		txs[i] = database.TransactionModel{CategoryID: r.CategoryID, TransferLinkID: r.TransferLinkID, AmountInMainCurrency: r.MainAmount}
+	}
+
+	if err := writeKindTotalsSheet(f, database.BuildKindTotals(categories, txs), headerStyle, amountStyle); err != nil {
+		return err
+	}
+	return writeCategoryTotalsSheet(f, database.BuildCategoryRollups(categories, txs), headerStyle, amountStyle)
+}
+
+// writeKindTotalsSheet lists income, expenses and net, followed by the
+// transfer and excluded amounts left out of them.
+func writeKindTotalsSheet(f *excelize.File, totals database.KindTotals, headerStyle, amountStyle int) error {
+	sheetName := "Summary"
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return err
+	}
+
+	lines := []struct {
+		label  string
+		amount int64
+	}{
+		{"Income", totals.Income},
+		{"Expenses", totals.Expenses},
+		{"Net", totals.Net},
+		{"Transfers (not in net)", totals.Transfers},
+		{"Excluded (not in net)", totals.Excluded},
+	}
+	headers := []string{"Total", "Amount (Main)"}
+	cols := []string{"A", "B"}
+	for i, header := range headers {
+		cell := cols[i] + "1"
+		if err := f.SetCellValue(sheetName, cell, header); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheetName, cell, cell, headerStyle); err != nil {
+			return err
+		}
+	}
+	for i, line := range lines {
+		row := strconv.Itoa(i + 2)
+		if err := f.SetCellValue(sheetName, "A"+row, line.label); err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheetName, "B"+row, centsToFloat64(line.amount)); err != nil {
+			return err
+		}
+		_ = f.SetCellStyle(sheetName, "B"+row, "B"+row, amountStyle)
+	}
+	return f.SetColWidth(sheetName, "A", "B", 24)
+}
+
+// writeCategoryTotalsSheet lists rollups. Total includes subcategories; Own
+// Total does not.
+func writeCategoryTotalsSheet(f *excelize.File, rollups []database.CategoryRollup, headerStyle, amountStyle int) error {
+	sheetName := "Category Totals"
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return err
+	}
+
+	headers := []string{"Category", "Top Category", "Kind", "Level", "Transactions", "Total (Main)", "Own Total (Main)"}
+	cols := []string{"A", "B", "C", "D", "E", "F", "G"}
+	for i, header := range headers {
+		cell := cols[i] + "1"
+		if err := f.SetCellValue(sheetName, cell, header); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheetName, cell, cell, headerStyle); err != nil {
+			return err
+		}
+	}
+
+	// Every ancestor of a listed category is listed too, so the top-level
+	// category is Depth parents up.
+	byID := make(map[int64]database.CategoryRollup, len(rollups))
+	for _, r := range rollups {
+		byID[r.CategoryID] = r
+	}
+	for i, r := range rollups {
+		row := strconv.Itoa(i + 2)
+		top := r
+		for step := 0; step < r.Depth && top.ParentID != nil; step++ {
+			top = byID[*top.ParentID]
+		}
+		values := []interface{}{r.FullName, top.Name, r.Kind, r.Depth + 1, r.Count, centsToFloat64(r.Total), centsToFloat64(r.OwnTotal)}
+		for j, val := range values {
+			cell := cols[j] + row
+			if err := f.SetCellValue(sheetName, cell, val); err != nil {
+				return err
+			}
+			if j >= 5 {
+				_ = f.SetCellStyle(sheetName, cell, cell, amountStyle)
+			}
+		}
+	}
+
+	if err := f.SetColWidth(sheetName, "A", "B", 30); err != nil {
+		return err
+	}
+	return f.SetColWidth(sheetName, "C", "G", 16)
+}
+
+// writeSheetHeaders adds a sheet with bold headers in its first row.
+func writeSheetHeaders(f *excelize.File, sheetName string, headers []string, headerStyle int) error {
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return err
+	}
+	for i, header := range headers {
+		cell, err := excelize.CoordinatesToCellName(i+1, 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheetName, cell, header); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheetName, cell, cell, headerStyle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSheetRow writes values to a row; the columns in amountCols get
+// amountStyle.
+func writeSheetRow(f *excelize.File, sheetName string, row int, values []interface{}, amountCols map[int]bool, amountStyle int) error {
+	for i, val := range values {
+		cell, err := excelize.CoordinatesToCellName(i+1, row)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheetName, cell, val); err != nil {
+			return err
+		}
+		if amountCols[i] {
+			_ = f.SetCellStyle(sheetName, cell, cell, amountStyle)
+		}
+	}
+	return nil
+}
+
+// writeComparisonSheets adds a Comparison sheet with income, expenses and net
+// followed by per-category amounts for both ranges, and a Merchant Changes
+// sheet with new and disappeared merchants.
+func writeComparisonSheets(f *excelize.File, c Comparison, headerStyle, amountStyle int) error {
+	sheetName := "Comparison"
+	previousHeader := fmt.Sprintf("Previous (%s to %s)", c.Previous.StartDate, c.Previous.EndDate)
+	currentHeader := fmt.Sprintf("Current (%s to %s)", c.Current.StartDate, c.Current.EndDate)
+	if err := writeSheetHeaders(f, sheetName, []string{"Group", previousHeader, currentHeader, "Change", "Change %"}, headerStyle); err != nil {
+		return err
+	}
+	amounts := map[int]bool{1: true, 2: true, 3: true}
+	percent := func(p *float64) interface{} {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	row := 2
+	for _, line := range []struct {
+		label             string
+		previous, current int64
+	}{
+		{"Total income", c.Previous.Totals.Income, c.Current.Totals.Income},
+		{"Total expenses", c.Previous.Totals.Expenses, c.Current.Totals.Expenses},
+		{"Total net", c.Previous.Totals.Net, c.Current.Totals.Net},
+	} {
+		delta := line.current - line.previous
+		values := []interface{}{line.label, centsToFloat64(line.previous), centsToFloat64(line.current), centsToFloat64(delta), percent(comparisonPercent(delta, line.previous))}
+		if err := writeSheetRow(f, sheetName, row, values, amounts, amountStyle); err != nil {
+			return err
+		}
+		row++
+	}
+	row++
+	for _, r := range c.Rows {
+		values := []interface{}{r.Group, centsToFloat64(r.Previous), centsToFloat64(r.Current), centsToFloat64(r.Delta), percent(r.DeltaPercent)}
+		if err := writeSheetRow(f, sheetName, row, values, amounts, amountStyle); err != nil {
+			return err
+		}
+		row++
+	}
+	if err := f.SetColWidth(sheetName, "A", "A", 30); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheetName, "B", "C", 28); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheetName, "D", "E", 14); err != nil {
+		return err
+	}
+
+	sheetName = "Merchant Changes"
+	if err := writeSheetHeaders(f, sheetName, []string{"Change", "Merchant", "Description", "Transactions", "Spent (Main)"}, headerStyle); err != nil {
+		return err
+	}
+	row = 2
+	for _, group := range []struct {
+		label     string
+		merchants []ComparisonMerchant
+	}{
+		{"New", c.NewMerchants},
+		{"Disappeared", c.DisappearedMerchants},
+	} {
+		for _, m := range group.merchants {
+			values := []interface{}{group.label, m.Merchant, m.Description, m.Count, centsToFloat64(m.Amount)}
+			if err := writeSheetRow(f, sheetName, row, values, map[int]bool{4: true}, amountStyle); err != nil {
+				return err
+			}
+			row++
+		}
+	}
+	if err := f.SetColWidth(sheetName, "A", "A", 14); err != nil {
+		return err
+	}
+	return f.SetColWidth(sheetName, "B", "C", 30)
+}
@@ -0,0 +1,380 @@
+package cashmop
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+const (
+	DefaultForecastMonths = 6
+	MaxForecastMonths     = 24
+
+	// forecastHistoryMonths is how many full months of history feed the
+	// trailing averages and recurring detection.
+	forecastHistoryMonths = 12
+	// forecastBandZ makes the low/high bands a 90% interval.
+	forecastBandZ = 1.645
+)
+
+var ErrInvalidForecastMonths = errors.New("forecast months must be between 1 and 24")
+
+// ForecastPoint is the projected amount of one series in one month, with its
+// confidence band.
+type ForecastPoint struct {
+	Month  string `json:"month"`
+	Amount int64  `json:"amount"`
+	Low    int64  `json:"low"`
+	High   int64  `json:"high"`
+}
+
+// ForecastSeries is the projection for one category or account. ID is nil for
+// uncategorized transactions.
+type ForecastSeries struct {
+	ID     *int64          `json:"id"`
+	Name   string          `json:"name"`
+	Points []ForecastPoint `json:"points"`
+}
+
+type ForecastMonth struct {
+	Month    string `json:"month"`
+	Income   int64  `json:"income"`
+	Expenses int64  `json:"expenses"`
+	Net      int64  `json:"net"`
+	Low      int64  `json:"low"`
+	High     int64  `json:"high"`
+	// Balance is the projected balance of all accounts at the end of this
+	// month: Forecast.OpeningBalance plus the net of every month so far.
+	Balance     int64 `json:"balance"`
+	BalanceLow  int64 `json:"balance_low"`
+	BalanceHigh int64 `json:"balance_high"`
+}
+
+// Forecast projects cash flow for the months after the current one from the
+// recurring series and trailing monthly averages of the history window.
+// Amounts are in the main currency; transfers and excluded categories are
+// left out of the flows.
+type Forecast struct {
+	MainCurrency string `json:"main_currency"`
+	HistoryStart string `json:"history_start"`
+	HistoryEnd   string `json:"history_end"`
+	// OpeningBalance is the balance of all accounts at the end of the current
+	// month, from the transactions recorded so far.
+	OpeningBalance int64            `json:"opening_balance"`
+	Months         []ForecastMonth  `json:"months"`
+	Categories     []ForecastSeries `json:"categories"`
+	// Accounts holds the projected flow of each account and Balances its
+	// projected balance at the end of each month.
+	Accounts  []ForecastSeries  `json:"accounts"`
+	Balances  []ForecastSeries  `json:"balances"`
+	Recurring []RecurringSeries `json:"recurring"`
+}
+
+// forecastCell is the projection for one category and account in one month.
+type forecastCell struct {
+	amount   float64
+	variance float64
+}
+
+type forecastGroup struct {
+	categoryID int64 // 0 when uncategorized
+	accountID  int64
+}
+
+// Forecast projects the next months (1 to MaxForecastMonths) from the last
+// forecastHistoryMonths full months.
+func (s *Service) Forecast(months int) (Forecast, error) {
+	return s.forecastAt(time.Now(), months)
+}
+
+func (s *Service) forecastAt(now time.Time, months int) (Forecast, error) {
+	if months < 1 || months > MaxForecastMonths {
+		return Forecast{}, ErrInvalidForecastMonths
+	}
+
+	settings, err := s.store.GetCurrencySettings()
+	if err != nil {
+		return Forecast{}, err
+	}
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	historyStart := currentMonth.AddDate(0, -forecastHistoryMonths, 0)
+	historyEnd := currentMonth.AddDate(0, 0, -1)
+	forecast := Forecast{
+		MainCurrency: settings.MainCurrency,
+		HistoryStart: historyStart.Format("2006-01-02"),
+		HistoryEnd:   historyEnd.Format("2006-01-02"),
+	}
+
+	all, err := s.store.GetAnalysisTransactions(forecast.HistoryStart, forecast.HistoryEnd, nil, nil, nil, nil)
+	if err != nil {
+		return Forecast{}, err
+	}
+	txs := make([]database.TransactionModel, 0, len(all))
+	for _, tx := range all {
+		if tx.AmountInMainCurrency != nil && database.CountsTowardsTotals(tx.CategoryKind) {
+			txs = append(txs, tx)
+		}
+	}
+
+	forecast.Recurring = []RecurringSeries{}
+	for _, r := range detectRecurring(txs, currentMonth) {
+		if r.Active {
+			forecast.Recurring = append(forecast.Recurring, r)
+		}
+	}
+	recurringIDs := map[int64]bool{}
+	for _, r := range forecast.Recurring {
+		for _, id := range r.transactionIDs {
+			recurringIDs[id] = true
+		}
+	}
+
+	categoryNames := map[int64]string{0: "Uncategorized"}
+	accountNames := map[int64]string{}
+	// Trailing averages of everything that is not recurring, per category and
+	// account. Months before the first transaction do not count, so a short
+	// history is not averaged down.
+	firstMonth := currentMonth
+	monthly := map[forecastGroup]map[string]int64{}
+	for _, tx := range txs {
+		group := forecastGroup{accountID: tx.AccountID}
+		if tx.CategoryID != nil {
+			group.categoryID = *tx.CategoryID
+			name := tx.CategoryFullName
+			if name == "" {
+				name = tx.CategoryName
+			}
+			categoryNames[group.categoryID] = name
+		}
+		accountNames[tx.AccountID] = tx.AccountName
+		if date, err := time.Parse("2006-01-02", tx.Date); err == nil {
+			if m := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC); m.Before(firstMonth) {
+				firstMonth = m
+			}
+		}
+		if tx.SplitID == nil && recurringIDs[tx.ID] {
+			continue
+		}
+		if monthly[group] == nil {
+			monthly[group] = map[string]int64{}
+		}
+		monthly[group][tx.Date[:7]] += *tx.AmountInMainCurrency
+	}
+	var historyMonths []string
+	for m := firstMonth; m.Before(currentMonth); m = m.AddDate(0, 1, 0) {
+		historyMonths = append(historyMonths, m.Format("2006-01"))
+	}
+
+	baseline := map[forecastGroup]forecastCell{}
+	for group, totals := range monthly {
+		n := float64(len(historyMonths))
+		var sum float64
+		for _, m := range historyMonths {
+			sum += float64(totals[m])
+		}
+		mean := sum / n
+		var sumSq float64
+		for _, m := range historyMonths {
+			d := float64(totals[m]) - mean
+			sumSq += d * d
+		}
+		baseline[group] = forecastCell{amount: mean, variance: sumSq / n}
+	}
+
+	// Project each month: the baseline plus every recurring occurrence that
+	// falls in it.
+	projected := make([]map[forecastGroup]forecastCell, months)
+	monthKeys := make([]string, months)
+	for i := range projected {
+		projected[i] = map[forecastGroup]forecastCell{}
+		for group, cell := range baseline {
+			projected[i][group] = cell
+		}
+		monthKeys[i] = currentMonth.AddDate(0, i+1, 0).Format("2006-01")
+	}
+	forecastStart := currentMonth.AddDate(0, 1, 0)
+	forecastEnd := currentMonth.AddDate(0, months+1, 0)
+	for _, r := range forecast.Recurring {
+		group := forecastGroup{accountID: r.AccountID}
+		if r.CategoryID != nil {
+			group.categoryID = *r.CategoryID
+		}
+		date, err := time.Parse("2006-01-02", r.NextDate)
+		if err != nil {
+			continue
+		}
+		for ; date.Before(forecastEnd); date = nextRecurringDate(date, r.cadence) {
+			if date.Before(forecastStart) {
+				continue
+			}
+			i := (date.Year()-forecastStart.Year())*12 + int(date.Month()) - int(forecastStart.Month())
+			cell := projected[i][group]
+			cell.amount += float64(r.Amount)
+			cell.variance += r.spread * r.spread
+			projected[i][group] = cell
+		}
+	}
+
+	opening, err := s.openingBalances(currentMonth.AddDate(0, 1, -1))
+	if err != nil {
+		return Forecast{}, err
+	}
+	accounts, err := s.store.GetAccountMap()
+	if err != nil {
+		return Forecast{}, err
+	}
+	for name, id := range accounts {
+		accountNames[id] = name
+	}
+	accountBalances := map[int64]forecastCell{}
+	for id, amount := range opening {
+		accountBalances[id] = forecastCell{amount: float64(amount)}
+		forecast.OpeningBalance += amount
+	}
+
+	categorySeries := map[int64]*ForecastSeries{}
+	accountSeries := map[int64]*ForecastSeries{}
+	balanceSeries := map[int64]*ForecastSeries{}
+	balance := float64(forecast.OpeningBalance)
+	var balanceVariance float64
+	for i, cells := range projected {
+		categoryCells := map[int64]forecastCell{}
+		accountCells := map[int64]forecastCell{}
+		var month ForecastMonth
+		var variance float64
+		for group, cell := range cells {
+			amount := int64(math.Round(cell.amount))
+			if amount > 0 {
+				month.Income += amount
+			} else {
+				month.Expenses += amount
+			}
+			variance += cell.variance
+
+			c := categoryCells[group.categoryID]
+			c.amount += cell.amount
+			c.variance += cell.variance
+			categoryCells[group.categoryID] = c
+			a := accountCells[group.accountID]
+			a.amount += cell.amount
+			a.variance += cell.variance
+			accountCells[group.accountID] = a
+		}
+
+		month.Month = monthKeys[i]
+		month.Net = month.Income + month.Expenses
+		band := forecastBandZ * math.Sqrt(variance)
+		month.Low = int64(math.Round(float64(month.Net) - band))
+		month.High = int64(math.Round(float64(month.Net) + band))
+		balance += float64(month.Net)
+		balanceVariance += variance
+		balanceBand := forecastBandZ * math.Sqrt(balanceVariance)
+		month.Balance = int64(math.Round(balance))
+		month.BalanceLow = int64(math.Round(balance - balanceBand))
+		month.BalanceHigh = int64(math.Round(balance + balanceBand))
+		forecast.Months = append(forecast.Months, month)
+
+		for id, cell := range accountCells {
+			b := accountBalances[id]
+			b.amount += cell.amount
+			b.variance += cell.variance
+			accountBalances[id] = b
+		}
+
+		addForecastPoints(categorySeries, categoryCells, categoryNames, monthKeys[i])
+		addForecastPoints(accountSeries, accountCells, accountNames, monthKeys[i])
+		addForecastPoints(balanceSeries, accountBalances, accountNames, monthKeys[i])
+	}
+
+	forecast.Categories = sortedForecastSeries(categorySeries)
+	for i := range forecast.Categories {
+		if forecast.Categories[i].ID != nil && *forecast.Categories[i].ID == 0 {
+			forecast.Categories[i].ID = nil
+		}
+	}
+	forecast.Accounts = sortedForecastSeries(accountSeries)
+	forecast.Balances = sortedForecastSeries(balanceSeries)
+	return forecast, nil
+}
+
+// openingBalances returns the balance of each account at the end of date in
+// the main currency: from its latest checkpoint when it has one, otherwise the
+// sum of its transactions. Amounts without an FX rate are left out.
+func (s *Service) openingBalances(date time.Time) (map[int64]int64, error) {
+	balances := map[int64]int64{}
+	netWorth, err := s.store.GetNetWorth(date.Format("2006-01"), date.Format("2006-01"))
+	if err != nil {
+		return nil, err
+	}
+	checkpointed := map[int64]bool{}
+	for _, point := range netWorth.Points {
+		for _, a := range point.Accounts {
+			if a.BalanceInMainCurrency != nil {
+				balances[a.AccountID] = *a.BalanceInMainCurrency
+				checkpointed[a.AccountID] = true
+			}
+		}
+	}
+
+	txs, err := s.store.GetAnalysisTransactions("0000-01-01", date.Format("2006-01-02"), nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		if !checkpointed[tx.AccountID] && tx.AmountInMainCurrency != nil {
+			balances[tx.AccountID] += *tx.AmountInMainCurrency
+		}
+	}
+	return balances, nil
+}
+
+func addForecastPoints(series map[int64]*ForecastSeries, cells map[int64]forecastCell, names map[int64]string, month string) {
+	for id, cell := range cells {
+		s, ok := series[id]
+		if !ok {
+			id := id
+			s = &ForecastSeries{ID: &id, Name: names[id]}
+			series[id] = s
+		}
+		band := forecastBandZ * math.Sqrt(cell.variance)
+		s.Points = append(s.Points, ForecastPoint{
+			Month:  month,
+			Amount: int64(math.Round(cell.amount)),
+			Low:    int64(math.Round(cell.amount - band)),
+			High:   int64(math.Round(cell.amount + band)),
+		})
+	}
+}
+
+// sortedForecastSeries orders series by their largest projected amounts,
+// expenses first.
+func sortedForecastSeries(series map[int64]*ForecastSeries) []ForecastSeries {
+	ids := make([]int64, 0, len(series))
+	totals := make(map[int64]int64, len(series))
+	for id, s := range series {
+		var total int64
+		for _, p := range s.Points {
+			total += p.Amount
+		}
+		totals[id] = total
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		if totals[a] != totals[b] {
+			return totals[a] < totals[b]
+		}
+		if series[a].Name != series[b].Name {
+			return series[a].Name < series[b].Name
+		}
+		return a < b
+	})
+	out := make([]ForecastSeries, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, *series[id])
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package cashmop
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func TestForecast(t *testing.T) {
+	svc := newTestService(t)
+
+	var txs []TransactionImportInput
+	for m := 1; m <= 6; m++ {
+		txs = append(txs,
+			TransactionImportInput{Date: fmt.Sprintf("2025-%02d-05", m), Description: fmt.Sprintf("NETFLIX.COM %d", m), Amount: -1599, Category: "Subscriptions", Account: "Visa"},
+			TransactionImportInput{Date: fmt.Sprintf("2025-%02d-20", m), Description: fmt.Sprintf("GROCER %c", 'A'+m), Amount: int64(-9000 - 1000*(m%2)), Category: "Groceries", Account: "Visa"},
+			TransactionImportInput{Date: fmt.Sprintf("2025-%02d-25", m), Description: "CARD PAYMENT", Amount: -50000, Category: "Card Payment", Account: "Chequing"},
+		)
+	}
+	for d := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC); d.Month() <= time.June; d = d.AddDate(0, 0, 14) {
+		txs = append(txs, TransactionImportInput{Date: d.Format("2006-01-02"), Description: "PAYROLL", Amount: 250000, Category: "Salary", Account: "Chequing"})
+	}
+	seedTransactions(t, svc, txs)
+	for name, kind := range map[string]string{"Salary": database.CategoryKindIncome, "Card Payment": database.CategoryKindTransfer} {
+		id, err := svc.store.GetOrCreateCategory(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.store.SetCategoryKind(id, kind); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	forecast, err := svc.forecastAt(time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC), 3)
+	if err != nil {
+		t.Fatalf("forecast failed: %v", err)
+	}
+	if forecast.HistoryStart != "2024-07-01" || forecast.HistoryEnd != "2025-06-30" || len(forecast.Months) != 3 {
+		t.Fatalf("unexpected forecast: %+v", forecast)
+	}
+	if len(forecast.Recurring) != 2 {
+		t.Fatalf("expected Netflix and payroll to recur, got %+v", forecast.Recurring)
+	}
+
+	// Without checkpoints the opening balance is the sum of all transactions.
+	var opening int64
+	for _, tx := range txs {
+		opening += tx.Amount
+	}
+	if forecast.OpeningBalance != opening {
+		t.Errorf("expected opening balance %d, got %d", opening, forecast.OpeningBalance)
+	}
+
+	// Payroll lands on Aug 1, 15 and 29, Sep 12 and 26, and Oct 10 and 24.
+	wantIncome := []int64{750000, 500000, 500000}
+	balance := opening
+	for i, m := range forecast.Months {
+		if m.Month != fmt.Sprintf("2025-%02d", 8+i) {
+			t.Errorf("unexpected month %q", m.Month)
+		}
+		if m.Income != wantIncome[i] {
+			t.Errorf("%s: expected income %d, got %d", m.Month, wantIncome[i], m.Income)
+		}
+		// Netflix plus the average grocery month; the card payment is a transfer.
+		if m.Expenses != -1599-9500 {
+			t.Errorf("%s: expected expenses %d, got %d", m.Month, -1599-9500, m.Expenses)
+		}
+		if !(m.Low < m.Net && m.Net < m.High) {
+			t.Errorf("%s: expected a band around net, got %+v", m.Month, m)
+		}
+		balance += m.Net
+		if m.Balance != balance || m.BalanceHigh-m.BalanceLow < m.High-m.Low {
+			t.Errorf("%s: unexpected balance %+v", m.Month, m)
+		}
+	}
+
+	names := map[string]ForecastSeries{}
+	for _, s := range forecast.Categories {
+		names[s.Name] = s
+	}
+	if _, ok := names["Card Payment"]; ok {
+		t.Errorf("transfers should not be forecast: %+v", forecast.Categories)
+	}
+	if p := names["Subscriptions"].Points[0]; p.Amount != -1599 || p.Low != -1599 || p.High != -1599 {
+		t.Errorf("expected an exact Netflix projection, got %+v", p)
+	}
+	if p := names["Groceries"].Points[0]; p.Amount != -9500 || p.Low >= p.Amount {
+		t.Errorf("unexpected groceries projection: %+v", p)
+	}
+	if len(forecast.Accounts) != 2 || len(forecast.Balances) != 2 {
+		t.Errorf("expected Visa and Chequing, got %+v and %+v", forecast.Accounts, forecast.Balances)
+	}
+
+	// A checkpoint replaces the transaction sum of its account.
+	accounts, err := svc.store.GetAccountMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.store.SetBalanceCheckpoint(database.BalanceCheckpoint{AccountID: accounts["Chequing"], Date: "2025-07-01", Balance: 1000000, Currency: "CAD", Source: database.BalanceSourceManual}); err != nil {
+		t.Fatal(err)
+	}
+	forecast, err = svc.forecastAt(time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC), 3)
+	if err != nil {
+		t.Fatalf("forecast failed: %v", err)
+	}
+	var visa int64
+	for _, tx := range txs {
+		if tx.Account == "Visa" {
+			visa += tx.Amount
+		}
+	}
+	if forecast.OpeningBalance != 1000000+visa {
+		t.Errorf("expected opening balance %d, got %d", 1000000+visa, forecast.OpeningBalance)
+	}
+	flows := map[string]int64{}
+	for _, s := range forecast.Accounts {
+		flows[s.Name] = s.Points[0].Amount
+	}
+	for _, s := range forecast.Balances {
+		want := visa + flows["Visa"]
+		if s.Name == "Chequing" {
+			want = 1000000 + flows["Chequing"]
+		}
+		if p := s.Points[0]; p.Amount != want || p.Low > p.Amount || p.High < p.Amount {
+			t.Errorf("%s: expected balance %d, got %+v", s.Name, want, p)
+		}
+	}
+
+	for _, months := range []int{0, MaxForecastMonths + 1} {
+		if _, err := svc.forecastAt(time.Now(), months); !errors.Is(err, ErrInvalidForecastMonths) {
+			t.Errorf("months %d: expected ErrInvalidForecastMonths, got %v", months, err)
+		}
+	}
+}
+
+func TestSortedForecastSeriesSameName(t *testing.T) {
+	// A category named "Uncategorized" shares its name with the bucket of
+	// uncategorized transactions; each must be ranked by its own total.
+	small, large := int64(0), int64(5)
+	series := map[int64]*ForecastSeries{
+		small: {ID: &small, Name: "Uncategorized", Points: []ForecastPoint{{Amount: -100}}},
+		large: {ID: &large, Name: "Uncategorized", Points: []ForecastPoint{{Amount: -5000}}},
+	}
+	sorted := sortedForecastSeries(series)
+	if len(sorted) != 2 || *sorted[0].ID != large || *sorted[1].ID != small {
+		t.Errorf("expected the larger series first, got %+v", sorted)
+	}
+}
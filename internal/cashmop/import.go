@@ -15,10 +15,14 @@ type TransactionImportInput struct {
 	Account     string
 	Owner       string
 	Currency    string
+	Note        string
 }
 
 type ImportOptions struct {
 	ApplyRules bool
+	// AutoAcceptThreshold categorizes remaining uncategorized transactions whose top
+	// prediction confidence is at least this value. Zero disables auto-accept.
+	AutoAcceptThreshold float64
 }
 
 func (s *Service) ImportTransactions(transactions []TransactionImportInput, opts ImportOptions) error {
@@ -94,13 +98,15 @@ func (s *Service) ImportTransactions(transactions []TransactionImportInput, opts
 			OwnerID:     ownerID,
 			Date:        t.Date,
 			Description: t.Description,
+			Note:        strings.TrimSpace(t.Note),
 			Amount:      t.Amount,
 			CategoryID:  catID,
 			Currency:    currency,
 		})
 	}
 
-	if err := s.store.BatchInsertTransactions(txModels); err != nil {
+	insertedIDs, err := s.store.BatchInsertTransactionsWithIDs(txModels)
+	if err != nil {
 		return err
 	}
 
@@ -110,6 +116,12 @@ func (s *Service) ImportTransactions(transactions []TransactionImportInput, opts
 		}
 	}
 
+	if opts.AutoAcceptThreshold > 0 {
+		if _, err := s.AutoAcceptPredictions(opts.AutoAcceptThreshold, insertedIDs); err != nil {
+			return err
+		}
+	}
+
 	s.store.ClearFxRateCache()
 
 	return nil
@@ -0,0 +1,421 @@
+package cashmop
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/default-anton/cashmop/internal/database"
+	"github.com/default-anton/cashmop/internal/fuzzy"
+)
+
+const (
+	MerchantSortSpend   = "spend"
+	MerchantSortVisits  = "visits"
+	MerchantSortAverage = "average"
+
+	// merchantPrefixMinLength is how long the first word of a description
+	// must be to anchor a cluster on its own; shorter ones ("sq", "pp") are
+	// payment processors and take the next word along.
+	merchantPrefixMinLength = 4
+	// merchantAliasMinLength is the shortest common prefix suggested as a
+	// starts_with alias.
+	merchantAliasMinLength = 3
+	// daysPerMonth is the average month length used for visit frequency.
+	daysPerMonth = 365.25 / 12
+)
+
+var (
+	ErrInvalidMerchantSort  = errors.New("merchant report sort must be spend, visits, or average")
+	ErrInvalidMerchantRange = errors.New("merchant report range must be valid YYYY-MM-DD dates with the end not before the start")
+)
+
+func (s *Service) GetMerchants() ([]database.Merchant, error) {
+	return s.store.GetMerchants()
+}
+
+func (s *Service) GetMerchant(id int64) (database.Merchant, error) {
+	return s.store.GetMerchant(id)
+}
+
+func (s *Service) CreateMerchant(name string, aliases []database.MerchantAlias) (database.Merchant, error) {
+	return s.store.CreateMerchant(name, aliases)
+}
+
+func (s *Service) RenameMerchant(id int64, name string) (database.Merchant, error) {
+	return s.store.RenameMerchant(id, name)
+}
+
+func (s *Service) DeleteMerchant(id int64) error {
+	return s.store.DeleteMerchant(id)
+}
+
+func (s *Service) AddMerchantAlias(merchantID int64, alias database.MerchantAlias) (database.Merchant, error) {
+	return s.store.AddMerchantAlias(merchantID, alias)
+}
+
+func (s *Service) DeleteMerchantAlias(id int64) (database.Merchant, error) {
+	return s.store.DeleteMerchantAlias(id)
+}
+
+// merchantOf returns the key and name used to group a transaction by
+// merchant: its assigned merchant, or else its description reduced like a
+// recurring series key.
+func merchantOf(tx database.TransactionModel) (string, string) {
+	if tx.MerchantID != nil {
+		return fmt.Sprintf("#%d", *tx.MerchantID), tx.MerchantName
+	}
+	key := recurringKey(tx.Description)
+	return key, key
+}
+
+type MerchantSuggestionOptions struct {
+	// MinTransactions is how many transactions a cluster needs.
+	MinTransactions int `json:"min_transactions"`
+	Limit           int `json:"limit"`
+}
+
+// MerchantSuggestion is a cluster of description variants without a merchant
+// that look like one payee.
+type MerchantSuggestion struct {
+	Name string `json:"name"`
+	// MerchantID is set when a merchant of that name exists; the aliases
+	// belong on it rather than on a new merchant.
+	MerchantID *int64 `json:"merchant_id"`
+	// Aliases match every description of the cluster: one starts_with alias
+	// for their common prefix, or one exact alias per description when they
+	// share too little.
+	Aliases []database.MerchantAlias `json:"aliases"`
+	// Descriptions are the distinct descriptions, most frequent first.
+	Descriptions     []string `json:"descriptions"`
+	TransactionCount int      `json:"transaction_count"`
+}
+
+// clusterPrefix returns the words every key of a cluster seeded by key
+// starts with: its first word, or its first two when the first is short.
+func clusterPrefix(key string) string {
+	words := strings.Fields(key)
+	if len(words) == 0 {
+		return ""
+	}
+	if len(words[0]) >= merchantPrefixMinLength || len(words) == 1 {
+		return words[0]
+	}
+	return words[0] + " " + words[1]
+}
+
+func hasWordPrefix(key, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+" ")
+}
+
+// commonAliasPrefix returns the longest prefix all descriptions share
+// (ignoring ASCII case), cut back to a word boundary, in the spelling of the
+// first description.
+func commonAliasPrefix(descriptions []string) string {
+	first := descriptions[0]
+	n := len(first)
+	for _, d := range descriptions[1:] {
+		i := 0
+		for i < n && i < len(d) && asciiLower(first[i:i+1]) == asciiLower(d[i:i+1]) {
+			i++
+		}
+		n = i
+	}
+	// A prefix that ends inside a word of some description is cut back to
+	// the last separator.
+	whole := true
+	for _, d := range descriptions {
+		if len(d) > n && isWordByte(d[n]) && n > 0 && isWordByte(d[n-1]) {
+			whole = false
+		}
+	}
+	prefix := first[:n]
+	if !whole {
+		prefix = prefix[:strings.LastIndexFunc(prefix, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })+1]
+	}
+	return strings.TrimRightFunc(prefix, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+func isWordByte(b byte) bool {
+	return b >= 0x80 || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
+
+// merchantSuggestionName turns a key into a display name: "blue bottle" is
+// "Blue Bottle".
+func merchantSuggestionName(key string) string {
+	words := strings.Fields(key)
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
+
+// SuggestMerchants clusters the descriptions of transactions without a
+// merchant. Descriptions are reduced like recurring series keys; the shortest
+// key seeds a cluster, and the keys starting with the same word that fuzzy
+// match it join the cluster. Clusters with a single description are left
+// out: there is nothing to normalize.
+func (s *Service) SuggestMerchants(opts MerchantSuggestionOptions) ([]MerchantSuggestion, error) {
+	if opts.MinTransactions < 1 {
+		opts.MinTransactions = 2
+	}
+	txs, err := s.store.GetAnalysisTransactions("0000-01-01", "9999-12-31", nil, nil, nil, []int64{database.MissingFilterID})
+	if err != nil {
+		return nil, err
+	}
+	merchants, err := s.store.GetMerchants()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]int64, len(merchants))
+	for _, m := range merchants {
+		existing[recurringKey(m.Name)] = m.ID
+	}
+
+	type keyStats struct {
+		count        int
+		descriptions map[string]int
+	}
+	stats := map[string]*keyStats{}
+	seen := map[int64]bool{}
+	for _, tx := range txs {
+		// Split lines repeat their transaction.
+		if seen[tx.ID] {
+			continue
+		}
+		seen[tx.ID] = true
+		key := recurringKey(tx.Description)
+		if key == "" {
+			continue
+		}
+		st, ok := stats[key]
+		if !ok {
+			st = &keyStats{descriptions: map[string]int{}}
+			stats[key] = st
+		}
+		st.count++
+		st.descriptions[tx.Description]++
+	}
+
+	keys := make([]string, 0, len(stats))
+	// byFirstWord narrows the keys a seed is matched against.
+	byFirstWord := map[string][]string{}
+	for key := range stats {
+		keys = append(keys, key)
+		first := strings.Fields(key)[0]
+		byFirstWord[first] = append(byFirstWord[first], key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		if stats[keys[i]].count != stats[keys[j]].count {
+			return stats[keys[i]].count > stats[keys[j]].count
+		}
+		return keys[i] < keys[j]
+	})
+
+	clustered := map[string]bool{}
+	suggestions := []MerchantSuggestion{}
+	for _, seed := range keys {
+		if clustered[seed] {
+			continue
+		}
+		prefix := clusterPrefix(seed)
+		var candidates []string
+		for _, key := range byFirstWord[strings.Fields(seed)[0]] {
+			if !clustered[key] && hasWordPrefix(key, prefix) {
+				candidates = append(candidates, key)
+			}
+		}
+		descriptions := map[string]int{}
+		count := 0
+		for _, key := range fuzzy.Match(seed, candidates) {
+			clustered[key] = true
+			count += stats[key].count
+			for d, n := range stats[key].descriptions {
+				descriptions[d] += n
+			}
+		}
+		if len(descriptions) < 2 || count < opts.MinTransactions {
+			continue
+		}
+
+		sg := MerchantSuggestion{Name: merchantSuggestionName(seed), TransactionCount: count}
+		for d := range descriptions {
+			sg.Descriptions = append(sg.Descriptions, d)
+		}
+		sort.Slice(sg.Descriptions, func(i, j int) bool {
+			a, b := sg.Descriptions[i], sg.Descriptions[j]
+			if descriptions[a] != descriptions[b] {
+				return descriptions[a] > descriptions[b]
+			}
+			return a < b
+		})
+		if id, ok := existing[seed]; ok {
+			sg.MerchantID = &id
+		}
+		if p := commonAliasPrefix(sg.Descriptions); len(p) >= merchantAliasMinLength {
+			sg.Aliases = []database.MerchantAlias{{MatchType: "starts_with", Pattern: p}}
+		} else {
+			for _, d := range sg.Descriptions {
+				sg.Aliases = append(sg.Aliases, database.MerchantAlias{MatchType: "exact", Pattern: d})
+			}
+		}
+		suggestions = append(suggestions, sg)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].TransactionCount > suggestions[j].TransactionCount
+	})
+	if opts.Limit > 0 && len(suggestions) > opts.Limit {
+		suggestions = suggestions[:opts.Limit]
+	}
+	return suggestions, nil
+}
+
+// MerchantReportOptions selects the spending to report on. The filters work
+// like those of GetAnalysisTransactions.
+type MerchantReportOptions struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	// SortBy is MerchantSortSpend (the default), MerchantSortVisits or
+	// MerchantSortAverage.
+	SortBy string `json:"sort_by"`
+	// Limit keeps the top merchants; 0 keeps all.
+	Limit       int     `json:"limit"`
+	CategoryIDs []int64 `json:"category_ids"`
+	OwnerIDs    []int64 `json:"owner_ids"`
+	TagIDs      []int64 `json:"tag_ids"`
+}
+
+// MerchantStats is the spending at one merchant in the main currency. Spent
+// is net of refunds and positive for money out.
+type MerchantStats struct {
+	MerchantID int64  `json:"merchant_id"`
+	Name       string `json:"name"`
+	Spent      int64  `json:"spent"`
+	// Visits counts charges; refunds are not visits.
+	Visits         int     `json:"visits"`
+	VisitsPerMonth float64 `json:"visits_per_month"`
+	// AverageTicket is Spent per visit.
+	AverageTicket int64  `json:"average_ticket"`
+	FirstDate     string `json:"first_date"`
+	LastDate      string `json:"last_date"`
+}
+
+// MerchantReport ranks merchants by spending, visits or average ticket.
+// Income, transfers and excluded categories are left out.
+type MerchantReport struct {
+	StartDate    string          `json:"start_date"`
+	EndDate      string          `json:"end_date"`
+	MainCurrency string          `json:"main_currency"`
+	SortBy       string          `json:"sort_by"`
+	Merchants    []MerchantStats `json:"merchants"`
+	// UnassignedCount and UnassignedSpent cover spending without a merchant.
+	UnassignedCount int   `json:"unassigned_count"`
+	UnassignedSpent int64 `json:"unassigned_spent"`
+	// Unconverted counts transactions left out for lack of an FX rate.
+	Unconverted int `json:"unconverted"`
+}
+
+// GetMerchantReport totals spending per merchant in the range.
+func (s *Service) GetMerchantReport(opts MerchantReportOptions) (MerchantReport, error) {
+	if opts.SortBy == "" {
+		opts.SortBy = MerchantSortSpend
+	}
+	switch opts.SortBy {
+	case MerchantSortSpend, MerchantSortVisits, MerchantSortAverage:
+	default:
+		return MerchantReport{}, ErrInvalidMerchantSort
+	}
+	start, errStart := time.Parse("2006-01-02", opts.StartDate)
+	end, errEnd := time.Parse("2006-01-02", opts.EndDate)
+	if errStart != nil || errEnd != nil || end.Before(start) {
+		return MerchantReport{}, ErrInvalidMerchantRange
+	}
+
+	settings, err := s.store.GetCurrencySettings()
+	if err != nil {
+		return MerchantReport{}, err
+	}
+	txs, err := s.store.GetAnalysisTransactions(opts.StartDate, opts.EndDate, opts.CategoryIDs, opts.OwnerIDs, opts.TagIDs, nil)
+	if err != nil {
+		return MerchantReport{}, err
+	}
+
+	report := MerchantReport{StartDate: opts.StartDate, EndDate: opts.EndDate, MainCurrency: settings.MainCurrency, SortBy: opts.SortBy}
+	byMerchant := map[int64]*MerchantStats{}
+	// A split transaction is one visit however many lines it has.
+	visited := map[int64]bool{}
+	for _, tx := range txs {
+		if !isSpending(tx) {
+			continue
+		}
+		if tx.AmountInMainCurrency == nil {
+			report.Unconverted++
+			continue
+		}
+		spent := -*tx.AmountInMainCurrency
+		if tx.MerchantID == nil {
+			report.UnassignedCount++
+			report.UnassignedSpent += spent
+			continue
+		}
+		m, ok := byMerchant[*tx.MerchantID]
+		if !ok {
+			m = &MerchantStats{MerchantID: *tx.MerchantID, Name: tx.MerchantName, FirstDate: tx.Date, LastDate: tx.Date}
+			byMerchant[*tx.MerchantID] = m
+		}
+		m.Spent += spent
+		if tx.Amount < 0 && !visited[tx.ID] {
+			visited[tx.ID] = true
+			m.Visits++
+		}
+		if tx.Date < m.FirstDate {
+			m.FirstDate = tx.Date
+		}
+		if tx.Date > m.LastDate {
+			m.LastDate = tx.Date
+		}
+	}
+
+	months := (end.Sub(start).Hours()/24 + 1) / daysPerMonth
+	report.Merchants = make([]MerchantStats, 0, len(byMerchant))
+	for _, m := range byMerchant {
+		if m.Visits > 0 {
+			m.AverageTicket = int64(math.Round(float64(m.Spent) / float64(m.Visits)))
+		}
+		m.VisitsPerMonth = math.Round(float64(m.Visits)/months*100) / 100
+		report.Merchants = append(report.Merchants, *m)
+	}
+	sort.Slice(report.Merchants, func(i, j int) bool {
+		a, b := report.Merchants[i], report.Merchants[j]
+		var x, y int64
+		switch opts.SortBy {
+		case MerchantSortVisits:
+			x, y = int64(a.Visits), int64(b.Visits)
+		case MerchantSortAverage:
+			x, y = a.AverageTicket, b.AverageTicket
+		default:
+			x, y = a.Spent, b.Spent
+		}
+		if x != y {
+			return x > y
+		}
+		if a.Spent != b.Spent {
+			return a.Spent > b.Spent
+		}
+		return a.Name < b.Name
+	})
+	if opts.Limit > 0 && len(report.Merchants) > opts.Limit {
+		report.Merchants = report.Merchants[:opts.Limit]
+	}
+	return report, nil
+}
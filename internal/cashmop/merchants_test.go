@@ -0,0 +1,142 @@
+package cashmop
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func TestSuggestMerchants(t *testing.T) {
+	svc := newTestService(t)
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2024-03-01", Description: "TIM HORTONS #1234", Amount: -250, Account: "Visa"},
+		{Date: "2024-03-02", Description: "TIM HORTONS #1234", Amount: -310, Account: "Visa"},
+		{Date: "2024-03-03", Description: "TIM HORTONS #0871", Amount: -420, Account: "Visa"},
+		{Date: "2024-03-04", Description: "Tim Hortons 55", Amount: -180, Account: "Visa"},
+		{Date: "2024-03-05", Description: "SQ *BLUE BOTTLE", Amount: -600, Account: "Visa"},
+		{Date: "2024-03-06", Description: "SQ *BLUE BOTTLE COFFEE", Amount: -650, Account: "Visa"},
+		{Date: "2024-03-07", Description: "SQ *CORNER DELI", Amount: -900, Account: "Visa"},
+		{Date: "2024-03-08", Description: "SHELL 123", Amount: -5000, Account: "Visa"},
+		{Date: "2024-03-09", Description: "SHELL 456", Amount: -4500, Account: "Visa"},
+		{Date: "2024-03-10", Description: "SHELLFISH BAR", Amount: -7000, Account: "Visa"},
+		{Date: "2024-03-11", Description: "AMZN MKTP A1", Amount: -1000, Account: "Visa"},
+		{Date: "2024-03-12", Description: "AMZN MKTP B2", Amount: -1100, Account: "Visa"},
+	})
+	if _, err := svc.CreateMerchant("Amazon", []database.MerchantAlias{{MatchType: "starts_with", Pattern: "AMZN"}}); err != nil {
+		t.Fatal(err)
+	}
+	timHortons, err := svc.CreateMerchant("Tim Hortons", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := svc.SuggestMerchants(MerchantSuggestionOptions{})
+	if err != nil {
+		t.Fatalf("SuggestMerchants failed: %v", err)
+	}
+	byName := map[string]MerchantSuggestion{}
+	for _, sg := range suggestions {
+		byName[sg.Name] = sg
+	}
+	if len(suggestions) != 3 {
+		t.Fatalf("expected 3 suggestions, got %+v", suggestions)
+	}
+	if suggestions[0].Name != "Tim Hortons" {
+		t.Errorf("expected the largest cluster first, got %q", suggestions[0].Name)
+	}
+
+	tim := byName["Tim Hortons"]
+	if tim.TransactionCount != 4 || len(tim.Descriptions) != 3 || tim.Descriptions[0] != "TIM HORTONS #1234" {
+		t.Errorf("unexpected Tim Hortons suggestion: %+v", tim)
+	}
+	if tim.MerchantID == nil || *tim.MerchantID != timHortons.ID {
+		t.Errorf("expected the existing merchant, got %v", tim.MerchantID)
+	}
+	if len(tim.Aliases) != 1 || tim.Aliases[0] != (database.MerchantAlias{MatchType: "starts_with", Pattern: "TIM HORTONS"}) {
+		t.Errorf("unexpected aliases: %+v", tim.Aliases)
+	}
+
+	blueBottle := byName["Sq Blue Bottle"]
+	if blueBottle.TransactionCount != 2 || len(blueBottle.Aliases) != 1 || blueBottle.Aliases[0].Pattern != "SQ *BLUE BOTTLE" {
+		t.Errorf("unexpected Blue Bottle suggestion: %+v", blueBottle)
+	}
+	if shell := byName["Shell"]; shell.TransactionCount != 2 || shell.MerchantID != nil {
+		t.Errorf("expected the Shell stations without the bar, got %+v", shell)
+	}
+
+	suggestions, err = svc.SuggestMerchants(MerchantSuggestionOptions{MinTransactions: 3, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Name != "Tim Hortons" {
+		t.Errorf("expected only Tim Hortons, got %+v", suggestions)
+	}
+}
+
+func TestGetMerchantReport(t *testing.T) {
+	svc := newTestService(t)
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-01-02", Description: "AMZN MKTP A1", Amount: -2500, Category: "Shopping", Account: "Visa"},
+		{Date: "2025-01-20", Description: "AMAZON.CA", Amount: -4000, Category: "Shopping", Account: "Visa"},
+		{Date: "2025-01-25", Description: "AMZN REFUND", Amount: 1000, Category: "Shopping", Account: "Visa"},
+		{Date: "2025-01-15", Description: "COSTCO WHOLESALE", Amount: -10000, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-16", Description: "GAS STATION", Amount: -3000, Category: "Transport", Account: "Visa"},
+		{Date: "2024-12-31", Description: "COSTCO WHOLESALE", Amount: -9000, Category: "Groceries", Account: "Visa"},
+	})
+	amazon, err := svc.CreateMerchant("Amazon", []database.MerchantAlias{
+		{MatchType: "starts_with", Pattern: "AMZN"},
+		{MatchType: "exact", Pattern: "AMAZON.CA"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateMerchant("Costco", []database.MerchantAlias{{MatchType: "contains", Pattern: "costco"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []MerchantReportOptions{
+		{StartDate: "2025-01-01", EndDate: "2025-01-31", SortBy: "name"},
+		{StartDate: "2025-01-31", EndDate: "2025-01-01"},
+	} {
+		if _, err := svc.GetMerchantReport(opts); !errors.Is(err, ErrInvalidMerchantSort) && !errors.Is(err, ErrInvalidMerchantRange) {
+			t.Errorf("GetMerchantReport(%+v): expected a validation error, got %v", opts, err)
+		}
+	}
+
+	report, err := svc.GetMerchantReport(MerchantReportOptions{StartDate: "2025-01-01", EndDate: "2025-01-31"})
+	if err != nil {
+		t.Fatalf("GetMerchantReport failed: %v", err)
+	}
+	if report.SortBy != MerchantSortSpend || len(report.Merchants) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	costco, amz := report.Merchants[0], report.Merchants[1]
+	if costco.Name != "Costco" || costco.Spent != 10000 || costco.Visits != 1 {
+		t.Errorf("unexpected Costco stats: %+v", costco)
+	}
+	want := MerchantStats{
+		MerchantID:     amazon.ID,
+		Name:           "Amazon",
+		Spent:          5500,
+		Visits:         2,
+		VisitsPerMonth: 1.96,
+		AverageTicket:  2750,
+		FirstDate:      "2025-01-02",
+		LastDate:       "2025-01-25",
+	}
+	if amz != want {
+		t.Errorf("expected %+v, got %+v", want, amz)
+	}
+	if report.UnassignedCount != 1 || report.UnassignedSpent != 3000 {
+		t.Errorf("unexpected unassigned spending: %d, %d", report.UnassignedCount, report.UnassignedSpent)
+	}
+
+	report, err = svc.GetMerchantReport(MerchantReportOptions{StartDate: "2025-01-01", EndDate: "2025-01-31", SortBy: MerchantSortVisits, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Merchants) != 1 || report.Merchants[0].Name != "Amazon" {
+		t.Errorf("expected Amazon first by visits, got %+v", report.Merchants)
+	}
+}
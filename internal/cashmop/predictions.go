@@ -0,0 +1,327 @@
+package cashmop
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+const defaultPredictionCount = 3
+
+type CategoryModelInfo struct {
+	TrainedAt        string `json:"trained_at"`
+	TransactionCount int64  `json:"transaction_count"`
+	CategoryCount    int    `json:"category_count"`
+	FeatureCount     int    `json:"feature_count"`
+}
+
+// categoryFeatures turns a transaction into the bag of features the naive Bayes
+// model is trained on: description tokens, an order-of-magnitude amount bucket
+// and the account.
+func categoryFeatures(tx database.TransactionModel) []string {
+	var features []string
+	seen := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(strings.ToLower(tx.Description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(token) < 2 || isDigits(token) {
+			continue
+		}
+		f := "w:" + token
+		if !seen[f] {
+			seen[f] = true
+			features = append(features, f)
+		}
+	}
+
+	amount := tx.Amount
+	if tx.AmountInMainCurrency != nil {
+		amount = *tx.AmountInMainCurrency
+	}
+	sign := "+"
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	features = append(features, fmt.Sprintf("amt:%s%d", sign, len(fmt.Sprint(amount/100))))
+	features = append(features, fmt.Sprintf("acct:%d", tx.AccountID))
+	return features
+}
+
+func trainCategoryModel(txs []database.TransactionModel, version int64) database.CategoryModel {
+	model := database.CategoryModel{
+		Classes:   make(map[int64]database.CategoryModelClass),
+		Features:  make(map[string]map[int64]int64),
+		Version:   version,
+		TrainedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, tx := range txs {
+		if tx.CategoryID == nil {
+			continue
+		}
+		id := *tx.CategoryID
+		features := categoryFeatures(tx)
+
+		class := model.Classes[id]
+		class.DocCount++
+		class.FeatureCount += int64(len(features))
+		model.Classes[id] = class
+
+		for _, f := range features {
+			counts, ok := model.Features[f]
+			if !ok {
+				counts = make(map[int64]int64)
+				model.Features[f] = counts
+			}
+			counts[id]++
+		}
+	}
+	return model
+}
+
+// predictCategories scores every category with multinomial naive Bayes (Laplace
+// smoothing) and returns the top n as normalized confidences. Transactions that
+// share no description token with the training data get no predictions.
+func predictCategories(model *database.CategoryModel, names map[int64]string, tx database.TransactionModel, n int) []database.CategoryPrediction {
+	if model == nil || len(model.Classes) == 0 {
+		return nil
+	}
+
+	var known []string
+	hasToken := false
+	for _, f := range categoryFeatures(tx) {
+		if _, ok := model.Features[f]; ok {
+			known = append(known, f)
+			if strings.HasPrefix(f, "w:") {
+				hasToken = true
+			}
+		}
+	}
+	if !hasToken {
+		return nil
+	}
+
+	var totalDocs int64
+	for _, c := range model.Classes {
+		totalDocs += c.DocCount
+	}
+	vocab := float64(len(model.Features))
+	numClasses := float64(len(model.Classes))
+
+	type scored struct {
+		id    int64
+		score float64
+	}
+	scores := make([]scored, 0, len(model.Classes))
+	maxScore := math.Inf(-1)
+	for id, c := range model.Classes {
+		score := math.Log((float64(c.DocCount) + 1) / (float64(totalDocs) + numClasses))
+		denom := float64(c.FeatureCount) + vocab
+		for _, f := range known {
+			score += math.Log((float64(model.Features[f][id]) + 1) / denom)
+		}
+		scores = append(scores, scored{id: id, score: score})
+		if score > maxScore {
+			maxScore = score
+		}
+	}
+
+	var sum float64
+	for i := range scores {
+		scores[i].score = math.Exp(scores[i].score - maxScore)
+		sum += scores[i].score
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score != scores[j].score {
+			return scores[i].score > scores[j].score
+		}
+		return scores[i].id < scores[j].id
+	})
+
+	var predictions []database.CategoryPrediction
+	for _, sc := range scores {
+		if len(predictions) >= n {
+			break
+		}
+		name, ok := names[sc.id]
+		if !ok {
+			continue
+		}
+		predictions = append(predictions, database.CategoryPrediction{
+			CategoryID:   sc.id,
+			CategoryName: name,
+			Confidence:   math.Round(sc.score/sum*10000) / 10000,
+		})
+	}
+	return predictions
+}
+
+// TrainCategoryModel rebuilds the prediction model from transactions
+// categorized by hand or by rules and stores it in the database.
+func (s *Service) TrainCategoryModel() (CategoryModelInfo, error) {
+	model, err := s.trainCategoryModel()
+	if err != nil {
+		return CategoryModelInfo{}, err
+	}
+	return modelInfo(model), nil
+}
+
+// trainCategoryModel trains and saves the model. Accepted predictions are left
+// out, so that confident guesses do not reinforce themselves.
+func (s *Service) trainCategoryModel() (*database.CategoryModel, error) {
+	// Read the version first: a write racing with training leaves the model
+	// tagged as stale rather than current.
+	version, err := s.store.CategoryModelVersion()
+	if err != nil {
+		return nil, err
+	}
+	categorized, err := s.store.GetCategorizedTransactions()
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]database.TransactionModel, 0, len(categorized))
+	for _, tx := range categorized {
+		if tx.CategorySource == database.CategorySourceManual || tx.CategorySource == database.CategorySourceRule {
+			txs = append(txs, tx)
+		}
+	}
+	model := trainCategoryModel(txs, version)
+	if err := s.store.SaveCategoryModel(model); err != nil {
+		return nil, err
+	}
+
+	s.modelMu.Lock()
+	s.model = &model
+	s.modelMu.Unlock()
+	return &model, nil
+}
+
+// categoryModel returns the current model, retraining and saving it when it
+// is older than the category model version.
+func (s *Service) categoryModel() (*database.CategoryModel, error) {
+	version, err := s.store.CategoryModelVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	s.modelMu.Lock()
+	cached := s.model
+	s.modelMu.Unlock()
+	if cached != nil && cached.Version == version {
+		return cached, nil
+	}
+
+	stored, err := s.store.LoadCategoryModel()
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && stored.Version == version {
+		s.modelMu.Lock()
+		s.model = stored
+		s.modelMu.Unlock()
+		return stored, nil
+	}
+	return s.trainCategoryModel()
+}
+
+func modelInfo(model *database.CategoryModel) CategoryModelInfo {
+	info := CategoryModelInfo{
+		TrainedAt:     model.TrainedAt,
+		CategoryCount: len(model.Classes),
+		FeatureCount:  len(model.Features),
+	}
+	for _, c := range model.Classes {
+		info.TransactionCount += c.DocCount
+	}
+	return info
+}
+
+func (s *Service) categoryNames() (map[int64]string, error) {
+	categories, err := s.store.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+	return names, nil
+}
+
+// PredictCategories fills Predictions on each transaction with the top n guesses.
+func (s *Service) PredictCategories(txs []database.TransactionModel, n int) ([]database.TransactionModel, error) {
+	if len(txs) == 0 {
+		return txs, nil
+	}
+	if n <= 0 {
+		n = defaultPredictionCount
+	}
+	model, err := s.categoryModel()
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.categoryNames()
+	if err != nil {
+		return nil, err
+	}
+	for i := range txs {
+		txs[i].Predictions = predictCategories(model, names, txs[i], n)
+	}
+	return txs, nil
+}
+
+func (s *Service) GetCategoryModelInfo() (CategoryModelInfo, error) {
+	model, err := s.categoryModel()
+	if err != nil {
+		return CategoryModelInfo{}, err
+	}
+	return modelInfo(model), nil
+}
+
+// AutoAcceptPredictions categorizes the given transactions whose top
+// prediction meets the confidence threshold and returns their IDs. IDs that
+// are missing or already categorized are ignored.
+func (s *Service) AutoAcceptPredictions(threshold float64, ids []int64) ([]int64, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("auto-accept threshold must be between 0 and 1")
+	}
+	accepted := []int64{}
+	if len(ids) == 0 {
+		return accepted, nil
+	}
+	wanted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	uncategorized, err := s.store.GetUncategorizedTransactions()
+	if err != nil {
+		return nil, err
+	}
+	txs := []database.TransactionModel{}
+	for _, tx := range uncategorized {
+		if wanted[tx.ID] {
+			txs = append(txs, tx)
+		}
+	}
+	txs, err = s.PredictCategories(txs, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range txs {
+		if len(tx.Predictions) == 0 || tx.Predictions[0].Confidence < threshold {
+			continue
+		}
+		if err := s.store.SetTransactionCategory(tx.ID, tx.Predictions[0].CategoryID, database.CategorySourcePrediction); err != nil {
+			return accepted, err
+		}
+		accepted = append(accepted, tx.ID)
+	}
+	return accepted, nil
+}
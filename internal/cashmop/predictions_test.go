@@ -0,0 +1,135 @@
+package cashmop
+
+import "testing"
+
+func TestGetUncategorizedTransactionsIncludesPredictions(t *testing.T) {
+	svc := newTestService(t)
+
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-01-02", Description: "COSTCO WHOLESALE #123", Amount: -8410, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-09", Description: "COSTCO WHOLESALE #456", Amount: -5120, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-12", Description: "SAFEWAY 4411", Amount: -3120, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-03", Description: "SHELL OIL 5521", Amount: -6000, Category: "Fuel", Account: "Visa"},
+		{Date: "2025-01-10", Description: "ESSO STATION", Amount: -5500, Category: "Fuel", Account: "Visa"},
+		{Date: "2025-01-20", Description: "COSTCO GAS #789", Amount: -2000, Account: "Visa"},
+		{Date: "2025-01-21", Description: "ZZZ UNKNOWN", Amount: -2000, Account: "Visa"},
+	})
+
+	txs, err := svc.GetUncategorizedTransactions()
+	if err != nil {
+		t.Fatalf("GetUncategorizedTransactions failed: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("expected 2 uncategorized transactions, got %d", len(txs))
+	}
+
+	for _, tx := range txs {
+		switch tx.Description {
+		case "COSTCO GAS #789":
+			if len(tx.Predictions) != 2 || tx.Predictions[0].CategoryName != "Groceries" {
+				t.Fatalf("expected Groceries first of 2 predictions, got %+v", tx.Predictions)
+			}
+			if tx.Predictions[0].Confidence <= tx.Predictions[1].Confidence {
+				t.Fatalf("expected predictions ranked by confidence, got %+v", tx.Predictions)
+			}
+		case "ZZZ UNKNOWN":
+			if len(tx.Predictions) != 0 {
+				t.Fatalf("expected no predictions for unseen tokens, got %+v", tx.Predictions)
+			}
+		}
+	}
+
+	info, err := svc.GetCategoryModelInfo()
+	if err != nil {
+		t.Fatalf("GetCategoryModelInfo failed: %v", err)
+	}
+	if info.TransactionCount != 5 || info.CategoryCount != 2 {
+		t.Fatalf("unexpected model info: %+v", info)
+	}
+
+	// Categorizing more transactions invalidates the stored model.
+	if err := svc.CategorizeTransaction(txs[0].ID, "Fuel"); err != nil {
+		t.Fatalf("CategorizeTransaction failed: %v", err)
+	}
+	info, err = svc.GetCategoryModelInfo()
+	if err != nil {
+		t.Fatalf("GetCategoryModelInfo failed: %v", err)
+	}
+	if info.TransactionCount != 6 {
+		t.Fatalf("expected retrained model over 6 transactions, got %+v", info)
+	}
+}
+
+func TestAutoAcceptPredictions(t *testing.T) {
+	svc := newTestService(t)
+
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-01-02", Description: "NETFLIX.COM", Amount: -1599, Category: "Subscriptions", Account: "Visa"},
+		{Date: "2025-02-02", Description: "NETFLIX.COM", Amount: -1599, Category: "Subscriptions", Account: "Visa"},
+		{Date: "2025-01-05", Description: "LOBLAWS 1234", Amount: -6400, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-02-20", Description: "NETFLIX.COM GIFT", Amount: -2500, Account: "Visa"},
+	})
+
+	if err := svc.ImportTransactions([]TransactionImportInput{
+		{Date: "2025-03-02", Description: "NETFLIX.COM", Amount: -1599, Account: "Visa"},
+	}, ImportOptions{AutoAcceptThreshold: 0.8}); err != nil {
+		t.Fatalf("ImportTransactions failed: %v", err)
+	}
+
+	txs, err := svc.GetUncategorizedTransactions()
+	if err != nil {
+		t.Fatalf("GetUncategorizedTransactions failed: %v", err)
+	}
+	// Only the imported row is accepted; older uncategorized history is left alone.
+	if len(txs) != 1 || txs[0].Description != "NETFLIX.COM GIFT" {
+		t.Fatalf("expected only the imported Netflix row to be categorized, got %+v", txs)
+	}
+
+	if _, err := svc.AutoAcceptPredictions(1.5, []int64{txs[0].ID}); err == nil {
+		t.Fatal("expected error for out-of-range threshold")
+	}
+}
+
+func TestTrainCategoryModelSkipsAcceptedPredictions(t *testing.T) {
+	svc := newTestService(t)
+
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-01-02", Description: "NETFLIX.COM", Amount: -1599, Category: "Subscriptions", Account: "Visa"},
+		{Date: "2025-02-02", Description: "NETFLIX.COM", Amount: -1599, Category: "Subscriptions", Account: "Visa"},
+		{Date: "2025-01-05", Description: "LOBLAWS 1234", Amount: -6400, Category: "Groceries", Account: "Visa"},
+	})
+	before, err := svc.TrainCategoryModel()
+	if err != nil {
+		t.Fatalf("TrainCategoryModel failed: %v", err)
+	}
+
+	if err := svc.ImportTransactions([]TransactionImportInput{
+		{Date: "2025-03-02", Description: "NETFLIX.COM", Amount: -1599, Account: "Visa"},
+	}, ImportOptions{AutoAcceptThreshold: 0.8}); err != nil {
+		t.Fatalf("ImportTransactions failed: %v", err)
+	}
+	if txs, _ := svc.GetUncategorizedTransactions(); len(txs) != 0 {
+		t.Fatalf("expected the imported row to be auto-accepted, got %+v", txs)
+	}
+
+	// The accepted row bumped the version, so this retrains and stores the model.
+	model, err := svc.categoryModel()
+	if err != nil {
+		t.Fatalf("categoryModel failed: %v", err)
+	}
+	after := modelInfo(model)
+	if after.TransactionCount != before.TransactionCount || after.CategoryCount != before.CategoryCount {
+		t.Errorf("expected the accepted prediction to be left out of training, before %+v, after %+v", before, after)
+	}
+	stored, err := svc.store.LoadCategoryModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := svc.store.CategoryModelVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored == nil || stored.Version != version {
+		t.Errorf("expected the retrained model to be stored at version %d, got %+v", version, stored)
+	}
+}
@@ -0,0 +1,436 @@
+package cashmop
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+const (
+	RecurringCadenceWeekly    = "weekly"
+	RecurringCadenceBiweekly  = "biweekly"
+	RecurringCadenceMonthly   = "monthly"
+	RecurringCadenceQuarterly = "quarterly"
+	RecurringCadenceAnnual    = "annual"
+
+	// maxRecurringAmountDeviation is how far amounts in one series may be
+	// from its smallest, as a fraction of it.
+	maxRecurringAmountDeviation = 0.25
+	// minRecurringRegularShare is the share of gaps that must match the cadence.
+	minRecurringRegularShare = 0.75
+	// recurringHistoryMonths is how far back recurring list looks, enough to
+	// see an annual charge three times.
+	recurringHistoryMonths = 36
+)
+
+var ErrRecurringSeriesNotFound = errors.New("recurring series not found")
+
+// recurringCadence describes one supported cadence and the gaps in days that
+// count as it.
+type recurringCadence struct {
+	name    string
+	days    int
+	minDays int
+	maxDays int
+	// months is set for calendar-month cadences, which step by month instead
+	// of by days.
+	months int
+	// minCount is how many occurrences make a series.
+	minCount int
+}
+
+var recurringCadences = []recurringCadence{
+	{name: RecurringCadenceWeekly, days: 7, minDays: 6, maxDays: 8, minCount: 3},
+	{name: RecurringCadenceBiweekly, days: 14, minDays: 12, maxDays: 16, minCount: 3},
+	{name: RecurringCadenceMonthly, days: 30, minDays: 26, maxDays: 35, months: 1, minCount: 3},
+	{name: RecurringCadenceQuarterly, days: 91, minDays: 84, maxDays: 98, months: 3, minCount: 3},
+	{name: RecurringCadenceAnnual, days: 365, minDays: 350, maxDays: 380, months: 12, minCount: 2},
+}
+
+// RecurringPriceChange is a new amount that held for the following
+// occurrences, in the series currency.
+type RecurringPriceChange struct {
+	Date string `json:"date"`
+	From int64  `json:"from"`
+	To   int64  `json:"to"`
+}
+
+// RecurringSeries is a run of transactions with the same payee, account,
+// direction and similar amount at a regular cadence, like a subscription or a
+// paycheck.
+type RecurringSeries struct {
+	// Key identifies the series across detections.
+	Key          string `json:"key"`
+	Description  string `json:"description"`
+	AccountID    int64  `json:"account_id"`
+	AccountName  string `json:"account_name"`
+	CategoryID   *int64 `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	CategoryKind string `json:"category_kind"`
+	Currency     string `json:"currency"`
+	Cadence      string `json:"cadence"`
+	// Amount is the latest amount in the main currency; AverageAmount and
+	// MonthlyAmount are in the main currency too.
+	Amount        int64  `json:"amount"`
+	AverageAmount int64  `json:"average_amount"`
+	MonthlyAmount int64  `json:"monthly_amount"`
+	Count         int    `json:"count"`
+	FirstDate     string `json:"first_date"`
+	LastDate      string `json:"last_date"`
+	NextDate      string `json:"next_date"`
+	// PriceChanges are in Currency, oldest first.
+	PriceChanges []RecurringPriceChange `json:"price_changes"`
+	// MissedDates are expected dates without a transaction, including ones
+	// after LastDate.
+	MissedDates []string `json:"missed_dates"`
+	// Active is false once more than one occurrence in a row is missing.
+	Active bool `json:"active"`
+	// Known is set when the series was marked as a known subscription.
+	Known bool `json:"known"`
+
+	cadence recurringCadence
+	// spread is the standard deviation of the amounts.
+	spread float64
+	// typical is the median absolute amount, which tells apart series that
+	// share a key.
+	typical        int64
+	transactionIDs []int64
+}
+
+// recurringKey reduces a description to its words without digits, so that
+// "NETFLIX.COM 8823" and "NETFLIX.COM 9120" fall in one series.
+func recurringKey(description string) string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0]
+	for _, w := range words {
+		if strings.IndexFunc(w, unicode.IsDigit) < 0 {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+func medianInt64(values []int64) int64 {
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// nextRecurringDate steps one cadence forward from date.
+func nextRecurringDate(date time.Time, cadence recurringCadence) time.Time {
+	if cadence.months == 0 {
+		return date.AddDate(0, 0, cadence.days)
+	}
+	// Keep the day of month where possible; the 31st becomes the last day of
+	// shorter months.
+	first := time.Date(date.Year(), date.Month()+time.Month(cadence.months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+type recurringOccurrence struct {
+	tx   database.TransactionModel
+	date time.Time
+}
+
+func daysBetween(a, b time.Time) int64 {
+	return int64(math.Round(b.Sub(a).Hours() / 24))
+}
+
+// clusterByAmount splits occurrences of one description into groups whose
+// amounts stay within maxRecurringAmountDeviation of the group's smallest.
+func clusterByAmount(occ []recurringOccurrence) [][]recurringOccurrence {
+	sorted := append([]recurringOccurrence(nil), occ...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return absInt64(*sorted[i].tx.AmountInMainCurrency) < absInt64(*sorted[j].tx.AmountInMainCurrency)
+	})
+	var clusters [][]recurringOccurrence
+	var base int64
+	for _, o := range sorted {
+		amount := absInt64(*o.tx.AmountInMainCurrency)
+		if len(clusters) == 0 || float64(amount) > float64(base)*(1+maxRecurringAmountDeviation) {
+			clusters = append(clusters, nil)
+			base = amount
+		}
+		clusters[len(clusters)-1] = append(clusters[len(clusters)-1], o)
+	}
+	return clusters
+}
+
+// detectRecurring finds recurring series in txs. A series needs the cadence's
+// minimum number of occurrences, mostly regular gaps and amounts within
+// maxRecurringAmountDeviation of each other. Missed occurrences are counted up
+// to asOf. Split lines and transactions without a main-currency amount are
+// ignored.
+func detectRecurring(txs []database.TransactionModel, asOf time.Time) []RecurringSeries {
+	groups := map[string][]recurringOccurrence{}
+	for _, tx := range txs {
+		if tx.SplitID != nil || tx.AmountInMainCurrency == nil || *tx.AmountInMainCurrency == 0 {
+			continue
+		}
+		desc := recurringKey(tx.Description)
+		if desc == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+		sign := "-"
+		if *tx.AmountInMainCurrency > 0 {
+			sign = "+"
+		}
+		key := fmt.Sprintf("%d|%s|%s", tx.AccountID, sign, desc)
+		groups[key] = append(groups[key], recurringOccurrence{tx: tx, date: date})
+	}
+
+	// Keys leave out the amount so a series keeps its key as prices change
+	// and old occurrences leave the window. Series that still collide, e.g.
+	// two plans billed by the same payee, are told apart by their typical
+	// amount: the smallest keeps the key and the others get the median amount
+	// in whole currency units appended.
+	byKey := map[string][]RecurringSeries{}
+	for key, occ := range groups {
+		for _, cluster := range clusterByAmount(occ) {
+			if s, ok := recurringSeriesFrom(key, cluster, asOf); ok {
+				byKey[s.Key] = append(byKey[s.Key], s)
+			}
+		}
+	}
+
+	var series []RecurringSeries
+	for key, group := range byKey {
+		sort.Slice(group, func(i, j int) bool { return group[i].typical < group[j].typical })
+		used := map[string]bool{key: true}
+		for i := range group {
+			if i > 0 {
+				group[i].Key = fmt.Sprintf("%s|%d", key, int64(math.Round(float64(group[i].typical)/100)))
+				// Small amounts can round to the same unit; fall back to cents.
+				if used[group[i].Key] {
+					group[i].Key = fmt.Sprintf("%s|%d", key, group[i].typical)
+				}
+				used[group[i].Key] = true
+			}
+		}
+		series = append(series, group...)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].MonthlyAmount != series[j].MonthlyAmount {
+			return series[i].MonthlyAmount < series[j].MonthlyAmount
+		}
+		return series[i].Key < series[j].Key
+	})
+	return series
+}
+
+// recurringSeriesFrom checks one amount cluster for a cadence and describes it.
+func recurringSeriesFrom(key string, occ []recurringOccurrence, asOf time.Time) (RecurringSeries, bool) {
+	if len(occ) < 2 {
+		return RecurringSeries{}, false
+	}
+	sort.Slice(occ, func(i, j int) bool {
+		if !occ[i].date.Equal(occ[j].date) {
+			return occ[i].date.Before(occ[j].date)
+		}
+		return occ[i].tx.ID < occ[j].tx.ID
+	})
+
+	gaps := make([]int64, 0, len(occ)-1)
+	for i := 1; i < len(occ); i++ {
+		gaps = append(gaps, daysBetween(occ[i-1].date, occ[i].date))
+	}
+	median := medianInt64(gaps)
+	var cadence recurringCadence
+	for _, c := range recurringCadences {
+		if median >= int64(c.minDays) && median <= int64(c.maxDays) {
+			cadence = c
+			break
+		}
+	}
+	if cadence.name == "" || len(occ) < cadence.minCount {
+		return RecurringSeries{}, false
+	}
+
+	// A gap spanning several cadences still counts as regular; the
+	// occurrences in between were missed.
+	var missed []string
+	regular := 0
+	for i, g := range gaps {
+		n := int64(math.Round(float64(g) / float64(cadence.days)))
+		if n < 1 || g < n*int64(cadence.minDays) || g > n*int64(cadence.maxDays) {
+			continue
+		}
+		regular++
+		date := occ[i].date
+		for k := int64(1); k < n; k++ {
+			date = nextRecurringDate(date, cadence)
+			missed = append(missed, date.Format("2006-01-02"))
+		}
+	}
+	if float64(regular) < minRecurringRegularShare*float64(len(gaps)) {
+		return RecurringSeries{}, false
+	}
+
+	last := occ[len(occ)-1]
+	next := nextRecurringDate(last.date, cadence)
+	grace := int64(cadence.maxDays - cadence.days)
+	overdue := 0
+	for date := next; daysBetween(date, asOf) > grace; date = nextRecurringDate(date, cadence) {
+		missed = append(missed, date.Format("2006-01-02"))
+		overdue++
+	}
+
+	var sum int64
+	var priceChanges []RecurringPriceChange
+	ids := make([]int64, len(occ))
+	amounts := make([]int64, len(occ))
+	for i, o := range occ {
+		sum += *o.tx.AmountInMainCurrency
+		ids[i] = o.tx.ID
+		amounts[i] = absInt64(*o.tx.AmountInMainCurrency)
+		// A price change is a new amount in the same currency that the next
+		// occurrence, if any, repeats.
+		if i == 0 {
+			continue
+		}
+		prev := occ[i-1].tx
+		if o.tx.Currency != prev.Currency || o.tx.Amount == prev.Amount {
+			continue
+		}
+		if i+1 < len(occ) && occ[i+1].tx.Amount != o.tx.Amount {
+			continue
+		}
+		priceChanges = append(priceChanges, RecurringPriceChange{Date: o.tx.Date, From: prev.Amount, To: o.tx.Amount})
+	}
+	average := float64(sum) / float64(len(occ))
+	var sumSq float64
+	for _, o := range occ {
+		d := float64(*o.tx.AmountInMainCurrency) - average
+		sumSq += d * d
+	}
+
+	amount := *last.tx.AmountInMainCurrency
+	monthly := int64(math.Round(float64(amount) * 365.25 / 12 / float64(cadence.days)))
+	if cadence.months > 0 {
+		monthly = int64(math.Round(float64(amount) / float64(cadence.months)))
+	}
+	s := RecurringSeries{
+		Key:           key + "|" + cadence.name,
+		Description:   last.tx.Description,
+		AccountID:     last.tx.AccountID,
+		AccountName:   last.tx.AccountName,
+		CategoryID:    last.tx.CategoryID,
+		CategoryName:  last.tx.CategoryFullName,
+		CategoryKind:  last.tx.CategoryKind,
+		Currency:      last.tx.Currency,
+		Cadence:       cadence.name,
+		Amount:        amount,
+		AverageAmount: int64(math.Round(average)),
+		MonthlyAmount: monthly,
+		Count:         len(occ),
+		FirstDate:     occ[0].tx.Date,
+		LastDate:      last.tx.Date,
+		NextDate:      next.Format("2006-01-02"),
+		PriceChanges:  priceChanges,
+		MissedDates:   missed,
+		Active:        overdue <= 1,
+
+		cadence:        cadence,
+		spread:         math.Sqrt(sumSq / float64(len(occ))),
+		typical:        medianInt64(amounts),
+		transactionIDs: ids,
+	}
+	if s.CategoryName == "" {
+		s.CategoryName = last.tx.CategoryName
+	}
+	if s.PriceChanges == nil {
+		s.PriceChanges = []RecurringPriceChange{}
+	}
+	if s.MissedDates == nil {
+		s.MissedDates = []string{}
+	}
+	return s, true
+}
+
+// GetRecurringSeries detects recurring series in the last three years up to
+// today, marking known subscriptions. Transfers and excluded categories are
+// left out. Ended series are only included with includeEnded.
+func (s *Service) GetRecurringSeries(includeEnded bool) ([]RecurringSeries, error) {
+	return s.recurringSeriesAt(time.Now(), includeEnded)
+}
+
+func (s *Service) recurringSeriesAt(now time.Time, includeEnded bool) ([]RecurringSeries, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -recurringHistoryMonths, 0)
+	all, err := s.store.GetAnalysisTransactions(start.Format("2006-01-02"), today.Format("2006-01-02"), nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]database.TransactionModel, 0, len(all))
+	for _, tx := range all {
+		if database.CountsTowardsTotals(tx.CategoryKind) {
+			txs = append(txs, tx)
+		}
+	}
+
+	known, err := s.store.GetKnownSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	series := []RecurringSeries{}
+	for _, r := range detectRecurring(txs, today) {
+		if !r.Active && !includeEnded {
+			continue
+		}
+		r.Known = known[r.Key]
+		series = append(series, r)
+	}
+	return series, nil
+}
+
+// SetKnownSubscription marks or unmarks a detected series as a known
+// subscription. Unmarking works for series that are no longer detected.
+func (s *Service) SetKnownSubscription(key string, known bool) error {
+	key = strings.TrimSpace(key)
+	if known {
+		series, err := s.GetRecurringSeries(true)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, r := range series {
+			if r.Key == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrRecurringSeriesNotFound
+		}
+	}
+	return s.store.SetKnownSubscription(key, known)
+}
@@ -0,0 +1,184 @@
+package cashmop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func TestRecurringKey(t *testing.T) {
+	tests := map[string]string{
+		"NETFLIX.COM 8823":      "netflix com",
+		"Spotify P1A2B3 Stockh": "spotify stockh",
+		"#1234":                 "",
+	}
+	for in, want := range tests {
+		if got := recurringKey(in); got != want {
+			t.Errorf("recurringKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDetectRecurring(t *testing.T) {
+	amount := func(v int64) *int64 { return &v }
+	var txs []database.TransactionModel
+	add := func(id int64, date, description string, cents int64) {
+		txs = append(txs, database.TransactionModel{ID: id, AccountID: 1, AccountName: "Visa", Date: date, Description: description, Amount: cents, AmountInMainCurrency: amount(cents)})
+	}
+	add(1, "2025-03-31", "NETFLIX.COM 1", -1599)
+	add(2, "2025-04-30", "NETFLIX.COM 2", -1599)
+	add(3, "2025-05-31", "NETFLIX.COM 3", -1699)
+	add(4, "2025-06-30", "NETFLIX.COM 4", -1699)
+	// Too few occurrences.
+	add(5, "2025-05-10", "DENTIST", -12000)
+	add(6, "2025-06-10", "DENTIST", -12000)
+	// Regular but stopped in February.
+	for i, date := range []string{"2024-11-01", "2024-12-01", "2025-01-01", "2025-02-01"} {
+		add(int64(10+i), date, "GYM", -5000)
+	}
+	// Regular dates but amounts that vary too much.
+	for i, date := range []string{"2025-04-02", "2025-05-02", "2025-06-02"} {
+		add(int64(20+i), date, "HYDRO", int64(-4000*(i+1)))
+	}
+
+	series := detectRecurring(txs, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+	if len(series) != 2 {
+		t.Fatalf("expected the Netflix and gym series, got %+v", series)
+	}
+	gym, netflix := series[0], series[1]
+	if netflix.Cadence != RecurringCadenceMonthly || netflix.Count != 4 || netflix.Amount != -1699 || netflix.AverageAmount != -1649 || netflix.MonthlyAmount != -1699 {
+		t.Errorf("unexpected series: %+v", netflix)
+	}
+	if netflix.Description != "NETFLIX.COM 4" || netflix.FirstDate != "2025-03-31" || netflix.LastDate != "2025-06-30" || netflix.NextDate != "2025-07-30" {
+		t.Errorf("unexpected dates: %+v", netflix)
+	}
+	if len(netflix.PriceChanges) != 1 || netflix.PriceChanges[0] != (RecurringPriceChange{Date: "2025-05-31", From: -1599, To: -1699}) {
+		t.Errorf("expected one price change, got %+v", netflix.PriceChanges)
+	}
+	if !netflix.Active || len(netflix.MissedDates) != 0 || netflix.Key != "1|-|netflix com|monthly" {
+		t.Errorf("unexpected status: %+v", netflix)
+	}
+	if gym.Active || len(gym.MissedDates) != 4 || gym.MissedDates[0] != "2025-03-01" {
+		t.Errorf("expected the gym series to have ended, got %+v", gym)
+	}
+}
+
+func TestDetectRecurringClustersByAmountAndCountsMissed(t *testing.T) {
+	amount := func(v int64) *int64 { return &v }
+	var txs []database.TransactionModel
+	add := func(id int64, date string, cents int64) {
+		txs = append(txs, database.TransactionModel{ID: id, AccountID: 1, Date: date, Description: "SPOTIFY", Amount: cents, AmountInMainCurrency: amount(cents)})
+	}
+	// Two plans billed by the same payee, one of them skipping April.
+	add(1, "2025-01-10", -1199)
+	add(2, "2025-02-10", -1199)
+	add(3, "2025-03-10", -1199)
+	add(4, "2025-05-10", -1199)
+	add(5, "2025-06-10", -1199)
+	add(6, "2025-01-20", -2199)
+	add(7, "2025-02-20", -2199)
+	add(8, "2025-03-20", -2199)
+	// Annual renewals need only two occurrences.
+	txs = append(txs,
+		database.TransactionModel{ID: 9, AccountID: 1, Date: "2024-02-01", Description: "DOMAIN RENEWAL", Amount: -2000, AmountInMainCurrency: amount(-2000)},
+		database.TransactionModel{ID: 10, AccountID: 1, Date: "2025-02-01", Description: "DOMAIN RENEWAL", Amount: -2000, AmountInMainCurrency: amount(-2000)},
+	)
+
+	series := detectRecurring(txs, time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC))
+	byKey := map[string]RecurringSeries{}
+	for _, s := range series {
+		byKey[s.Key] = s
+	}
+	if len(series) != 3 {
+		t.Fatalf("expected 3 series, got %+v", series)
+	}
+	small := byKey["1|-|spotify|monthly"]
+	if small.Count != 5 || !small.Active || len(small.MissedDates) != 1 || small.MissedDates[0] != "2025-04-10" {
+		t.Errorf("unexpected small plan: %+v", small)
+	}
+	large := byKey["1|-|spotify|monthly|22"]
+	if large.Count != 3 || large.Active || len(large.MissedDates) != 2 {
+		t.Errorf("unexpected large plan: %+v", large)
+	}
+	annual := byKey["1|-|domain renewal|annual"]
+	if annual.Cadence != RecurringCadenceAnnual || annual.NextDate != "2026-02-01" || annual.MonthlyAmount != -167 || !annual.Active {
+		t.Errorf("unexpected annual series: %+v", annual)
+	}
+}
+
+func TestRecurringKeySurvivesWindowShift(t *testing.T) {
+	amount := func(v int64) *int64 { return &v }
+	var txs []database.TransactionModel
+	for i, date := range []string{"2025-01-15", "2025-02-15", "2025-03-15", "2025-04-15", "2025-05-15"} {
+		cents := int64(-1699)
+		if i == 0 {
+			cents = -1599
+		}
+		txs = append(txs, database.TransactionModel{ID: int64(i + 1), AccountID: 1, Date: date, Description: "NETFLIX.COM", Amount: cents, AmountInMainCurrency: amount(cents)})
+	}
+	asOf := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	before := detectRecurring(txs, asOf)
+	if len(before) != 1 {
+		t.Fatalf("expected one series, got %+v", before)
+	}
+	known := map[string]bool{before[0].Key: true}
+
+	// The old price leaves the history window; the marked series stays marked.
+	after := detectRecurring(txs[1:], asOf)
+	if len(after) != 1 || !known[after[0].Key] {
+		t.Errorf("expected key %q to survive the window shift, got %+v", before[0].Key, after)
+	}
+}
+
+func TestRecurringKeySuffixSurvivesWindowShift(t *testing.T) {
+	amount := func(v int64) *int64 { return &v }
+	var txs []database.TransactionModel
+	add := func(date string, cents int64) {
+		txs = append(txs, database.TransactionModel{ID: int64(len(txs) + 1), AccountID: 1, Date: date, Description: "SPOTIFY", Amount: cents, AmountInMainCurrency: amount(cents)})
+	}
+	// The family plan started before the individual one.
+	for m := 11; m <= 18; m++ {
+		date := time.Date(2024, time.Month(m), 20, 0, 0, 0, 0, time.UTC)
+		add(date.Format("2006-01-02"), -2199)
+	}
+	for m := 1; m <= 6; m++ {
+		date := time.Date(2025, time.Month(m), 5, 0, 0, 0, 0, time.UTC)
+		add(date.Format("2006-01-02"), -1199)
+	}
+	detect := func(windowStart string, asOf time.Time) map[string]int64 {
+		var window []database.TransactionModel
+		for _, tx := range txs {
+			if tx.Date >= windowStart {
+				window = append(window, tx)
+			}
+		}
+		keys := map[string]int64{}
+		for _, s := range detectRecurring(window, asOf) {
+			keys[s.Key] = s.Amount
+		}
+		return keys
+	}
+
+	before := detect("2024-07-01", time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC))
+	if len(before) != 2 {
+		t.Fatalf("expected two series, got %v", before)
+	}
+	// The family plan's first months leave the window, so the individual
+	// plan now starts first; both keep their keys.
+	after := detect("2025-02-01", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+	for key, amount := range before {
+		if after[key] != amount {
+			t.Errorf("expected key %q to stay on the %d series, got %v", key, amount, after)
+		}
+	}
+}
+
+func TestNextRecurringDateClampsToMonthEnd(t *testing.T) {
+	monthly := recurringCadences[2]
+	got := nextRecurringDate(time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), monthly)
+	if got.Format("2006-01-02") != "2025-02-28" {
+		t.Errorf("expected 2025-02-28, got %s", got.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,123 @@
+package cashmop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidRuleCases is wrapped by errors caused by the contents of a rule case file.
+var ErrInvalidRuleCases = errors.New("invalid rule cases")
+
+// RuleCaseFile lists sample transactions and the category the rules are
+// expected to assign. An empty Expected means no rule should match.
+type RuleCaseFile struct {
+	Cases []RuleCase `json:"cases" yaml:"cases"`
+}
+
+type RuleCase struct {
+	Description string `json:"description" yaml:"description"`
+	// Amount is a decimal string; it is only needed for rules with amount bounds.
+	Amount string `json:"amount" yaml:"amount"`
+	// Currency defaults to the main currency and Date to today; both are only
+	// used to convert Amount for rules with amount bounds.
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+	Date     string `json:"date,omitempty" yaml:"date,omitempty"`
+	Expected string `json:"expected" yaml:"expected"`
+}
+
+// RuleCaseResult reports the outcome of one case. Index is 1-based.
+type RuleCaseResult struct {
+	Index       int    `json:"index"`
+	Description string `json:"description"`
+	Amount      string `json:"amount"`
+	Expected    string `json:"expected"`
+	Actual      string `json:"actual"`
+	RuleID      *int64 `json:"rule_id"`
+	MatchType   string `json:"match_type,omitempty"`
+	MatchValue  string `json:"match_value,omitempty"`
+	Passed      bool   `json:"passed"`
+}
+
+type RuleCaseReport struct {
+	Total   int              `json:"total"`
+	Passed  int              `json:"passed"`
+	Failed  int              `json:"failed"`
+	Results []RuleCaseResult `json:"results"`
+}
+
+// RunRuleCases evaluates every case in the file (YAML, or JSON for .json files)
+// against the current rules.
+func (s *Service) RunRuleCases(path string) (RuleCaseReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RuleCaseReport{}, err
+	}
+
+	var file RuleCaseFile
+	if isJSONRuleset(path) {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return RuleCaseReport{}, fmt.Errorf("%w: %v", ErrInvalidRuleCases, err)
+	}
+	if len(file.Cases) == 0 {
+		return RuleCaseReport{}, fmt.Errorf("%w: no cases found", ErrInvalidRuleCases)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	report := RuleCaseReport{Total: len(file.Cases), Results: make([]RuleCaseResult, 0, len(file.Cases))}
+	for i, c := range file.Cases {
+		if strings.TrimSpace(c.Description) == "" {
+			return RuleCaseReport{}, fmt.Errorf("%w: case %d: description cannot be empty", ErrInvalidRuleCases, i+1)
+		}
+		var amount int64
+		if strings.TrimSpace(c.Amount) != "" {
+			cents, err := parseRulesetAmount(c.Amount)
+			if err != nil {
+				return RuleCaseReport{}, fmt.Errorf("%w: case %d: %v", ErrInvalidRuleCases, i+1, err)
+			}
+			amount = *cents
+		}
+		date := c.Date
+		if date == "" {
+			date = today
+		} else if _, err := time.Parse("2006-01-02", date); err != nil {
+			return RuleCaseReport{}, fmt.Errorf("%w: case %d: date must be YYYY-MM-DD", ErrInvalidRuleCases, i+1)
+		}
+
+		rule, err := s.store.MatchRule(c.Description, amount, strings.ToUpper(strings.TrimSpace(c.Currency)), date)
+		if err != nil {
+			return RuleCaseReport{}, err
+		}
+
+		result := RuleCaseResult{
+			Index:       i + 1,
+			Description: c.Description,
+			Amount:      formatCents(amount),
+			Expected:    strings.TrimSpace(c.Expected),
+		}
+		if rule != nil {
+			id := rule.ID
+			result.RuleID = &id
+			result.MatchType = rule.MatchType
+			result.MatchValue = rule.MatchValue
+			result.Actual = rule.CategoryName
+		}
+		result.Passed = result.Actual == result.Expected
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
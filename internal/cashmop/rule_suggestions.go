@@ -0,0 +1,304 @@
+package cashmop
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+const (
+	defaultSuggestionMinSupport   = 3
+	defaultSuggestionMinPrecision = 0.9
+	defaultSuggestionLimit        = 20
+	defaultSuggestionPreviewLimit = 5
+
+	maxSuggestionPrefixTokens = 3
+	minSuggestionValueLength  = 3
+	minSuggestionTokenLength  = 4
+)
+
+type RuleSuggestionOptions struct {
+	MinSupport   int     `json:"min_support"`
+	MinPrecision float64 `json:"min_precision"`
+	Limit        int     `json:"limit"`
+	PreviewLimit int     `json:"preview_limit"`
+}
+
+type RuleSuggestion struct {
+	MatchType    string `json:"match_type"`
+	MatchValue   string `json:"match_value"`
+	CategoryID   int64  `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	// Support is the number of manually categorized transactions that match and carry CategoryID.
+	Support int `json:"support"`
+	// Matches is the number of manually categorized transactions that match, regardless of category.
+	Matches   int     `json:"matches"`
+	Precision float64 `json:"precision"`
+	// Preview lists the uncategorized transactions the rule would categorize.
+	Preview database.RuleMatchPreview `json:"preview"`
+}
+
+type suggestionCandidate struct {
+	matchType  string
+	matchValue string
+	support    int
+	matches    int
+	categoryID int64
+	category   string
+	covered    []int64
+}
+
+// SuggestCategorizationRules mines manually categorized transactions for description
+// prefixes and tokens that consistently map to one category. Only transactions whose
+// category source is manual count; those set by rules or accepted predictions are left out.
+func (s *Service) SuggestCategorizationRules(opts RuleSuggestionOptions) ([]RuleSuggestion, error) {
+	if opts.MinSupport <= 0 {
+		opts.MinSupport = defaultSuggestionMinSupport
+	}
+	if opts.MinPrecision <= 0 || opts.MinPrecision > 1 {
+		opts.MinPrecision = defaultSuggestionMinPrecision
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultSuggestionLimit
+	}
+	if opts.PreviewLimit <= 0 {
+		opts.PreviewLimit = defaultSuggestionPreviewLimit
+	}
+
+	rules, err := s.store.GetRules()
+	if err != nil {
+		return nil, err
+	}
+	categorized, err := s.store.GetCategorizedTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	manual := make([]database.TransactionModel, 0, len(categorized))
+	for _, tx := range categorized {
+		if tx.CategorySource == database.CategorySourceManual {
+			manual = append(manual, tx)
+		}
+	}
+
+	existing := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		existing[suggestionKey(r.MatchType, r.MatchValue)] = true
+	}
+
+	candidates := mineSuggestionCandidates(manual, existing, opts)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.support != b.support {
+			return a.support > b.support
+		}
+		if a.matches != b.matches {
+			return a.matches < b.matches
+		}
+		if a.matchType != b.matchType {
+			return a.matchType == "starts_with"
+		}
+		if len(a.matchValue) != len(b.matchValue) {
+			return len(a.matchValue) < len(b.matchValue)
+		}
+		return strings.ToLower(a.matchValue) < strings.ToLower(b.matchValue)
+	})
+
+	covered := make(map[int64]bool)
+	suggestions := []RuleSuggestion{}
+	for _, c := range candidates {
+		if len(suggestions) >= opts.Limit {
+			break
+		}
+
+		adds := false
+		for _, id := range c.covered {
+			if !covered[id] {
+				adds = true
+				break
+			}
+		}
+		if !adds {
+			continue
+		}
+		for _, id := range c.covered {
+			covered[id] = true
+		}
+
+		preview, err := s.store.PreviewRuleMatches(c.matchValue, c.matchType, nil, nil, false, opts.PreviewLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		suggestions = append(suggestions, RuleSuggestion{
+			MatchType:    c.matchType,
+			MatchValue:   c.matchValue,
+			CategoryID:   c.categoryID,
+			CategoryName: c.category,
+			Support:      c.support,
+			Matches:      c.matches,
+			Precision:    float64(c.support) / float64(c.matches),
+			Preview:      preview,
+		})
+	}
+
+	return suggestions, nil
+}
+
+func mineSuggestionCandidates(manual []database.TransactionModel, existing map[string]bool, opts RuleSuggestionOptions) []suggestionCandidate {
+	type proposal struct {
+		matchType  string
+		matchValue string
+		counts     map[int64]int
+	}
+
+	proposals := make(map[string]*proposal)
+	categoryNames := make(map[int64]string)
+	for _, tx := range manual {
+		if tx.CategoryID == nil {
+			continue
+		}
+		categoryNames[*tx.CategoryID] = tx.CategoryName
+
+		seen := make(map[string]bool)
+		for _, c := range descriptionCandidates(tx.Description) {
+			key := suggestionKey(c[0], c[1])
+			if seen[key] || existing[key] {
+				continue
+			}
+			seen[key] = true
+
+			p, ok := proposals[key]
+			if !ok {
+				p = &proposal{matchType: c[0], matchValue: c[1], counts: make(map[int64]int)}
+				proposals[key] = p
+			}
+			p.counts[*tx.CategoryID]++
+		}
+	}
+
+	var candidates []suggestionCandidate
+	for _, p := range proposals {
+		var bestID int64
+		best := 0
+		for id, n := range p.counts {
+			if n > best || (n == best && id < bestID) {
+				bestID, best = id, n
+			}
+		}
+		if best < opts.MinSupport {
+			continue
+		}
+
+		// Token counts only see whole tokens; re-scan with the rule matcher so
+		// substring hits in other categories count against precision.
+		c := suggestionCandidate{
+			matchType:  p.matchType,
+			matchValue: p.matchValue,
+			categoryID: bestID,
+			category:   categoryNames[bestID],
+		}
+		for _, tx := range manual {
+			if !ruleMatchesDescription(p.matchType, p.matchValue, tx.Description) {
+				continue
+			}
+			c.matches++
+			if tx.CategoryID != nil && *tx.CategoryID == bestID {
+				c.support++
+				c.covered = append(c.covered, tx.ID)
+			}
+		}
+		if c.matches == 0 || float64(c.support)/float64(c.matches) < opts.MinPrecision {
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// descriptionCandidates returns [matchType, matchValue] pairs: prefixes made of the
+// first few tokens and individual distinctive tokens.
+func descriptionCandidates(description string) [][2]string {
+	desc := strings.TrimSpace(description)
+	if desc == "" {
+		return nil
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	start := -1
+	for i, r := range desc {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start == -1 {
+			start = i
+		} else if !isWord && start != -1 {
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	if start != -1 {
+		spans = append(spans, span{start, len(desc)})
+	}
+
+	var out [][2]string
+	for k := 0; k < len(spans) && k < maxSuggestionPrefixTokens; k++ {
+		if isDigits(desc[spans[k].start:spans[k].end]) {
+			break
+		}
+		prefix := desc[:spans[k].end]
+		if len(prefix) < minSuggestionValueLength || prefix == desc {
+			continue
+		}
+		out = append(out, [2]string{"starts_with", prefix})
+	}
+	for _, sp := range spans {
+		token := desc[sp.start:sp.end]
+		if len(token) < minSuggestionTokenLength || isDigits(token) {
+			continue
+		}
+		out = append(out, [2]string{"contains", token})
+	}
+	return out
+}
+
+// ruleMatchesDescription mirrors the SQL used by rule application: LIKE is
+// case-insensitive for ASCII, while exact matches compare with "=".
+func ruleMatchesDescription(matchType, matchValue, description string) bool {
+	d := asciiLower(description)
+	v := asciiLower(matchValue)
+	switch matchType {
+	case "contains":
+		return strings.Contains(d, v)
+	case "starts_with":
+		return strings.HasPrefix(d, v)
+	case "ends_with":
+		return strings.HasSuffix(d, v)
+	case "exact":
+		return description == matchValue
+	}
+	return false
+}
+
+func suggestionKey(matchType, matchValue string) string {
+	return matchType + "\x00" + asciiLower(matchValue)
+}
+
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, s)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
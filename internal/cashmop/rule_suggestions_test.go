@@ -0,0 +1,116 @@
+package cashmop
+
+import (
+	"testing"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func TestSuggestCategorizationRules(t *testing.T) {
+	svc := newTestService(t)
+
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-01-02", Description: "COSTCO WHOLESALE #123", Amount: -8410, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-09", Description: "COSTCO WHOLESALE #123", Amount: -5120, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-16", Description: "COSTCO WHOLESALE #456", Amount: -9900, Category: "Groceries", Account: "Visa"},
+		{Date: "2025-01-03", Description: "SHELL OIL 5521", Amount: -6000, Category: "Fuel", Account: "Visa"},
+		{Date: "2025-01-10", Description: "SHELL OIL 7781", Amount: -5500, Category: "Fuel", Account: "Visa"},
+		{Date: "2025-01-17", Description: "SHELL OIL 5521", Amount: -4000, Category: "Car Wash", Account: "Visa"},
+		{Date: "2025-01-20", Description: "COSTCO WHOLESALE #789", Amount: -2000, Account: "Visa"},
+	})
+
+	suggestions, err := svc.SuggestCategorizationRules(RuleSuggestionOptions{})
+	if err != nil {
+		t.Fatalf("SuggestCategorizationRules failed: %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d: %+v", len(suggestions), suggestions)
+	}
+
+	got := suggestions[0]
+	if got.MatchType != "starts_with" || got.MatchValue != "COSTCO" {
+		t.Fatalf("expected starts_with COSTCO, got %s %q", got.MatchType, got.MatchValue)
+	}
+	if got.CategoryName != "Groceries" || got.Support != 3 || got.Matches != 3 || got.Precision != 1 {
+		t.Fatalf("unexpected stats: %+v", got)
+	}
+	if got.Preview.Count != 1 || got.Preview.Transactions[0].Description != "COSTCO WHOLESALE #789" {
+		t.Fatalf("expected preview of the uncategorized Costco row, got %+v", got.Preview)
+	}
+
+	// Lowering precision lets the mixed Shell history through.
+	suggestions, err = svc.SuggestCategorizationRules(RuleSuggestionOptions{MinSupport: 2, MinPrecision: 0.6})
+	if err != nil {
+		t.Fatalf("SuggestCategorizationRules failed: %v", err)
+	}
+	foundShell := false
+	for _, s := range suggestions {
+		if s.MatchValue == "SHELL" && s.CategoryName == "Fuel" {
+			foundShell = true
+		}
+	}
+	if !foundShell {
+		t.Fatalf("expected SHELL -> Fuel suggestion, got %+v", suggestions)
+	}
+}
+
+func TestSuggestCategorizationRulesSkipsRuleAndPredictionCategories(t *testing.T) {
+	svc := newTestService(t)
+
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-01-02", Description: "NETFLIX.COM", Amount: -1599, Account: "Visa"},
+		{Date: "2025-02-02", Description: "NETFLIX.COM", Amount: -1599, Account: "Visa"},
+		{Date: "2025-03-02", Description: "NETFLIX.COM", Amount: -1599, Account: "Visa"},
+		{Date: "2025-01-05", Description: "SPOTIFY P1234", Amount: -1099, Account: "Visa"},
+		{Date: "2025-02-05", Description: "SPOTIFY P1234", Amount: -1099, Account: "Visa"},
+		{Date: "2025-03-05", Description: "SPOTIFY P1234", Amount: -1099, Account: "Visa"},
+	})
+
+	// The rule categorizes the Netflix rows; editing it afterwards would not
+	// make them manual.
+	if _, _, err := svc.SaveCategorizationRule(database.CategorizationRule{MatchType: "contains", MatchValue: "netflix", CategoryName: "Subscriptions"}); err != nil {
+		t.Fatalf("SaveCategorizationRule failed: %v", err)
+	}
+	music, err := svc.store.GetOrCreateCategory("Music")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncategorized, err := svc.store.GetUncategorizedTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uncategorized) != 3 {
+		t.Fatalf("expected the Spotify rows to be uncategorized, got %+v", uncategorized)
+	}
+	for _, tx := range uncategorized {
+		if err := svc.store.SetTransactionCategory(tx.ID, music, database.CategorySourcePrediction); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	suggestions, err := svc.SuggestCategorizationRules(RuleSuggestionOptions{})
+	if err != nil {
+		t.Fatalf("SuggestCategorizationRules failed: %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatalf("expected no suggestions from rule or prediction categories, got %+v", suggestions)
+	}
+}
+
+func TestDescriptionCandidates(t *testing.T) {
+	got := descriptionCandidates("  UBER *TRIP 1234 ")
+	want := [][2]string{
+		{"starts_with", "UBER"},
+		{"starts_with", "UBER *TRIP"},
+		{"contains", "UBER"},
+		{"contains", "TRIP"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("candidate %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
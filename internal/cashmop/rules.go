@@ -19,14 +19,43 @@ func (s *Service) CreateCategory(name string) (int64, error) {
 	return s.store.GetOrCreateCategory(name)
 }
 
+// MoveCategory makes a category a subcategory of parentID, or a top-level
+// category when parentID is nil.
+func (s *Service) MoveCategory(id int64, parentID *int64) error {
+	if id == 0 {
+		return fmt.Errorf("category id is required")
+	}
+	return s.store.MoveCategory(id, parentID)
+}
+
+func (s *Service) SetCategoryKind(id int64, kind string) error {
+	if id == 0 {
+		return fmt.Errorf("category id is required")
+	}
+	return s.store.SetCategoryKind(id, strings.ToLower(strings.TrimSpace(kind)))
+}
+
+func (s *Service) MergeCategories(sourceIDs []int64, targetID int64) (database.CategoryMergeResult, error) {
+	if targetID == 0 {
+		return database.CategoryMergeResult{}, fmt.Errorf("target category id is required")
+	}
+	return s.store.MergeCategories(sourceIDs, targetID)
+}
+
 func (s *Service) GetCategorySummaries() ([]database.CategorySummary, error) {
 	return s.store.GetCategorySummaries()
 }
 
-func (s *Service) DeleteCategory(id int64) (database.CategoryDeleteStats, error) {
+// DeleteCategory deletes a category. With reassignTo its transactions, rules
+// and subcategories move to that category; otherwise transactions become
+// uncategorized and rules are deleted.
+func (s *Service) DeleteCategory(id int64, reassignTo *int64) (database.CategoryDeleteStats, error) {
 	if id == 0 {
 		return database.CategoryDeleteStats{}, fmt.Errorf("category id is required")
 	}
+	if reassignTo != nil {
+		return s.store.DeleteCategoryReassign(id, *reassignTo)
+	}
 	return s.store.DeleteCategory(id)
 }
 
@@ -168,6 +197,32 @@ func (s *Service) UndoCategorizationRule(ruleID int64, transactionIDs []int64) e
 	return s.store.UndoRule(ruleID, transactionIDs)
 }
 
+// GetStaleRules returns rules that have not categorized any transaction dated on
+// or after since (YYYY-MM-DD), including rules that never matched.
+func (s *Service) GetStaleRules(since string) ([]database.CategorizationRule, error) {
+	rules, err := s.store.GetRules()
+	if err != nil {
+		return nil, err
+	}
+	stale := []database.CategorizationRule{}
+	for _, r := range rules {
+		if r.LastMatchedDate == "" || r.LastMatchedDate < since {
+			stale = append(stale, r)
+		}
+	}
+	return stale, nil
+}
+
 func (s *Service) ApplyAllRules() (int, error) {
 	return s.store.ApplyAllRules()
 }
+
+// ReapplyRules re-runs rules over uncategorized transactions and, depending on
+// overwrite, over categories previously set by rules or all categories.
+func (s *Service) ReapplyRules(ruleIDs []int64, overwrite string, dryRun bool) (database.RuleApplyResult, error) {
+	return s.store.ReapplyRules(ruleIDs, overwrite, dryRun)
+}
+
+func (s *Service) UndoRuleApply(batchID int64) (database.RuleApplyUndoResult, error) {
+	return s.store.UndoRuleApply(batchID)
+}
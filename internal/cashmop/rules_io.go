@@ -0,0 +1,312 @@
+package cashmop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/default-anton/cashmop/internal/database"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RulesetVersion = 1
+
+	RulesetModeMerge   = "merge"
+	RulesetModeReplace = "replace"
+)
+
+// ErrInvalidRuleset is wrapped by errors caused by the contents of a ruleset file.
+var ErrInvalidRuleset = errors.New("invalid ruleset")
+
+// Ruleset is the portable rules format. Categories are referenced by their full
+// name, e.g. "Food > Groceries", so a ruleset can be shared between databases.
+type Ruleset struct {
+	Version int           `json:"version" yaml:"version"`
+	Rules   []RulesetRule `json:"rules" yaml:"rules"`
+}
+
+type RulesetRule struct {
+	MatchType  string `json:"match_type" yaml:"match_type"`
+	MatchValue string `json:"match_value" yaml:"match_value"`
+	Category   string `json:"category" yaml:"category"`
+	// CategoryKind is the kind given to the category if import creates it.
+	CategoryKind string `json:"category_kind,omitempty" yaml:"category_kind,omitempty"`
+	// AmountMin and AmountMax are decimal strings in the main currency.
+	AmountMin *string `json:"amount_min,omitempty" yaml:"amount_min,omitempty"`
+	AmountMax *string `json:"amount_max,omitempty" yaml:"amount_max,omitempty"`
+}
+
+type RulesetImportOptions struct {
+	Mode   string `json:"mode"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// RulesetIssue describes a ruleset entry that was skipped. Index is 1-based.
+type RulesetIssue struct {
+	Index            int         `json:"index"`
+	Rule             RulesetRule `json:"rule"`
+	ExistingRuleID   int64       `json:"existing_rule_id,omitempty"`
+	ExistingCategory string      `json:"existing_category"`
+}
+
+type RulesetImportResult struct {
+	Mode              string         `json:"mode"`
+	DryRun            bool           `json:"dry_run"`
+	Total             int            `json:"total"`
+	CreatedCount      int            `json:"created_count"`
+	DeletedCount      int            `json:"deleted_count"`
+	CreatedCategories []string       `json:"created_categories"`
+	Duplicates        []RulesetIssue `json:"duplicates"`
+	Conflicts         []RulesetIssue `json:"conflicts"`
+	AppliedCount      int            `json:"applied_count"`
+}
+
+// ExportRuleset writes all rules to path as YAML, or JSON when the file has a
+// .json extension, and returns the number of rules written.
+func (s *Service) ExportRuleset(path string) (int, error) {
+	rules, err := s.store.GetRules()
+	if err != nil {
+		return 0, err
+	}
+	categories, err := s.store.GetAllCategories()
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[int64]database.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	ruleset := Ruleset{Version: RulesetVersion, Rules: make([]RulesetRule, 0, len(rules))}
+	for _, r := range rules {
+		item := RulesetRule{
+			MatchType:  r.MatchType,
+			MatchValue: r.MatchValue,
+			Category:   r.CategoryName,
+		}
+		if c, ok := byID[r.CategoryID]; ok {
+			item.Category = c.FullName
+			item.CategoryKind = c.Kind
+		}
+		if r.AmountMin != nil {
+			v := formatCents(*r.AmountMin)
+			item.AmountMin = &v
+		}
+		if r.AmountMax != nil {
+			v := formatCents(*r.AmountMax)
+			item.AmountMax = &v
+		}
+		ruleset.Rules = append(ruleset.Rules, item)
+	}
+
+	var data []byte
+	if isJSONRuleset(path) {
+		data, err = json.MarshalIndent(ruleset, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(ruleset)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return 0, err
+	}
+	return len(ruleset.Rules), nil
+}
+
+// ImportRuleset reads a ruleset and adds its rules. In merge mode existing rules
+// are kept and entries that duplicate or conflict with them are skipped; in
+// replace mode all existing rules are removed first. Missing categories are
+// created along each rule's category path. Rules are applied to uncategorized
+// transactions unless DryRun is set.
+func (s *Service) ImportRuleset(path string, opts RulesetImportOptions) (RulesetImportResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = RulesetModeMerge
+	}
+	if opts.Mode != RulesetModeMerge && opts.Mode != RulesetModeReplace {
+		return RulesetImportResult{}, fmt.Errorf("unknown import mode %q", opts.Mode)
+	}
+
+	ruleset, err := readRuleset(path)
+	if err != nil {
+		return RulesetImportResult{}, err
+	}
+
+	result := RulesetImportResult{
+		Mode:              opts.Mode,
+		DryRun:            opts.DryRun,
+		Total:             len(ruleset.Rules),
+		CreatedCategories: []string{},
+		Duplicates:        []RulesetIssue{},
+		Conflicts:         []RulesetIssue{},
+	}
+
+	type seenRule struct {
+		id       int64
+		category string
+	}
+	seen := make(map[string]seenRule)
+	existing, err := s.store.GetRules()
+	if err != nil {
+		return result, err
+	}
+	if opts.Mode == RulesetModeReplace {
+		result.DeletedCount = len(existing)
+	} else {
+		for _, r := range existing {
+			key := rulesetKey(r.MatchType, r.MatchValue, r.AmountMin, r.AmountMax)
+			if _, ok := seen[key]; !ok {
+				seen[key] = seenRule{id: r.ID, category: r.CategoryName}
+			}
+		}
+	}
+
+	categories, err := s.store.GetAllCategories()
+	if err != nil {
+		return result, err
+	}
+	knownCategories := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		knownCategories[c.Name] = true
+	}
+
+	toCreate := []database.ImportedRule{}
+	for i, item := range ruleset.Rules {
+		rule, err := rulesetRuleToRule(item)
+		if err != nil {
+			return result, fmt.Errorf("%w: rule %d: %v", ErrInvalidRuleset, i+1, err)
+		}
+
+		key := rulesetKey(rule.MatchType, rule.MatchValue, rule.AmountMin, rule.AmountMax)
+		if prev, ok := seen[key]; ok {
+			issue := RulesetIssue{Index: i + 1, Rule: item, ExistingRuleID: prev.id, ExistingCategory: prev.category}
+			if prev.category == rule.CategoryName {
+				result.Duplicates = append(result.Duplicates, issue)
+			} else {
+				result.Conflicts = append(result.Conflicts, issue)
+			}
+			continue
+		}
+		seen[key] = seenRule{category: rule.CategoryName}
+
+		for j, name := range rule.CategoryPath {
+			if !knownCategories[name] {
+				knownCategories[name] = true
+				result.CreatedCategories = append(result.CreatedCategories, strings.Join(rule.CategoryPath[:j+1], database.CategoryPathSeparator))
+			}
+		}
+		toCreate = append(toCreate, rule)
+	}
+	result.CreatedCount = len(toCreate)
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if _, err := s.store.ImportRules(toCreate, opts.Mode == RulesetModeReplace); err != nil {
+		return result, err
+	}
+	if result.AppliedCount, err = s.store.ApplyAllRules(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func readRuleset(path string) (Ruleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Ruleset{}, err
+	}
+
+	var ruleset Ruleset
+	if isJSONRuleset(path) {
+		err = json.Unmarshal(data, &ruleset)
+	} else {
+		err = yaml.Unmarshal(data, &ruleset)
+	}
+	if err != nil {
+		return Ruleset{}, fmt.Errorf("%w: %v", ErrInvalidRuleset, err)
+	}
+	if ruleset.Version != RulesetVersion {
+		return Ruleset{}, fmt.Errorf("%w: unsupported version %d (expected %d)", ErrInvalidRuleset, ruleset.Version, RulesetVersion)
+	}
+	return ruleset, nil
+}
+
+// rulesetRuleToRule converts a ruleset entry. CategoryName is the last part of
+// the category path, since category names are unique.
+func rulesetRuleToRule(item RulesetRule) (database.ImportedRule, error) {
+	rule := database.ImportedRule{
+		CategorizationRule: database.CategorizationRule{
+			MatchType:  strings.TrimSpace(item.MatchType),
+			MatchValue: item.MatchValue,
+		},
+		CategoryKind: strings.TrimSpace(item.CategoryKind),
+	}
+	for _, name := range strings.Split(item.Category, strings.TrimSpace(database.CategoryPathSeparator)) {
+		if name = strings.TrimSpace(name); name != "" {
+			rule.CategoryPath = append(rule.CategoryPath, name)
+		}
+	}
+	if len(rule.CategoryPath) > 0 {
+		rule.CategoryName = rule.CategoryPath[len(rule.CategoryPath)-1]
+	}
+	switch rule.MatchType {
+	case "starts_with", "ends_with", "contains", "exact":
+	default:
+		return rule, fmt.Errorf("match_type must be starts_with, ends_with, contains, or exact")
+	}
+	if strings.TrimSpace(rule.MatchValue) == "" {
+		return rule, fmt.Errorf("match_value cannot be empty")
+	}
+	if rule.CategoryName == "" {
+		return rule, fmt.Errorf("category cannot be empty")
+	}
+	if rule.CategoryKind != "" && !database.IsValidCategoryKind(rule.CategoryKind) {
+		return rule, fmt.Errorf("category_kind must be income, expense, transfer, or excluded")
+	}
+
+	var err error
+	if item.AmountMin != nil {
+		if rule.AmountMin, err = parseRulesetAmount(*item.AmountMin); err != nil {
+			return rule, fmt.Errorf("amount_min: %v", err)
+		}
+	}
+	if item.AmountMax != nil {
+		if rule.AmountMax, err = parseRulesetAmount(*item.AmountMax); err != nil {
+			return rule, fmt.Errorf("amount_max: %v", err)
+		}
+	}
+	return rule, nil
+}
+
+func parseRulesetAmount(value string) (*int64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	cents := int64(math.Round(v * 100))
+	return &cents, nil
+}
+
+// rulesetKey identifies a rule by everything except its category.
+func rulesetKey(matchType, matchValue string, amountMin, amountMax *int64) string {
+	bound := func(v *int64) string {
+		if v == nil {
+			return "-"
+		}
+		return strconv.FormatInt(*v, 10)
+	}
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s", matchType, matchValue, bound(amountMin), bound(amountMax))
+}
+
+func isJSONRuleset(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
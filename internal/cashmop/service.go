@@ -10,6 +10,9 @@ type Service struct {
 	store *database.Store
 
 	webSearchCache sync.Map
+
+	modelMu sync.Mutex
+	model   *database.CategoryModel
 }
 
 func New(store *database.Store) *Service {
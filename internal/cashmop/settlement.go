@@ -0,0 +1,283 @@
+package cashmop
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+var (
+	ErrInvalidSettlementShares   = errors.New("settlement shares must be between 0 and 100, name each owner once and add up to 100")
+	ErrUnknownSettlementOwner    = errors.New("unknown owner in settlement shares")
+	ErrUnknownSettlementCategory = errors.New("unknown category in settlement shares")
+)
+
+// OwnerShare is the percentage of shared spending an owner bears.
+type OwnerShare struct {
+	OwnerID int64   `json:"owner_id"`
+	Percent float64 `json:"percent"`
+}
+
+// CategoryShares overrides the split for a category and its subcategories.
+type CategoryShares struct {
+	CategoryID int64        `json:"category_id"`
+	Shares     []OwnerShare `json:"shares"`
+}
+
+// SettlementOptions selects the shared spending and how to split it. The
+// filters work like those of GetAnalysisTransactions.
+type SettlementOptions struct {
+	StartDate   string  `json:"start_date"`
+	EndDate     string  `json:"end_date"`
+	CategoryIDs []int64 `json:"category_ids"`
+	TagIDs      []int64 `json:"tag_ids"`
+	MerchantIDs []int64 `json:"merchant_ids"`
+	// Shares splits spending between owners; empty splits it evenly between
+	// all owners.
+	Shares []OwnerShare `json:"shares"`
+	// CategoryShares overrides Shares; the override of the closest category
+	// up the tree wins.
+	CategoryShares []CategoryShares `json:"category_shares"`
+}
+
+// SettlementOwner compares what an owner paid with their share. Balance is
+// Paid minus Share: positive when the others owe them.
+type SettlementOwner struct {
+	OwnerID int64  `json:"owner_id"`
+	Name    string `json:"name"`
+	Paid    int64  `json:"paid"`
+	Share   int64  `json:"share"`
+	Balance int64  `json:"balance"`
+}
+
+// SettlementPayment is one payment that settles the balances.
+type SettlementPayment struct {
+	FromOwnerID int64  `json:"from_owner_id"`
+	From        string `json:"from"`
+	ToOwnerID   int64  `json:"to_owner_id"`
+	To          string `json:"to"`
+	Amount      int64  `json:"amount"`
+}
+
+// Settlement splits spending in the main currency between owners. Spending
+// is expenses net of refunds; income, transfers and excluded categories are
+// left out. Transactions (or split lines) without an owner have no payer and
+// are only counted in Unassigned.
+type Settlement struct {
+	StartDate    string              `json:"start_date"`
+	EndDate      string              `json:"end_date"`
+	MainCurrency string              `json:"main_currency"`
+	Total        int64               `json:"total"`
+	Owners       []SettlementOwner   `json:"owners"`
+	Payments     []SettlementPayment `json:"payments"`
+	// UnassignedCount and UnassignedTotal cover spending without an owner.
+	UnassignedCount int   `json:"unassigned_count"`
+	UnassignedTotal int64 `json:"unassigned_total"`
+	// Unconverted counts transactions left out for lack of an FX rate.
+	Unconverted int `json:"unconverted"`
+}
+
+// isSpending reports whether tx is an expense or a refund of one: income,
+// transfers, excluded categories and uncategorized deposits are not.
+func isSpending(tx database.TransactionModel) bool {
+	if !database.CountsTowardsTotals(tx.CategoryKind) || tx.CategoryKind == database.CategoryKindIncome {
+		return false
+	}
+	return tx.CategoryKind != "" || tx.Amount < 0
+}
+
+func validateOwnerShares(shares []OwnerShare, owners map[int64]string) error {
+	seen := map[int64]bool{}
+	var sum float64
+	for _, s := range shares {
+		if _, ok := owners[s.OwnerID]; !ok {
+			return ErrUnknownSettlementOwner
+		}
+		if seen[s.OwnerID] || s.Percent < 0 || s.Percent > 100 || math.IsNaN(s.Percent) {
+			return ErrInvalidSettlementShares
+		}
+		seen[s.OwnerID] = true
+		sum += s.Percent
+	}
+	if math.Abs(sum-100) > 0.01 {
+		return ErrInvalidSettlementShares
+	}
+	return nil
+}
+
+// splitCents divides amount in proportion to the percentages, giving leftover
+// cents to the largest remainders so that the parts add up to amount.
+func splitCents(amount int64, shares []OwnerShare) []int64 {
+	sign := int64(1)
+	if amount < 0 {
+		sign, amount = -1, -amount
+	}
+	var total float64
+	for _, s := range shares {
+		total += s.Percent
+	}
+	parts := make([]int64, len(shares))
+	if total == 0 {
+		return parts
+	}
+	remainders := make([]float64, len(shares))
+	left := amount
+	for i, s := range shares {
+		exact := float64(amount) * s.Percent / total
+		parts[i] = int64(math.Floor(exact))
+		remainders[i] = exact - float64(parts[i])
+		left -= parts[i]
+	}
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; left > 0; i = (i + 1) % len(order) {
+		parts[order[i]]++
+		left--
+	}
+	for i := range parts {
+		parts[i] *= sign
+	}
+	return parts
+}
+
+// settlePayments pairs the largest debtor with the largest creditor until
+// every balance is zero.
+func settlePayments(owners []SettlementOwner) []SettlementPayment {
+	balances := make([]SettlementOwner, len(owners))
+	copy(balances, owners)
+	payments := []SettlementPayment{}
+	for {
+		debtor, creditor := -1, -1
+		for i, o := range balances {
+			if o.Balance < 0 && (debtor < 0 || o.Balance < balances[debtor].Balance) {
+				debtor = i
+			}
+			if o.Balance > 0 && (creditor < 0 || o.Balance > balances[creditor].Balance) {
+				creditor = i
+			}
+		}
+		if debtor < 0 || creditor < 0 {
+			return payments
+		}
+		amount := min(-balances[debtor].Balance, balances[creditor].Balance)
+		payments = append(payments, SettlementPayment{
+			FromOwnerID: balances[debtor].OwnerID,
+			From:        balances[debtor].Name,
+			ToOwnerID:   balances[creditor].OwnerID,
+			To:          balances[creditor].Name,
+			Amount:      amount,
+		})
+		balances[debtor].Balance += amount
+		balances[creditor].Balance -= amount
+	}
+}
+
+// Settle computes each owner's share of spending against what they paid, and
+// the payments that settle the difference.
+func (s *Service) Settle(opts SettlementOptions) (Settlement, error) {
+	users, err := s.store.GetAllUsers()
+	if err != nil {
+		return Settlement{}, err
+	}
+	names := make(map[int64]string, len(users))
+	for _, u := range users {
+		names[u.ID] = u.Name
+	}
+
+	shares := opts.Shares
+	if len(shares) == 0 {
+		for _, u := range users {
+			shares = append(shares, OwnerShare{OwnerID: u.ID, Percent: 100 / float64(len(users))})
+		}
+	} else if err := validateOwnerShares(shares, names); err != nil {
+		return Settlement{}, err
+	}
+
+	categories, err := s.store.GetAllCategories()
+	if err != nil {
+		return Settlement{}, err
+	}
+	parents := make(map[int64]*int64, len(categories))
+	for _, c := range categories {
+		parents[c.ID] = c.ParentID
+	}
+	overrides := make(map[int64][]OwnerShare, len(opts.CategoryShares))
+	for _, cs := range opts.CategoryShares {
+		if _, ok := parents[cs.CategoryID]; !ok {
+			return Settlement{}, ErrUnknownSettlementCategory
+		}
+		if _, ok := overrides[cs.CategoryID]; ok {
+			return Settlement{}, ErrInvalidSettlementShares
+		}
+		if err := validateOwnerShares(cs.Shares, names); err != nil {
+			return Settlement{}, err
+		}
+		overrides[cs.CategoryID] = cs.Shares
+	}
+	sharesFor := func(categoryID *int64) []OwnerShare {
+		for id := categoryID; id != nil; id = parents[*id] {
+			if o, ok := overrides[*id]; ok {
+				return o
+			}
+		}
+		return shares
+	}
+
+	settings, err := s.store.GetCurrencySettings()
+	if err != nil {
+		return Settlement{}, err
+	}
+	txs, err := s.store.GetAnalysisTransactions(opts.StartDate, opts.EndDate, opts.CategoryIDs, nil, opts.TagIDs, opts.MerchantIDs)
+	if err != nil {
+		return Settlement{}, err
+	}
+
+	result := Settlement{StartDate: opts.StartDate, EndDate: opts.EndDate, MainCurrency: settings.MainCurrency}
+	byOwner := map[int64]*SettlementOwner{}
+	owner := func(id int64) *SettlementOwner {
+		o, ok := byOwner[id]
+		if !ok {
+			o = &SettlementOwner{OwnerID: id, Name: names[id]}
+			byOwner[id] = o
+		}
+		return o
+	}
+	for _, sh := range shares {
+		owner(sh.OwnerID)
+	}
+	for _, tx := range txs {
+		if !isSpending(tx) {
+			continue
+		}
+		if tx.AmountInMainCurrency == nil {
+			result.Unconverted++
+			continue
+		}
+		spent := -*tx.AmountInMainCurrency
+		if tx.OwnerID == nil {
+			result.UnassignedCount++
+			result.UnassignedTotal += spent
+			continue
+		}
+		result.Total += spent
+		owner(*tx.OwnerID).Paid += spent
+		split := sharesFor(tx.CategoryID)
+		for i, part := range splitCents(spent, split) {
+			owner(split[i].OwnerID).Share += part
+		}
+	}
+
+	result.Owners = make([]SettlementOwner, 0, len(byOwner))
+	for _, o := range byOwner {
+		o.Balance = o.Paid - o.Share
+		result.Owners = append(result.Owners, *o)
+	}
+	sort.Slice(result.Owners, func(i, j int) bool { return result.Owners[i].Name < result.Owners[j].Name })
+	result.Payments = settlePayments(result.Owners)
+	return result, nil
+}
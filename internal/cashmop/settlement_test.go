@@ -0,0 +1,86 @@
+package cashmop
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func TestSettle(t *testing.T) {
+	svc := newTestService(t)
+
+	seedTransactions(t, svc, []TransactionImportInput{
+		{Date: "2025-03-02", Description: "GROCER", Amount: -10000, Category: "Groceries", Account: "Visa", Owner: "Alex"},
+		{Date: "2025-03-01", Description: "RENT", Amount: -100000, Category: "Rent", Account: "Chequing", Owner: "Sam"},
+		{Date: "2025-03-05", Description: "GUITAR STRINGS", Amount: -5000, Category: "Hobbies", Account: "Visa", Owner: "Alex"},
+		{Date: "2025-03-15", Description: "PAYROLL", Amount: 300000, Category: "Salary", Account: "Chequing", Owner: "Alex"},
+		{Date: "2025-03-20", Description: "PHARMACY", Amount: -2000, Account: "Cash"},
+		{Date: "2025-04-01", Description: "RENT", Amount: -100000, Category: "Rent", Account: "Chequing", Owner: "Sam"},
+	})
+	salary, _ := svc.store.GetOrCreateCategory("Salary")
+	if err := svc.store.SetCategoryKind(salary, database.CategoryKindIncome); err != nil {
+		t.Fatal(err)
+	}
+	hobbies, _ := svc.store.GetOrCreateCategory("Hobbies")
+	users, _ := svc.store.GetUserMap()
+	alex, sam := users["Alex"], users["Sam"]
+
+	settlement, err := svc.Settle(SettlementOptions{
+		StartDate:      "2025-03-01",
+		EndDate:        "2025-03-31",
+		CategoryShares: []CategoryShares{{CategoryID: hobbies, Shares: []OwnerShare{{OwnerID: alex, Percent: 100}}}},
+	})
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if settlement.Total != 115000 || settlement.UnassignedCount != 1 || settlement.UnassignedTotal != 2000 {
+		t.Errorf("unexpected settlement: %+v", settlement)
+	}
+	if len(settlement.Owners) != 2 {
+		t.Fatalf("expected 2 owners, got %+v", settlement.Owners)
+	}
+	if a := settlement.Owners[0]; a.Name != "Alex" || a.Paid != 15000 || a.Share != 60000 || a.Balance != -45000 {
+		t.Errorf("unexpected Alex: %+v", a)
+	}
+	if s := settlement.Owners[1]; s.Name != "Sam" || s.Paid != 100000 || s.Share != 55000 || s.Balance != 45000 {
+		t.Errorf("unexpected Sam: %+v", s)
+	}
+	if len(settlement.Payments) != 1 || settlement.Payments[0].From != "Alex" || settlement.Payments[0].To != "Sam" || settlement.Payments[0].Amount != 45000 {
+		t.Errorf("unexpected payments: %+v", settlement.Payments)
+	}
+
+	settlement, err = svc.Settle(SettlementOptions{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-31",
+		Shares:    []OwnerShare{{OwnerID: alex, Percent: 60}, {OwnerID: sam, Percent: 40}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := settlement.Owners[0]; a.Share != 69000 || a.Balance != -54000 {
+		t.Errorf("unexpected Alex with 60/40: %+v", a)
+	}
+
+	for _, tc := range []struct {
+		opts SettlementOptions
+		want error
+	}{
+		{SettlementOptions{Shares: []OwnerShare{{OwnerID: alex, Percent: 60}}}, ErrInvalidSettlementShares},
+		{SettlementOptions{Shares: []OwnerShare{{OwnerID: alex, Percent: 50}, {OwnerID: alex, Percent: 50}}}, ErrInvalidSettlementShares},
+		{SettlementOptions{Shares: []OwnerShare{{OwnerID: 999, Percent: 100}}}, ErrUnknownSettlementOwner},
+		{SettlementOptions{CategoryShares: []CategoryShares{{CategoryID: 999, Shares: []OwnerShare{{OwnerID: alex, Percent: 100}}}}}, ErrUnknownSettlementCategory},
+	} {
+		if _, err := svc.Settle(tc.opts); !errors.Is(err, tc.want) {
+			t.Errorf("Settle(%+v) = %v, want %v", tc.opts, err, tc.want)
+		}
+	}
+}
+
+func TestSplitCents(t *testing.T) {
+	third := 100.0 / 3
+	parts := splitCents(-100, []OwnerShare{{Percent: third}, {Percent: third}, {Percent: third}})
+	if parts[0]+parts[1]+parts[2] != -100 || parts[0] != -34 || parts[1] != -33 {
+		t.Errorf("unexpected parts: %v", parts)
+	}
+}
@@ -0,0 +1,30 @@
+package cashmop
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := database.Open(path, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return New(store)
+}
+
+func seedTransactions(t *testing.T, svc *Service, txs []TransactionImportInput) {
+	t.Helper()
+
+	if err := svc.ImportTransactions(txs, ImportOptions{}); err != nil {
+		t.Fatalf("import transactions: %v", err)
+	}
+}
@@ -7,7 +7,17 @@ import (
 )
 
 func (s *Service) GetUncategorizedTransactions() ([]database.TransactionModel, error) {
-	return s.store.GetUncategorizedTransactions()
+	return s.PredictUncategorizedTransactions(defaultPredictionCount)
+}
+
+// PredictUncategorizedTransactions returns the uncategorized transactions with
+// the top n category guesses on each.
+func (s *Service) PredictUncategorizedTransactions(n int) ([]database.TransactionModel, error) {
+	txs, err := s.store.GetUncategorizedTransactions()
+	if err != nil {
+		return nil, err
+	}
+	return s.PredictCategories(txs, n)
 }
 
 func (s *Service) SearchTransactions(descriptionMatch string, matchType string, amountMin *int64, amountMax *int64) ([]database.TransactionModel, error) {
@@ -18,8 +28,20 @@ func (s *Service) GetMonthList() ([]string, error) {
 	return s.store.GetMonthList()
 }
 
-func (s *Service) GetAnalysisTransactions(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64) ([]database.TransactionModel, error) {
-	return s.store.GetAnalysisTransactions(startDate, endDate, categoryIDs, ownerIDs)
+func (s *Service) GetAnalysisTransactions(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64, tagIDs []int64, merchantIDs []int64) ([]database.TransactionModel, error) {
+	return s.store.GetAnalysisTransactions(startDate, endDate, categoryIDs, ownerIDs, tagIDs, merchantIDs)
+}
+
+func (s *Service) GetCategoryRollups(txs []database.TransactionModel) ([]database.CategoryRollup, error) {
+	return s.store.GetCategoryRollups(txs)
+}
+
+func (s *Service) GetKindTotals(txs []database.TransactionModel) (database.KindTotals, error) {
+	return s.store.GetKindTotals(txs)
+}
+
+func (s *Service) GetSummary(opts database.SummaryOptions) (database.Summary, error) {
+	return s.store.GetSummary(opts)
 }
 
 func (s *Service) GetAnalysisFacets(startDate string, endDate string) (database.AnalysisFacets, error) {
@@ -30,6 +52,92 @@ func (s *Service) DeleteTransactions(ids []int64) (int, error) {
 	return s.store.DeleteTransactions(ids)
 }
 
+func (s *Service) GetTransaction(id int64) (database.TransactionModel, error) {
+	return s.store.GetTransaction(id)
+}
+
+// CreateTransaction adds a manually entered transaction, creating its account,
+// owner and category by name when they do not exist yet.
+func (s *Service) CreateTransaction(input TransactionImportInput) (database.TransactionModel, error) {
+	accountID, err := s.store.GetOrCreateAccount(strings.TrimSpace(input.Account))
+	if err != nil {
+		return database.TransactionModel{}, err
+	}
+	ownerID, err := s.store.GetOrCreateUser(strings.TrimSpace(input.Owner))
+	if err != nil {
+		return database.TransactionModel{}, err
+	}
+	var categoryID *int64
+	if name := strings.TrimSpace(input.Category); name != "" {
+		id, err := s.store.GetOrCreateCategory(name)
+		if err != nil {
+			return database.TransactionModel{}, err
+		}
+		categoryID = &id
+	}
+	return s.store.CreateTransaction(database.TransactionModel{
+		AccountID:   accountID,
+		OwnerID:     ownerID,
+		Date:        strings.TrimSpace(input.Date),
+		Description: input.Description,
+		Note:        input.Note,
+		Amount:      input.Amount,
+		CategoryID:  categoryID,
+		Currency:    input.Currency,
+	})
+}
+
+func (s *Service) UpdateTransaction(id int64, update database.TransactionUpdate) (database.TransactionModel, error) {
+	if update.Date != nil {
+		date := strings.TrimSpace(*update.Date)
+		update.Date = &date
+	}
+	return s.store.UpdateTransaction(id, update)
+}
+
+func (s *Service) SetTransactionNote(id int64, note string) error {
+	return s.store.SetTransactionNote(id, strings.TrimSpace(note))
+}
+
+func (s *Service) AttachFile(transactionID int64, path string) (database.Attachment, error) {
+	return s.store.AttachFile(transactionID, path)
+}
+
+func (s *Service) GetTransactionAttachments(transactionID int64) ([]database.Attachment, error) {
+	return s.store.GetTransactionAttachments(transactionID)
+}
+
+func (s *Service) GetTransactionSplits(transactionID int64) ([]database.SplitLine, error) {
+	return s.store.GetTransactionSplits(transactionID)
+}
+
+func (s *Service) SplitTransaction(transactionID int64, lines []database.SplitLine) ([]database.SplitLine, error) {
+	for i := range lines {
+		lines[i].Note = strings.TrimSpace(lines[i].Note)
+	}
+	return s.store.SplitTransaction(transactionID, lines)
+}
+
+func (s *Service) UnsplitTransaction(transactionID int64) (int64, error) {
+	return s.store.UnsplitTransaction(transactionID)
+}
+
+func (s *Service) GetTags() ([]database.Tag, error) {
+	return s.store.GetTags()
+}
+
+func (s *Service) GetTagIDsByName(names []string) ([]int64, []string, error) {
+	return s.store.GetTagIDsByName(names)
+}
+
+func (s *Service) AddTransactionTags(transactionIDs []int64, names []string) (database.TagChangeResult, error) {
+	return s.store.AddTransactionTags(transactionIDs, names)
+}
+
+func (s *Service) RemoveTransactionTags(transactionIDs []int64, names []string) (database.TagChangeResult, error) {
+	return s.store.RemoveTransactionTags(transactionIDs, names)
+}
+
 func (s *Service) RenameCategory(id int64, newName string) error {
 	return s.store.RenameCategory(id, newName)
 }
@@ -62,6 +170,6 @@ func (s *Service) GetUserMap() (map[string]int64, error) {
 	return s.store.GetUserMap()
 }
 
-func (s *Service) BatchInsertTransactionsWithCount(txs []database.TransactionModel) (int, int, error) {
-	return s.store.BatchInsertTransactionsWithCount(txs)
+func (s *Service) BatchInsertTransactionsWithIDs(txs []database.TransactionModel) ([]int64, error) {
+	return s.store.BatchInsertTransactionsWithIDs(txs)
 }
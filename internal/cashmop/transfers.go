@@ -0,0 +1,15 @@
+package cashmop
+
+import "github.com/default-anton/cashmop/internal/database"
+
+func (s *Service) DetectTransfers(opts database.TransferDetectOptions) ([]database.TransferLink, error) {
+	return s.store.DetectTransfers(opts)
+}
+
+func (s *Service) GetTransferLinks() ([]database.TransferLink, error) {
+	return s.store.GetTransferLinks()
+}
+
+func (s *Service) UnlinkTransfer(id int64) error {
+	return s.store.UnlinkTransfer(id)
+}
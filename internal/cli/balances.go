@@ -0,0 +1,316 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+type balanceCheckpoint struct {
+	ID       int64  `json:"id"`
+	Account  string `json:"account"`
+	Date     string `json:"date"`
+	Balance  string `json:"balance"`
+	Currency string `json:"currency"`
+	Source   string `json:"source"`
+	Note     string `json:"note"`
+}
+
+func newBalanceCheckpoint(cp database.BalanceCheckpoint) balanceCheckpoint {
+	return balanceCheckpoint{
+		ID:       cp.ID,
+		Account:  cp.AccountName,
+		Date:     cp.Date,
+		Balance:  formatCentsDecimal(cp.Balance),
+		Currency: cp.Currency,
+		Source:   cp.Source,
+		Note:     cp.Note,
+	}
+}
+
+type balancesListResponse struct {
+	Ok          bool                `json:"ok"`
+	Count       int                 `json:"count"`
+	Checkpoints []balanceCheckpoint `json:"checkpoints"`
+}
+
+type balanceResponse struct {
+	Ok         bool              `json:"ok"`
+	Checkpoint balanceCheckpoint `json:"checkpoint"`
+}
+
+type balanceDeleteResponse struct {
+	Ok bool  `json:"ok"`
+	ID int64 `json:"id"`
+}
+
+func (r balancesListResponse) TableHeaders() []string {
+	return []string{"ID", "Account", "Date", "Balance", "Currency", "Source", "Note"}
+}
+
+func (r balancesListResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Checkpoints))
+	for i, cp := range r.Checkpoints {
+		rows[i] = []string{fmt.Sprint(cp.ID), cp.Account, cp.Date, cp.Balance, cp.Currency, cp.Source, cp.Note}
+	}
+	return rows
+}
+
+type balancesReconcileResponse struct {
+	Ok         bool                    `json:"ok"`
+	Reconciled bool                    `json:"reconciled"`
+	Accounts   []accountReconciliation `json:"accounts"`
+}
+
+type accountReconciliation struct {
+	AccountID          int64                 `json:"account_id"`
+	Account            string                `json:"account"`
+	Currency           string                `json:"currency"`
+	Reconciled         bool                  `json:"reconciled"`
+	OpeningBalance     string                `json:"opening_balance"`
+	CurrentBalance     string                `json:"current_balance"`
+	OtherCurrencyCount int                   `json:"other_currency_count"`
+	Checkpoints        []reconciliationEntry `json:"checkpoints"`
+}
+
+type reconciliationEntry struct {
+	balanceCheckpoint
+	TransactionCount int     `json:"transaction_count"`
+	Expected         *string `json:"expected"`
+	Gap              *string `json:"gap"`
+}
+
+func (r balancesReconcileResponse) TableHeaders() []string {
+	return []string{"Account", "Date", "Balance", "Expected", "Gap", "Transactions", "Source"}
+}
+
+func (r balancesReconcileResponse) ToTable() [][]string {
+	var rows [][]string
+	for _, a := range r.Accounts {
+		for _, e := range a.Checkpoints {
+			expected, gap := "", ""
+			if e.Expected != nil {
+				expected = *e.Expected
+			}
+			if e.Gap != nil {
+				gap = *e.Gap
+			}
+			rows = append(rows, []string{a.Account, e.Date, e.Balance, expected, gap, fmt.Sprint(e.TransactionCount), e.Source})
+		}
+	}
+	return rows
+}
+
+func handleBalances(svc *cashmop.Service, args []string) commandResult {
+	if len(args) == 0 {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Missing balances subcommand (list, set, delete, reconcile).",
+			Hint:    "Use \"cashmop balances list\" or \"cashmop balances reconcile\".",
+		})}
+	}
+
+	switch args[0] {
+	case "list":
+		return handleBalancesList(svc, args[1:])
+	case "set":
+		return handleBalancesSet(svc, args[1:])
+	case "delete":
+		return handleBalancesDelete(svc, args[1:])
+	case "reconcile":
+		return handleBalancesReconcile(svc, args[1:])
+	default:
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Unknown balances subcommand.",
+			Hint:    "Use list, set, delete, or reconcile.",
+		})}
+	}
+}
+
+// resolveAccountFilter looks up an optional --account name.
+func resolveAccountFilter(svc *cashmop.Service, account string) (*int64, *cliError) {
+	if strings.TrimSpace(account) == "" {
+		return nil, nil
+	}
+	id, err := svc.AccountID(account)
+	if err != nil {
+		if errors.Is(err, database.ErrUnknownAccount) {
+			return nil, runtimeError(unknownAccountError())
+		}
+		return nil, runtimeError(ErrorDetail{Message: err.Error()})
+	}
+	return &id, nil
+}
+
+func unknownAccountError() ErrorDetail {
+	return ErrorDetail{Field: "account", Message: "Account not found.", Hint: "Use an account name from \"cashmop tx list\" or an import."}
+}
+
+func handleBalancesList(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("balances list")
+	var account string
+	fs.StringVar(&account, "account", "", "")
+	if ok, res := fs.parse(args, "balances"); !ok {
+		return res
+	}
+
+	accountID, cErr := resolveAccountFilter(svc, account)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	checkpoints, err := svc.GetBalanceCheckpoints(accountID)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	out := make([]balanceCheckpoint, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		out = append(out, newBalanceCheckpoint(cp))
+	}
+	return commandResult{Response: balancesListResponse{Ok: true, Count: len(out), Checkpoints: out}}
+}
+
+func handleBalancesSet(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("balances set")
+	var account string
+	var date string
+	var balance string
+	var currency string
+	var source string
+	var note string
+
+	fs.StringVar(&account, "account", "", "")
+	fs.StringVar(&date, "date", "", "")
+	fs.StringVar(&balance, "balance", "", "")
+	fs.StringVar(&currency, "currency", "", "")
+	fs.StringVar(&source, "source", database.BalanceSourceManual, "")
+	fs.StringVar(&note, "note", "", "")
+	if ok, res := fs.parse(args, "balances"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if strings.TrimSpace(account) == "" {
+		details = append(details, requiredFlagError("account", "Provide --account <name>."))
+	}
+	if date == "" {
+		details = append(details, requiredFlagError("date", "Provide --date YYYY-MM-DD."))
+	} else if _, err := parseDate(date); err != nil {
+		details = append(details, ErrorDetail{Field: "date", Message: "Invalid date.", Hint: "Use YYYY-MM-DD."})
+	}
+	var cents int64
+	if balance == "" {
+		details = append(details, requiredFlagError("balance", "Provide --balance <decimal>, e.g. 1234.56."))
+	} else {
+		v, err := parseCentsString(balance)
+		if err != nil {
+			details = append(details, ErrorDetail{Field: "balance", Message: "Invalid balance.", Hint: "Use a decimal string like 1234.56 (negative for debt)."})
+		}
+		cents = v
+	}
+	source = strings.ToLower(strings.TrimSpace(source))
+	if source != database.BalanceSourceManual && source != database.BalanceSourceStatement {
+		details = append(details, ErrorDetail{Field: "source", Message: "Invalid source.", Hint: "Use manual or statement."})
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	accountID, cErr := resolveAccountFilter(svc, account)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	cp, err := svc.SetBalanceCheckpoint(database.BalanceCheckpoint{
+		AccountID: *accountID,
+		Date:      date,
+		Balance:   cents,
+		Currency:  currency,
+		Source:    source,
+		Note:      note,
+	})
+	if err != nil {
+		return commandResult{Err: balanceWriteError(err)}
+	}
+	return commandResult{Response: balanceResponse{Ok: true, Checkpoint: newBalanceCheckpoint(cp)}}
+}
+
+func balanceWriteError(err error) *cliError {
+	switch {
+	case errors.Is(err, database.ErrUnknownAccount):
+		return runtimeError(unknownAccountError())
+	case errors.Is(err, database.ErrInvalidCurrency):
+		return validationError(ErrorDetail{Field: "currency", Message: "Invalid currency.", Hint: "Use a three-letter code like CAD."})
+	case errors.Is(err, database.ErrInvalidBalanceDate):
+		return validationError(ErrorDetail{Field: "date", Message: "Invalid date.", Hint: "Use YYYY-MM-DD."})
+	}
+	return runtimeError(ErrorDetail{Message: err.Error()})
+}
+
+func handleBalancesDelete(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("balances delete")
+	var id int64
+	fs.Int64Var(&id, "id", 0, "")
+	if ok, res := fs.parse(args, "balances"); !ok {
+		return res
+	}
+
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <checkpoint id>."))}
+	}
+	if err := svc.DeleteBalanceCheckpoint(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Balance checkpoint not found.", Hint: "Use \"cashmop balances list\" to see checkpoint IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: balanceDeleteResponse{Ok: true, ID: id}}
+}
+
+func handleBalancesReconcile(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("balances reconcile")
+	var account string
+	fs.StringVar(&account, "account", "", "")
+	if ok, res := fs.parse(args, "balances"); !ok {
+		return res
+	}
+
+	accountID, cErr := resolveAccountFilter(svc, account)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	results, err := svc.ReconcileAccounts(accountID)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	resp := balancesReconcileResponse{Ok: true, Reconciled: true, Accounts: make([]accountReconciliation, 0, len(results))}
+	for _, r := range results {
+		entries := make([]reconciliationEntry, 0, len(r.Entries))
+		for _, e := range r.Entries {
+			entry := reconciliationEntry{balanceCheckpoint: newBalanceCheckpoint(e.BalanceCheckpoint), TransactionCount: e.TransactionCount}
+			if e.Expected != nil {
+				expected := formatCentsDecimal(*e.Expected)
+				gap := formatCentsDecimal(*e.Gap)
+				entry.Expected = &expected
+				entry.Gap = &gap
+			}
+			entries = append(entries, entry)
+		}
+		resp.Reconciled = resp.Reconciled && r.Reconciled
+		resp.Accounts = append(resp.Accounts, accountReconciliation{
+			AccountID:          r.AccountID,
+			Account:            r.AccountName,
+			Currency:           r.Currency,
+			Reconciled:         r.Reconciled,
+			OpeningBalance:     formatCentsDecimal(r.OpeningBalance),
+			CurrentBalance:     formatCentsDecimal(r.CurrentBalance),
+			OtherCurrencyCount: r.OtherCurrencyCount,
+			Checkpoints:        entries,
+		})
+	}
+	return commandResult{Response: resp}
+}
@@ -0,0 +1,407 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+type budget struct {
+	ID         int64  `json:"id"`
+	CategoryID int64  `json:"category_id"`
+	Category   string `json:"category"`
+	Period     string `json:"period"`
+	Amount     string `json:"amount"`
+	Rollover   bool   `json:"rollover"`
+	StartMonth string `json:"start_month"`
+}
+
+func newBudget(b database.Budget) budget {
+	return budget{
+		ID:         b.ID,
+		CategoryID: b.CategoryID,
+		Category:   b.CategoryName,
+		Period:     b.Period,
+		Amount:     formatCentsDecimal(b.Amount),
+		Rollover:   b.Rollover,
+		StartMonth: b.StartMonth,
+	}
+}
+
+type budgetsListResponse struct {
+	Ok      bool     `json:"ok"`
+	Count   int      `json:"count"`
+	Budgets []budget `json:"budgets"`
+}
+
+type budgetResponse struct {
+	Ok     bool   `json:"ok"`
+	Budget budget `json:"budget"`
+}
+
+type budgetDeleteResponse struct {
+	Ok bool  `json:"ok"`
+	ID int64 `json:"id"`
+}
+
+func (r budgetsListResponse) TableHeaders() []string {
+	return []string{"ID", "Category", "Period", "Amount", "Rollover", "Start"}
+}
+
+func (r budgetsListResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Budgets))
+	for i, b := range r.Budgets {
+		rollover := ""
+		if b.Rollover {
+			rollover = "yes"
+		}
+		rows[i] = []string{fmt.Sprint(b.ID), b.Category, b.Period, b.Amount, rollover, b.StartMonth}
+	}
+	return rows
+}
+
+type budgetsReportResponse struct {
+	Ok           bool                `json:"ok"`
+	Start        string              `json:"start"`
+	End          string              `json:"end"`
+	MainCurrency string              `json:"main_currency"`
+	Rows         []budgetReportRow   `json:"rows"`
+	Totals       []budgetReportTotal `json:"totals"`
+}
+
+type budgetReportRow struct {
+	Month       string `json:"month"`
+	CategoryID  int64  `json:"category_id"`
+	Category    string `json:"category"`
+	ParentID    *int64 `json:"parent_id"`
+	Depth       int    `json:"depth"`
+	BudgetID    *int64 `json:"budget_id"`
+	PeriodStart string `json:"period_start"`
+	Budget      string `json:"budget"`
+	Carry       string `json:"carry"`
+	Available   string `json:"available"`
+	Actual      string `json:"actual"`
+	Remaining   string `json:"remaining"`
+	Overspent   bool   `json:"overspent"`
+	Unconverted int    `json:"unconverted"`
+}
+
+type budgetReportTotal struct {
+	Month     string `json:"month"`
+	Budget    string `json:"budget"`
+	Carry     string `json:"carry"`
+	Available string `json:"available"`
+	Actual    string `json:"actual"`
+	Remaining string `json:"remaining"`
+	Overspent bool   `json:"overspent"`
+}
+
+func (r budgetsReportResponse) TableHeaders() []string {
+	return []string{"Month", "Category", "Budget", "Carry", "Actual", "Remaining", "Status"}
+}
+
+func (r budgetsReportResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		var status []string
+		if row.BudgetID == nil {
+			status = append(status, "rollup")
+		}
+		if row.Overspent {
+			status = append(status, "over")
+		}
+		rows[i] = []string{
+			row.Month,
+			strings.Repeat("  ", row.Depth) + row.Category,
+			row.Budget,
+			row.Carry,
+			row.Actual,
+			row.Remaining,
+			strings.Join(status, ", "),
+		}
+	}
+	return rows
+}
+
+func handleBudgets(svc *cashmop.Service, args []string) commandResult {
+	if len(args) == 0 {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Missing budgets subcommand (list, create, update, delete, report).",
+			Hint:    "Use \"cashmop budgets list\" or \"cashmop budgets report\".",
+		})}
+	}
+
+	switch args[0] {
+	case "list":
+		return handleBudgetsList(svc, args[1:])
+	case "create":
+		return handleBudgetsCreate(svc, args[1:])
+	case "update":
+		return handleBudgetsUpdate(svc, args[1:])
+	case "delete":
+		return handleBudgetsDelete(svc, args[1:])
+	case "report":
+		return handleBudgetsReport(svc, args[1:])
+	default:
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Unknown budgets subcommand.",
+			Hint:    "Use list, create, update, delete, or report.",
+		})}
+	}
+}
+
+func handleBudgetsList(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("budgets list")
+	if ok, res := fs.parse(args, "budgets"); !ok {
+		return res
+	}
+
+	budgets, err := svc.GetBudgets()
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	out := make([]budget, 0, len(budgets))
+	for _, b := range budgets {
+		out = append(out, newBudget(b))
+	}
+	return commandResult{Response: budgetsListResponse{Ok: true, Count: len(out), Budgets: out}}
+}
+
+func handleBudgetsCreate(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("budgets create")
+	var categoryID int64
+	var amount string
+	var period string
+	var rollover bool
+	var startMonth string
+
+	fs.Int64Var(&categoryID, "category-id", 0, "")
+	fs.StringVar(&amount, "amount", "", "")
+	fs.StringVar(&period, "period", database.BudgetPeriodMonthly, "")
+	fs.BoolVar(&rollover, "rollover", false, "")
+	fs.StringVar(&startMonth, "start-month", "", "")
+	if ok, res := fs.parse(args, "budgets"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if categoryID == 0 {
+		details = append(details, requiredFlagError("category-id", "Provide --category-id <id>."))
+	}
+	var cents int64
+	if amount == "" {
+		details = append(details, requiredFlagError("amount", "Provide --amount <decimal>, e.g. 500.00."))
+	} else {
+		v, err := parseCentsString(amount)
+		if err != nil {
+			details = append(details, ErrorDetail{Field: "amount", Message: "Invalid amount.", Hint: "Use a decimal string like 500.00."})
+		}
+		cents = v
+	}
+	if startMonth != "" {
+		if _, err := parseMonth(startMonth); err != nil {
+			details = append(details, ErrorDetail{Field: "start-month", Message: "Invalid month.", Hint: "Use YYYY-MM."})
+		}
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	b, err := svc.CreateBudget(database.Budget{
+		CategoryID: categoryID,
+		Period:     strings.ToLower(strings.TrimSpace(period)),
+		Amount:     cents,
+		Rollover:   rollover,
+		StartMonth: startMonth,
+	})
+	if err != nil {
+		return commandResult{Err: budgetWriteError(err, "category")}
+	}
+	return commandResult{Response: budgetResponse{Ok: true, Budget: newBudget(b)}}
+}
+
+func handleBudgetsUpdate(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("budgets update")
+	var id int64
+	var amount optionalStringFlag
+	var period optionalStringFlag
+	var startMonth optionalStringFlag
+	var rollover bool
+	var noRollover bool
+
+	fs.Int64Var(&id, "id", 0, "")
+	fs.Var(&amount, "amount", "")
+	fs.Var(&period, "period", "")
+	fs.Var(&startMonth, "start-month", "")
+	fs.BoolVar(&rollover, "rollover", false, "")
+	fs.BoolVar(&noRollover, "no-rollover", false, "")
+	if ok, res := fs.parse(args, "budgets"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if id == 0 {
+		details = append(details, requiredFlagError("id", "Provide --id <budget id>."))
+	}
+	if !amount.set && !period.set && !startMonth.set && !rollover && !noRollover {
+		details = append(details, ErrorDetail{
+			Field:   "id",
+			Message: "Nothing to change.",
+			Hint:    "Provide at least one of --amount, --period, --start-month, --rollover, or --no-rollover.",
+		})
+	}
+	if rollover && noRollover {
+		details = append(details, ErrorDetail{Field: "rollover", Message: "--rollover and --no-rollover cannot be combined.", Hint: "Use only one of them."})
+	}
+
+	var update database.BudgetUpdate
+	if amount.set {
+		v, err := parseCentsString(amount.value)
+		if err != nil {
+			details = append(details, ErrorDetail{Field: "amount", Message: "Invalid amount.", Hint: "Use a decimal string like 500.00."})
+		}
+		update.Amount = &v
+	}
+	if period.set {
+		p := strings.ToLower(strings.TrimSpace(period.value))
+		update.Period = &p
+	}
+	if startMonth.set {
+		if _, err := parseMonth(startMonth.value); err != nil {
+			details = append(details, ErrorDetail{Field: "start-month", Message: "Invalid month.", Hint: "Use YYYY-MM."})
+		}
+		update.StartMonth = &startMonth.value
+	}
+	if rollover || noRollover {
+		update.Rollover = &rollover
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	b, err := svc.UpdateBudget(id, update)
+	if err != nil {
+		return commandResult{Err: budgetWriteError(err, "budget")}
+	}
+	return commandResult{Response: budgetResponse{Ok: true, Budget: newBudget(b)}}
+}
+
+func handleBudgetsDelete(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("budgets delete")
+	var id int64
+	fs.Int64Var(&id, "id", 0, "")
+	if ok, res := fs.parse(args, "budgets"); !ok {
+		return res
+	}
+
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <budget id>."))}
+	}
+	if err := svc.DeleteBudget(id); err != nil {
+		return commandResult{Err: budgetWriteError(err, "budget")}
+	}
+	return commandResult{Response: budgetDeleteResponse{Ok: true, ID: id}}
+}
+
+// budgetWriteError maps store errors from creating, updating or deleting a
+// budget to CLI errors. notFound names what a sql.ErrNoRows refers to.
+func budgetWriteError(err error, notFound string) *cliError {
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && notFound == "category":
+		return runtimeError(ErrorDetail{Message: "Category not found.", Hint: "Use \"cashmop categories list\" to see category IDs."})
+	case errors.Is(err, sql.ErrNoRows):
+		return runtimeError(ErrorDetail{Message: "Budget not found.", Hint: "Use \"cashmop budgets list\" to see budget IDs."})
+	case errors.Is(err, database.ErrInvalidBudgetPeriod):
+		return validationError(ErrorDetail{Field: "period", Message: "Invalid period.", Hint: "Use monthly, quarterly, or yearly."})
+	case errors.Is(err, database.ErrInvalidBudgetAmount):
+		return validationError(ErrorDetail{Field: "amount", Message: "Budget amount must be greater than zero.", Hint: "Use a positive decimal string like 500.00."})
+	case errors.Is(err, database.ErrInvalidBudgetMonth):
+		return validationError(ErrorDetail{Field: "start-month", Message: "Invalid month.", Hint: "Use YYYY-MM."})
+	case errors.Is(err, database.ErrBudgetCategoryKind):
+		return validationError(ErrorDetail{Field: "category-id", Message: "Budgets can only be set on expense categories.", Hint: "Use \"cashmop categories set-kind\" or pick another category."})
+	case errors.Is(err, database.ErrBudgetExists):
+		return validationError(ErrorDetail{Field: "category-id", Message: "The category already has a budget.", Hint: "Use \"cashmop budgets update\" to change it."})
+	}
+	return runtimeError(ErrorDetail{Message: err.Error()})
+}
+
+func handleBudgetsReport(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("budgets report")
+	var month string
+	var start string
+	var end string
+
+	fs.StringVar(&month, "month", "", "")
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	if ok, res := fs.parse(args, "budgets"); !ok {
+		return res
+	}
+
+	if month != "" {
+		if start != "" || end != "" {
+			return commandResult{Err: validationError(ErrorDetail{Field: "month", Message: "--month cannot be combined with --start or --end.", Hint: "Use either --month or --start/--end."})}
+		}
+		if _, err := parseMonth(month); err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "month", Message: "Invalid month.", Hint: "Use YYYY-MM."})}
+		}
+		start, end = month, month
+	}
+	current := time.Now().Format(monthLayout)
+	start, end, cErr := validateMonthRange(start, end, current, current)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	report, err := svc.GetBudgetReport(start, end)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	rows := make([]budgetReportRow, 0, len(report.Rows))
+	for _, r := range report.Rows {
+		rows = append(rows, budgetReportRow{
+			Month:       r.Month,
+			CategoryID:  r.CategoryID,
+			Category:    r.FullName,
+			ParentID:    r.ParentID,
+			Depth:       r.Depth,
+			BudgetID:    r.BudgetID,
+			PeriodStart: r.PeriodStart,
+			Budget:      formatCentsDecimal(r.Budget),
+			Carry:       formatCentsDecimal(r.Carry),
+			Available:   formatCentsDecimal(r.Available),
+			Actual:      formatCentsDecimal(r.Actual),
+			Remaining:   formatCentsDecimal(r.Remaining),
+			Overspent:   r.Overspent,
+			Unconverted: r.Unconverted,
+		})
+	}
+	totals := make([]budgetReportTotal, 0, len(report.Totals))
+	for _, t := range report.Totals {
+		totals = append(totals, budgetReportTotal{
+			Month:     t.Month,
+			Budget:    formatCentsDecimal(t.Budget),
+			Carry:     formatCentsDecimal(t.Carry),
+			Available: formatCentsDecimal(t.Available),
+			Actual:    formatCentsDecimal(t.Actual),
+			Remaining: formatCentsDecimal(t.Remaining),
+			Overspent: t.Overspent,
+		})
+	}
+	return commandResult{Response: budgetsReportResponse{
+		Ok:           true,
+		Start:        report.StartMonth,
+		End:          report.EndMonth,
+		MainCurrency: report.MainCurrency,
+		Rows:         rows,
+		Totals:       totals,
+	}}
+}
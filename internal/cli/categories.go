@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/default-anton/cashmop/internal/cashmop"
 	"github.com/default-anton/cashmop/internal/database"
@@ -13,29 +16,48 @@ type categoryListResponse struct {
 }
 
 func (r categoryListResponse) TableHeaders() []string {
-	return []string{"ID", "Name"}
+	return []string{"ID", "Name", "Path", "Kind"}
 }
 
 func (r categoryListResponse) ToTable() [][]string {
 	rows := make([][]string, len(r.Items))
 	for i, item := range r.Items {
-		rows[i] = []string{fmt.Sprint(item.ID), item.Name}
+		rows[i] = []string{fmt.Sprint(item.ID), item.Name, item.FullName, item.Kind}
 	}
 	return rows
 }
 
 type categoryResponse struct {
-	Ok   bool   `json:"ok"`
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	Ok       bool   `json:"ok"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+}
+
+type categoryMergeResponse struct {
+	Ok bool `json:"ok"`
+	database.CategoryMergeResult
+}
+
+type categoryDeleteResponse struct {
+	Ok bool `json:"ok"`
+	database.CategoryDeleteStats
+}
+
+type categoryMoveResponse struct {
+	Ok       bool   `json:"ok"`
+	ID       int64  `json:"id"`
+	ParentID *int64 `json:"parent_id"`
+	FullName string `json:"full_name"`
 }
 
 func handleCategories(svc *cashmop.Service, args []string) commandResult {
 	if len(args) == 0 {
 		return commandResult{Err: validationError(ErrorDetail{
 			Field:   "subcommand",
-			Message: "Missing categories subcommand (list, rename, create).",
-			Hint:    "Use \"cashmop categories list\", \"cashmop categories rename\", or \"cashmop categories create\".",
+			Message: "Missing categories subcommand (list, rename, create, move, set-kind, merge, delete).",
+			Hint:    "Use \"cashmop categories list\", \"cashmop categories rename\", \"cashmop categories create\", \"cashmop categories move\", \"cashmop categories set-kind\", \"cashmop categories merge\", or \"cashmop categories delete\".",
 		})}
 	}
 
@@ -46,11 +68,19 @@ func handleCategories(svc *cashmop.Service, args []string) commandResult {
 		return handleCategoriesRename(svc, args[1:])
 	case "create":
 		return handleCategoriesCreate(svc, args[1:])
+	case "move":
+		return handleCategoriesMove(svc, args[1:])
+	case "set-kind":
+		return handleCategoriesSetKind(svc, args[1:])
+	case "merge":
+		return handleCategoriesMerge(svc, args[1:])
+	case "delete":
+		return handleCategoriesDelete(svc, args[1:])
 	default:
 		return commandResult{Err: validationError(ErrorDetail{
 			Field:   "subcommand",
 			Message: "Unknown categories subcommand.",
-			Hint:    "Use \"cashmop categories list\", \"cashmop categories rename\", or \"cashmop categories create\".",
+			Hint:    "Use \"cashmop categories list\", \"cashmop categories rename\", \"cashmop categories create\", \"cashmop categories move\", \"cashmop categories set-kind\", \"cashmop categories merge\", or \"cashmop categories delete\".",
 		})}
 	}
 }
@@ -100,7 +130,11 @@ func handleCategoriesRename(svc *cashmop.Service, args []string) commandResult {
 func handleCategoriesCreate(svc *cashmop.Service, args []string) commandResult {
 	fs := newSubcommandFlagSet("categories create")
 	var name string
+	var parent int64
+	var kind string
 	fs.StringVar(&name, "name", "", "")
+	fs.Int64Var(&parent, "parent", 0, "")
+	fs.StringVar(&kind, "kind", "", "")
 	if ok, res := fs.parse(args, "categories"); !ok {
 		return res
 	}
@@ -108,11 +142,265 @@ func handleCategoriesCreate(svc *cashmop.Service, args []string) commandResult {
 	if name == "" {
 		return commandResult{Err: validationError(requiredFlagError("name", "Provide --name <name>."))}
 	}
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind != "" && !database.IsValidCategoryKind(kind) {
+		return commandResult{Err: validationError(categoryKindError())}
+	}
+
+	var parentID *int64
+	if parent != 0 {
+		parentID = &parent
+		_, found, err := lookupCategory(svc, parent)
+		if err != nil {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+		if !found {
+			return commandResult{Err: runtimeError(ErrorDetail{Field: "parent", Message: "Parent category not found.", Hint: "Use \"cashmop categories list\" to see category IDs."})}
+		}
+	}
+
+	// Creating is get-or-create; an existing category is only reused when it
+	// already sits under the requested parent.
+	existing, found, err := lookupCategoryByName(svc, name)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	if found && !sameCategoryParent(existing.ParentID, parentID) {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "name",
+			Message: fmt.Sprintf("Category %q already exists as %q.", name, existing.FullName),
+			Hint:    "Use \"cashmop categories move\" to change its parent.",
+		})}
+	}
 
 	id, err := svc.CreateCategory(name)
 	if err != nil {
 		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
 	}
+	if parentID != nil && !found {
+		if cErr := moveCategory(svc, id, parentID); cErr != nil {
+			return commandResult{Err: cErr}
+		}
+	}
+	if kind != "" {
+		if err := svc.SetCategoryKind(id, kind); err != nil {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+	}
 
-	return commandResult{Response: categoryResponse{Ok: true, ID: id, Name: name}}
+	return commandResult{Response: categoryResponse{Ok: true, ID: id, Name: name, ParentID: parentID, Kind: kind}}
+}
+
+func handleCategoriesSetKind(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("categories set-kind")
+	var id int64
+	var kind string
+	fs.Int64Var(&id, "id", 0, "")
+	fs.StringVar(&kind, "kind", "", "")
+	if ok, res := fs.parse(args, "categories"); !ok {
+		return res
+	}
+
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if id == 0 || kind == "" {
+		var details []ErrorDetail
+		if id == 0 {
+			details = append(details, requiredFlagError("id", "Provide --id <category id>."))
+		}
+		if kind == "" {
+			details = append(details, requiredFlagError("kind", "Provide --kind income|expense|transfer|excluded."))
+		}
+		return commandResult{Err: validationError(details...)}
+	}
+	if !database.IsValidCategoryKind(kind) {
+		return commandResult{Err: validationError(categoryKindError())}
+	}
+
+	if err := svc.SetCategoryKind(id, kind); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Category not found.", Hint: "Use \"cashmop categories list\" to see category IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	category, _, err := lookupCategory(svc, id)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: categoryResponse{Ok: true, ID: id, Name: category.Name, Kind: kind}}
+}
+
+func handleCategoriesMerge(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("categories merge")
+	var sourceIDs stringSliceFlag
+	var targetID int64
+	fs.Var(&sourceIDs, "source-ids", "")
+	fs.Int64Var(&targetID, "target-id", 0, "")
+	if ok, res := fs.parse(args, "categories"); !ok {
+		return res
+	}
+
+	var ids []int64
+	for _, s := range sourceIDs.values {
+		for _, p := range strings.Split(s, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			var id int64
+			if _, err := fmt.Sscanf(p, "%d", &id); err != nil {
+				return commandResult{Err: validationError(ErrorDetail{Field: "source-ids", Message: fmt.Sprintf("Invalid category ID: %s", p), Hint: "Provide comma-separated numeric IDs."})}
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 || targetID == 0 {
+		var details []ErrorDetail
+		if len(ids) == 0 {
+			details = append(details, requiredFlagError("source-ids", "Provide --source-ids <id,id> for the categories to merge away."))
+		}
+		if targetID == 0 {
+			details = append(details, requiredFlagError("target-id", "Provide --target-id <id> for the category to keep."))
+		}
+		return commandResult{Err: validationError(details...)}
+	}
+
+	res, err := svc.MergeCategories(ids, targetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrInvalidCategoryMerge):
+			return commandResult{Err: validationError(ErrorDetail{Field: "source-ids", Message: "The target category cannot also be a source.", Hint: "Remove --target-id from --source-ids."})}
+		case errors.Is(err, sql.ErrNoRows):
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Category not found.", Hint: "Use \"cashmop categories list\" to see category IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	return commandResult{Response: categoryMergeResponse{Ok: true, CategoryMergeResult: res}}
+}
+
+func handleCategoriesDelete(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("categories delete")
+	var id int64
+	var reassign int64
+	fs.Int64Var(&id, "id", 0, "")
+	fs.Int64Var(&reassign, "reassign-to", 0, "")
+	if ok, res := fs.parse(args, "categories"); !ok {
+		return res
+	}
+
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <category id>."))}
+	}
+	var reassignTo *int64
+	if reassign != 0 {
+		if reassign == id {
+			return commandResult{Err: validationError(ErrorDetail{Field: "reassign-to", Message: "A category cannot be reassigned to itself.", Hint: "Provide --reassign-to <another category id>."})}
+		}
+		reassignTo = &reassign
+	}
+
+	res, err := svc.DeleteCategory(id, reassignTo)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Category not found.", Hint: "Use \"cashmop categories list\" to see category IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	return commandResult{Response: categoryDeleteResponse{Ok: true, CategoryDeleteStats: res}}
+}
+
+func categoryKindError() ErrorDetail {
+	return ErrorDetail{
+		Field:   "kind",
+		Message: "Invalid category kind.",
+		Hint:    "Use one of: " + strings.Join(database.CategoryKinds(), ", ") + ".",
+	}
+}
+
+func handleCategoriesMove(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("categories move")
+	var id int64
+	var parent int64
+	var root bool
+	fs.Int64Var(&id, "id", 0, "")
+	fs.Int64Var(&parent, "parent", 0, "")
+	fs.BoolVar(&root, "root", false, "")
+	if ok, res := fs.parse(args, "categories"); !ok {
+		return res
+	}
+
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <category id>."))}
+	}
+	if (parent == 0) == !root {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "parent",
+			Message: "Provide exactly one of --parent or --root.",
+			Hint:    "Use --parent <category id> to nest the category, or --root to make it top-level.",
+		})}
+	}
+
+	var parentID *int64
+	if !root {
+		parentID = &parent
+	}
+	if cErr := moveCategory(svc, id, parentID); cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	category, _, err := lookupCategory(svc, id)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: categoryMoveResponse{Ok: true, ID: id, ParentID: parentID, FullName: category.FullName}}
+}
+
+func lookupCategory(svc *cashmop.Service, id int64) (database.Category, bool, error) {
+	categories, err := svc.GetCategories()
+	if err != nil {
+		return database.Category{}, false, err
+	}
+	for _, c := range categories {
+		if c.ID == id {
+			return c, true, nil
+		}
+	}
+	return database.Category{}, false, nil
+}
+
+func lookupCategoryByName(svc *cashmop.Service, name string) (database.Category, bool, error) {
+	categories, err := svc.GetCategories()
+	if err != nil {
+		return database.Category{}, false, err
+	}
+	for _, c := range categories {
+		if c.Name == name {
+			return c, true, nil
+		}
+	}
+	return database.Category{}, false, nil
+}
+
+func sameCategoryParent(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func moveCategory(svc *cashmop.Service, id int64, parentID *int64) *cliError {
+	err := svc.MoveCategory(id, parentID)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, database.ErrCategoryCycle):
+		return validationError(ErrorDetail{Field: "parent", Message: err.Error(), Hint: "Choose a parent outside this category's subtree."})
+	case errors.Is(err, sql.ErrNoRows):
+		return runtimeError(ErrorDetail{Message: "Category not found.", Hint: "Use \"cashmop categories list\" to see category IDs."})
+	default:
+		return runtimeError(ErrorDetail{Message: err.Error()})
+	}
 }
@@ -91,6 +91,20 @@ func Run(args []string) int {
 		result = handleSettings(svc, rest[1:])
 	case "fx":
 		result = handleFx(svc, rest[1:])
+	case "report":
+		result = handleReport(svc, rest[1:])
+	case "forecast":
+		result = handleForecast(svc, rest[1:])
+	case "recurring":
+		result = handleRecurring(svc, rest[1:])
+	case "budgets":
+		result = handleBudgets(svc, rest[1:])
+	case "balances":
+		result = handleBalances(svc, rest[1:])
+	case "transfers":
+		result = handleTransfers(svc, rest[1:])
+	case "merchants":
+		result = handleMerchants(svc, rest[1:])
 	case "install-cli":
 		result = handleInstallCli(rest[1:])
 	case "uninstall-cli":
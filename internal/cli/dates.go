@@ -63,3 +63,103 @@ func validateDateRange(start, end string) (string, string, *cliError) {
 
 	return startDate.Format(dateLayout), endDate.Format(dateLayout), nil
 }
+
+// lastFullMonthsRange returns the n full calendar months before now.
+func lastFullMonthsRange(now time.Time, n int) (string, string) {
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return startOfMonth.AddDate(0, -n, 0).Format(dateLayout), startOfMonth.AddDate(0, 0, -1).Format(dateLayout)
+}
+
+// validateReportRange checks --start/--end for reports, which may span years.
+// Omitting both defaults to the last 12 full calendar months.
+func validateReportRange(start, end string) (string, string, *cliError) {
+	if start == "" && end == "" {
+		start, end = lastFullMonthsRange(time.Now(), 12)
+		return start, end, nil
+	}
+	if start == "" || end == "" {
+		return "", "", validationError(ErrorDetail{
+			Field:   "start",
+			Message: "--start requires --end.",
+			Hint:    "Provide both --start and --end, or omit both to default to the last 12 calendar months.",
+		})
+	}
+
+	startDate, err := parseDate(start)
+	if err != nil {
+		return "", "", validationError(ErrorDetail{Field: "start", Message: "Invalid start date.", Hint: "Use YYYY-MM-DD."})
+	}
+	endDate, err := parseDate(end)
+	if err != nil {
+		return "", "", validationError(ErrorDetail{Field: "end", Message: "Invalid end date.", Hint: "Use YYYY-MM-DD."})
+	}
+	if endDate.Before(startDate) {
+		return "", "", validationError(ErrorDetail{Field: "end", Message: "--end must be on or after --start.", Hint: "Ensure --end is on or after --start."})
+	}
+	return start, end, nil
+}
+
+// validateMonthRange checks --start/--end given as YYYY-MM. Omitting both
+// uses the defaults.
+func validateMonthRange(start, end, defaultStart, defaultEnd string) (string, string, *cliError) {
+	if start == "" && end == "" {
+		return defaultStart, defaultEnd, nil
+	}
+	if start == "" || end == "" {
+		return "", "", validationError(ErrorDetail{
+			Field:   "start",
+			Message: "--start requires --end.",
+			Hint:    "Provide both --start YYYY-MM and --end YYYY-MM, or omit both.",
+		})
+	}
+
+	startMonth, err := parseMonth(start)
+	if err != nil {
+		return "", "", validationError(ErrorDetail{Field: "start", Message: "Invalid start month.", Hint: "Use YYYY-MM."})
+	}
+	endMonth, err := parseMonth(end)
+	if err != nil {
+		return "", "", validationError(ErrorDetail{Field: "end", Message: "Invalid end month.", Hint: "Use YYYY-MM."})
+	}
+	if endMonth.Before(startMonth) {
+		return "", "", validationError(ErrorDetail{Field: "end", Message: "--end must be on or after --start.", Hint: "Ensure --end is on or after --start."})
+	}
+	return start, end, nil
+}
+
+// addMonthsClamped moves date by n months, keeping the day within the
+// target month (Mar 31 minus one month is Feb 28 or 29).
+func addMonthsClamped(date time.Time, n int) time.Time {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location()).AddDate(0, n, 0)
+	last := first.AddDate(0, 1, -1)
+	if date.Day() > last.Day() {
+		return last
+	}
+	return first.AddDate(0, 0, date.Day()-1)
+}
+
+func isMonthEnd(date time.Time) bool {
+	return date.AddDate(0, 0, 1).Day() == 1
+}
+
+// shiftRangeMonths moves start..end by n months; an end on the last day of a
+// month stays on the last day.
+func shiftRangeMonths(start, end time.Time, n int) (time.Time, time.Time) {
+	shiftedEnd := addMonthsClamped(end, n)
+	if isMonthEnd(end) {
+		shiftedEnd = time.Date(shiftedEnd.Year(), shiftedEnd.Month(), 1, 0, 0, 0, 0, end.Location()).AddDate(0, 1, -1)
+	}
+	return addMonthsClamped(start, n), shiftedEnd
+}
+
+// previousRange returns the range right before start..end: as many calendar
+// months back when start is the first of a month (so month-to-date compares
+// with the same days of the previous month), otherwise as many days back.
+func previousRange(start, end time.Time) (time.Time, time.Time) {
+	if start.Day() == 1 {
+		months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+		return shiftRangeMonths(start, end, -months)
+	}
+	days := int(end.Sub(start).Hours()/24) + 1
+	return start.AddDate(0, 0, -days), end.AddDate(0, 0, -days)
+}
@@ -19,13 +19,25 @@ func handleExport(svc *cashmop.Service, args []string) commandResult {
 	var end string
 	var format string
 	var out string
+	var previousStart string
+	var previousEnd string
 	var categoryIDs stringSliceFlag
+	var tags stringSliceFlag
+	var untagged bool
+	var merchantIDs stringSliceFlag
+	var noMerchant bool
 
 	fs.StringVar(&start, "start", "", "")
 	fs.StringVar(&end, "end", "", "")
 	fs.StringVar(&format, "format", "", "")
 	fs.StringVar(&out, "out", "", "")
+	fs.StringVar(&previousStart, "previous-start", "", "")
+	fs.StringVar(&previousEnd, "previous-end", "", "")
 	fs.Var(&categoryIDs, "category-ids", "")
+	fs.Var(&tags, "tags", "")
+	fs.BoolVar(&untagged, "untagged", false, "")
+	fs.Var(&merchantIDs, "merchant-ids", "")
+	fs.BoolVar(&noMerchant, "no-merchant", false, "")
 
 	if ok, res := fs.parse(args, "export"); !ok {
 		return res
@@ -58,6 +70,15 @@ func handleExport(svc *cashmop.Service, args []string) commandResult {
 		return commandResult{Err: validationError(ErrorDetail{Field: "format", Message: "Format must be csv or xlsx.", Hint: "Use --format csv or --format xlsx."})}
 	}
 
+	if previousStart != "" || previousEnd != "" {
+		if format != "xlsx" {
+			return commandResult{Err: validationError(ErrorDetail{Field: "previous-start", Message: "Comparison sheets need --format xlsx.", Hint: "Use --format xlsx with --previous-start/--previous-end."})}
+		}
+		if previousStart, previousEnd, cErr = validateReportRange(previousStart, previousEnd); cErr != nil {
+			return commandResult{Err: cErr}
+		}
+	}
+
 	var catIDs []int64
 	for _, s := range categoryIDs.values {
 		parts := strings.Split(s, ",")
@@ -74,12 +95,25 @@ func handleExport(svc *cashmop.Service, args []string) commandResult {
 		}
 	}
 
+	tagIDs, cErr := resolveTagFilter(svc, tags, untagged)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	mIDs, cErr := parseMerchantIDs(merchantIDs, noMerchant)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
 	count, err := svc.ExportTransactions(cashmop.ExportParams{
-		StartDate:       start,
-		EndDate:         end,
-		CategoryIDs:     catIDs,
-		Format:          format,
-		DestinationPath: out,
+		StartDate:         start,
+		EndDate:           end,
+		CategoryIDs:       catIDs,
+		TagIDs:            tagIDs,
+		MerchantIDs:       mIDs,
+		Format:            format,
+		DestinationPath:   out,
+		PreviousStartDate: previousStart,
+		PreviousEndDate:   previousEnd,
 	})
 	if err != nil {
 		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
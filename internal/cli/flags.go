@@ -107,6 +107,20 @@ func (s *stringSliceFlag) Set(value string) error {
 	return nil
 }
 
+// split returns the comma-separated items of every value, trimmed, without
+// empty ones.
+func (s *stringSliceFlag) split() []string {
+	var items []string
+	for _, v := range s.values {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				items = append(items, p)
+			}
+		}
+	}
+	return items
+}
+
 type optionalStringFlag struct {
 	value string
 	set   bool
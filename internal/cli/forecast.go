@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/default-anton/cashmop/internal/cashmop"
+)
+
+type forecastResponse struct {
+	Ok             bool                `json:"ok"`
+	MainCurrency   string              `json:"main_currency"`
+	HistoryStart   string              `json:"history_start"`
+	HistoryEnd     string              `json:"history_end"`
+	OpeningBalance string              `json:"opening_balance"`
+	Months         []forecastMonth     `json:"months"`
+	Categories     []forecastSeries    `json:"categories"`
+	Accounts       []forecastSeries    `json:"accounts"`
+	Balances       []forecastSeries    `json:"balances"`
+	Recurring      []forecastRecurring `json:"recurring"`
+}
+
+type forecastMonth struct {
+	Month       string `json:"month"`
+	Income      string `json:"income"`
+	Expenses    string `json:"expenses"`
+	Net         string `json:"net"`
+	Low         string `json:"low"`
+	High        string `json:"high"`
+	Balance     string `json:"balance"`
+	BalanceLow  string `json:"balance_low"`
+	BalanceHigh string `json:"balance_high"`
+}
+
+type forecastSeries struct {
+	ID     *int64          `json:"id"`
+	Name   string          `json:"name"`
+	Points []forecastPoint `json:"points"`
+}
+
+type forecastPoint struct {
+	Month  string `json:"month"`
+	Amount string `json:"amount"`
+	Low    string `json:"low"`
+	High   string `json:"high"`
+}
+
+type forecastRecurring struct {
+	Description   string `json:"description"`
+	Account       string `json:"account"`
+	Category      string `json:"category"`
+	Cadence       string `json:"cadence"`
+	Amount        string `json:"amount"`
+	MonthlyAmount string `json:"monthly_amount"`
+	NextDate      string `json:"next_date"`
+}
+
+func (r forecastResponse) TableHeaders() []string {
+	return []string{"Month", "Income", "Expenses", "Net", "Low", "High", "Balance"}
+}
+
+func (r forecastResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Months))
+	for i, m := range r.Months {
+		rows[i] = []string{m.Month, m.Income, m.Expenses, m.Net, m.Low, m.High, m.Balance}
+	}
+	return rows
+}
+
+func newForecastSeries(series []cashmop.ForecastSeries) []forecastSeries {
+	out := make([]forecastSeries, 0, len(series))
+	for _, s := range series {
+		points := make([]forecastPoint, 0, len(s.Points))
+		for _, p := range s.Points {
+			points = append(points, forecastPoint{
+				Month:  p.Month,
+				Amount: formatCentsDecimal(p.Amount),
+				Low:    formatCentsDecimal(p.Low),
+				High:   formatCentsDecimal(p.High),
+			})
+		}
+		out = append(out, forecastSeries{ID: s.ID, Name: s.Name, Points: points})
+	}
+	return out
+}
+
+func handleForecast(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("forecast")
+	var months int
+
+	fs.IntVar(&months, "months", cashmop.DefaultForecastMonths, "")
+	if ok, res := fs.parse(args, "forecast"); !ok {
+		return res
+	}
+
+	forecast, err := svc.Forecast(months)
+	if err != nil {
+		if errors.Is(err, cashmop.ErrInvalidForecastMonths) {
+			return commandResult{Err: validationError(ErrorDetail{
+				Field:   "months",
+				Message: fmt.Sprintf("--months must be between 1 and %d.", cashmop.MaxForecastMonths),
+				Hint:    "Provide --months <n>, e.g. --months 6.",
+			})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	out := make([]forecastMonth, 0, len(forecast.Months))
+	for _, m := range forecast.Months {
+		out = append(out, forecastMonth{
+			Month:       m.Month,
+			Income:      formatCentsDecimal(m.Income),
+			Expenses:    formatCentsDecimal(m.Expenses),
+			Net:         formatCentsDecimal(m.Net),
+			Low:         formatCentsDecimal(m.Low),
+			High:        formatCentsDecimal(m.High),
+			Balance:     formatCentsDecimal(m.Balance),
+			BalanceLow:  formatCentsDecimal(m.BalanceLow),
+			BalanceHigh: formatCentsDecimal(m.BalanceHigh),
+		})
+	}
+	recurring := make([]forecastRecurring, 0, len(forecast.Recurring))
+	for _, r := range forecast.Recurring {
+		recurring = append(recurring, forecastRecurring{
+			Description:   r.Description,
+			Account:       r.AccountName,
+			Category:      r.CategoryName,
+			Cadence:       r.Cadence,
+			Amount:        formatCentsDecimal(r.Amount),
+			MonthlyAmount: formatCentsDecimal(r.MonthlyAmount),
+			NextDate:      r.NextDate,
+		})
+	}
+	return commandResult{Response: forecastResponse{
+		Ok:             true,
+		MainCurrency:   forecast.MainCurrency,
+		HistoryStart:   forecast.HistoryStart,
+		HistoryEnd:     forecast.HistoryEnd,
+		OpeningBalance: formatCentsDecimal(forecast.OpeningBalance),
+		Months:         out,
+		Categories:     newForecastSeries(forecast.Categories),
+		Accounts:       newForecastSeries(forecast.Accounts),
+		Balances:       newForecastSeries(forecast.Balances),
+		Recurring:      recurring,
+	}}
+}
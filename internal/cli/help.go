@@ -26,6 +26,20 @@ func printHelp(command string) {
 		fmt.Fprintln(os.Stdout, settingsHelp())
 	case "fx":
 		fmt.Fprintln(os.Stdout, fxHelp())
+	case "report":
+		fmt.Fprintln(os.Stdout, reportHelp())
+	case "forecast":
+		fmt.Fprintln(os.Stdout, forecastHelp())
+	case "recurring":
+		fmt.Fprintln(os.Stdout, recurringHelp())
+	case "budgets":
+		fmt.Fprintln(os.Stdout, budgetsHelp())
+	case "balances":
+		fmt.Fprintln(os.Stdout, balancesHelp())
+	case "transfers":
+		fmt.Fprintln(os.Stdout, transfersHelp())
+	case "merchants":
+		fmt.Fprintln(os.Stdout, merchantsHelp())
 	case "install-cli":
 		fmt.Fprintln(os.Stdout, installCliHelp())
 	case "uninstall-cli":
@@ -46,7 +60,7 @@ func rootHelp() string {
 	b.WriteString("Notes:\n")
 	b.WriteString("  - Global flags must appear before <subcommand> (Go flag parsing stops at the first non-flag).\n")
 	b.WriteString("  - All non-help output goes to stdout (stderr is empty). Default output is JSON.\n")
-	b.WriteString("  - Global --format is json|table. table works for: categories list, tx list, rules list.\n")
+	b.WriteString("  - Global --format is json|table. table works for: categories list, tx list, tx attachments, tx predict, report summary, report compare, report net-worth, report settle, report anomalies, report merchants, forecast, recurring list, budgets list, budgets report, balances list, balances reconcile, transfers list, transfers detect, merchants list, merchants suggest, rules list, rules stale, rules suggest, rules test, rules apply.\n")
 	b.WriteString("  - export has its own --format csv|xlsx (after the export subcommand).\n")
 	b.WriteString("\n")
 	b.WriteString("Global flags:\n")
@@ -79,6 +93,20 @@ func rootHelp() string {
 	b.WriteString(settingsHelp())
 	b.WriteString("\n\n[fx]\n")
 	b.WriteString(fxHelp())
+	b.WriteString("\n\n[report]\n")
+	b.WriteString(reportHelp())
+	b.WriteString("\n\n[forecast]\n")
+	b.WriteString(forecastHelp())
+	b.WriteString("\n\n[recurring]\n")
+	b.WriteString(recurringHelp())
+	b.WriteString("\n\n[budgets]\n")
+	b.WriteString(budgetsHelp())
+	b.WriteString("\n\n[balances]\n")
+	b.WriteString(balancesHelp())
+	b.WriteString("\n\n[transfers]\n")
+	b.WriteString(transfersHelp())
+	b.WriteString("\n\n[merchants]\n")
+	b.WriteString(merchantsHelp())
 	b.WriteString("\n\n[install-cli]\n")
 	b.WriteString(installCliHelp())
 	b.WriteString("\n\n[uninstall-cli]\n")
@@ -88,14 +116,17 @@ func rootHelp() string {
 
 func importHelp() string {
 	return strings.TrimSpace(`Usage:
-  cashmop import --file <path> --mapping <path|name|-> [--month YYYY-MM ...] [--dry-run] [--no-apply-rules]
+  cashmop import --file <path> --mapping <path|name|-> [--month YYYY-MM ...] [--dry-run] [--no-apply-rules] [--auto-accept 0.9] [--closing-balance <decimal> [--closing-date YYYY-MM-DD]]
 
 Flags:
   --file <path>          Import CSV/XLSX file
   --mapping <path|name|->  Mapping file path, saved mapping name, or - for stdin
   --month YYYY-MM        Repeat to select months
   --dry-run              Parse/validate only
-  --no-apply-rules       Skip rule application`)
+  --no-apply-rules       Skip rule application
+  --auto-accept <0..1>   Categorize imported rows left uncategorized whose top prediction reaches this confidence
+  --closing-balance <decimal>  Record the statement's closing balance for its one account; needs every month of the file
+  --closing-date YYYY-MM-DD    Date of the closing balance (default: the last imported row)`)
 }
 
 func mappingsHelp() string {
@@ -108,30 +139,59 @@ func mappingsHelp() string {
 
 func txHelp() string {
 	return strings.TrimSpace(`Usage:
-  cashmop tx list [--start YYYY-MM-DD --end YYYY-MM-DD] [--uncategorized] [--category-ids 1,2] [--query "..."] [--amount-min "12.34"] [--amount-max "99.99"] [--sort date|amount] [--order asc|desc]
+  cashmop tx list [--start YYYY-MM-DD --end YYYY-MM-DD] [--uncategorized] [--category-ids 1,2] [--tags a,b] [--untagged] [--merchant-ids 1,2] [--no-merchant] [--query "..."] [--amount-min "12.34"] [--amount-max "99.99"] [--sort date|amount] [--order asc|desc]
+  cashmop tx add --date YYYY-MM-DD --description "<text>" --amount <decimal> --account <name> [--currency <code>] [--category <name>] [--owner <name>] [--note "<text>"]
+  cashmop tx edit --id <id> [--date YYYY-MM-DD] [--description "<text>"] [--amount <decimal>] [--account <name>] [--currency <code>] [--owner <name>]
   cashmop tx categorize --id <id> --category <name>
-  cashmop tx categorize --id <id> --uncategorize`)
+  cashmop tx categorize --id <id> --uncategorize
+  cashmop tx tag --ids <id,id> [--add <tag,tag>] [--remove <tag,tag>]
+  cashmop tx note --id <id> (--note "<text>" | --clear)
+  cashmop tx attach --id <id> --file <path>
+  cashmop tx attachments --id <id>
+  cashmop tx split --id <id> --line "<amount>|<category>[|<note>[|<owner>]]" --line ...
+  cashmop tx unsplit --id <id>
+  cashmop tx predict [--id <id>] [--top 3] [--retrain]`)
 }
 
 func categoriesHelp() string {
 	return strings.TrimSpace(`Usage:
   cashmop categories list
   cashmop categories rename --id <id> --name <new>
-  cashmop categories create --name <name>`)
+  cashmop categories create --name <name> [--parent <id>] [--kind income|expense|transfer|excluded]
+  cashmop categories move --id <id> (--parent <id> | --root)
+  cashmop categories set-kind --id <id> --kind income|expense|transfer|excluded
+  cashmop categories merge --source-ids <id,id> --target-id <id>
+  cashmop categories delete --id <id> [--reassign-to <id>]`)
 }
 
 func rulesHelp() string {
 	return strings.TrimSpace(`Usage:
   cashmop rules list
+  cashmop rules stale --since YYYY-MM-DD
   cashmop rules preview --match-value <v> --match-type <starts_with|ends_with|contains|exact> [--amount-min "..."] [--amount-max "..."]
+  cashmop rules suggest [--min-support 3] [--min-precision 0.9] [--limit 20] [--preview-limit 5]
   cashmop rules create --match-value <v> --match-type <...> [--amount-min "..."] [--amount-max "..."] --category <name>
   cashmop rules update --id <id> [--match-value <v>] [--match-type <...>] [--amount-min "..."] [--amount-max "..."] [--category <name>] [--recategorize]
-  cashmop rules delete --id <id> [--uncategorize]`)
+  cashmop rules delete --id <id> [--uncategorize]
+  cashmop rules export --file <rules.yaml|rules.json>
+  cashmop rules import --file <rules.yaml|rules.json> [--mode merge|replace] [--dry-run]
+  cashmop rules test --file <cases.yaml|cases.json>
+  cashmop rules apply --all | --id <id> [--overwrite none|rule-only|all] [--dry-run]
+  cashmop rules apply --undo <batch id>
+
+Rule test cases:
+  cases:
+    - description: "UBER *TRIP"
+      amount: "-12.34"       # optional; currency/date optional for FX rules
+      expected: Transport    # empty = no rule should match`)
 }
 
 func exportHelp() string {
 	return strings.TrimSpace(`Usage:
-  cashmop export --start YYYY-MM-DD --end YYYY-MM-DD --format csv|xlsx --out <path> [--category-ids 1,2]`)
+  cashmop export --start YYYY-MM-DD --end YYYY-MM-DD --format csv|xlsx --out <path> [--category-ids 1,2] [--tags a,b] [--untagged] [--merchant-ids 1,2] [--no-merchant] [--previous-start YYYY-MM-DD --previous-end YYYY-MM-DD]
+
+Notes:
+  - --previous-start/--previous-end add Comparison and Merchant Changes sheets (xlsx only), as in report compare.`)
 }
 
 func backupHelp() string {
@@ -155,6 +215,105 @@ func fxHelp() string {
   cashmop fx rate --base <ISO> --quote <ISO> --date YYYY-MM-DD`)
 }
 
+func reportHelp() string {
+	return strings.TrimSpace(`Usage:
+  cashmop report summary [--start YYYY-MM-DD --end YYYY-MM-DD] [--period month|quarter|year] [--by category|owner|account|tag|merchant] [--uncategorized] [--category-ids 1,2] [--tags a,b] [--untagged] [--merchant-ids 1,2] [--no-merchant] [--include-transfers]
+  cashmop report compare [--start YYYY-MM-DD --end YYYY-MM-DD] [--vs previous|year | --previous-start YYYY-MM-DD --previous-end YYYY-MM-DD] [--by category|owner|account|tag|merchant] [--uncategorized] [--category-ids 1,2] [--tags a,b] [--untagged] [--merchant-ids 1,2] [--no-merchant]
+  cashmop report net-worth [--start YYYY-MM --end YYYY-MM]
+  cashmop report settle [--start YYYY-MM-DD --end YYYY-MM-DD] [--share <owner>=<percent>,...] [--category-share <category id>:<owner>=<percent>,...] [--uncategorized] [--category-ids 1,2] [--tags a,b] [--untagged] [--merchant-ids 1,2] [--no-merchant]
+  cashmop report anomalies [--month YYYY-MM]
+  cashmop report merchants [--start YYYY-MM-DD --end YYYY-MM-DD] [--sort spend|visits|average] [--limit 20] [--uncategorized] [--category-ids 1,2] [--tags a,b] [--untagged]
+
+Notes:
+  - Without --start/--end, summary, settle and merchants cover the last 12 full calendar months and net-worth the 12 months ending with the current one.
+  - Amounts are in the main currency, split into income, expenses, transfers, and excluded by category kind.
+  - Linked transfers count as transfers; summary --include-transfers counts them by their own category instead.
+  - compare defaults to the month so far against the same days of the previous month; --vs year compares with the same dates a year earlier (e.g. YTD vs last YTD).
+  - net-worth sums month-end balances of accounts with balance checkpoints (see balances).
+  - settle splits spending between owners (evenly unless --share is given; --category-share overrides a category and its subcategories) and compares it with what each owner paid.
+  - anomalies flags categories whose monthly spending spikes, and merchant charges far from usual, against the previous 12 months (median/MAD z-score); --month defaults to the current month.
+  - merchants ranks merchants by spending net of refunds, visits (charges), or average ticket; --limit 0 lists all.
+  - --merchant-ids filters by merchant (see merchants list); --no-merchant selects transactions without one.`)
+}
+
+func forecastHelp() string {
+	return strings.TrimSpace(`Usage:
+  cashmop forecast [--months 6]
+
+Notes:
+  - Projects the next 1-24 calendar months from recurring transactions plus trailing 12-month averages.
+  - Balances are the projected change from the start of the forecast, with 90% low/high bands.`)
+}
+
+func recurringHelp() string {
+	return strings.TrimSpace(`Usage:
+  cashmop recurring list [--include-ended] [--unknown]
+  cashmop recurring mark --key <key>
+  cashmop recurring unmark --key <key>
+
+Notes:
+  - Detects weekly, biweekly, monthly, quarterly, and annual series in the last 3 years.
+  - --unknown hides series marked as known subscriptions.`)
+}
+
+func budgetsHelp() string {
+	return strings.TrimSpace(`Usage:
+  cashmop budgets list
+  cashmop budgets create --category-id <id> --amount <decimal> [--period monthly|quarterly|yearly] [--rollover] [--start-month YYYY-MM]
+  cashmop budgets update --id <id> [--amount <decimal>] [--period monthly|quarterly|yearly] [--start-month YYYY-MM] [--rollover|--no-rollover]
+  cashmop budgets delete --id <id>
+  cashmop budgets report [--month YYYY-MM | --start YYYY-MM --end YYYY-MM]
+
+Notes:
+  - Budgets are in the main currency, one per expense category, and cover its subcategories.
+  - Quarterly and yearly budgets are spread evenly over their months.
+  - --rollover carries what is left (or overspent) each month into the next, from --start-month (default: current month).
+  - report defaults to the current month; parents without a budget roll up the budgets below them.`)
+}
+
+func balancesHelp() string {
+	return strings.TrimSpace(`Usage:
+  cashmop balances list [--account <name>]
+  cashmop balances set --account <name> --date YYYY-MM-DD --balance <decimal> [--currency CAD] [--source manual|statement] [--note <text>]
+  cashmop balances delete --id <id>
+  cashmop balances reconcile [--account <name>]
+
+Notes:
+  - A checkpoint is the balance at the end of --date; setting a date again replaces it.
+  - reconcile compares each checkpoint with the previous one plus the transactions in between and reports the gap.`)
+}
+
+func transfersHelp() string {
+	return strings.TrimSpace(`Usage:
+  cashmop transfers detect [--start YYYY-MM-DD --end YYYY-MM-DD] [--window-days 3] [--dry-run]
+  cashmop transfers list
+  cashmop transfers unlink --id <id>
+
+Notes:
+  - detect links an outgoing and an incoming transaction of different accounts with the same amount within --window-days; --dry-run only lists the pairs.
+  - Amounts in different currencies match within 2% after conversion to the main currency.
+  - Transactions put in an income or expense category by hand are never linked.
+  - Linked transactions count as transfers in reports, whatever their category (report summary --include-transfers counts them by category).
+  - Editing the amount, account, date or currency of a side removes the link once the two sides no longer match.
+  - unlink keeps detect from linking the same pair again.`)
+}
+
+func merchantsHelp() string {
+	return strings.TrimSpace(`Usage:
+  cashmop merchants list
+  cashmop merchants create --name <name> [--alias <match_type>:<pattern> ...]
+  cashmop merchants rename --id <id> --name <name>
+  cashmop merchants delete --id <id>
+  cashmop merchants add-alias --id <id> --match-type <starts_with|ends_with|contains|exact> --pattern <text>
+  cashmop merchants remove-alias --alias-id <id>
+  cashmop merchants suggest [--min-count 2] [--limit 20]
+
+Notes:
+  - Aliases match descriptions like rules; a transaction belongs to the merchant of its best alias: exact first, then starts_with and ends_with, then contains, longer patterns first.
+  - Transactions are reassigned when aliases change and when transactions are imported, added, or edited.
+  - suggest clusters descriptions of transactions without a merchant and proposes aliases for them; merchant_id is set when a merchant of that name exists.`)
+}
+
 func installCliHelp() string {
 	return strings.TrimSpace(`Usage:
   cashmop install-cli [--path <dir>]
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -20,6 +21,9 @@ type importResponse struct {
 	Months        []string `json:"months"`
 	AppliedRules  bool     `json:"applied_rules"`
 	AppliedCount  int      `json:"applied_count"`
+	AutoAccepted  int      `json:"auto_accepted_count"`
+	// ClosingBalance is the statement checkpoint recorded by --closing-balance.
+	ClosingBalance *balanceCheckpoint `json:"closing_balance,omitempty"`
 }
 
 type importDryRunResponse struct {
@@ -37,12 +41,18 @@ func handleImport(svc *cashmop.Service, args []string) commandResult {
 	var selectedMonths stringSliceFlag
 	var dryRun bool
 	var noApplyRules bool
+	var autoAccept float64
+	var closingBalance string
+	var closingDate string
 
 	fs.StringVar(&filePath, "file", "", "")
 	fs.StringVar(&mappingSpec, "mapping", "", "")
 	fs.Var(&selectedMonths, "month", "")
 	fs.BoolVar(&dryRun, "dry-run", false, "")
 	fs.BoolVar(&noApplyRules, "no-apply-rules", false, "")
+	fs.Float64Var(&autoAccept, "auto-accept", 0, "")
+	fs.StringVar(&closingBalance, "closing-balance", "", "")
+	fs.StringVar(&closingDate, "closing-date", "", "")
 
 	if ok, res := fs.parse(args, "import"); !ok {
 		return res
@@ -59,6 +69,26 @@ func handleImport(svc *cashmop.Service, args []string) commandResult {
 		return commandResult{Err: validationError(details...)}
 	}
 
+	if autoAccept < 0 || autoAccept > 1 {
+		return commandResult{Err: validationError(ErrorDetail{Field: "auto-accept", Message: "--auto-accept must be between 0 and 1.", Hint: "Use a confidence like 0.9, or omit it to keep predictions as suggestions."})}
+	}
+	var closingCents int64
+	if closingBalance != "" {
+		v, err := parseCentsString(closingBalance)
+		if err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "closing-balance", Message: "Invalid closing balance.", Hint: "Use a decimal string like 1234.56 (negative for debt)."})}
+		}
+		closingCents = v
+	}
+	if closingDate != "" {
+		if closingBalance == "" {
+			return commandResult{Err: validationError(ErrorDetail{Field: "closing-date", Message: "--closing-date requires --closing-balance.", Hint: "Provide --closing-balance <decimal>."})}
+		}
+		if _, err := parseDate(closingDate); err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "closing-date", Message: "Invalid closing date.", Hint: "Use YYYY-MM-DD."})}
+		}
+	}
+
 	mappingData, mErr := resolveMapping(svc, mappingSpec)
 	if mErr != nil {
 		return commandResult{Err: mErr}
@@ -95,11 +125,55 @@ func handleImport(svc *cashmop.Service, args []string) commandResult {
 		}
 	}
 
+	// A closing balance is only right after every row of the statement.
+	if closingBalance != "" {
+		selected := make(map[string]bool, len(finalMonths))
+		for _, month := range finalMonths {
+			selected[month] = true
+		}
+		for _, month := range allMonths {
+			if !selected[month] {
+				return commandResult{Err: validationError(ErrorDetail{Field: "closing-balance", Message: "--closing-balance needs every month of the file.", Hint: "Drop --month, or set the balance with \"cashmop balances set\"."})}
+			}
+		}
+	}
+
 	txs, err := normalizeTransactions(svc, parsed, m, finalMonths)
 	if err != nil {
 		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
 	}
 
+	// The closing balance belongs to the statement's one account, at
+	// --closing-date or else the date of its last transaction.
+	var closing *database.BalanceCheckpoint
+	if closingBalance != "" {
+		if len(txs) == 0 {
+			return commandResult{Err: validationError(ErrorDetail{Field: "closing-balance", Message: "--closing-balance needs at least one imported transaction.", Hint: "Check --month, or set the balance with \"cashmop balances set\"."})}
+		}
+		closing = &database.BalanceCheckpoint{
+			AccountID: txs[0].AccountID,
+			Date:      txs[0].Date,
+			Balance:   closingCents,
+			Currency:  txs[0].Currency,
+			Source:    database.BalanceSourceStatement,
+			Note:      "Closing balance from " + filepath.Base(filePath),
+		}
+		for _, tx := range txs {
+			if tx.AccountID != closing.AccountID {
+				return commandResult{Err: validationError(ErrorDetail{Field: "closing-balance", Message: "--closing-balance needs a file with a single account.", Hint: "Import each account separately, or set balances with \"cashmop balances set\"."})}
+			}
+			if tx.Date > closing.Date {
+				closing.Date = tx.Date
+			}
+		}
+		if closingDate != "" {
+			if closingDate < closing.Date {
+				return commandResult{Err: validationError(ErrorDetail{Field: "closing-date", Message: fmt.Sprintf("--closing-date is before the last imported transaction (%s).", closing.Date), Hint: "Use the statement's closing date."})}
+			}
+			closing.Date = closingDate
+		}
+	}
+
 	if dryRun {
 		return commandResult{Response: importDryRunResponse{
 			Ok:          true,
@@ -110,10 +184,11 @@ func handleImport(svc *cashmop.Service, args []string) commandResult {
 		}}
 	}
 
-	inserted, skipped, err := svc.BatchInsertTransactionsWithCount(txs)
+	insertedIDs, err := svc.BatchInsertTransactionsWithIDs(txs)
 	if err != nil {
 		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
 	}
+	inserted, skipped := len(insertedIDs), len(txs)-len(insertedIDs)
 
 	appliedCount := 0
 	if !noApplyRules {
@@ -122,13 +197,34 @@ func handleImport(svc *cashmop.Service, args []string) commandResult {
 		}
 	}
 
+	autoAccepted := 0
+	if autoAccept > 0 {
+		ids, err := svc.AutoAcceptPredictions(autoAccept, insertedIDs)
+		if err != nil {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+		autoAccepted = len(ids)
+	}
+
+	var closingCheckpoint *balanceCheckpoint
+	if closing != nil {
+		cp, err := svc.SetBalanceCheckpoint(*closing)
+		if err != nil {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+		c := newBalanceCheckpoint(cp)
+		closingCheckpoint = &c
+	}
+
 	return commandResult{Response: importResponse{
-		Ok:            true,
-		ImportedCount: inserted,
-		SkippedCount:  skipped,
-		Months:        finalMonths,
-		AppliedRules:  appliedCount > 0,
-		AppliedCount:  appliedCount,
+		Ok:             true,
+		ImportedCount:  inserted,
+		SkippedCount:   skipped,
+		Months:         finalMonths,
+		AppliedRules:   appliedCount > 0,
+		AppliedCount:   appliedCount,
+		AutoAccepted:   autoAccepted,
+		ClosingBalance: closingCheckpoint,
 	}}
 }
 
@@ -209,6 +305,7 @@ func normalizeTransactions(svc *cashmop.Service, parsed *parsedFile, mapping map
 
 	accountIdx := findHeader(headers, mapping.CSV.Account)
 	currencyIdx := findHeader(headers, mapping.CSV.Currency)
+	memoIdx := findHeader(headers, mapping.CSV.Memo)
 
 	settings, _ := svc.GetCurrencySettings()
 	defaultCurrency := strings.ToUpper(strings.TrimSpace(settings.MainCurrency))
@@ -249,6 +346,11 @@ func normalizeTransactions(svc *cashmop.Service, parsed *parsedFile, mapping map
 
 		amount := amountParser(row)
 
+		note := ""
+		if memoIdx != -1 && memoIdx < len(row) {
+			note = strings.TrimSpace(row[memoIdx])
+		}
+
 		currency := ""
 		if currencyIdx != -1 && currencyIdx < len(row) {
 			currency = strings.ToUpper(strings.TrimSpace(row[currencyIdx]))
@@ -303,6 +405,7 @@ func normalizeTransactions(svc *cashmop.Service, parsed *parsedFile, mapping map
 			OwnerID:     ownerID,
 			Date:        d.Format("2006-01-02"),
 			Description: description,
+			Note:        note,
 			Amount:      amount,
 			CategoryID:  nil,
 			Currency:    currency,
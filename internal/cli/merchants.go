@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+type merchantAlias struct {
+	ID        int64  `json:"id,omitempty"`
+	MatchType string `json:"match_type"`
+	Pattern   string `json:"pattern"`
+}
+
+type merchant struct {
+	ID               int64           `json:"id"`
+	Name             string          `json:"name"`
+	Aliases          []merchantAlias `json:"aliases"`
+	TransactionCount int             `json:"transaction_count"`
+}
+
+func newMerchantAliases(aliases []database.MerchantAlias) []merchantAlias {
+	out := make([]merchantAlias, 0, len(aliases))
+	for _, a := range aliases {
+		out = append(out, merchantAlias{ID: a.ID, MatchType: a.MatchType, Pattern: a.Pattern})
+	}
+	return out
+}
+
+func newMerchant(m database.Merchant) merchant {
+	return merchant{
+		ID:               m.ID,
+		Name:             m.Name,
+		Aliases:          newMerchantAliases(m.Aliases),
+		TransactionCount: m.TransactionCount,
+	}
+}
+
+// formatMerchantAliases renders aliases as "type:pattern" for tables.
+func formatMerchantAliases(aliases []merchantAlias) string {
+	parts := make([]string, len(aliases))
+	for i, a := range aliases {
+		parts[i] = a.MatchType + ":" + a.Pattern
+	}
+	return strings.Join(parts, ", ")
+}
+
+type merchantsListResponse struct {
+	Ok        bool       `json:"ok"`
+	Count     int        `json:"count"`
+	Merchants []merchant `json:"merchants"`
+}
+
+type merchantResponse struct {
+	Ok       bool     `json:"ok"`
+	Merchant merchant `json:"merchant"`
+}
+
+type merchantDeleteResponse struct {
+	Ok bool  `json:"ok"`
+	ID int64 `json:"id"`
+}
+
+func (r merchantsListResponse) TableHeaders() []string {
+	return []string{"ID", "Name", "Aliases", "Transactions"}
+}
+
+func (r merchantsListResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Merchants))
+	for i, m := range r.Merchants {
+		rows[i] = []string{fmt.Sprint(m.ID), m.Name, formatMerchantAliases(m.Aliases), fmt.Sprint(m.TransactionCount)}
+	}
+	return rows
+}
+
+type merchantSuggestion struct {
+	Name             string          `json:"name"`
+	MerchantID       *int64          `json:"merchant_id"`
+	Aliases          []merchantAlias `json:"aliases"`
+	Descriptions     []string        `json:"descriptions"`
+	TransactionCount int             `json:"transaction_count"`
+}
+
+type merchantsSuggestResponse struct {
+	Ok          bool                 `json:"ok"`
+	Count       int                  `json:"count"`
+	Suggestions []merchantSuggestion `json:"suggestions"`
+}
+
+func (r merchantsSuggestResponse) TableHeaders() []string {
+	return []string{"Name", "Merchant", "Aliases", "Transactions", "Descriptions"}
+}
+
+func (r merchantsSuggestResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Suggestions))
+	for i, s := range r.Suggestions {
+		existing := ""
+		if s.MerchantID != nil {
+			existing = fmt.Sprint(*s.MerchantID)
+		}
+		rows[i] = []string{s.Name, existing, formatMerchantAliases(s.Aliases), fmt.Sprint(s.TransactionCount), strings.Join(s.Descriptions, "; ")}
+	}
+	return rows
+}
+
+func handleMerchants(svc *cashmop.Service, args []string) commandResult {
+	if len(args) == 0 {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Missing merchants subcommand (list, create, rename, delete, add-alias, remove-alias, suggest).",
+			Hint:    "Use \"cashmop merchants list\" or \"cashmop merchants suggest\".",
+		})}
+	}
+
+	switch args[0] {
+	case "list":
+		return handleMerchantsList(svc, args[1:])
+	case "create":
+		return handleMerchantsCreate(svc, args[1:])
+	case "rename":
+		return handleMerchantsRename(svc, args[1:])
+	case "delete":
+		return handleMerchantsDelete(svc, args[1:])
+	case "add-alias":
+		return handleMerchantsAddAlias(svc, args[1:])
+	case "remove-alias":
+		return handleMerchantsRemoveAlias(svc, args[1:])
+	case "suggest":
+		return handleMerchantsSuggest(svc, args[1:])
+	default:
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Unknown merchants subcommand.",
+			Hint:    "Use list, create, rename, delete, add-alias, remove-alias, or suggest.",
+		})}
+	}
+}
+
+func handleMerchantsList(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("merchants list")
+	if ok, res := fs.parse(args, "merchants"); !ok {
+		return res
+	}
+
+	merchants, err := svc.GetMerchants()
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	out := make([]merchant, 0, len(merchants))
+	for _, m := range merchants {
+		out = append(out, newMerchant(m))
+	}
+	return commandResult{Response: merchantsListResponse{Ok: true, Count: len(out), Merchants: out}}
+}
+
+// parseMerchantAlias parses a "<match_type>:<pattern>" alias.
+func parseMerchantAlias(s string) (database.MerchantAlias, *cliError) {
+	matchType, pattern, ok := strings.Cut(s, ":")
+	if !ok {
+		return database.MerchantAlias{}, validationError(ErrorDetail{
+			Field:   "alias",
+			Message: fmt.Sprintf("Invalid alias: %s", s),
+			Hint:    "Use <match_type>:<pattern>, e.g. starts_with:AMZN MKTP.",
+		})
+	}
+	return database.MerchantAlias{MatchType: matchType, Pattern: pattern}, nil
+}
+
+func handleMerchantsCreate(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("merchants create")
+	var name string
+	var aliasFlags stringSliceFlag
+	fs.StringVar(&name, "name", "", "")
+	fs.Var(&aliasFlags, "alias", "")
+	if ok, res := fs.parse(args, "merchants"); !ok {
+		return res
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return commandResult{Err: validationError(requiredFlagError("name", "Provide --name <merchant name>."))}
+	}
+	// Patterns may contain commas, so each --alias is one alias.
+	var aliases []database.MerchantAlias
+	for _, s := range aliasFlags.values {
+		a, cErr := parseMerchantAlias(s)
+		if cErr != nil {
+			return commandResult{Err: cErr}
+		}
+		aliases = append(aliases, a)
+	}
+
+	m, err := svc.CreateMerchant(name, aliases)
+	if err != nil {
+		return commandResult{Err: merchantWriteError(err, "merchant")}
+	}
+	return commandResult{Response: merchantResponse{Ok: true, Merchant: newMerchant(m)}}
+}
+
+func handleMerchantsRename(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("merchants rename")
+	var id int64
+	var name string
+	fs.Int64Var(&id, "id", 0, "")
+	fs.StringVar(&name, "name", "", "")
+	if ok, res := fs.parse(args, "merchants"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if id == 0 {
+		details = append(details, requiredFlagError("id", "Provide --id <merchant id>."))
+	}
+	if strings.TrimSpace(name) == "" {
+		details = append(details, requiredFlagError("name", "Provide --name <merchant name>."))
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	m, err := svc.RenameMerchant(id, name)
+	if err != nil {
+		return commandResult{Err: merchantWriteError(err, "merchant")}
+	}
+	return commandResult{Response: merchantResponse{Ok: true, Merchant: newMerchant(m)}}
+}
+
+func handleMerchantsDelete(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("merchants delete")
+	var id int64
+	fs.Int64Var(&id, "id", 0, "")
+	if ok, res := fs.parse(args, "merchants"); !ok {
+		return res
+	}
+
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <merchant id>."))}
+	}
+	if err := svc.DeleteMerchant(id); err != nil {
+		return commandResult{Err: merchantWriteError(err, "merchant")}
+	}
+	return commandResult{Response: merchantDeleteResponse{Ok: true, ID: id}}
+}
+
+func handleMerchantsAddAlias(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("merchants add-alias")
+	var id int64
+	var matchType string
+	var pattern string
+	fs.Int64Var(&id, "id", 0, "")
+	fs.StringVar(&matchType, "match-type", "", "")
+	fs.StringVar(&pattern, "pattern", "", "")
+	if ok, res := fs.parse(args, "merchants"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if id == 0 {
+		details = append(details, requiredFlagError("id", "Provide --id <merchant id>."))
+	}
+	if matchType == "" {
+		details = append(details, requiredFlagError("match-type", "Provide --match-type <starts_with|ends_with|contains|exact>."))
+	}
+	if pattern == "" {
+		details = append(details, requiredFlagError("pattern", "Provide --pattern <text>."))
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	m, err := svc.AddMerchantAlias(id, database.MerchantAlias{MatchType: matchType, Pattern: pattern})
+	if err != nil {
+		return commandResult{Err: merchantWriteError(err, "merchant")}
+	}
+	return commandResult{Response: merchantResponse{Ok: true, Merchant: newMerchant(m)}}
+}
+
+func handleMerchantsRemoveAlias(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("merchants remove-alias")
+	var aliasID int64
+	fs.Int64Var(&aliasID, "alias-id", 0, "")
+	if ok, res := fs.parse(args, "merchants"); !ok {
+		return res
+	}
+
+	if aliasID == 0 {
+		return commandResult{Err: validationError(requiredFlagError("alias-id", "Provide --alias-id <alias id>."))}
+	}
+	m, err := svc.DeleteMerchantAlias(aliasID)
+	if err != nil {
+		return commandResult{Err: merchantWriteError(err, "alias")}
+	}
+	return commandResult{Response: merchantResponse{Ok: true, Merchant: newMerchant(m)}}
+}
+
+func handleMerchantsSuggest(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("merchants suggest")
+	var minCount int
+	var limit int
+	fs.IntVar(&minCount, "min-count", 2, "")
+	fs.IntVar(&limit, "limit", 20, "")
+	if ok, res := fs.parse(args, "merchants"); !ok {
+		return res
+	}
+
+	if minCount < 1 || limit < 1 {
+		field := "min-count"
+		if minCount >= 1 {
+			field = "limit"
+		}
+		return commandResult{Err: validationError(ErrorDetail{Field: field, Message: fmt.Sprintf("--%s must be at least 1.", field), Hint: fmt.Sprintf("Provide --%s <n> with n >= 1.", field)})}
+	}
+
+	suggestions, err := svc.SuggestMerchants(cashmop.MerchantSuggestionOptions{MinTransactions: minCount, Limit: limit})
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	out := make([]merchantSuggestion, 0, len(suggestions))
+	for _, sg := range suggestions {
+		out = append(out, merchantSuggestion{
+			Name:             sg.Name,
+			MerchantID:       sg.MerchantID,
+			Aliases:          newMerchantAliases(sg.Aliases),
+			Descriptions:     sg.Descriptions,
+			TransactionCount: sg.TransactionCount,
+		})
+	}
+	return commandResult{Response: merchantsSuggestResponse{Ok: true, Count: len(out), Suggestions: out}}
+}
+
+// merchantWriteError maps store errors from changing merchants and their
+// aliases to CLI errors. notFound names what a sql.ErrNoRows refers to.
+func merchantWriteError(err error, notFound string) *cliError {
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && notFound == "alias":
+		return runtimeError(ErrorDetail{Message: "Merchant alias not found.", Hint: "Use \"cashmop merchants list\" to see alias IDs."})
+	case errors.Is(err, sql.ErrNoRows):
+		return runtimeError(ErrorDetail{Message: "Merchant not found.", Hint: "Use \"cashmop merchants list\" to see merchant IDs."})
+	case errors.Is(err, database.ErrInvalidMerchantName):
+		return validationError(ErrorDetail{Field: "name", Message: "Merchant name cannot be empty.", Hint: "Provide --name <merchant name>."})
+	case errors.Is(err, database.ErrMerchantExists):
+		return validationError(ErrorDetail{Field: "name", Message: "A merchant with this name already exists.", Hint: "Pick another name or add aliases to the existing merchant."})
+	case errors.Is(err, database.ErrInvalidMerchantAlias):
+		return validationError(ErrorDetail{Field: "alias", Message: "Invalid alias.", Hint: "Use a match type of starts_with, ends_with, contains, or exact and a non-empty pattern."})
+	case errors.Is(err, database.ErrMerchantAliasExists):
+		return validationError(ErrorDetail{Field: "alias", Message: "The alias already exists.", Hint: "Use \"cashmop merchants list\" to see which merchant has it."})
+	}
+	return runtimeError(ErrorDetail{Message: err.Error()})
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/default-anton/cashmop/internal/cashmop"
+)
+
+type recurringListResponse struct {
+	Ok     bool              `json:"ok"`
+	Count  int               `json:"count"`
+	Series []recurringSeries `json:"series"`
+}
+
+type recurringSeries struct {
+	Key           string                 `json:"key"`
+	Description   string                 `json:"description"`
+	Account       string                 `json:"account"`
+	Category      string                 `json:"category"`
+	Cadence       string                 `json:"cadence"`
+	Currency      string                 `json:"currency"`
+	Amount        string                 `json:"amount"`
+	AverageAmount string                 `json:"average_amount"`
+	MonthlyAmount string                 `json:"monthly_amount"`
+	Count         int                    `json:"count"`
+	FirstDate     string                 `json:"first_date"`
+	LastDate      string                 `json:"last_date"`
+	NextDate      string                 `json:"next_date"`
+	PriceChanges  []recurringPriceChange `json:"price_changes"`
+	MissedDates   []string               `json:"missed_dates"`
+	Active        bool                   `json:"active"`
+	Known         bool                   `json:"known"`
+}
+
+type recurringPriceChange struct {
+	Date string `json:"date"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type recurringMarkResponse struct {
+	Ok    bool   `json:"ok"`
+	Key   string `json:"key"`
+	Known bool   `json:"known"`
+}
+
+func (r recurringListResponse) TableHeaders() []string {
+	return []string{"Description", "Account", "Cadence", "Amount", "Monthly", "Next", "Missed", "Status", "Key"}
+}
+
+func (r recurringListResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Series))
+	for i, s := range r.Series {
+		var status []string
+		if !s.Active {
+			status = append(status, "ended")
+		}
+		if s.Known {
+			status = append(status, "known")
+		}
+		if len(s.PriceChanges) > 0 {
+			status = append(status, "price changed")
+		}
+		rows[i] = []string{
+			s.Description,
+			s.Account,
+			s.Cadence,
+			s.Amount,
+			s.MonthlyAmount,
+			s.NextDate,
+			fmt.Sprint(len(s.MissedDates)),
+			strings.Join(status, ", "),
+			s.Key,
+		}
+	}
+	return rows
+}
+
+func handleRecurring(svc *cashmop.Service, args []string) commandResult {
+	if len(args) == 0 {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Missing recurring subcommand (list, mark, unmark).",
+			Hint:    "Use \"cashmop recurring list\", \"cashmop recurring mark\", or \"cashmop recurring unmark\".",
+		})}
+	}
+
+	switch args[0] {
+	case "list":
+		return handleRecurringList(svc, args[1:])
+	case "mark":
+		return handleRecurringMark(svc, args[1:], "recurring mark", true)
+	case "unmark":
+		return handleRecurringMark(svc, args[1:], "recurring unmark", false)
+	default:
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Unknown recurring subcommand.",
+			Hint:    "Use \"cashmop recurring list\", \"cashmop recurring mark\", or \"cashmop recurring unmark\".",
+		})}
+	}
+}
+
+func handleRecurringList(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("recurring list")
+	var includeEnded bool
+	var unknown bool
+
+	fs.BoolVar(&includeEnded, "include-ended", false, "")
+	fs.BoolVar(&unknown, "unknown", false, "")
+	if ok, res := fs.parse(args, "recurring"); !ok {
+		return res
+	}
+
+	series, err := svc.GetRecurringSeries(includeEnded)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	out := make([]recurringSeries, 0, len(series))
+	for _, s := range series {
+		if unknown && s.Known {
+			continue
+		}
+		changes := make([]recurringPriceChange, 0, len(s.PriceChanges))
+		for _, c := range s.PriceChanges {
+			changes = append(changes, recurringPriceChange{Date: c.Date, From: formatCentsDecimal(c.From), To: formatCentsDecimal(c.To)})
+		}
+		out = append(out, recurringSeries{
+			Key:           s.Key,
+			Description:   s.Description,
+			Account:       s.AccountName,
+			Category:      s.CategoryName,
+			Cadence:       s.Cadence,
+			Currency:      s.Currency,
+			Amount:        formatCentsDecimal(s.Amount),
+			AverageAmount: formatCentsDecimal(s.AverageAmount),
+			MonthlyAmount: formatCentsDecimal(s.MonthlyAmount),
+			Count:         s.Count,
+			FirstDate:     s.FirstDate,
+			LastDate:      s.LastDate,
+			NextDate:      s.NextDate,
+			PriceChanges:  changes,
+			MissedDates:   s.MissedDates,
+			Active:        s.Active,
+			Known:         s.Known,
+		})
+	}
+	return commandResult{Response: recurringListResponse{Ok: true, Count: len(out), Series: out}}
+}
+
+func handleRecurringMark(svc *cashmop.Service, args []string, name string, known bool) commandResult {
+	fs := newSubcommandFlagSet(name)
+	var key string
+
+	fs.StringVar(&key, "key", "", "")
+	if ok, res := fs.parse(args, "recurring"); !ok {
+		return res
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return commandResult{Err: validationError(requiredFlagError("key", "Provide --key <series key> from \"cashmop recurring list\"."))}
+	}
+
+	if err := svc.SetKnownSubscription(key, known); err != nil {
+		if errors.Is(err, cashmop.ErrRecurringSeriesNotFound) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Recurring series not found.", Hint: "Use \"cashmop recurring list --include-ended\" to find series keys."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: recurringMarkResponse{Ok: true, Key: key, Known: known}}
+}
@@ -0,0 +1,839 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+// reportTotals is database.KindTotals with decimal amounts.
+type reportTotals struct {
+	Income      string `json:"income"`
+	Expenses    string `json:"expenses"`
+	Net         string `json:"net"`
+	Transfers   string `json:"transfers"`
+	Excluded    string `json:"excluded"`
+	Unconverted int    `json:"unconverted"`
+}
+
+func newReportTotals(t database.KindTotals) reportTotals {
+	return reportTotals{
+		Income:      formatCentsDecimal(t.Income),
+		Expenses:    formatCentsDecimal(t.Expenses),
+		Net:         formatCentsDecimal(t.Net),
+		Transfers:   formatCentsDecimal(t.Transfers),
+		Excluded:    formatCentsDecimal(t.Excluded),
+		Unconverted: t.Unconverted,
+	}
+}
+
+type reportSummaryResponse struct {
+	Ok           bool               `json:"ok"`
+	Start        string             `json:"start"`
+	End          string             `json:"end"`
+	Period       string             `json:"period"`
+	By           string             `json:"by"`
+	MainCurrency string             `json:"main_currency"`
+	Rows         []reportSummaryRow `json:"rows"`
+	Totals       reportTotals       `json:"totals"`
+}
+
+type reportSummaryRow struct {
+	Period  string `json:"period"`
+	GroupID *int64 `json:"group_id"`
+	Group   string `json:"group"`
+	Count   int    `json:"count"`
+	reportTotals
+}
+
+func (r reportSummaryResponse) TableHeaders() []string {
+	return []string{"Period", "Group", "Count", "Income", "Expenses", "Net", "Transfers"}
+}
+
+func (r reportSummaryResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = []string{
+			row.Period,
+			row.Group,
+			fmt.Sprint(row.Count),
+			row.Income,
+			row.Expenses,
+			row.Net,
+			row.Transfers,
+		}
+	}
+	return rows
+}
+
+type reportCompareResponse struct {
+	Ok                   bool                    `json:"ok"`
+	By                   string                  `json:"by"`
+	MainCurrency         string                  `json:"main_currency"`
+	Current              reportCompareRange      `json:"current"`
+	Previous             reportCompareRange      `json:"previous"`
+	Rows                 []reportCompareRow      `json:"rows"`
+	NewMerchants         []reportCompareMerchant `json:"new_merchants"`
+	DisappearedMerchants []reportCompareMerchant `json:"disappeared_merchants"`
+}
+
+type reportCompareRange struct {
+	Start  string       `json:"start"`
+	End    string       `json:"end"`
+	Totals reportTotals `json:"totals"`
+}
+
+type reportCompareRow struct {
+	GroupID       *int64   `json:"group_id"`
+	Group         string   `json:"group"`
+	Current       string   `json:"current"`
+	Previous      string   `json:"previous"`
+	Delta         string   `json:"delta"`
+	DeltaPercent  *float64 `json:"delta_percent"`
+	CurrentCount  int      `json:"current_count"`
+	PreviousCount int      `json:"previous_count"`
+}
+
+type reportCompareMerchant struct {
+	Merchant    string `json:"merchant"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+	Amount      string `json:"amount"`
+}
+
+func newReportCompareMerchants(merchants []cashmop.ComparisonMerchant) []reportCompareMerchant {
+	out := make([]reportCompareMerchant, 0, len(merchants))
+	for _, m := range merchants {
+		out = append(out, reportCompareMerchant{Merchant: m.Merchant, Description: m.Description, Count: m.Count, Amount: formatCentsDecimal(m.Amount)})
+	}
+	return out
+}
+
+func (r reportCompareResponse) TableHeaders() []string {
+	return []string{"Group", "Previous", "Current", "Delta", "Delta %"}
+}
+
+func (r reportCompareResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Rows))
+	for i, row := range r.Rows {
+		pct := ""
+		if row.DeltaPercent != nil {
+			pct = fmt.Sprintf("%.1f%%", *row.DeltaPercent)
+		}
+		rows[i] = []string{row.Group, row.Previous, row.Current, row.Delta, pct}
+	}
+	return rows
+}
+
+type reportNetWorthResponse struct {
+	Ok              bool                  `json:"ok"`
+	Start           string                `json:"start"`
+	End             string                `json:"end"`
+	MainCurrency    string                `json:"main_currency"`
+	Points          []reportNetWorthPoint `json:"points"`
+	MissingAccounts []string              `json:"missing_accounts"`
+}
+
+type reportNetWorthPoint struct {
+	Month       string                  `json:"month"`
+	Date        string                  `json:"date"`
+	Total       string                  `json:"total"`
+	Unconverted int                     `json:"unconverted"`
+	Accounts    []reportNetWorthAccount `json:"accounts"`
+}
+
+type reportNetWorthAccount struct {
+	AccountID             int64   `json:"account_id"`
+	Account               string  `json:"account"`
+	Currency              string  `json:"currency"`
+	Balance               string  `json:"balance"`
+	BalanceInMainCurrency *string `json:"balance_in_main_currency"`
+}
+
+func (r reportNetWorthResponse) TableHeaders() []string {
+	return []string{"Month", "Date", "Total", "Accounts", "Unconverted"}
+}
+
+func (r reportNetWorthResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Points))
+	for i, p := range r.Points {
+		rows[i] = []string{p.Month, p.Date, p.Total, fmt.Sprint(len(p.Accounts)), fmt.Sprint(p.Unconverted)}
+	}
+	return rows
+}
+
+type reportSettleResponse struct {
+	Ok              bool                  `json:"ok"`
+	Start           string                `json:"start"`
+	End             string                `json:"end"`
+	MainCurrency    string                `json:"main_currency"`
+	Total           string                `json:"total"`
+	Owners          []reportSettleOwner   `json:"owners"`
+	Payments        []reportSettlePayment `json:"payments"`
+	UnassignedCount int                   `json:"unassigned_count"`
+	UnassignedTotal string                `json:"unassigned_total"`
+	Unconverted     int                   `json:"unconverted"`
+}
+
+type reportSettleOwner struct {
+	OwnerID int64  `json:"owner_id"`
+	Owner   string `json:"owner"`
+	Paid    string `json:"paid"`
+	Share   string `json:"share"`
+	Balance string `json:"balance"`
+}
+
+type reportSettlePayment struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+}
+
+func (r reportSettleResponse) TableHeaders() []string {
+	return []string{"Owner", "Paid", "Share", "Balance", "Settle"}
+}
+
+func (r reportSettleResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Owners))
+	for i, o := range r.Owners {
+		var settle []string
+		for _, p := range r.Payments {
+			if p.From == o.Owner {
+				settle = append(settle, fmt.Sprintf("pays %s %s", p.To, p.Amount))
+			}
+		}
+		rows[i] = []string{o.Owner, o.Paid, o.Share, o.Balance, strings.Join(settle, "; ")}
+	}
+	return rows
+}
+
+type reportAnomaliesResponse struct {
+	Ok           bool            `json:"ok"`
+	Month        string          `json:"month"`
+	MainCurrency string          `json:"main_currency"`
+	Count        int             `json:"count"`
+	Anomalies    []reportAnomaly `json:"anomalies"`
+	Unconverted  int             `json:"unconverted"`
+}
+
+type reportAnomaly struct {
+	Kind          string  `json:"kind"`
+	Direction     string  `json:"direction"`
+	CategoryID    *int64  `json:"category_id"`
+	Category      string  `json:"category"`
+	Merchant      string  `json:"merchant,omitempty"`
+	Description   string  `json:"description,omitempty"`
+	TransactionID *int64  `json:"transaction_id,omitempty"`
+	Date          string  `json:"date,omitempty"`
+	Amount        string  `json:"amount"`
+	Median        string  `json:"median"`
+	MAD           string  `json:"mad"`
+	Score         float64 `json:"score"`
+	HistoryCount  int     `json:"history_count"`
+}
+
+func (r reportAnomaliesResponse) TableHeaders() []string {
+	return []string{"Kind", "Category", "Description", "Date", "Amount", "Median", "Score"}
+}
+
+func (r reportAnomaliesResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Anomalies))
+	for i, a := range r.Anomalies {
+		rows[i] = []string{a.Kind, a.Category, a.Description, a.Date, a.Amount, a.Median, fmt.Sprintf("%.2f", a.Score)}
+	}
+	return rows
+}
+
+type reportMerchantsResponse struct {
+	Ok              bool             `json:"ok"`
+	Start           string           `json:"start"`
+	End             string           `json:"end"`
+	MainCurrency    string           `json:"main_currency"`
+	Sort            string           `json:"sort"`
+	Count           int              `json:"count"`
+	Merchants       []reportMerchant `json:"merchants"`
+	UnassignedCount int              `json:"unassigned_count"`
+	UnassignedSpent string           `json:"unassigned_spent"`
+	Unconverted     int              `json:"unconverted"`
+}
+
+type reportMerchant struct {
+	MerchantID     int64   `json:"merchant_id"`
+	Merchant       string  `json:"merchant"`
+	Spent          string  `json:"spent"`
+	Visits         int     `json:"visits"`
+	VisitsPerMonth float64 `json:"visits_per_month"`
+	AverageTicket  string  `json:"average_ticket"`
+	FirstDate      string  `json:"first_date"`
+	LastDate       string  `json:"last_date"`
+}
+
+func (r reportMerchantsResponse) TableHeaders() []string {
+	return []string{"Merchant", "Spent", "Visits", "Per Month", "Average", "Last"}
+}
+
+func (r reportMerchantsResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Merchants))
+	for i, m := range r.Merchants {
+		rows[i] = []string{m.Merchant, m.Spent, fmt.Sprint(m.Visits), fmt.Sprintf("%.2f", m.VisitsPerMonth), m.AverageTicket, m.LastDate}
+	}
+	return rows
+}
+
+func handleReport(svc *cashmop.Service, args []string) commandResult {
+	if len(args) == 0 {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Missing report subcommand (summary, compare, net-worth, settle, anomalies, merchants).",
+			Hint:    "Use \"cashmop report summary\" or \"cashmop report compare\".",
+		})}
+	}
+
+	switch args[0] {
+	case "summary":
+		return handleReportSummary(svc, args[1:])
+	case "compare":
+		return handleReportCompare(svc, args[1:])
+	case "net-worth":
+		return handleReportNetWorth(svc, args[1:])
+	case "settle":
+		return handleReportSettle(svc, args[1:])
+	case "anomalies":
+		return handleReportAnomalies(svc, args[1:])
+	case "merchants":
+		return handleReportMerchants(svc, args[1:])
+	default:
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Unknown report subcommand.",
+			Hint:    "Use summary, compare, net-worth, settle, anomalies, or merchants.",
+		})}
+	}
+}
+
+func handleReportSummary(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("report summary")
+	var start string
+	var end string
+	var period string
+	var by string
+	var uncategorized bool
+	var categoryIDs stringSliceFlag
+	var tags stringSliceFlag
+	var untagged bool
+	var merchantIDs stringSliceFlag
+	var noMerchant bool
+	var includeTransfers bool
+
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	fs.StringVar(&period, "period", database.SummaryPeriodMonth, "")
+	fs.StringVar(&by, "by", database.SummaryGroupCategory, "")
+	fs.BoolVar(&uncategorized, "uncategorized", false, "")
+	fs.Var(&categoryIDs, "category-ids", "")
+	fs.Var(&tags, "tags", "")
+	fs.BoolVar(&untagged, "untagged", false, "")
+	fs.Var(&merchantIDs, "merchant-ids", "")
+	fs.BoolVar(&noMerchant, "no-merchant", false, "")
+	fs.BoolVar(&includeTransfers, "include-transfers", false, "")
+	if ok, res := fs.parse(args, "report"); !ok {
+		return res
+	}
+
+	start, end, cErr := validateReportRange(start, end)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	catIDs, cErr := parseCategoryIDs(categoryIDs, uncategorized)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	tagIDs, cErr := resolveTagFilter(svc, tags, untagged)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	mIDs, cErr := parseMerchantIDs(merchantIDs, noMerchant)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	summary, err := svc.GetSummary(database.SummaryOptions{
+		StartDate:        start,
+		EndDate:          end,
+		Period:           period,
+		GroupBy:          by,
+		CategoryIDs:      catIDs,
+		TagIDs:           tagIDs,
+		MerchantIDs:      mIDs,
+		IncludeTransfers: includeTransfers,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrInvalidSummaryPeriod):
+			return commandResult{Err: validationError(ErrorDetail{Field: "period", Message: "Invalid period.", Hint: "Use month, quarter, or year."})}
+		case errors.Is(err, database.ErrInvalidSummaryGroup):
+			return commandResult{Err: validationError(ErrorDetail{Field: "by", Message: "Invalid grouping.", Hint: "Use category, owner, account, tag, or merchant."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	rows := make([]reportSummaryRow, 0, len(summary.Rows))
+	for _, row := range summary.Rows {
+		rows = append(rows, reportSummaryRow{
+			Period:       row.Period,
+			GroupID:      row.GroupID,
+			Group:        row.Group,
+			Count:        row.Count,
+			reportTotals: newReportTotals(row.KindTotals),
+		})
+	}
+	return commandResult{Response: reportSummaryResponse{
+		Ok:           true,
+		Start:        start,
+		End:          end,
+		Period:       summary.Period,
+		By:           summary.GroupBy,
+		MainCurrency: summary.MainCurrency,
+		Rows:         rows,
+		Totals:       newReportTotals(summary.Totals),
+	}}
+}
+
+func handleReportCompare(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("report compare")
+	var start string
+	var end string
+	var previousStart string
+	var previousEnd string
+	var vs string
+	var by string
+	var uncategorized bool
+	var categoryIDs stringSliceFlag
+	var tags stringSliceFlag
+	var untagged bool
+	var merchantIDs stringSliceFlag
+	var noMerchant bool
+
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	fs.StringVar(&previousStart, "previous-start", "", "")
+	fs.StringVar(&previousEnd, "previous-end", "", "")
+	fs.StringVar(&vs, "vs", "", "")
+	fs.StringVar(&by, "by", database.SummaryGroupCategory, "")
+	fs.BoolVar(&uncategorized, "uncategorized", false, "")
+	fs.Var(&categoryIDs, "category-ids", "")
+	fs.Var(&tags, "tags", "")
+	fs.BoolVar(&untagged, "untagged", false, "")
+	fs.Var(&merchantIDs, "merchant-ids", "")
+	fs.BoolVar(&noMerchant, "no-merchant", false, "")
+	if ok, res := fs.parse(args, "report"); !ok {
+		return res
+	}
+
+	// The current range defaults to the month so far.
+	if start == "" && end == "" {
+		now := time.Now()
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(dateLayout)
+		end = now.Format(dateLayout)
+	}
+	start, end, cErr := validateReportRange(start, end)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	if previousStart != "" || previousEnd != "" {
+		if vs != "" {
+			return commandResult{Err: validationError(ErrorDetail{Field: "vs", Message: "--vs cannot be combined with --previous-start/--previous-end.", Hint: "Use either --vs or an explicit previous range."})}
+		}
+		if previousStart == "" || previousEnd == "" {
+			return commandResult{Err: validationError(ErrorDetail{Field: "previous-start", Message: "--previous-start requires --previous-end.", Hint: "Provide both --previous-start and --previous-end."})}
+		}
+		ps, err := parseDate(previousStart)
+		if err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "previous-start", Message: "Invalid previous start date.", Hint: "Use YYYY-MM-DD."})}
+		}
+		pe, err := parseDate(previousEnd)
+		if err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "previous-end", Message: "Invalid previous end date.", Hint: "Use YYYY-MM-DD."})}
+		}
+		if pe.Before(ps) {
+			return commandResult{Err: validationError(ErrorDetail{Field: "previous-end", Message: "--previous-end must be on or after --previous-start.", Hint: "Ensure --previous-end is on or after --previous-start."})}
+		}
+	} else {
+		startDate, _ := parseDate(start)
+		endDate, _ := parseDate(end)
+		var ps, pe time.Time
+		switch vs {
+		case "", "previous":
+			ps, pe = previousRange(startDate, endDate)
+		case "year":
+			ps, pe = shiftRangeMonths(startDate, endDate, -12)
+		default:
+			return commandResult{Err: validationError(ErrorDetail{Field: "vs", Message: "Invalid --vs.", Hint: "Use previous or year."})}
+		}
+		previousStart, previousEnd = ps.Format(dateLayout), pe.Format(dateLayout)
+	}
+
+	catIDs, cErr := parseCategoryIDs(categoryIDs, uncategorized)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	tagIDs, cErr := resolveTagFilter(svc, tags, untagged)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	mIDs, cErr := parseMerchantIDs(merchantIDs, noMerchant)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	comparison, err := svc.Compare(cashmop.ComparisonOptions{
+		CurrentStart:  start,
+		CurrentEnd:    end,
+		PreviousStart: previousStart,
+		PreviousEnd:   previousEnd,
+		GroupBy:       by,
+		CategoryIDs:   catIDs,
+		TagIDs:        tagIDs,
+		MerchantIDs:   mIDs,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidSummaryGroup) {
+			return commandResult{Err: validationError(ErrorDetail{Field: "by", Message: "Invalid grouping.", Hint: "Use category, owner, account, tag, or merchant."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	resp := reportCompareResponse{
+		Ok:                   true,
+		By:                   comparison.GroupBy,
+		MainCurrency:         comparison.MainCurrency,
+		Current:              reportCompareRange{Start: start, End: end, Totals: newReportTotals(comparison.Current.Totals)},
+		Previous:             reportCompareRange{Start: previousStart, End: previousEnd, Totals: newReportTotals(comparison.Previous.Totals)},
+		Rows:                 make([]reportCompareRow, 0, len(comparison.Rows)),
+		NewMerchants:         newReportCompareMerchants(comparison.NewMerchants),
+		DisappearedMerchants: newReportCompareMerchants(comparison.DisappearedMerchants),
+	}
+	for _, r := range comparison.Rows {
+		resp.Rows = append(resp.Rows, reportCompareRow{
+			GroupID:       r.GroupID,
+			Group:         r.Group,
+			Current:       formatCentsDecimal(r.Current),
+			Previous:      formatCentsDecimal(r.Previous),
+			Delta:         formatCentsDecimal(r.Delta),
+			DeltaPercent:  r.DeltaPercent,
+			CurrentCount:  r.CurrentCount,
+			PreviousCount: r.PreviousCount,
+		})
+	}
+	return commandResult{Response: resp}
+}
+
+func handleReportNetWorth(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("report net-worth")
+	var start string
+	var end string
+
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	if ok, res := fs.parse(args, "report"); !ok {
+		return res
+	}
+
+	now := time.Now()
+	start, end, cErr := validateMonthRange(start, end, now.AddDate(0, -11, 0).Format(monthLayout), now.Format(monthLayout))
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	netWorth, err := svc.GetNetWorth(start, end)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	points := make([]reportNetWorthPoint, 0, len(netWorth.Points))
+	for _, p := range netWorth.Points {
+		accounts := make([]reportNetWorthAccount, 0, len(p.Accounts))
+		for _, a := range p.Accounts {
+			account := reportNetWorthAccount{
+				AccountID: a.AccountID,
+				Account:   a.Name,
+				Currency:  a.Currency,
+				Balance:   formatCentsDecimal(a.Balance),
+			}
+			if a.BalanceInMainCurrency != nil {
+				converted := formatCentsDecimal(*a.BalanceInMainCurrency)
+				account.BalanceInMainCurrency = &converted
+			}
+			accounts = append(accounts, account)
+		}
+		points = append(points, reportNetWorthPoint{
+			Month:       p.Month,
+			Date:        p.Date,
+			Total:       formatCentsDecimal(p.Total),
+			Unconverted: p.Unconverted,
+			Accounts:    accounts,
+		})
+	}
+	return commandResult{Response: reportNetWorthResponse{
+		Ok:              true,
+		Start:           start,
+		End:             end,
+		MainCurrency:    netWorth.MainCurrency,
+		Points:          points,
+		MissingAccounts: netWorth.MissingAccounts,
+	}}
+}
+
+func handleReportSettle(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("report settle")
+	var start string
+	var end string
+	var shares stringSliceFlag
+	var categoryShares stringSliceFlag
+	var uncategorized bool
+	var categoryIDs stringSliceFlag
+	var tags stringSliceFlag
+	var untagged bool
+	var merchantIDs stringSliceFlag
+	var noMerchant bool
+
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	fs.Var(&shares, "share", "")
+	fs.Var(&categoryShares, "category-share", "")
+	fs.BoolVar(&uncategorized, "uncategorized", false, "")
+	fs.Var(&categoryIDs, "category-ids", "")
+	fs.Var(&tags, "tags", "")
+	fs.BoolVar(&untagged, "untagged", false, "")
+	fs.Var(&merchantIDs, "merchant-ids", "")
+	fs.BoolVar(&noMerchant, "no-merchant", false, "")
+	if ok, res := fs.parse(args, "report"); !ok {
+		return res
+	}
+
+	start, end, cErr := validateReportRange(start, end)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	catIDs, cErr := parseCategoryIDs(categoryIDs, uncategorized)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	tagIDs, cErr := resolveTagFilter(svc, tags, untagged)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	mIDs, cErr := parseMerchantIDs(merchantIDs, noMerchant)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	owners, err := svc.GetUserMap()
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	opts := cashmop.SettlementOptions{StartDate: start, EndDate: end, CategoryIDs: catIDs, TagIDs: tagIDs, MerchantIDs: mIDs}
+	if opts.Shares, cErr = parseOwnerShares("share", shares.split(), owners); cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	for _, raw := range categoryShares.values {
+		idPart, sharesPart, ok := strings.Cut(raw, ":")
+		id, err := strconv.ParseInt(strings.TrimSpace(idPart), 10, 64)
+		if !ok || err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "category-share", Message: fmt.Sprintf("Invalid category share: %s", raw), Hint: "Use <category id>:<owner>=<percent>[,<owner>=<percent>], e.g. 12:Alex=100."})}
+		}
+		cs := cashmop.CategoryShares{CategoryID: id}
+		if cs.Shares, cErr = parseOwnerShares("category-share", strings.Split(sharesPart, ","), owners); cErr != nil {
+			return commandResult{Err: cErr}
+		}
+		opts.CategoryShares = append(opts.CategoryShares, cs)
+	}
+
+	settlement, err := svc.Settle(opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, cashmop.ErrInvalidSettlementShares):
+			return commandResult{Err: validationError(ErrorDetail{Field: "share", Message: "Shares must add up to 100.", Hint: "Give each owner once, e.g. --share Alex=60,Sam=40."})}
+		case errors.Is(err, cashmop.ErrUnknownSettlementCategory):
+			return commandResult{Err: validationError(ErrorDetail{Field: "category-share", Message: "Category not found.", Hint: "Use a category ID from \"cashmop categories list\"."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	resp := reportSettleResponse{
+		Ok:              true,
+		Start:           start,
+		End:             end,
+		MainCurrency:    settlement.MainCurrency,
+		Total:           formatCentsDecimal(settlement.Total),
+		Owners:          make([]reportSettleOwner, 0, len(settlement.Owners)),
+		Payments:        make([]reportSettlePayment, 0, len(settlement.Payments)),
+		UnassignedCount: settlement.UnassignedCount,
+		UnassignedTotal: formatCentsDecimal(settlement.UnassignedTotal),
+		Unconverted:     settlement.Unconverted,
+	}
+	for _, o := range settlement.Owners {
+		resp.Owners = append(resp.Owners, reportSettleOwner{
+			OwnerID: o.OwnerID,
+			Owner:   o.Name,
+			Paid:    formatCentsDecimal(o.Paid),
+			Share:   formatCentsDecimal(o.Share),
+			Balance: formatCentsDecimal(o.Balance),
+		})
+	}
+	for _, p := range settlement.Payments {
+		resp.Payments = append(resp.Payments, reportSettlePayment{From: p.From, To: p.To, Amount: formatCentsDecimal(p.Amount)})
+	}
+	return commandResult{Response: resp}
+}
+
+func handleReportAnomalies(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("report anomalies")
+	var month string
+	fs.StringVar(&month, "month", "", "")
+	if ok, res := fs.parse(args, "report"); !ok {
+		return res
+	}
+
+	if month == "" {
+		month = time.Now().Format(monthLayout)
+	} else if _, err := parseMonth(month); err != nil {
+		return commandResult{Err: validationError(ErrorDetail{Field: "month", Message: "Invalid month.", Hint: "Use YYYY-MM."})}
+	}
+
+	report, err := svc.DetectAnomalies(month)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	resp := reportAnomaliesResponse{
+		Ok:           true,
+		Month:        report.Month,
+		MainCurrency: report.MainCurrency,
+		Count:        len(report.Anomalies),
+		Anomalies:    make([]reportAnomaly, 0, len(report.Anomalies)),
+		Unconverted:  report.Unconverted,
+	}
+	for _, a := range report.Anomalies {
+		resp.Anomalies = append(resp.Anomalies, reportAnomaly{
+			Kind:          a.Kind,
+			Direction:     a.Direction,
+			CategoryID:    a.CategoryID,
+			Category:      a.CategoryName,
+			Merchant:      a.Merchant,
+			Description:   a.Description,
+			TransactionID: a.TransactionID,
+			Date:          a.Date,
+			Amount:        formatCentsDecimal(a.Amount),
+			Median:        formatCentsDecimal(a.Median),
+			MAD:           formatCentsDecimal(a.MAD),
+			Score:         a.Score,
+			HistoryCount:  a.HistoryCount,
+		})
+	}
+	return commandResult{Response: resp}
+}
+
+func handleReportMerchants(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("report merchants")
+	var start string
+	var end string
+	var sortBy string
+	var limit int
+	var uncategorized bool
+	var categoryIDs stringSliceFlag
+	var tags stringSliceFlag
+	var untagged bool
+
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	fs.StringVar(&sortBy, "sort", cashmop.MerchantSortSpend, "")
+	fs.IntVar(&limit, "limit", 20, "")
+	fs.BoolVar(&uncategorized, "uncategorized", false, "")
+	fs.Var(&categoryIDs, "category-ids", "")
+	fs.Var(&tags, "tags", "")
+	fs.BoolVar(&untagged, "untagged", false, "")
+	if ok, res := fs.parse(args, "report"); !ok {
+		return res
+	}
+
+	start, end, cErr := validateReportRange(start, end)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	if limit < 0 {
+		return commandResult{Err: validationError(ErrorDetail{Field: "limit", Message: "--limit cannot be negative.", Hint: "Provide --limit <n>, or 0 for all merchants."})}
+	}
+	catIDs, cErr := parseCategoryIDs(categoryIDs, uncategorized)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+	tagIDs, cErr := resolveTagFilter(svc, tags, untagged)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	report, err := svc.GetMerchantReport(cashmop.MerchantReportOptions{
+		StartDate:   start,
+		EndDate:     end,
+		SortBy:      strings.ToLower(strings.TrimSpace(sortBy)),
+		Limit:       limit,
+		CategoryIDs: catIDs,
+		TagIDs:      tagIDs,
+	})
+	if err != nil {
+		if errors.Is(err, cashmop.ErrInvalidMerchantSort) {
+			return commandResult{Err: validationError(ErrorDetail{Field: "sort", Message: "Invalid sort.", Hint: "Use spend, visits, or average."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	resp := reportMerchantsResponse{
+		Ok:              true,
+		Start:           report.StartDate,
+		End:             report.EndDate,
+		MainCurrency:    report.MainCurrency,
+		Sort:            report.SortBy,
+		Count:           len(report.Merchants),
+		Merchants:       make([]reportMerchant, 0, len(report.Merchants)),
+		UnassignedCount: report.UnassignedCount,
+		UnassignedSpent: formatCentsDecimal(report.UnassignedSpent),
+		Unconverted:     report.Unconverted,
+	}
+	for _, m := range report.Merchants {
+		resp.Merchants = append(resp.Merchants, reportMerchant{
+			MerchantID:     m.MerchantID,
+			Merchant:       m.Name,
+			Spent:          formatCentsDecimal(m.Spent),
+			Visits:         m.Visits,
+			VisitsPerMonth: m.VisitsPerMonth,
+			AverageTicket:  formatCentsDecimal(m.AverageTicket),
+			FirstDate:      m.FirstDate,
+			LastDate:       m.LastDate,
+		})
+	}
+	return commandResult{Response: resp}
+}
+
+// parseOwnerShares parses "<owner>=<percent>" items, matching owners by name.
+func parseOwnerShares(field string, items []string, owners map[string]int64) ([]cashmop.OwnerShare, *cliError) {
+	var shares []cashmop.OwnerShare
+	for _, item := range items {
+		name, pct, ok := strings.Cut(item, "=")
+		percent, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if !ok || err != nil {
+			return nil, validationError(ErrorDetail{Field: field, Message: fmt.Sprintf("Invalid share: %s", item), Hint: "Use <owner>=<percent>, e.g. Alex=60."})
+		}
+		id, ok := owners[strings.TrimSpace(name)]
+		if !ok {
+			return nil, validationError(ErrorDetail{Field: field, Message: fmt.Sprintf("Unknown owner: %s", strings.TrimSpace(name)), Hint: "Owners are set on transactions with --owner; check the spelling."})
+		}
+		shares = append(shares, cashmop.OwnerShare{OwnerID: id, Percent: percent})
+	}
+	return shares, nil
+}
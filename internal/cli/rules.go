@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/default-anton/cashmop/internal/cashmop"
@@ -13,7 +15,7 @@ type ruleListResponse struct {
 }
 
 func (r ruleListResponse) TableHeaders() []string {
-	return []string{"ID", "Type", "Value", "Min", "Max", "Category"}
+	return []string{"ID", "Type", "Value", "Min", "Max", "Category", "Hits", "Last Match"}
 }
 
 func (r ruleListResponse) ToTable() [][]string {
@@ -27,6 +29,10 @@ func (r ruleListResponse) ToTable() [][]string {
 		if item.AmountMax != nil {
 			max = *item.AmountMax
 		}
+		lastMatch := "-"
+		if item.LastMatchedDate != nil {
+			lastMatch = *item.LastMatchedDate
+		}
 		rows[i] = []string{
 			fmt.Sprint(item.ID),
 			item.MatchType,
@@ -34,19 +40,23 @@ func (r ruleListResponse) ToTable() [][]string {
 			min,
 			max,
 			item.CategoryName,
+			fmt.Sprint(item.HitCount),
+			lastMatch,
 		}
 	}
 	return rows
 }
 
 type ruleListRule struct {
-	ID           int64   `json:"id"`
-	MatchType    string  `json:"match_type"`
-	MatchValue   string  `json:"match_value"`
-	AmountMin    *string `json:"amount_min"`
-	AmountMax    *string `json:"amount_max"`
-	CategoryID   int64   `json:"category_id"`
-	CategoryName string  `json:"category_name"`
+	ID              int64   `json:"id"`
+	MatchType       string  `json:"match_type"`
+	MatchValue      string  `json:"match_value"`
+	AmountMin       *string `json:"amount_min"`
+	AmountMax       *string `json:"amount_max"`
+	CategoryID      int64   `json:"category_id"`
+	CategoryName    string  `json:"category_name"`
+	HitCount        int64   `json:"hit_count"`
+	LastMatchedDate *string `json:"last_matched_date"`
 }
 
 type rulePreviewResponse struct {
@@ -78,37 +88,183 @@ type ruleUpdateResponse struct {
 	AppliedCount      int   `json:"applied_count"`
 }
 
+type ruleSuggestResponse struct {
+	Ok    bool                `json:"ok"`
+	Items []ruleSuggestionOut `json:"items"`
+}
+
+func (r ruleSuggestResponse) TableHeaders() []string {
+	return []string{"Type", "Value", "Category", "Support", "Precision", "Uncategorized"}
+}
+
+func (r ruleSuggestResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Items))
+	for i, item := range r.Items {
+		rows[i] = []string{
+			item.MatchType,
+			item.MatchValue,
+			item.CategoryName,
+			fmt.Sprintf("%d/%d", item.Support, item.Matches),
+			fmt.Sprintf("%.0f%%", item.Precision*100),
+			fmt.Sprint(item.Preview.Count),
+		}
+	}
+	return rows
+}
+
+type ruleSuggestionOut struct {
+	MatchType    string                `json:"match_type"`
+	MatchValue   string                `json:"match_value"`
+	CategoryID   int64                 `json:"category_id"`
+	CategoryName string                `json:"category_name"`
+	Support      int                   `json:"support"`
+	Matches      int                   `json:"matches"`
+	Precision    float64               `json:"precision"`
+	Preview      ruleSuggestionPreview `json:"preview"`
+}
+
+type ruleSuggestionPreview struct {
+	Count        int                      `json:"count"`
+	Transactions []rulePreviewTransaction `json:"transactions"`
+}
+
 type ruleDeleteResponse struct {
 	Ok                 bool  `json:"ok"`
 	RuleID             int64 `json:"rule_id"`
 	UncategorizedCount int   `json:"uncategorized_count"`
 }
 
+type ruleExportResponse struct {
+	Ok    bool   `json:"ok"`
+	File  string `json:"file"`
+	Count int    `json:"count"`
+}
+
+type ruleImportResponse struct {
+	Ok                bool                   `json:"ok"`
+	Mode              string                 `json:"mode"`
+	DryRun            bool                   `json:"dry_run"`
+	Total             int                    `json:"total"`
+	CreatedCount      int                    `json:"created_count"`
+	DeletedCount      int                    `json:"deleted_count"`
+	CreatedCategories []string               `json:"created_categories"`
+	Duplicates        []cashmop.RulesetIssue `json:"duplicates"`
+	Conflicts         []cashmop.RulesetIssue `json:"conflicts"`
+	AppliedCount      int                    `json:"applied_count"`
+}
+
+type ruleTestResponse struct {
+	Ok      bool                     `json:"ok"`
+	Total   int                      `json:"total"`
+	Passed  int                      `json:"passed"`
+	Failed  int                      `json:"failed"`
+	Results []cashmop.RuleCaseResult `json:"results"`
+}
+
+func (r ruleTestResponse) TableHeaders() []string {
+	return []string{"#", "Result", "Description", "Amount", "Expected", "Actual", "Rule"}
+}
+
+func (r ruleTestResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Results))
+	for i, item := range r.Results {
+		status := "PASS"
+		if !item.Passed {
+			status = "FAIL"
+		}
+		rule := "-"
+		if item.RuleID != nil {
+			rule = fmt.Sprintf("#%d %s %q", *item.RuleID, item.MatchType, item.MatchValue)
+		}
+		rows[i] = []string{
+			fmt.Sprint(item.Index),
+			status,
+			item.Description,
+			item.Amount,
+			item.Expected,
+			item.Actual,
+			rule,
+		}
+	}
+	return rows
+}
+
+type ruleApplyResponse struct {
+	Ok           bool                       `json:"ok"`
+	BatchID      *int64                     `json:"batch_id"`
+	Overwrite    string                     `json:"overwrite"`
+	DryRun       bool                       `json:"dry_run"`
+	ChangedCount int                        `json:"changed_count"`
+	Changes      []database.RuleApplyChange `json:"changes"`
+}
+
+func (r ruleApplyResponse) TableHeaders() []string {
+	return []string{"Tx", "Date", "Description", "Old", "New", "Rule"}
+}
+
+func (r ruleApplyResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Changes))
+	for i, c := range r.Changes {
+		old := "-"
+		if c.OldCategoryID != nil {
+			old = fmt.Sprintf("%s (%s)", c.OldCategoryName, c.OldSource)
+		}
+		rows[i] = []string{
+			fmt.Sprint(c.TransactionID),
+			c.Date,
+			c.Description,
+			old,
+			c.NewCategoryName,
+			fmt.Sprint(c.RuleID),
+		}
+	}
+	return rows
+}
+
+type ruleApplyUndoResponse struct {
+	Ok       bool  `json:"ok"`
+	BatchID  int64 `json:"batch_id"`
+	Restored int   `json:"restored"`
+	Skipped  int   `json:"skipped"`
+}
+
 func handleRules(svc *cashmop.Service, args []string) commandResult {
 	if len(args) == 0 {
 		return commandResult{Err: validationError(ErrorDetail{
 			Field:   "subcommand",
-			Message: "Missing rules subcommand (list, preview, create, update, delete).",
-			Hint:    "Use \"cashmop rules list\", \"cashmop rules preview\", \"cashmop rules create\", \"cashmop rules update\", or \"cashmop rules delete\".",
+			Message: "Missing rules subcommand (list, stale, preview, suggest, create, update, delete, export, import, test, apply).",
+			Hint:    "Use \"cashmop rules list\", \"cashmop rules stale\", \"cashmop rules preview\", \"cashmop rules suggest\", \"cashmop rules create\", \"cashmop rules update\", \"cashmop rules delete\", \"cashmop rules export\", \"cashmop rules import\", \"cashmop rules test\", or \"cashmop rules apply\".",
 		})}
 	}
 
 	switch args[0] {
 	case "list":
 		return handleRulesList(svc, args[1:])
+	case "stale":
+		return handleRulesStale(svc, args[1:])
 	case "preview":
 		return handleRulesPreview(svc, args[1:])
+	case "suggest":
+		return handleRulesSuggest(svc, args[1:])
 	case "create":
 		return handleRulesCreate(svc, args[1:])
 	case "update":
 		return handleRulesUpdate(svc, args[1:])
 	case "delete":
 		return handleRulesDelete(svc, args[1:])
+	case "export":
+		return handleRulesExport(svc, args[1:])
+	case "import":
+		return handleRulesImport(svc, args[1:])
+	case "test":
+		return handleRulesTest(svc, args[1:])
+	case "apply":
+		return handleRulesApply(svc, args[1:])
 	default:
 		return commandResult{Err: validationError(ErrorDetail{
 			Field:   "subcommand",
 			Message: "Unknown rules subcommand.",
-			Hint:    "Use \"cashmop rules list\", \"cashmop rules preview\", \"cashmop rules create\", \"cashmop rules update\", or \"cashmop rules delete\".",
+			Hint:    "Use \"cashmop rules list\", \"cashmop rules stale\", \"cashmop rules preview\", \"cashmop rules suggest\", \"cashmop rules create\", \"cashmop rules update\", \"cashmop rules delete\", \"cashmop rules export\", \"cashmop rules import\", \"cashmop rules test\", or \"cashmop rules apply\".",
 		})}
 	}
 }
@@ -124,6 +280,33 @@ func handleRulesList(svc *cashmop.Service, args []string) commandResult {
 		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
 	}
 
+	return commandResult{Response: toRuleListResponse(rules)}
+}
+
+func handleRulesStale(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("rules stale")
+	var since string
+	fs.StringVar(&since, "since", "", "")
+	if ok, res := fs.parse(args, "rules"); !ok {
+		return res
+	}
+
+	if since == "" {
+		return commandResult{Err: validationError(requiredFlagError("since", "Provide --since YYYY-MM-DD."))}
+	}
+	if _, err := parseDate(since); err != nil {
+		return commandResult{Err: validationError(ErrorDetail{Field: "since", Message: "Invalid since date.", Hint: "Use YYYY-MM-DD."})}
+	}
+
+	rules, err := svc.GetStaleRules(since)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	return commandResult{Response: toRuleListResponse(rules)}
+}
+
+func toRuleListResponse(rules []database.CategorizationRule) ruleListResponse {
 	out := make([]ruleListRule, 0, len(rules))
 	for _, r := range rules {
 		var min *string
@@ -136,18 +319,24 @@ func handleRulesList(svc *cashmop.Service, args []string) commandResult {
 			s := formatCentsDecimal(*r.AmountMax)
 			max = &s
 		}
+		var lastMatched *string
+		if r.LastMatchedDate != "" {
+			s := r.LastMatchedDate
+			lastMatched = &s
+		}
 		out = append(out, ruleListRule{
-			ID:           r.ID,
-			MatchType:    r.MatchType,
-			MatchValue:   r.MatchValue,
-			AmountMin:    min,
-			AmountMax:    max,
-			CategoryID:   r.CategoryID,
-			CategoryName: r.CategoryName,
+			ID:              r.ID,
+			MatchType:       r.MatchType,
+			MatchValue:      r.MatchValue,
+			AmountMin:       min,
+			AmountMax:       max,
+			CategoryID:      r.CategoryID,
+			CategoryName:    r.CategoryName,
+			HitCount:        r.HitCount,
+			LastMatchedDate: lastMatched,
 		})
 	}
-
-	return commandResult{Response: ruleListResponse{Ok: true, Items: out}}
+	return ruleListResponse{Ok: true, Items: out}
 }
 
 func handleRulesPreview(svc *cashmop.Service, args []string) commandResult {
@@ -197,6 +386,10 @@ func handleRulesPreview(svc *cashmop.Service, args []string) commandResult {
 		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
 	}
 
+	return commandResult{Response: toRulePreviewResponse(preview)}
+}
+
+func toRulePreviewResponse(preview database.RuleMatchPreview) rulePreviewResponse {
 	var minS, maxS *string
 	if preview.MinAmount != nil {
 		s := formatCentsDecimal(*preview.MinAmount)
@@ -218,13 +411,69 @@ func handleRulesPreview(svc *cashmop.Service, args []string) commandResult {
 		})
 	}
 
-	return commandResult{Response: rulePreviewResponse{
+	return rulePreviewResponse{
 		Ok:           true,
 		Count:        preview.Count,
 		MinAmount:    minS,
 		MaxAmount:    maxS,
 		Transactions: txs,
-	}}
+	}
+}
+
+func handleRulesSuggest(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("rules suggest")
+	var minSupport int
+	var minPrecision float64
+	var limit int
+	var previewLimit int
+	fs.IntVar(&minSupport, "min-support", 3, "")
+	fs.Float64Var(&minPrecision, "min-precision", 0.9, "")
+	fs.IntVar(&limit, "limit", 20, "")
+	fs.IntVar(&previewLimit, "preview-limit", 5, "")
+	if ok, res := fs.parse(args, "rules"); !ok {
+		return res
+	}
+
+	if minSupport < 1 {
+		return commandResult{Err: validationError(ErrorDetail{Field: "min-support", Message: "--min-support must be at least 1.", Hint: "Provide --min-support <n> with n >= 1."})}
+	}
+	if minPrecision <= 0 || minPrecision > 1 {
+		return commandResult{Err: validationError(ErrorDetail{Field: "min-precision", Message: "--min-precision must be between 0 and 1.", Hint: "Use a value like 0.9."})}
+	}
+	if limit < 1 || previewLimit < 1 {
+		field := "limit"
+		if limit >= 1 {
+			field = "preview-limit"
+		}
+		return commandResult{Err: validationError(ErrorDetail{Field: field, Message: fmt.Sprintf("--%s must be at least 1.", field), Hint: fmt.Sprintf("Provide --%s <n> with n >= 1.", field)})}
+	}
+
+	suggestions, err := svc.SuggestCategorizationRules(cashmop.RuleSuggestionOptions{
+		MinSupport:   minSupport,
+		MinPrecision: minPrecision,
+		Limit:        limit,
+		PreviewLimit: previewLimit,
+	})
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	out := make([]ruleSuggestionOut, 0, len(suggestions))
+	for _, sg := range suggestions {
+		preview := toRulePreviewResponse(sg.Preview)
+		out = append(out, ruleSuggestionOut{
+			MatchType:    sg.MatchType,
+			MatchValue:   sg.MatchValue,
+			CategoryID:   sg.CategoryID,
+			CategoryName: sg.CategoryName,
+			Support:      sg.Support,
+			Matches:      sg.Matches,
+			Precision:    sg.Precision,
+			Preview:      ruleSuggestionPreview{Count: preview.Count, Transactions: preview.Transactions},
+		})
+	}
+
+	return commandResult{Response: ruleSuggestResponse{Ok: true, Items: out}}
 }
 
 func handleRulesCreate(svc *cashmop.Service, args []string) commandResult {
@@ -388,3 +637,169 @@ func handleRulesDelete(svc *cashmop.Service, args []string) commandResult {
 		UncategorizedCount: uncategorizedCount,
 	}}
 }
+
+func handleRulesExport(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("rules export")
+	var file string
+	fs.StringVar(&file, "file", "", "")
+	if ok, res := fs.parse(args, "rules"); !ok {
+		return res
+	}
+
+	if file == "" {
+		return commandResult{Err: validationError(requiredFlagError("file", "Provide --file <path> (.yaml, .yml, or .json)."))}
+	}
+
+	count, err := svc.ExportRuleset(file)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	return commandResult{Response: ruleExportResponse{Ok: true, File: file, Count: count}}
+}
+
+func handleRulesImport(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("rules import")
+	var file string
+	var mode string
+	var dryRun bool
+	fs.StringVar(&file, "file", "", "")
+	fs.StringVar(&mode, "mode", cashmop.RulesetModeMerge, "")
+	fs.BoolVar(&dryRun, "dry-run", false, "")
+	if ok, res := fs.parse(args, "rules"); !ok {
+		return res
+	}
+
+	if file == "" {
+		return commandResult{Err: validationError(requiredFlagError("file", "Provide --file <path> (.yaml, .yml, or .json)."))}
+	}
+	if mode != cashmop.RulesetModeMerge && mode != cashmop.RulesetModeReplace {
+		return commandResult{Err: validationError(ErrorDetail{Field: "mode", Message: "Invalid mode.", Hint: "Use --mode merge or --mode replace."})}
+	}
+
+	result, err := svc.ImportRuleset(file, cashmop.RulesetImportOptions{Mode: mode, DryRun: dryRun})
+	if err != nil {
+		if errors.Is(err, cashmop.ErrInvalidRuleset) {
+			return commandResult{Err: validationError(ErrorDetail{Field: "file", Message: err.Error(), Hint: "Use the format written by \"cashmop rules export\"."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	return commandResult{Response: ruleImportResponse{
+		Ok:                true,
+		Mode:              result.Mode,
+		DryRun:            result.DryRun,
+		Total:             result.Total,
+		CreatedCount:      result.CreatedCount,
+		DeletedCount:      result.DeletedCount,
+		CreatedCategories: result.CreatedCategories,
+		Duplicates:        result.Duplicates,
+		Conflicts:         result.Conflicts,
+		AppliedCount:      result.AppliedCount,
+	}}
+}
+
+func handleRulesTest(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("rules test")
+	var file string
+	fs.StringVar(&file, "file", "", "")
+	if ok, res := fs.parse(args, "rules"); !ok {
+		return res
+	}
+
+	if file == "" {
+		return commandResult{Err: validationError(requiredFlagError("file", "Provide --file <cases.yaml>."))}
+	}
+
+	report, err := svc.RunRuleCases(file)
+	if err != nil {
+		if errors.Is(err, cashmop.ErrInvalidRuleCases) {
+			return commandResult{Err: validationError(ErrorDetail{Field: "file", Message: err.Error(), Hint: "Each case needs a description and expected category; see \"cashmop help rules\"."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	if report.Failed > 0 {
+		failures := make([]cashmop.RuleCaseResult, 0, report.Failed)
+		for _, r := range report.Results {
+			if !r.Passed {
+				failures = append(failures, r)
+			}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{
+			Field:   "file",
+			Message: fmt.Sprintf("%d of %d rule test cases failed.", report.Failed, report.Total),
+			Hint:    "Each failure lists the expected category, the actual category, and the rule that fired.",
+			Details: failures,
+		})}
+	}
+
+	return commandResult{Response: ruleTestResponse{
+		Ok:      true,
+		Total:   report.Total,
+		Passed:  report.Passed,
+		Failed:  report.Failed,
+		Results: report.Results,
+	}}
+}
+
+func handleRulesApply(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("rules apply")
+	var all bool
+	var id int64
+	var overwrite string
+	var dryRun bool
+	var undo int64
+	fs.BoolVar(&all, "all", false, "")
+	fs.Int64Var(&id, "id", 0, "")
+	fs.StringVar(&overwrite, "overwrite", database.RuleOverwriteNone, "")
+	fs.BoolVar(&dryRun, "dry-run", false, "")
+	fs.Int64Var(&undo, "undo", 0, "")
+	if ok, res := fs.parse(args, "rules"); !ok {
+		return res
+	}
+
+	if undo != 0 {
+		if all || id != 0 || dryRun {
+			return commandResult{Err: validationError(ErrorDetail{Field: "undo", Message: "--undo cannot be combined with --all, --id, or --dry-run.", Hint: "Run \"cashmop rules apply --undo <batch id>\" on its own."})}
+		}
+		result, err := svc.UndoRuleApply(undo)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return commandResult{Err: runtimeError(ErrorDetail{Field: "undo", Message: "Apply batch not found.", Hint: "Use the batch_id printed by \"cashmop rules apply\"; each batch can be undone once."})}
+			}
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+		return commandResult{Response: ruleApplyUndoResponse{Ok: true, BatchID: result.BatchID, Restored: result.Restored, Skipped: result.Skipped}}
+	}
+
+	if all == (id != 0) {
+		return commandResult{Err: validationError(ErrorDetail{Field: "all", Message: "Provide exactly one of --all or --id.", Hint: "Use --all to apply every rule, or --id <rule id> for one rule."})}
+	}
+	switch overwrite {
+	case database.RuleOverwriteNone, database.RuleOverwriteRuleOnly, database.RuleOverwriteAll:
+	default:
+		return commandResult{Err: validationError(ErrorDetail{Field: "overwrite", Message: "Invalid overwrite policy.", Hint: "Use --overwrite none, rule-only, or all."})}
+	}
+
+	var ruleIDs []int64
+	if id != 0 {
+		ruleIDs = []int64{id}
+	}
+	result, err := svc.ReapplyRules(ruleIDs, overwrite, dryRun)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Field: "id", Message: "Rule not found.", Hint: "Use \"cashmop rules list\" to find rule IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	return commandResult{Response: ruleApplyResponse{
+		Ok:           true,
+		BatchID:      result.BatchID,
+		Overwrite:    result.Overwrite,
+		DryRun:       result.DryRun,
+		ChangedCount: len(result.Changes),
+		Changes:      result.Changes,
+	}}
+}
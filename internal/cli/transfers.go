@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/default-anton/cashmop/internal/cashmop"
+	"github.com/default-anton/cashmop/internal/database"
+)
+
+type transferSide struct {
+	TransactionID int64  `json:"transaction_id"`
+	Account       string `json:"account"`
+	Date          string `json:"date"`
+	Description   string `json:"description"`
+	Amount        string `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+type transferLink struct {
+	// ID is null for pairs found by a dry run.
+	ID        *int64       `json:"id"`
+	DaysApart int          `json:"days_apart"`
+	Out       transferSide `json:"out"`
+	In        transferSide `json:"in"`
+}
+
+func newTransferSide(s database.TransferSide) transferSide {
+	return transferSide{
+		TransactionID: s.TransactionID,
+		Account:       s.AccountName,
+		Date:          s.Date,
+		Description:   s.Description,
+		Amount:        formatCentsDecimal(s.Amount),
+		Currency:      s.Currency,
+	}
+}
+
+func newTransferLinks(links []database.TransferLink) []transferLink {
+	out := make([]transferLink, 0, len(links))
+	for _, l := range links {
+		t := transferLink{DaysApart: l.DaysApart, Out: newTransferSide(l.Out), In: newTransferSide(l.In)}
+		if l.ID != 0 {
+			id := l.ID
+			t.ID = &id
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func transferTableRows(links []transferLink) [][]string {
+	rows := make([][]string, len(links))
+	for i, l := range links {
+		id := ""
+		if l.ID != nil {
+			id = fmt.Sprint(*l.ID)
+		}
+		rows[i] = []string{
+			id,
+			l.Out.Date, l.Out.Account, l.Out.Amount + " " + l.Out.Currency,
+			l.In.Date, l.In.Account, l.In.Amount + " " + l.In.Currency,
+		}
+	}
+	return rows
+}
+
+var transferTableHeaders = []string{"ID", "Out Date", "From", "Out", "In Date", "To", "In"}
+
+type transfersListResponse struct {
+	Ok        bool           `json:"ok"`
+	Count     int            `json:"count"`
+	Transfers []transferLink `json:"transfers"`
+}
+
+func (r transfersListResponse) TableHeaders() []string { return transferTableHeaders }
+
+func (r transfersListResponse) ToTable() [][]string { return transferTableRows(r.Transfers) }
+
+type transfersDetectResponse struct {
+	Ok        bool           `json:"ok"`
+	DryRun    bool           `json:"dry_run"`
+	Count     int            `json:"count"`
+	Transfers []transferLink `json:"transfers"`
+}
+
+func (r transfersDetectResponse) TableHeaders() []string { return transferTableHeaders }
+
+func (r transfersDetectResponse) ToTable() [][]string { return transferTableRows(r.Transfers) }
+
+type transferUnlinkResponse struct {
+	Ok bool  `json:"ok"`
+	ID int64 `json:"id"`
+}
+
+func handleTransfers(svc *cashmop.Service, args []string) commandResult {
+	if len(args) == 0 {
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Missing transfers subcommand (detect, list, unlink).",
+			Hint:    "Use \"cashmop transfers detect\" or \"cashmop transfers list\".",
+		})}
+	}
+
+	switch args[0] {
+	case "detect":
+		return handleTransfersDetect(svc, args[1:])
+	case "list":
+		return handleTransfersList(svc, args[1:])
+	case "unlink":
+		return handleTransfersUnlink(svc, args[1:])
+	default:
+		return commandResult{Err: validationError(ErrorDetail{
+			Field:   "subcommand",
+			Message: "Unknown transfers subcommand.",
+			Hint:    "Use detect, list, or unlink.",
+		})}
+	}
+}
+
+func handleTransfersDetect(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("transfers detect")
+	var start string
+	var end string
+	var windowDays int
+	var dryRun bool
+
+	fs.StringVar(&start, "start", "", "")
+	fs.StringVar(&end, "end", "", "")
+	fs.IntVar(&windowDays, "window-days", database.DefaultTransferWindowDays, "")
+	fs.BoolVar(&dryRun, "dry-run", false, "")
+	if ok, res := fs.parse(args, "transfers"); !ok {
+		return res
+	}
+
+	// Without a range, every transaction is considered.
+	if start != "" || end != "" {
+		var cErr *cliError
+		if start, end, cErr = validateReportRange(start, end); cErr != nil {
+			return commandResult{Err: cErr}
+		}
+	}
+
+	links, err := svc.DetectTransfers(database.TransferDetectOptions{StartDate: start, EndDate: end, WindowDays: windowDays, DryRun: dryRun})
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidTransferWindow) {
+			return commandResult{Err: validationError(ErrorDetail{
+				Field:   "window-days",
+				Message: fmt.Sprintf("--window-days must be between 0 and %d.", database.MaxTransferWindowDays),
+				Hint:    "Provide --window-days <n>, e.g. --window-days 3.",
+			})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	out := newTransferLinks(links)
+	return commandResult{Response: transfersDetectResponse{Ok: true, DryRun: dryRun, Count: len(out), Transfers: out}}
+}
+
+func handleTransfersList(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("transfers list")
+	if ok, res := fs.parse(args, "transfers"); !ok {
+		return res
+	}
+
+	links, err := svc.GetTransferLinks()
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	out := newTransferLinks(links)
+	return commandResult{Response: transfersListResponse{Ok: true, Count: len(out), Transfers: out}}
+}
+
+func handleTransfersUnlink(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("transfers unlink")
+	var id int64
+	fs.Int64Var(&id, "id", 0, "")
+	if ok, res := fs.parse(args, "transfers"); !ok {
+		return res
+	}
+
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <transfer id>."))}
+	}
+	if err := svc.UnlinkTransfer(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Transfer not found.", Hint: "Use \"cashmop transfers list\" to see transfer IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: transferUnlinkResponse{Ok: true, ID: id}}
+}
@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -37,14 +40,134 @@ func (r txListResponse) ToTable() [][]string {
 }
 
 type txListTransaction struct {
-	ID          int64  `json:"id"`
-	Date        string `json:"date"`
-	Description string `json:"description"`
-	Amount      string `json:"amount"`
-	Currency    string `json:"currency"`
-	Category    string `json:"category"`
-	Account     string `json:"account"`
-	Owner       string `json:"owner"`
+	ID          int64    `json:"id"`
+	Date        string   `json:"date"`
+	Description string   `json:"description"`
+	Amount      string   `json:"amount"`
+	Currency    string   `json:"currency"`
+	Category    string   `json:"category"`
+	Account     string   `json:"account"`
+	Owner       string   `json:"owner"`
+	Merchant    string   `json:"merchant"`
+	Tags        []string `json:"tags"`
+	Note        string   `json:"note"`
+	// SplitID is set on rows that are one line of a split transaction.
+	SplitID   *int64 `json:"split_id,omitempty"`
+	SplitNote string `json:"split_note,omitempty"`
+}
+
+type txPredictResponse struct {
+	Ok    bool                      `json:"ok"`
+	Model cashmop.CategoryModelInfo `json:"model"`
+	Count int                       `json:"count"`
+	Items []txPredictionTransaction `json:"items"`
+}
+
+func (r txPredictResponse) TableHeaders() []string {
+	return []string{"ID", "Date", "Amount", "Curr", "Description", "Prediction", "Confidence"}
+}
+
+func (r txPredictResponse) ToTable() [][]string {
+	rows := make([][]string, 0, len(r.Items))
+	for _, tx := range r.Items {
+		prediction, confidence := "-", "-"
+		if len(tx.Predictions) > 0 {
+			prediction = tx.Predictions[0].CategoryName
+			confidence = fmt.Sprintf("%.0f%%", tx.Predictions[0].Confidence*100)
+		}
+		rows = append(rows, []string{
+			fmt.Sprint(tx.ID),
+			tx.Date,
+			tx.Amount,
+			tx.Currency,
+			tx.Description,
+			prediction,
+			confidence,
+		})
+	}
+	return rows
+}
+
+type txPredictionTransaction struct {
+	ID          int64                         `json:"id"`
+	Date        string                        `json:"date"`
+	Description string                        `json:"description"`
+	Amount      string                        `json:"amount"`
+	Currency    string                        `json:"currency"`
+	Account     string                        `json:"account"`
+	Predictions []database.CategoryPrediction `json:"predictions"`
+}
+
+type txTagResponse struct {
+	Ok             bool     `json:"ok"`
+	TransactionIDs []int64  `json:"transaction_ids"`
+	Added          []string `json:"added"`
+	Removed        []string `json:"removed"`
+	// Changed counts transaction-tag links added or removed.
+	Changed int64 `json:"changed"`
+}
+
+type txEditResponse struct {
+	Ok          bool              `json:"ok"`
+	Transaction txListTransaction `json:"transaction"`
+}
+
+type txNoteResponse struct {
+	Ok            bool   `json:"ok"`
+	TransactionID int64  `json:"transaction_id"`
+	Note          string `json:"note"`
+}
+
+type txAttachResponse struct {
+	Ok         bool                `json:"ok"`
+	Attachment database.Attachment `json:"attachment"`
+}
+
+type txAttachmentsResponse struct {
+	Ok            bool                  `json:"ok"`
+	TransactionID int64                 `json:"transaction_id"`
+	Count         int                   `json:"count"`
+	Attachments   []database.Attachment `json:"attachments"`
+}
+
+func (r txAttachmentsResponse) TableHeaders() []string {
+	return []string{"ID", "File", "Type", "Size", "Path"}
+}
+
+func (r txAttachmentsResponse) ToTable() [][]string {
+	rows := make([][]string, len(r.Attachments))
+	for i, a := range r.Attachments {
+		rows[i] = []string{
+			fmt.Sprint(a.ID),
+			a.FileName,
+			a.MimeType,
+			fmt.Sprint(a.Size),
+			a.Path,
+		}
+	}
+	return rows
+}
+
+type txSplitResponse struct {
+	Ok            bool          `json:"ok"`
+	TransactionID int64         `json:"transaction_id"`
+	Amount        string        `json:"amount"`
+	Currency      string        `json:"currency"`
+	Lines         []txSplitLine `json:"lines"`
+}
+
+type txSplitLine struct {
+	ID       int64  `json:"id"`
+	Amount   string `json:"amount"`
+	Category string `json:"category"`
+	Owner    string `json:"owner"`
+	Note     string `json:"note"`
+}
+
+type txUnsplitResponse struct {
+	Ok            bool  `json:"ok"`
+	TransactionID int64 `json:"transaction_id"`
+	RemovedLines  int64 `json:"removed_lines"`
 }
 
 type txCategorizeResponse struct {
@@ -57,21 +180,39 @@ func handleTransactions(svc *cashmop.Service, args []string) commandResult {
 	if len(args) == 0 {
 		return commandResult{Err: validationError(ErrorDetail{
 			Field:   "subcommand",
-			Message: "Missing tx subcommand (list, categorize).",
-			Hint:    "Use \"cashmop tx list\" or \"cashmop tx categorize\".",
+			Message: "Missing tx subcommand (list, add, edit, categorize, tag, note, attach, attachments, split, unsplit, predict).",
+			Hint:    "Use \"cashmop tx list\", \"cashmop tx add\", \"cashmop tx edit\", \"cashmop tx categorize\", \"cashmop tx tag\", \"cashmop tx note\", \"cashmop tx attach\", \"cashmop tx split\", or \"cashmop tx predict\".",
 		})}
 	}
 
 	switch args[0] {
 	case "list":
 		return handleTxList(svc, args[1:])
+	case "add":
+		return handleTxAdd(svc, args[1:])
+	case "edit":
+		return handleTxEdit(svc, args[1:])
 	case "categorize":
 		return handleTxCategorize(svc, args[1:])
+	case "tag":
+		return handleTxTag(svc, args[1:])
+	case "note":
+		return handleTxNote(svc, args[1:])
+	case "attach":
+		return handleTxAttach(svc, args[1:])
+	case "attachments":
+		return handleTxAttachments(svc, args[1:])
+	case "split":
+		return handleTxSplit(svc, args[1:])
+	case "unsplit":
+		return handleTxUnsplit(svc, args[1:])
+	case "predict":
+		return handleTxPredict(svc, args[1:])
 	default:
 		return commandResult{Err: validationError(ErrorDetail{
 			Field:   "subcommand",
 			Message: "Unknown tx subcommand.",
-			Hint:    "Use \"cashmop tx list\" or \"cashmop tx categorize\".",
+			Hint:    "Use \"cashmop tx list\", \"cashmop tx add\", \"cashmop tx edit\", \"cashmop tx categorize\", \"cashmop tx tag\", \"cashmop tx note\", \"cashmop tx attach\", \"cashmop tx split\", or \"cashmop tx predict\".",
 		})}
 	}
 }
@@ -82,6 +223,10 @@ func handleTxList(svc *cashmop.Service, args []string) commandResult {
 	var end string
 	var uncategorized bool
 	var categoryIDs stringSliceFlag
+	var tags stringSliceFlag
+	var untagged bool
+	var merchantIDs stringSliceFlag
+	var noMerchant bool
 	var query string
 	var amountMin string
 	var amountMax string
@@ -92,6 +237,10 @@ func handleTxList(svc *cashmop.Service, args []string) commandResult {
 	fs.StringVar(&end, "end", "", "")
 	fs.BoolVar(&uncategorized, "uncategorized", false, "")
 	fs.Var(&categoryIDs, "category-ids", "")
+	fs.Var(&tags, "tags", "")
+	fs.BoolVar(&untagged, "untagged", false, "")
+	fs.Var(&merchantIDs, "merchant-ids", "")
+	fs.BoolVar(&noMerchant, "no-merchant", false, "")
 	fs.StringVar(&query, "query", "", "")
 	fs.StringVar(&amountMin, "amount-min", "", "")
 	fs.StringVar(&amountMax, "amount-max", "", "")
@@ -124,26 +273,22 @@ func handleTxList(svc *cashmop.Service, args []string) commandResult {
 		maxCents = &v
 	}
 
-	var catIDs []int64
-	for _, s := range categoryIDs.values {
-		parts := strings.Split(s, ",")
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			var id int64
-			if _, err := fmt.Sscanf(p, "%d", &id); err != nil {
-				return commandResult{Err: validationError(ErrorDetail{Field: "category-ids", Message: fmt.Sprintf("Invalid category ID: %s", p), Hint: "Provide comma-separated numeric IDs."})}
-			}
-			catIDs = append(catIDs, id)
-		}
+	catIDs, cErr := parseCategoryIDs(categoryIDs, uncategorized)
+	if cErr != nil {
+		return commandResult{Err: cErr}
 	}
-	if uncategorized {
-		catIDs = append(catIDs, 0)
+
+	tagIDs, cErr := resolveTagFilter(svc, tags, untagged)
+	if cErr != nil {
+		return commandResult{Err: cErr}
+	}
+
+	mIDs, cErr := parseMerchantIDs(merchantIDs, noMerchant)
+	if cErr != nil {
+		return commandResult{Err: cErr}
 	}
 
-	txs, err := svc.GetAnalysisTransactions(start, end, catIDs, nil)
+	txs, err := svc.GetAnalysisTransactions(start, end, catIDs, nil, tagIDs, mIDs)
 	if err != nil {
 		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
 	}
@@ -233,21 +378,7 @@ func handleTxList(svc *cashmop.Service, args []string) commandResult {
 
 	out := make([]txListTransaction, 0, len(extended))
 	for _, e := range extended {
-		tx := e.TransactionModel
-		cat := tx.CategoryName
-		if tx.CategoryID == nil {
-			cat = "Uncategorized"
-		}
-		out = append(out, txListTransaction{
-			ID:          tx.ID,
-			Date:        tx.Date,
-			Description: tx.Description,
-			Amount:      formatCentsDecimal(tx.Amount),
-			Currency:    tx.Currency,
-			Category:    cat,
-			Account:     tx.AccountName,
-			Owner:       tx.OwnerName,
-		})
+		out = append(out, newTxListTransaction(e.TransactionModel))
 	}
 
 	return commandResult{Response: txListResponse{Ok: true, Count: len(out), Transactions: out}}
@@ -290,6 +421,638 @@ func handleTxCategorize(svc *cashmop.Service, args []string) commandResult {
 	return commandResult{Response: txCategorizeResponse{Ok: true, TransactionID: id, AffectedIDs: []int64{id}}}
 }
 
+func handleTxTag(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx tag")
+	var idsFlag stringSliceFlag
+	var add stringSliceFlag
+	var remove stringSliceFlag
+
+	fs.Var(&idsFlag, "ids", "")
+	fs.Var(&add, "add", "")
+	fs.Var(&remove, "remove", "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+
+	var ids []int64
+	for _, p := range idsFlag.split() {
+		var id int64
+		if _, err := fmt.Sscanf(p, "%d", &id); err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "ids", Message: fmt.Sprintf("Invalid transaction ID: %s", p), Hint: "Provide comma-separated numeric IDs."})}
+		}
+		ids = append(ids, id)
+	}
+
+	addNames, removeNames := add.split(), remove.split()
+	if len(ids) == 0 || (len(addNames) == 0 && len(removeNames) == 0) {
+		var details []ErrorDetail
+		if len(ids) == 0 {
+			details = append(details, requiredFlagError("ids", "Provide --ids <id,id> for the transactions to tag."))
+		}
+		if len(addNames) == 0 && len(removeNames) == 0 {
+			details = append(details, ErrorDetail{Field: "add", Message: "Either --add or --remove must be provided.", Hint: "Provide --add <tag,tag> and/or --remove <tag,tag>."})
+		}
+		return commandResult{Err: validationError(details...)}
+	}
+
+	resp := txTagResponse{Ok: true, TransactionIDs: ids, Added: []string{}, Removed: []string{}}
+	// Remove first so "--remove old --add new" swaps a tag.
+	if len(removeNames) > 0 {
+		res, err := svc.RemoveTransactionTags(ids, removeNames)
+		if err != nil {
+			return commandResult{Err: tagChangeError(err, "remove")}
+		}
+		resp.Removed = res.Tags
+		resp.Changed += res.Changed
+	}
+	if len(addNames) > 0 {
+		res, err := svc.AddTransactionTags(ids, addNames)
+		if err != nil {
+			return commandResult{Err: tagChangeError(err, "add")}
+		}
+		resp.Added = res.Tags
+		resp.Changed += res.Changed
+	}
+
+	return commandResult{Response: resp}
+}
+
+func handleTxSplit(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx split")
+	var id int64
+	var lineFlags stringSliceFlag
+
+	fs.Int64Var(&id, "id", 0, "")
+	fs.Var(&lineFlags, "line", "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+
+	if id == 0 || len(lineFlags.values) == 0 {
+		var details []ErrorDetail
+		if id == 0 {
+			details = append(details, requiredFlagError("id", "Provide --id <transaction id>."))
+		}
+		if len(lineFlags.values) == 0 {
+			details = append(details, requiredFlagError("line", "Provide --line \"<amount>|<category>[|<note>[|<owner>]]\" once per split line."))
+		}
+		return commandResult{Err: validationError(details...)}
+	}
+
+	parent, err := svc.GetTransaction(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Transaction not found.", Hint: "Use \"cashmop tx list\" to find transaction IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	type splitInput struct {
+		amount   int64
+		category string
+		note     string
+		owner    string
+	}
+	inputs := make([]splitInput, 0, len(lineFlags.values))
+	var sum int64
+	for _, raw := range lineFlags.values {
+		parts := strings.SplitN(raw, "|", 4)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		if len(parts) < 2 || parts[1] == "" {
+			return commandResult{Err: validationError(ErrorDetail{Field: "line", Message: fmt.Sprintf("Invalid split line: %s", raw), Hint: "Use \"<amount>|<category>[|<note>[|<owner>]]\", e.g. \"-42.10|Groceries\"."})}
+		}
+		amount, err := parseCentsString(parts[0])
+		if err != nil {
+			return commandResult{Err: validationError(ErrorDetail{Field: "line", Message: fmt.Sprintf("Invalid amount in split line: %s", raw), Hint: "Use a decimal string like -12.34, signed like the transaction."})}
+		}
+		in := splitInput{amount: amount, category: parts[1]}
+		if len(parts) > 2 {
+			in.note = parts[2]
+		}
+		if len(parts) > 3 {
+			in.owner = parts[3]
+		}
+		sum += amount
+		inputs = append(inputs, in)
+	}
+
+	// Check the amounts before creating categories or owners, so that a
+	// rejected split leaves nothing behind.
+	if len(inputs) < 2 {
+		return commandResult{Err: splitLinesError(database.ErrSplitTooFewLines, sum, parent.Amount)}
+	}
+	for _, in := range inputs {
+		if in.amount == 0 {
+			return commandResult{Err: splitLinesError(database.ErrSplitZeroAmount, sum, parent.Amount)}
+		}
+	}
+	if sum != parent.Amount {
+		return commandResult{Err: splitLinesError(database.ErrSplitSumMismatch, sum, parent.Amount)}
+	}
+
+	lines := make([]database.SplitLine, 0, len(inputs))
+	for _, in := range inputs {
+		catID, err := svc.CreateCategory(in.category)
+		if err != nil {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+		line := database.SplitLine{Amount: in.amount, CategoryID: &catID, Note: in.note}
+		if in.owner != "" {
+			ownerID, err := svc.CreateOwner(in.owner)
+			if err != nil {
+				return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+			}
+			line.OwnerID = ownerID
+		}
+		lines = append(lines, line)
+	}
+
+	saved, err := svc.SplitTransaction(id, lines)
+	if err != nil {
+		if cErr := splitLinesError(err, sum, parent.Amount); cErr != nil {
+			return commandResult{Err: cErr}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	out := make([]txSplitLine, 0, len(saved))
+	for _, l := range saved {
+		out = append(out, txSplitLine{
+			ID:       l.ID,
+			Amount:   formatCentsDecimal(l.Amount),
+			Category: l.CategoryName,
+			Owner:    l.OwnerName,
+			Note:     l.Note,
+		})
+	}
+	return commandResult{Response: txSplitResponse{Ok: true, TransactionID: id, Amount: formatCentsDecimal(parent.Amount), Currency: parent.Currency, Lines: out}}
+}
+
+// splitLinesError turns a rejected set of split lines into a validation error,
+// or returns nil for other errors.
+func splitLinesError(err error, sum, amount int64) *cliError {
+	switch {
+	case errors.Is(err, database.ErrSplitTooFewLines):
+		return validationError(ErrorDetail{Field: "line", Message: "A split needs at least two lines.", Hint: "Repeat --line for each part, or use \"cashmop tx unsplit\" to undo a split."})
+	case errors.Is(err, database.ErrSplitZeroAmount):
+		return validationError(ErrorDetail{Field: "line", Message: "Split line amounts cannot be zero.", Hint: "Drop the zero line."})
+	case errors.Is(err, database.ErrSplitSumMismatch):
+		return validationError(ErrorDetail{
+			Field:   "line",
+			Message: fmt.Sprintf("Split lines sum to %s but the transaction amount is %s.", formatCentsDecimal(sum), formatCentsDecimal(amount)),
+			Hint:    "Adjust the line amounts so they add up exactly.",
+		})
+	}
+	return nil
+}
+
+func newTxListTransaction(tx database.TransactionModel) txListTransaction {
+	cat := tx.CategoryFullName
+	if cat == "" {
+		cat = tx.CategoryName
+	}
+	if tx.CategoryID == nil {
+		cat = "Uncategorized"
+	}
+	return txListTransaction{
+		ID:          tx.ID,
+		Date:        tx.Date,
+		Description: tx.Description,
+		Amount:      formatCentsDecimal(tx.Amount),
+		Currency:    tx.Currency,
+		Category:    cat,
+		Account:     tx.AccountName,
+		Owner:       tx.OwnerName,
+		Merchant:    tx.MerchantName,
+		Tags:        tx.Tags,
+		Note:        tx.Note,
+		SplitID:     tx.SplitID,
+		SplitNote:   tx.SplitNote,
+	}
+}
+
+func handleTxAdd(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx add")
+	var date string
+	var description string
+	var amount string
+	var account string
+	var currency string
+	var category string
+	var owner string
+	var note string
+
+	fs.StringVar(&date, "date", "", "")
+	fs.StringVar(&description, "description", "", "")
+	fs.StringVar(&amount, "amount", "", "")
+	fs.StringVar(&account, "account", "", "")
+	fs.StringVar(&currency, "currency", "", "")
+	fs.StringVar(&category, "category", "", "")
+	fs.StringVar(&owner, "owner", "", "")
+	fs.StringVar(&note, "note", "", "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if date == "" {
+		details = append(details, requiredFlagError("date", "Provide --date YYYY-MM-DD."))
+	} else if _, err := parseDate(date); err != nil {
+		details = append(details, ErrorDetail{Field: "date", Message: "Invalid date.", Hint: "Use YYYY-MM-DD."})
+	}
+	if strings.TrimSpace(description) == "" {
+		details = append(details, requiredFlagError("description", "Provide --description \"<text>\"."))
+	}
+	var cents int64
+	if amount == "" {
+		details = append(details, requiredFlagError("amount", "Provide --amount <decimal>; use a negative amount for expenses."))
+	} else {
+		v, err := parseCentsString(amount)
+		if err != nil {
+			details = append(details, ErrorDetail{Field: "amount", Message: "Invalid amount.", Hint: "Use a decimal string like -12.34."})
+		}
+		cents = v
+	}
+	if strings.TrimSpace(account) == "" {
+		details = append(details, requiredFlagError("account", "Provide --account <name>; it is created if missing."))
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	tx, err := svc.CreateTransaction(cashmop.TransactionImportInput{
+		Date:        date,
+		Description: description,
+		Amount:      cents,
+		Category:    category,
+		Account:     account,
+		Owner:       owner,
+		Currency:    currency,
+		Note:        note,
+	})
+	if err != nil {
+		return commandResult{Err: txWriteError(err)}
+	}
+	return commandResult{Response: txEditResponse{Ok: true, Transaction: newTxListTransaction(tx)}}
+}
+
+func handleTxEdit(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx edit")
+	var id int64
+	var date optionalStringFlag
+	var description optionalStringFlag
+	var amount optionalStringFlag
+	var account optionalStringFlag
+	var currency optionalStringFlag
+	var owner optionalStringFlag
+
+	fs.Int64Var(&id, "id", 0, "")
+	fs.Var(&date, "date", "")
+	fs.Var(&description, "description", "")
+	fs.Var(&amount, "amount", "")
+	fs.Var(&account, "account", "")
+	fs.Var(&currency, "currency", "")
+	fs.Var(&owner, "owner", "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if id == 0 {
+		details = append(details, requiredFlagError("id", "Provide --id <transaction id>."))
+	}
+	if !date.set && !description.set && !amount.set && !account.set && !currency.set && !owner.set {
+		details = append(details, ErrorDetail{
+			Field:   "id",
+			Message: "Nothing to change.",
+			Hint:    "Provide at least one of --date, --description, --amount, --account, --currency, or --owner.",
+		})
+	}
+
+	var update database.TransactionUpdate
+	if date.set {
+		if _, err := parseDate(date.value); err != nil {
+			details = append(details, ErrorDetail{Field: "date", Message: "Invalid date.", Hint: "Use YYYY-MM-DD."})
+		}
+		update.Date = &date.value
+	}
+	if description.set {
+		update.Description = &description.value
+	}
+	if amount.set {
+		v, err := parseCentsString(amount.value)
+		if err != nil {
+			details = append(details, ErrorDetail{Field: "amount", Message: "Invalid amount.", Hint: "Use a decimal string like -12.34."})
+		}
+		update.Amount = &v
+	}
+	if currency.set {
+		update.Currency = &currency.value
+	}
+	if account.set && strings.TrimSpace(account.value) == "" {
+		details = append(details, ErrorDetail{Field: "account", Message: "Account name cannot be empty.", Hint: "Provide --account <name>."})
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	if account.set {
+		accountID, err := svc.CreateAccount(account.value)
+		if err != nil {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+		update.AccountID = &accountID
+	}
+	if owner.set {
+		// An empty --owner clears the owner.
+		ownerID, err := svc.CreateOwner(owner.value)
+		if err != nil {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+		}
+		update.OwnerID = ownerID
+		update.ClearOwner = ownerID == nil
+	}
+
+	tx, err := svc.UpdateTransaction(id, update)
+	if err != nil {
+		return commandResult{Err: txWriteError(err)}
+	}
+	return commandResult{Response: txEditResponse{Ok: true, Transaction: newTxListTransaction(tx)}}
+}
+
+// txWriteError maps errors from creating or editing a transaction.
+func txWriteError(err error) *cliError {
+	switch {
+	case errors.Is(err, database.ErrInvalidTransactionDate):
+		return validationError(ErrorDetail{Field: "date", Message: "Invalid date.", Hint: "Use YYYY-MM-DD."})
+	case errors.Is(err, database.ErrEmptyDescription):
+		return validationError(ErrorDetail{Field: "description", Message: "Description cannot be empty.", Hint: "Provide --description \"<text>\"."})
+	case errors.Is(err, database.ErrInvalidCurrency):
+		return validationError(ErrorDetail{Field: "currency", Message: "Invalid currency.", Hint: "Use a three-letter code like CAD or USD."})
+	case errors.Is(err, database.ErrDuplicateTransaction):
+		return validationError(ErrorDetail{
+			Field:   "description",
+			Message: "A transaction with the same account, date, description, and amount already exists.",
+			Hint:    "Change one of these fields, or edit the existing transaction instead.",
+		})
+	case errors.Is(err, database.ErrSplitAmountChange):
+		return validationError(ErrorDetail{Field: "amount", Message: "Cannot change the amount of a split transaction.", Hint: "Use \"cashmop tx unsplit\" first, then edit the amount."})
+	case errors.Is(err, sql.ErrNoRows):
+		return runtimeError(ErrorDetail{Message: "Transaction not found.", Hint: "Use \"cashmop tx list\" to find transaction IDs."})
+	}
+	return runtimeError(ErrorDetail{Message: err.Error()})
+}
+
+func handleTxNote(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx note")
+	var id int64
+	var note string
+	var clear bool
+
+	fs.Int64Var(&id, "id", 0, "")
+	fs.StringVar(&note, "note", "", "")
+	fs.BoolVar(&clear, "clear", false, "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+
+	note = strings.TrimSpace(note)
+	var details []ErrorDetail
+	if id == 0 {
+		details = append(details, requiredFlagError("id", "Provide --id <transaction id>."))
+	}
+	if note == "" && !clear {
+		details = append(details, requiredFlagError("note", "Provide --note \"<text>\", or --clear to remove the note."))
+	}
+	if note != "" && clear {
+		details = append(details, ErrorDetail{Field: "clear", Message: "--note and --clear cannot be used together.", Hint: "Use one of them."})
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	if err := svc.SetTransactionNote(id, note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Transaction not found.", Hint: "Use \"cashmop tx list\" to find transaction IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: txNoteResponse{Ok: true, TransactionID: id, Note: note}}
+}
+
+func handleTxAttach(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx attach")
+	var id int64
+	var file string
+
+	fs.Int64Var(&id, "id", 0, "")
+	fs.StringVar(&file, "file", "", "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+
+	var details []ErrorDetail
+	if id == 0 {
+		details = append(details, requiredFlagError("id", "Provide --id <transaction id>."))
+	}
+	if file == "" {
+		details = append(details, requiredFlagError("file", "Provide --file <path> to a receipt or document."))
+	}
+	if len(details) > 0 {
+		return commandResult{Err: validationError(details...)}
+	}
+
+	attachment, err := svc.AttachFile(id, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Transaction not found.", Hint: "Use \"cashmop tx list\" to find transaction IDs."})}
+		case errors.Is(err, os.ErrNotExist), errors.Is(err, database.ErrAttachmentNotFile):
+			return commandResult{Err: validationError(ErrorDetail{Field: "file", Message: fmt.Sprintf("Cannot read file: %s", file), Hint: "Provide the path to an existing file."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: txAttachResponse{Ok: true, Attachment: attachment}}
+}
+
+func handleTxAttachments(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx attachments")
+	var id int64
+
+	fs.Int64Var(&id, "id", 0, "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <transaction id>."))}
+	}
+
+	attachments, err := svc.GetTransactionAttachments(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Transaction not found.", Hint: "Use \"cashmop tx list\" to find transaction IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: txAttachmentsResponse{Ok: true, TransactionID: id, Count: len(attachments), Attachments: attachments}}
+}
+
+func handleTxUnsplit(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx unsplit")
+	var id int64
+
+	fs.Int64Var(&id, "id", 0, "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+	if id == 0 {
+		return commandResult{Err: validationError(requiredFlagError("id", "Provide --id <transaction id>."))}
+	}
+
+	removed, err := svc.UnsplitTransaction(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commandResult{Err: runtimeError(ErrorDetail{Message: "Transaction not found.", Hint: "Use \"cashmop tx list\" to find transaction IDs."})}
+		}
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	return commandResult{Response: txUnsplitResponse{Ok: true, TransactionID: id, RemovedLines: removed}}
+}
+
+func tagChangeError(err error, field string) *cliError {
+	switch {
+	case errors.Is(err, database.ErrInvalidTagName):
+		return validationError(ErrorDetail{Field: field, Message: "Tag names must be non-empty and cannot contain commas.", Hint: "Provide comma-separated tag names like vacation-2025,reimbursable."})
+	case errors.Is(err, sql.ErrNoRows):
+		return runtimeError(ErrorDetail{Message: "Transaction not found.", Hint: "Use \"cashmop tx list\" to find transaction IDs."})
+	}
+	return runtimeError(ErrorDetail{Message: err.Error()})
+}
+
+// parseCategoryIDs turns --category-ids and --uncategorized into category
+// filter IDs.
+func parseCategoryIDs(categoryIDs stringSliceFlag, uncategorized bool) ([]int64, *cliError) {
+	var ids []int64
+	for _, s := range categoryIDs.values {
+		parts := strings.Split(s, ",")
+		for _, p := range parts {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			var id int64
+			if _, err := fmt.Sscanf(p, "%d", &id); err != nil {
+				return nil, validationError(ErrorDetail{Field: "category-ids", Message: fmt.Sprintf("Invalid category ID: %s", p), Hint: "Provide comma-separated numeric IDs."})
+			}
+			ids = append(ids, id)
+		}
+	}
+	if uncategorized {
+		ids = append(ids, 0)
+	}
+	return ids, nil
+}
+
+// resolveTagFilter turns --tags names and --untagged into tag filter IDs.
+func resolveTagFilter(svc *cashmop.Service, tags stringSliceFlag, untagged bool) ([]int64, *cliError) {
+	names := tags.split()
+	ids, missing, err := svc.GetTagIDsByName(names)
+	if err != nil {
+		return nil, runtimeError(ErrorDetail{Message: err.Error()})
+	}
+	if len(missing) > 0 {
+		return nil, validationError(ErrorDetail{Field: "tags", Message: fmt.Sprintf("Unknown tag: %s", missing[0]), Hint: "Tags are added with \"cashmop tx tag\"; check the spelling."})
+	}
+	if untagged {
+		ids = append(ids, database.MissingFilterID)
+	}
+	return ids, nil
+}
+
+// parseMerchantIDs turns --merchant-ids and --no-merchant into merchant filter
+// IDs.
+func parseMerchantIDs(merchantIDs stringSliceFlag, noMerchant bool) ([]int64, *cliError) {
+	var ids []int64
+	for _, p := range merchantIDs.split() {
+		var id int64
+		if _, err := fmt.Sscanf(p, "%d", &id); err != nil || id <= 0 {
+			return nil, validationError(ErrorDetail{Field: "merchant-ids", Message: fmt.Sprintf("Invalid merchant ID: %s", p), Hint: "Provide comma-separated numeric IDs from \"cashmop merchants list\"."})
+		}
+		ids = append(ids, id)
+	}
+	if noMerchant {
+		ids = append(ids, database.MissingFilterID)
+	}
+	return ids, nil
+}
+
+func handleTxPredict(svc *cashmop.Service, args []string) commandResult {
+	fs := newSubcommandFlagSet("tx predict")
+	var id int64
+	var top int
+	var retrain bool
+
+	fs.Int64Var(&id, "id", 0, "")
+	fs.IntVar(&top, "top", 3, "")
+	fs.BoolVar(&retrain, "retrain", false, "")
+	if ok, res := fs.parse(args, "tx"); !ok {
+		return res
+	}
+
+	if top < 1 {
+		return commandResult{Err: validationError(ErrorDetail{Field: "top", Message: "--top must be at least 1.", Hint: "Provide --top <n> with n >= 1."})}
+	}
+
+	var info cashmop.CategoryModelInfo
+	var err error
+	if retrain {
+		info, err = svc.TrainCategoryModel()
+	} else {
+		info, err = svc.GetCategoryModelInfo()
+	}
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+
+	txs, err := svc.PredictUncategorizedTransactions(top)
+	if err != nil {
+		return commandResult{Err: runtimeError(ErrorDetail{Message: err.Error()})}
+	}
+	if id != 0 {
+		filtered := make([]database.TransactionModel, 0, 1)
+		for _, tx := range txs {
+			if tx.ID == id {
+				filtered = append(filtered, tx)
+			}
+		}
+		if len(filtered) == 0 {
+			return commandResult{Err: validationError(ErrorDetail{Field: "id", Message: fmt.Sprintf("Uncategorized transaction %d not found.", id), Hint: "Use \"cashmop tx list --uncategorized\" to find uncategorized transaction IDs."})}
+		}
+		txs = filtered
+	}
+
+	out := make([]txPredictionTransaction, 0, len(txs))
+	for _, tx := range txs {
+		predictions := tx.Predictions
+		if predictions == nil {
+			predictions = []database.CategoryPrediction{}
+		}
+		out = append(out, txPredictionTransaction{
+			ID:          tx.ID,
+			Date:        tx.Date,
+			Description: tx.Description,
+			Amount:      formatCentsDecimal(tx.Amount),
+			Currency:    tx.Currency,
+			Account:     tx.AccountName,
+			Predictions: predictions,
+		})
+	}
+
+	return commandResult{Response: txPredictResponse{Ok: true, Model: info, Count: len(out), Items: out}}
+}
+
 func buildSearchLabel(tx database.TransactionModel) string {
 	cat := tx.CategoryName
 	if tx.CategoryID == nil {
@@ -308,5 +1071,13 @@ func buildSearchLabel(tx database.TransactionModel) string {
 		formatCentsDecimal(tx.Amount),
 		tx.Currency,
 	}
+	if len(tx.Tags) > 0 {
+		parts = append(parts, strings.Join(tx.Tags, " "))
+	}
+	for _, note := range []string{tx.Note, tx.SplitNote} {
+		if note != "" {
+			parts = append(parts, note)
+		}
+	}
 	return strings.Join(parts, " | ") + " ::" + fmt.Sprint(tx.ID)
 }
@@ -17,22 +17,30 @@ type AnalysisFilterOption struct {
 type AnalysisFacets struct {
 	Categories       []AnalysisFilterOption `json:"categories"`
 	Owners           []AnalysisFilterOption `json:"owners"`
+	Tags             []AnalysisFilterOption `json:"tags"`
+	Merchants        []AnalysisFilterOption `json:"merchants"`
 	HasUncategorized bool                   `json:"has_uncategorized"`
 	HasNoOwner       bool                   `json:"has_no_owner"`
+	HasUntagged      bool                   `json:"has_untagged"`
+	HasNoMerchant    bool                   `json:"has_no_merchant"`
 }
 
 type AnalysisView struct {
 	Transactions []TransactionModel `json:"transactions"`
 	Facets       AnalysisFacets     `json:"facets"`
+	// Rollups totals Transactions per category, including subcategories.
+	Rollups []CategoryRollup `json:"rollups"`
+	// Totals splits Transactions by category kind.
+	Totals KindTotals `json:"totals"`
 }
 
 func (s *Store) GetAnalysisFacets(startDate string, endDate string) (AnalysisFacets, error) {
-	// Categories present in the month.
-	catRows, err := s.db.Query(`
+	// Categories present in the month, counting split lines.
+	catRows, err := s.db.Query(expandedTransactionsCTE+`
 		SELECT DISTINCT
 			t.category_id,
 			COALESCE(c.name, printf('Category #%d', t.category_id))
-		FROM transactions t
+		FROM expanded t
 		LEFT JOIN categories c ON t.category_id = c.id
 		WHERE t.date >= ? AND t.date <= ? AND t.category_id IS NOT NULL
 		ORDER BY LOWER(COALESCE(c.name, '')) ASC
@@ -57,11 +65,11 @@ func (s *Store) GetAnalysisFacets(startDate string, endDate string) (AnalysisFac
 	}
 
 	// Owners present in the month.
-	ownerRows, err := s.db.Query(`
+	ownerRows, err := s.db.Query(expandedTransactionsCTE+`
 		SELECT DISTINCT
 			t.owner_id,
 			COALESCE(u.name, printf('Owner #%d', t.owner_id))
-		FROM transactions t
+		FROM expanded t
 		LEFT JOIN users u ON t.owner_id = u.id
 		WHERE t.date >= ? AND t.date <= ? AND t.owner_id IS NOT NULL
 		ORDER BY LOWER(COALESCE(u.name, '')) ASC
@@ -85,22 +93,73 @@ func (s *Store) GetAnalysisFacets(startDate string, endDate string) (AnalysisFac
 		owners = append(owners, AnalysisFilterOption{ID: id, Name: name})
 	}
 
-	var hasUncategorized, hasNoOwner int
-	row := s.db.QueryRow(`
+	// Tags present in the month.
+	tagRows, err := s.db.Query(`
+		SELECT DISTINCT tg.id, tg.name
+		FROM transactions t
+		JOIN transaction_tags tt ON tt.transaction_id = t.id
+		JOIN tags tg ON tg.id = tt.tag_id
+		WHERE t.date >= ? AND t.date <= ?
+		ORDER BY LOWER(tg.name) ASC
+	`, startDate, endDate)
+	if err != nil {
+		return AnalysisFacets{}, err
+	}
+	defer tagRows.Close()
+
+	var tags []AnalysisFilterOption
+	for tagRows.Next() {
+		var tag AnalysisFilterOption
+		if err := tagRows.Scan(&tag.ID, &tag.Name); err != nil {
+			return AnalysisFacets{}, err
+		}
+		tags = append(tags, tag)
+	}
+
+	// Merchants present in the month.
+	merchantRows, err := s.db.Query(`
+		SELECT DISTINCT m.id, m.name
+		FROM transactions t
+		JOIN merchants m ON m.id = t.merchant_id
+		WHERE t.date >= ? AND t.date <= ?
+		ORDER BY LOWER(m.name) ASC
+	`, startDate, endDate)
+	if err != nil {
+		return AnalysisFacets{}, err
+	}
+	defer merchantRows.Close()
+
+	var merchants []AnalysisFilterOption
+	for merchantRows.Next() {
+		var merchant AnalysisFilterOption
+		if err := merchantRows.Scan(&merchant.ID, &merchant.Name); err != nil {
+			return AnalysisFacets{}, err
+		}
+		merchants = append(merchants, merchant)
+	}
+
+	var hasUncategorized, hasNoOwner, hasUntagged, hasNoMerchant int
+	row := s.db.QueryRow(expandedTransactionsCTE+`
 		SELECT
 			SUM(CASE WHEN category_id IS NULL THEN 1 ELSE 0 END) > 0 AS has_uncategorized,
-			SUM(CASE WHEN owner_id IS NULL THEN 1 ELSE 0 END) > 0 AS has_no_owner
-		FROM transactions
+			SUM(CASE WHEN owner_id IS NULL THEN 1 ELSE 0 END) > 0 AS has_no_owner,
+			SUM(CASE WHEN NOT EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = expanded.id) THEN 1 ELSE 0 END) > 0 AS has_untagged,
+			SUM(CASE WHEN merchant_id IS NULL THEN 1 ELSE 0 END) > 0 AS has_no_merchant
+		FROM expanded
 		WHERE date >= ? AND date <= ?
 	`, startDate, endDate)
-	if err := row.Scan(&hasUncategorized, &hasNoOwner); err != nil {
+	if err := row.Scan(&hasUncategorized, &hasNoOwner, &hasUntagged, &hasNoMerchant); err != nil {
 		return AnalysisFacets{}, err
 	}
 
 	return AnalysisFacets{
 		Categories:       categories,
 		Owners:           owners,
+		Tags:             tags,
+		Merchants:        merchants,
 		HasUncategorized: hasUncategorized == 1,
 		HasNoOwner:       hasNoOwner == 1,
+		HasUntagged:      hasUntagged == 1,
+		HasNoMerchant:    hasNoMerchant == 1,
 	}, nil
 }
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrAttachmentNotFile is returned when the path to attach is a directory or
+// not a regular file.
+var ErrAttachmentNotFile = errors.New("attachment path must be a regular file")
+
+type Attachment struct {
+	ID            int64  `json:"id"`
+	TransactionID int64  `json:"transaction_id"`
+	SHA256        string `json:"sha256"`
+	FileName      string `json:"file_name"`
+	MimeType      string `json:"mime_type"`
+	Size          int64  `json:"size"`
+	CreatedAt     string `json:"created_at"`
+	// Path is the stored copy of the file.
+	Path string `json:"path"`
+}
+
+// attachmentsDirFor returns the content-addressed attachment directory that
+// belongs to a database or backup file.
+func attachmentsDirFor(dbPath string) string {
+	return dbPath + "-attachments"
+}
+
+// attachmentPath returns where a file with the given hash is stored below dir.
+func attachmentPath(dir, sha string) string {
+	return filepath.Join(dir, sha[:2], sha)
+}
+
+// AttachmentsDir returns the directory attachment files are stored in.
+func (s *Store) AttachmentsDir() string {
+	return attachmentsDirFor(s.filePath)
+}
+
+// AttachFile copies a file into the attachment store and links it to a
+// transaction. Attaching the same content to a transaction twice returns the
+// existing attachment.
+func (s *Store) AttachFile(transactionID int64, srcPath string) (Attachment, error) {
+	var exists int
+	if err := s.db.QueryRow("SELECT 1 FROM transactions WHERE id = ?", transactionID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return Attachment{}, fmt.Errorf("transaction %d not found: %w", transactionID, sql.ErrNoRows)
+		}
+		return Attachment{}, err
+	}
+
+	sha, size, mimeType, err := s.storeAttachmentFile(srcPath)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	if _, err := s.db.Exec(`
+		INSERT OR IGNORE INTO transaction_attachments (transaction_id, sha256, file_name, mime_type, size)
+		VALUES (?, ?, ?, ?, ?)
+	`, transactionID, sha, filepath.Base(srcPath), mimeType, size); err != nil {
+		return Attachment{}, err
+	}
+
+	attachments, err := s.queryAttachments("WHERE a.transaction_id = ? AND a.sha256 = ?", transactionID, sha)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if len(attachments) == 0 {
+		return Attachment{}, fmt.Errorf("attachment for transaction %d not saved", transactionID)
+	}
+	return attachments[0], nil
+}
+
+// storeAttachmentFile copies srcPath into the attachment directory under its
+// SHA-256 and returns the hash, size and detected MIME type.
+func (s *Store) storeAttachmentFile(srcPath string) (string, int64, string, error) {
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return "", 0, "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", 0, "", ErrAttachmentNotFile
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer src.Close()
+
+	dir := s.AttachmentsDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", 0, "", fmt.Errorf("create attachments directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(src, head)
+	head = head[:n]
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.MultiReader(bytes.NewReader(head), src))
+	if err != nil {
+		tmp.Close()
+		return "", 0, "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, "", err
+	}
+
+	sha := hex.EncodeToString(hash.Sum(nil))
+	dest := attachmentPath(dir, sha)
+	if _, err := os.Stat(dest); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return "", 0, "", err
+		}
+		if err := os.Rename(tmp.Name(), dest); err != nil {
+			return "", 0, "", err
+		}
+	}
+
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(srcPath)))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+	return sha, size, mimeType, nil
+}
+
+// GetTransactionAttachments returns the attachments of a transaction, oldest
+// first.
+func (s *Store) GetTransactionAttachments(transactionID int64) ([]Attachment, error) {
+	var exists int
+	if err := s.db.QueryRow("SELECT 1 FROM transactions WHERE id = ?", transactionID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("transaction %d not found: %w", transactionID, sql.ErrNoRows)
+		}
+		return nil, err
+	}
+	return s.queryAttachments("WHERE a.transaction_id = ?", transactionID)
+}
+
+func (s *Store) queryAttachments(where string, args ...any) ([]Attachment, error) {
+	rows, err := s.db.Query(`
+		SELECT a.id, a.transaction_id, a.sha256, a.file_name, a.mime_type, a.size, COALESCE(a.created_at, '')
+		FROM transaction_attachments a
+		`+where+`
+		ORDER BY a.id ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dir := s.AttachmentsDir()
+	attachments := []Attachment{}
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.TransactionID, &a.SHA256, &a.FileName, &a.MimeType, &a.Size, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Path = attachmentPath(dir, a.SHA256)
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// attachmentHashes returns the hashes of all attachment files db refers to.
+// A database from before attachments existed has none.
+func attachmentHashes(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT DISTINCT sha256 FROM transaction_attachments")
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "no such table") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var sha string
+		if err := rows.Scan(&sha); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, sha)
+	}
+	return hashes, rows.Err()
+}
+
+// copyAttachmentFiles copies the files with the given hashes from srcDir to
+// dstDir. Files already present in dstDir are kept, since equal names mean
+// equal content; files missing from srcDir are returned in missing.
+func copyAttachmentFiles(hashes []string, srcDir, dstDir string) (missing []string, err error) {
+	for _, sha := range hashes {
+		dst := attachmentPath(dstDir, sha)
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		src := attachmentPath(srcDir, sha)
+		if _, err := os.Stat(src); err != nil {
+			missing = append(missing, sha)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return missing, err
+		}
+		tmp := dst + ".tmp"
+		if err := copyFile(src, tmp); err != nil {
+			_ = os.Remove(tmp)
+			return missing, err
+		}
+		if err := os.Rename(tmp, dst); err != nil {
+			_ = os.Remove(tmp)
+			return missing, err
+		}
+	}
+	return missing, nil
+}
+
+// removeUnusedAttachmentFiles deletes the stored files among hashes that no
+// attachment refers to anymore.
+func (s *Store) removeUnusedAttachmentFiles(hashes []string) error {
+	dir := s.AttachmentsDir()
+	for _, sha := range hashes {
+		var exists int
+		err := s.db.QueryRow("SELECT 1 FROM transaction_attachments WHERE sha256 = ? LIMIT 1", sha).Scan(&exists)
+		if err == nil {
+			continue
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+		if err := os.Remove(attachmentPath(dir, sha)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newAttachmentTestTransaction(t *testing.T, store *Store) int64 {
+	t.Helper()
+	accID, err := store.GetOrCreateAccount("TestAccount")
+	if err != nil {
+		t.Fatalf("Failed to create test account: %v", err)
+	}
+	tx := TransactionModel{AccountID: accID, Date: "2024-01-01", Description: "Office supplies", Amount: -4200, Currency: "CAD"}
+	if err := store.BatchInsertTransactions([]TransactionModel{tx}); err != nil {
+		t.Fatalf("Failed to insert test transaction: %v", err)
+	}
+	var id int64
+	if err := store.DB().QueryRow("SELECT id FROM transactions").Scan(&id); err != nil {
+		t.Fatalf("Failed to read transaction id: %v", err)
+	}
+	return id
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAttachFile(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	txID := newAttachmentTestTransaction(t, store)
+	receipt := writeTestFile(t, "receipt.pdf", "%PDF-1.4 receipt")
+
+	a, err := store.AttachFile(txID, receipt)
+	if err != nil {
+		t.Fatalf("AttachFile failed: %v", err)
+	}
+	if a.FileName != "receipt.pdf" || a.MimeType != "application/pdf" || a.Size != 16 {
+		t.Errorf("unexpected attachment: %+v", a)
+	}
+	data, err := os.ReadFile(a.Path)
+	if err != nil || string(data) != "%PDF-1.4 receipt" {
+		t.Fatalf("expected stored copy at %s, got %q (%v)", a.Path, data, err)
+	}
+	if filepath.Base(a.Path) != a.SHA256 {
+		t.Errorf("expected file to be named by its hash, got %s", a.Path)
+	}
+
+	again, err := store.AttachFile(txID, receipt)
+	if err != nil {
+		t.Fatalf("AttachFile again failed: %v", err)
+	}
+	if again.ID != a.ID {
+		t.Errorf("expected attaching the same file twice to return attachment %d, got %d", a.ID, again.ID)
+	}
+
+	if _, err := store.AttachFile(txID, writeTestFile(t, "notes.txt", "paid cash")); err != nil {
+		t.Fatalf("AttachFile second file failed: %v", err)
+	}
+	attachments, err := store.GetTransactionAttachments(txID)
+	if err != nil {
+		t.Fatalf("GetTransactionAttachments failed: %v", err)
+	}
+	if len(attachments) != 2 {
+		t.Fatalf("expected 2 attachments, got %d", len(attachments))
+	}
+
+	if _, err := store.AttachFile(9999, receipt); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing transaction, got %v", err)
+	}
+	if _, err := store.AttachFile(txID, t.TempDir()); !errors.Is(err, ErrAttachmentNotFile) {
+		t.Errorf("expected ErrAttachmentNotFile for a directory, got %v", err)
+	}
+
+	if _, err := store.DeleteTransactions([]int64{txID}); err != nil {
+		t.Fatalf("DeleteTransactions failed: %v", err)
+	}
+	if _, err := os.Stat(a.Path); !os.IsNotExist(err) {
+		t.Errorf("expected attachment file to be removed with its transaction, got %v", err)
+	}
+}
+
+func TestBackupRestoreKeepsAttachments(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	txID := newAttachmentTestTransaction(t, store)
+	a, err := store.AttachFile(txID, writeTestFile(t, "receipt.jpg", "jpeg bytes"))
+	if err != nil {
+		t.Fatalf("AttachFile failed: %v", err)
+	}
+
+	backupPath := filepath.Join(t.TempDir(), "backup.db")
+	if err := store.CreateBackup(backupPath); err != nil {
+		t.Fatalf("CreateBackup failed: %v", err)
+	}
+	if _, err := os.Stat(attachmentPath(attachmentsDirFor(backupPath), a.SHA256)); err != nil {
+		t.Fatalf("expected attachment in backup sidecar: %v", err)
+	}
+
+	if err := os.RemoveAll(store.AttachmentsDir()); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RestoreBackup(backupPath); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+
+	attachments, err := store.GetTransactionAttachments(txID)
+	if err != nil || len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment after restore, got %d (%v)", len(attachments), err)
+	}
+	data, err := os.ReadFile(attachments[0].Path)
+	if err != nil || string(data) != "jpeg bytes" {
+		t.Errorf("expected attachment file restored, got %q (%v)", data, err)
+	}
+}
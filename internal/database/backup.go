@@ -167,6 +167,20 @@ func (s *Store) RestoreBackupWithSafety(backupPath string) (string, error) {
 		return "", fmt.Errorf("Unable to reopen the database after restore.")
 	}
 
+	// Files are named by content, so the ones already on disk are kept and
+	// only those the restored database needs are copied back.
+	hashes, err := attachmentHashes(s.db)
+	if err != nil {
+		return safetyBackupPath, fmt.Errorf("The backup was restored, but its attachments could not be read.")
+	}
+	missing, err := copyAttachmentFiles(hashes, attachmentsDirFor(backupPath), s.AttachmentsDir())
+	if err != nil {
+		return safetyBackupPath, fmt.Errorf("The backup was restored, but its attachments could not be copied.")
+	}
+	if len(missing) > 0 {
+		s.logger.Warn("attachment files missing after restore", "count", len(missing))
+	}
+
 	return safetyBackupPath, nil
 }
 
@@ -282,6 +296,7 @@ func (s *Store) CleanupOldBackups() error {
 	for _, b := range backups {
 		if !keep[b.name] {
 			_ = os.Remove(filepath.Join(backupDir, b.name))
+			_ = os.RemoveAll(attachmentsDirFor(filepath.Join(backupDir, b.name)))
 		}
 	}
 
@@ -315,7 +330,7 @@ func (s *Store) createBackupUnsafe(destination string) error {
 		return fmt.Errorf("Unable to access the database.")
 	}
 
-	hasSpace, err := hasSufficientSpace(destination, dbInfo.Size())
+	hasSpace, err := hasSufficientSpace(destination, dbInfo.Size()+dirSize(s.AttachmentsDir()))
 	if err != nil {
 		return err
 	}
@@ -336,6 +351,22 @@ func (s *Store) createBackupUnsafe(destination string) error {
 		return fmt.Errorf("Backup verification failed.")
 	}
 
+	// Attachment files go into a sidecar folder named after the backup file.
+	hashes, err := attachmentHashes(s.db)
+	if err != nil {
+		_ = os.Remove(destination)
+		return fmt.Errorf("Unable to read attachments for the backup.")
+	}
+	missing, err := copyAttachmentFiles(hashes, s.AttachmentsDir(), attachmentsDirFor(destination))
+	if err != nil {
+		_ = os.Remove(destination)
+		_ = os.RemoveAll(attachmentsDirFor(destination))
+		return fmt.Errorf("Unable to copy attachments into the backup.")
+	}
+	if len(missing) > 0 {
+		s.logger.Warn("attachment files missing from backup", "count", len(missing))
+	}
+
 	return nil
 }
 
@@ -426,6 +457,21 @@ func copyFile(src, dst string) error {
 	return dstFile.Sync()
 }
 
+// dirSize returns the total size of the files below dir; a missing dir is 0.
+func dirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
 func hasSufficientSpace(destPath string, required int64) (bool, error) {
 	dir := destPath
 	if fi, err := os.Stat(destPath); err == nil && fi.IsDir() {
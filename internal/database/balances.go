@@ -0,0 +1,429 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	BalanceSourceManual    = "manual"
+	BalanceSourceStatement = "statement"
+)
+
+var (
+	ErrInvalidBalanceDate   = errors.New("balance date must be a valid YYYY-MM-DD date")
+	ErrInvalidBalanceSource = errors.New("balance source must be manual or statement")
+	ErrInvalidNetWorthRange = errors.New("net worth range must be valid YYYY-MM months with the end not before the start")
+)
+
+// BalanceCheckpoint is the known balance of an account at the end of a day,
+// in cents of Currency.
+type BalanceCheckpoint struct {
+	ID          int64  `json:"id"`
+	AccountID   int64  `json:"account_id"`
+	AccountName string `json:"account_name"`
+	Date        string `json:"date"`
+	Balance     int64  `json:"balance"`
+	Currency    string `json:"currency"`
+	// Source is BalanceSourceManual or BalanceSourceStatement.
+	Source    string `json:"source"`
+	Note      string `json:"note"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ReconciliationEntry checks one checkpoint against the previous one of the
+// same account and currency. Expected and Gap are nil for the first.
+type ReconciliationEntry struct {
+	BalanceCheckpoint
+	// TransactionCount counts the transactions since the previous checkpoint.
+	TransactionCount int    `json:"transaction_count"`
+	Expected         *int64 `json:"expected"`
+	// Gap is Balance minus Expected: positive when transactions are missing
+	// deposits, negative when they are missing charges.
+	Gap *int64 `json:"gap"`
+}
+
+// AccountReconciliation compares the running sum of an account's transactions
+// with its checkpoints.
+type AccountReconciliation struct {
+	AccountID   int64                 `json:"account_id"`
+	AccountName string                `json:"account_name"`
+	Currency    string                `json:"currency"`
+	Entries     []ReconciliationEntry `json:"entries"`
+	// Reconciled is true when no checkpoint has a gap.
+	Reconciled bool `json:"reconciled"`
+	// OpeningBalance is the balance before the first transaction implied by
+	// the first checkpoint; CurrentBalance includes every transaction so far.
+	OpeningBalance int64 `json:"opening_balance"`
+	CurrentBalance int64 `json:"current_balance"`
+	// OtherCurrencyCount counts transactions in another currency than the
+	// checkpoints, which the running sums leave out.
+	OtherCurrencyCount int `json:"other_currency_count"`
+}
+
+type NetWorthAccount struct {
+	AccountID int64  `json:"account_id"`
+	Name      string `json:"name"`
+	Currency  string `json:"currency"`
+	Balance   int64  `json:"balance"`
+	// BalanceInMainCurrency is nil when there is no FX rate for the date.
+	BalanceInMainCurrency *int64 `json:"balance_in_main_currency"`
+}
+
+// NetWorthPoint is the sum of account balances at the end of a month.
+type NetWorthPoint struct {
+	Month       string            `json:"month"`
+	Date        string            `json:"date"`
+	Total       int64             `json:"total"`
+	Accounts    []NetWorthAccount `json:"accounts"`
+	Unconverted int               `json:"unconverted"`
+}
+
+type NetWorth struct {
+	MainCurrency string          `json:"main_currency"`
+	Points       []NetWorthPoint `json:"points"`
+	// MissingAccounts lists accounts without a checkpoint, whose balance is
+	// unknown and left out.
+	MissingAccounts []string `json:"missing_accounts"`
+}
+
+// accountLedger is the transactions of one account in one currency, in date
+// order, for computing balances from a checkpoint.
+type accountLedger struct {
+	dates   []string
+	running []int64
+}
+
+func (l accountLedger) total() (int64, int) {
+	if len(l.running) == 0 {
+		return 0, 0
+	}
+	return l.running[len(l.running)-1], len(l.running)
+}
+
+// through returns the sum of transactions up to and including date, and how
+// many there are.
+func (l accountLedger) through(date string) (int64, int) {
+	n := sort.Search(len(l.dates), func(i int) bool { return l.dates[i] > date })
+	if n == 0 {
+		return 0, 0
+	}
+	return l.running[n-1], n
+}
+
+type ledgerKey struct {
+	accountID int64
+	currency  string
+}
+
+// loadLedgers reads every transaction amount per account and currency.
+func (s *Store) loadLedgers() (map[ledgerKey]*accountLedger, map[int64]int, error) {
+	rows, err := s.db.Query("SELECT account_id, date, amount, UPPER(COALESCE(currency, '')) FROM transactions ORDER BY date, id")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ledgers := map[ledgerKey]*accountLedger{}
+	counts := map[int64]int{}
+	for rows.Next() {
+		var key ledgerKey
+		var date string
+		var amount int64
+		if err := rows.Scan(&key.accountID, &date, &amount, &key.currency); err != nil {
+			return nil, nil, err
+		}
+		l, ok := ledgers[key]
+		if !ok {
+			l = &accountLedger{}
+			ledgers[key] = l
+		}
+		var prev int64
+		if len(l.running) > 0 {
+			prev = l.running[len(l.running)-1]
+		}
+		l.dates = append(l.dates, date)
+		l.running = append(l.running, prev+amount)
+		counts[key.accountID]++
+	}
+	return ledgers, counts, rows.Err()
+}
+
+// SetBalanceCheckpoint records the balance of an account at the end of a day,
+// replacing any checkpoint it already has for that date. An empty currency
+// means the most common currency of the account's transactions, or the main
+// currency when it has none; an empty source means manual.
+func (s *Store) SetBalanceCheckpoint(cp BalanceCheckpoint) (BalanceCheckpoint, error) {
+	if _, err := time.Parse("2006-01-02", cp.Date); err != nil {
+		return BalanceCheckpoint{}, ErrInvalidBalanceDate
+	}
+	if cp.Source == "" {
+		cp.Source = BalanceSourceManual
+	}
+	if cp.Source != BalanceSourceManual && cp.Source != BalanceSourceStatement {
+		return BalanceCheckpoint{}, ErrInvalidBalanceSource
+	}
+	cp.Note = strings.TrimSpace(cp.Note)
+
+	var exists int
+	if err := s.db.QueryRow("SELECT 1 FROM accounts WHERE id = ?", cp.AccountID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return BalanceCheckpoint{}, ErrUnknownAccount
+		}
+		return BalanceCheckpoint{}, err
+	}
+
+	if strings.TrimSpace(cp.Currency) == "" {
+		err := s.db.QueryRow(
+			"SELECT currency FROM transactions WHERE account_id = ? AND currency IS NOT NULL AND currency != '' GROUP BY currency ORDER BY COUNT(*) DESC, MAX(date) DESC LIMIT 1",
+			cp.AccountID,
+		).Scan(&cp.Currency)
+		if err == sql.ErrNoRows {
+			settings, err := s.GetCurrencySettings()
+			if err != nil {
+				return BalanceCheckpoint{}, err
+			}
+			cp.Currency = settings.MainCurrency
+		} else if err != nil {
+			return BalanceCheckpoint{}, err
+		}
+	}
+	currency, err := normalizeCurrency(cp.Currency)
+	if err != nil {
+		return BalanceCheckpoint{}, err
+	}
+	cp.Currency = currency
+
+	if _, err := s.db.Exec(`
+		INSERT INTO account_balances (account_id, date, balance, currency, source, note)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(account_id, date) DO UPDATE SET
+			balance = excluded.balance,
+			currency = excluded.currency,
+			source = excluded.source,
+			note = excluded.note
+	`, cp.AccountID, cp.Date, cp.Balance, cp.Currency, cp.Source, cp.Note); err != nil {
+		return BalanceCheckpoint{}, err
+	}
+
+	checkpoints, err := s.queryBalanceCheckpoints("WHERE b.account_id = ? AND b.date = ?", cp.AccountID, cp.Date)
+	if err != nil {
+		return BalanceCheckpoint{}, err
+	}
+	if len(checkpoints) == 0 {
+		return BalanceCheckpoint{}, sql.ErrNoRows
+	}
+	return checkpoints[0], nil
+}
+
+func (s *Store) DeleteBalanceCheckpoint(id int64) error {
+	res, err := s.db.Exec("DELETE FROM account_balances WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("balance checkpoint %d not found: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetBalanceCheckpoints returns the checkpoints of one account, or of all
+// accounts when accountID is nil, ordered by account name and date.
+func (s *Store) GetBalanceCheckpoints(accountID *int64) ([]BalanceCheckpoint, error) {
+	if accountID != nil {
+		return s.queryBalanceCheckpoints("WHERE b.account_id = ?", *accountID)
+	}
+	return s.queryBalanceCheckpoints("")
+}
+
+func (s *Store) queryBalanceCheckpoints(where string, args ...any) ([]BalanceCheckpoint, error) {
+	rows, err := s.db.Query(`
+		SELECT b.id, b.account_id, a.name, b.date, b.balance, b.currency, b.source, b.note, COALESCE(b.created_at, '')
+		FROM account_balances b
+		JOIN accounts a ON a.id = b.account_id
+		`+where+`
+		ORDER BY a.name, b.date
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkpoints := []BalanceCheckpoint{}
+	for rows.Next() {
+		var cp BalanceCheckpoint
+		if err := rows.Scan(&cp.ID, &cp.AccountID, &cp.AccountName, &cp.Date, &cp.Balance, &cp.Currency, &cp.Source, &cp.Note, &cp.CreatedAt); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
+
+// ReconcileAccounts checks, for each account with checkpoints (or only
+// accountID when set), that every checkpoint equals the previous one plus the
+// transactions in between. Sums only include transactions in the checkpoint's
+// currency.
+func (s *Store) ReconcileAccounts(accountID *int64) ([]AccountReconciliation, error) {
+	checkpoints, err := s.GetBalanceCheckpoints(accountID)
+	if err != nil {
+		return nil, err
+	}
+	ledgers, counts, err := s.loadLedgers()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []AccountReconciliation
+	byAccount := map[int64]int{}
+	prev := map[ledgerKey]BalanceCheckpoint{}
+	for _, cp := range checkpoints {
+		i, ok := byAccount[cp.AccountID]
+		if !ok {
+			i = len(result)
+			byAccount[cp.AccountID] = i
+			result = append(result, AccountReconciliation{
+				AccountID:   cp.AccountID,
+				AccountName: cp.AccountName,
+				Entries:     []ReconciliationEntry{},
+				Reconciled:  true,
+			})
+		}
+		r := &result[i]
+
+		key := ledgerKey{cp.AccountID, cp.Currency}
+		ledger := accountLedger{}
+		if l := ledgers[key]; l != nil {
+			ledger = *l
+		}
+		sum, n := ledger.through(cp.Date)
+		entry := ReconciliationEntry{BalanceCheckpoint: cp, TransactionCount: n}
+		if p, ok := prev[key]; ok {
+			prevSum, prevN := ledger.through(p.Date)
+			expected := p.Balance + sum - prevSum
+			gap := cp.Balance - expected
+			entry.Expected = &expected
+			entry.Gap = &gap
+			entry.TransactionCount = n - prevN
+			if gap != 0 {
+				r.Reconciled = false
+			}
+		}
+		prev[key] = cp
+		if len(r.Entries) == 0 {
+			r.OpeningBalance = cp.Balance - sum
+		}
+		r.Entries = append(r.Entries, entry)
+
+		// The latest checkpoint decides the account's currency and balances.
+		total, totalN := ledger.total()
+		r.Currency = cp.Currency
+		r.CurrentBalance = cp.Balance + total - sum
+		r.OtherCurrencyCount = counts[cp.AccountID] - totalN
+	}
+	if result == nil {
+		result = []AccountReconciliation{}
+	}
+	return result, nil
+}
+
+// GetNetWorth sums the balances of all accounts with checkpoints at the end of
+// each month from startMonth to endMonth (YYYY-MM), in the main currency. An
+// account's balance on a date starts from its latest checkpoint on or before
+// it (or its earliest one) and adds or removes the transactions in between.
+func (s *Store) GetNetWorth(startMonth, endMonth string) (NetWorth, error) {
+	start, err := time.Parse("2006-01", startMonth)
+	if err != nil {
+		return NetWorth{}, ErrInvalidNetWorthRange
+	}
+	end, err := time.Parse("2006-01", endMonth)
+	if err != nil || end.Before(start) {
+		return NetWorth{}, ErrInvalidNetWorthRange
+	}
+
+	settings, err := s.GetCurrencySettings()
+	if err != nil {
+		return NetWorth{}, err
+	}
+	checkpoints, err := s.GetBalanceCheckpoints(nil)
+	if err != nil {
+		return NetWorth{}, err
+	}
+	ledgers, _, err := s.loadLedgers()
+	if err != nil {
+		return NetWorth{}, err
+	}
+
+	byAccount := map[int64][]BalanceCheckpoint{}
+	var accountIDs []int64
+	for _, cp := range checkpoints {
+		if _, ok := byAccount[cp.AccountID]; !ok {
+			accountIDs = append(accountIDs, cp.AccountID)
+		}
+		byAccount[cp.AccountID] = append(byAccount[cp.AccountID], cp)
+	}
+
+	accounts, err := s.GetAccountMap()
+	if err != nil {
+		return NetWorth{}, err
+	}
+	netWorth := NetWorth{MainCurrency: settings.MainCurrency, Points: []NetWorthPoint{}, MissingAccounts: []string{}}
+	for name, id := range accounts {
+		if _, ok := byAccount[id]; !ok {
+			netWorth.MissingAccounts = append(netWorth.MissingAccounts, name)
+		}
+	}
+	sort.Strings(netWorth.MissingAccounts)
+
+	for m := start; !m.After(end); m = m.AddDate(0, 1, 0) {
+		date := m.AddDate(0, 1, -1).Format("2006-01-02")
+		point := NetWorthPoint{Month: m.Format("2006-01"), Date: date, Accounts: []NetWorthAccount{}}
+		for _, id := range accountIDs {
+			cps := byAccount[id]
+			anchor := cps[0]
+			for _, cp := range cps {
+				if cp.Date <= date {
+					anchor = cp
+				}
+			}
+			ledger := accountLedger{}
+			if l := ledgers[ledgerKey{id, anchor.Currency}]; l != nil {
+				ledger = *l
+			}
+			at, _ := ledger.through(date)
+			from, _ := ledger.through(anchor.Date)
+			account := NetWorthAccount{
+				AccountID: id,
+				Name:      anchor.AccountName,
+				Currency:  anchor.Currency,
+				Balance:   anchor.Balance + at - from,
+			}
+			if account.Currency == settings.MainCurrency {
+				converted := account.Balance
+				account.BalanceInMainCurrency = &converted
+			} else {
+				account.BalanceInMainCurrency, err = s.ConvertAmount(account.Balance, settings.MainCurrency, account.Currency, date)
+				if err != nil {
+					s.logger.Error("fx conversion failed", "error", err, "account_id", id, "currency", account.Currency, "date", date)
+					account.BalanceInMainCurrency = nil
+				}
+			}
+			if account.BalanceInMainCurrency == nil {
+				point.Unconverted++
+			} else {
+				point.Total += *account.BalanceInMainCurrency
+			}
+			point.Accounts = append(point.Accounts, account)
+		}
+		netWorth.Points = append(netWorth.Points, point)
+	}
+	return netWorth, nil
+}
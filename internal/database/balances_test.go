@@ -0,0 +1,143 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestBalanceCheckpoints(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	checking, _ := store.GetOrCreateAccount("Checking")
+	brokerage, _ := store.GetOrCreateAccount("Brokerage")
+	if _, err := store.GetOrCreateAccount("Card"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range []TransactionModel{
+		{AccountID: checking, Date: "2025-01-05", Description: "Payroll", Amount: 100000},
+		{AccountID: checking, Date: "2025-01-20", Description: "Rent", Amount: -30000},
+		{AccountID: checking, Date: "2025-02-10", Description: "Hydro", Amount: -20000},
+		{AccountID: checking, Date: "2025-03-01", Description: "Internet", Amount: -5000},
+		{AccountID: checking, Date: "2025-03-02", Description: "Wire", Amount: -1000, Currency: "USD"},
+		{AccountID: brokerage, Date: "2025-03-10", Description: "Deposit", Amount: 7500, Currency: "USD"},
+	} {
+		if _, err := store.CreateTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.UpsertFxRates([]FxRate{
+		{BaseCurrency: "CAD", QuoteCurrency: "USD", RateDate: "2025-01-01", Rate: 1.333333333333, Source: "test"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cp := range []BalanceCheckpoint{
+		{AccountID: checking, Date: "2025-01-31", Balance: 150000, Source: BalanceSourceStatement},
+		{AccountID: checking, Date: "2025-02-28", Balance: 130000, Source: BalanceSourceStatement},
+		{AccountID: checking, Date: "2025-03-31", Balance: 999},
+	} {
+		if _, err := store.SetBalanceCheckpoint(cp); err != nil {
+			t.Fatalf("SetBalanceCheckpoint failed: %v", err)
+		}
+	}
+	// Setting the same date again replaces the balance.
+	march, err := store.SetBalanceCheckpoint(BalanceCheckpoint{AccountID: checking, Date: "2025-03-31", Balance: 120000, Note: " online banking "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if march.Currency != "CAD" || march.Source != BalanceSourceManual || march.Note != "online banking" || march.AccountName != "Checking" {
+		t.Errorf("unexpected checkpoint: %+v", march)
+	}
+	usd, err := store.SetBalanceCheckpoint(BalanceCheckpoint{AccountID: brokerage, Date: "2025-02-15", Balance: 75000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usd.Currency != "USD" {
+		t.Errorf("expected the currency of the account's transactions, got %q", usd.Currency)
+	}
+
+	if _, err := store.SetBalanceCheckpoint(BalanceCheckpoint{AccountID: checking, Date: "2025-02-30", Balance: 1}); !errors.Is(err, ErrInvalidBalanceDate) {
+		t.Errorf("expected invalid date error, got %v", err)
+	}
+	if _, err := store.SetBalanceCheckpoint(BalanceCheckpoint{AccountID: checking, Date: "2025-02-01", Balance: 1, Source: "bank"}); !errors.Is(err, ErrInvalidBalanceSource) {
+		t.Errorf("expected invalid source error, got %v", err)
+	}
+	if _, err := store.SetBalanceCheckpoint(BalanceCheckpoint{AccountID: 999, Date: "2025-02-01", Balance: 1}); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("expected unknown account error, got %v", err)
+	}
+
+	t.Run("Reconcile", func(t *testing.T) {
+		results, err := store.ReconcileAccounts(&checking)
+		if err != nil {
+			t.Fatalf("ReconcileAccounts failed: %v", err)
+		}
+		if len(results) != 1 || len(results[0].Entries) != 3 {
+			t.Fatalf("unexpected reconciliation: %+v", results)
+		}
+		r := results[0]
+		if r.Reconciled || r.OpeningBalance != 80000 || r.CurrentBalance != 120000 || r.OtherCurrencyCount != 1 {
+			t.Errorf("unexpected account reconciliation: %+v", r)
+		}
+		if e := r.Entries[0]; e.Expected != nil || e.Gap != nil || e.TransactionCount != 2 {
+			t.Errorf("unexpected first entry: %+v", e)
+		}
+		if e := r.Entries[1]; e.Expected == nil || *e.Expected != 130000 || *e.Gap != 0 || e.TransactionCount != 1 {
+			t.Errorf("unexpected second entry: %+v", e)
+		}
+		if e := r.Entries[2]; e.Expected == nil || *e.Expected != 125000 || *e.Gap != -5000 {
+			t.Errorf("unexpected third entry: %+v", e)
+		}
+
+		all, err := store.ReconcileAccounts(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 2 || all[0].AccountName != "Brokerage" || !all[0].Reconciled || all[0].CurrentBalance != 82500 {
+			t.Errorf("unexpected reconciliation of all accounts: %+v", all)
+		}
+	})
+
+	t.Run("NetWorth", func(t *testing.T) {
+		netWorth, err := store.GetNetWorth("2025-01", "2025-03")
+		if err != nil {
+			t.Fatalf("GetNetWorth failed: %v", err)
+		}
+		if len(netWorth.MissingAccounts) != 1 || netWorth.MissingAccounts[0] != "Card" {
+			t.Errorf("expected Card to be missing, got %v", netWorth.MissingAccounts)
+		}
+		// Brokerage is anchored at its only checkpoint, so January goes back
+		// from it: 750.00 USD, then 825.00 USD after the March deposit.
+		want := []int64{150000 + 100000, 130000 + 100000, 120000 + 110000}
+		if len(netWorth.Points) != 3 {
+			t.Fatalf("expected 3 points, got %+v", netWorth.Points)
+		}
+		for i, p := range netWorth.Points {
+			if p.Total != want[i] {
+				t.Errorf("%s: expected %d, got %d (%+v)", p.Month, want[i], p.Total, p.Accounts)
+			}
+		}
+		if jan := netWorth.Points[0]; jan.Date != "2025-01-31" || jan.Unconverted != 0 || len(jan.Accounts) != 2 {
+			t.Errorf("unexpected january point: %+v", jan)
+		}
+
+		if _, err := store.GetNetWorth("2025-03", "2025-01"); !errors.Is(err, ErrInvalidNetWorthRange) {
+			t.Errorf("expected invalid range error, got %v", err)
+		}
+	})
+
+	if err := store.DeleteBalanceCheckpoint(march.ID); err != nil {
+		t.Fatalf("DeleteBalanceCheckpoint failed: %v", err)
+	}
+	if err := store.DeleteBalanceCheckpoint(march.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected not found deleting twice, got %v", err)
+	}
+	checkpoints, err := store.GetBalanceCheckpoints(&checking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 2 {
+		t.Errorf("expected 2 checkpoints left, got %+v", checkpoints)
+	}
+}
@@ -0,0 +1,490 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	BudgetPeriodMonthly   = "monthly"
+	BudgetPeriodQuarterly = "quarterly"
+	BudgetPeriodYearly    = "yearly"
+)
+
+var (
+	ErrInvalidBudgetPeriod = errors.New("budget period must be monthly, quarterly, or yearly")
+	ErrInvalidBudgetAmount = errors.New("budget amount must be greater than zero")
+	ErrInvalidBudgetMonth  = errors.New("budget month must be a valid YYYY-MM month")
+	ErrBudgetExists        = errors.New("the category already has a budget")
+	// ErrBudgetCategoryKind is returned when budgeting a category that is not
+	// an expense category.
+	ErrBudgetCategoryKind = errors.New("budgets can only be set on expense categories")
+)
+
+// Budget is a spending limit for a category and all of its subcategories, in
+// the main currency per period.
+type Budget struct {
+	ID           int64  `json:"id"`
+	CategoryID   int64  `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Period       string `json:"period"`
+	Amount       int64  `json:"amount"`
+	// Rollover carries what is left of (or overspent in) each period into the
+	// next, counting from StartMonth.
+	Rollover bool `json:"rollover"`
+	// StartMonth is the first month (YYYY-MM) the budget applies to.
+	StartMonth string `json:"start_month"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// BudgetUpdate lists the fields to change; nil fields are kept.
+type BudgetUpdate struct {
+	Period     *string `json:"period"`
+	Amount     *int64  `json:"amount"`
+	Rollover   *bool   `json:"rollover"`
+	StartMonth *string `json:"start_month"`
+}
+
+// BudgetReportRow compares budget and spending for one category in one month.
+// Amounts are in the main currency and spending is positive. Rows without a
+// BudgetID roll up the budgets of their subcategories.
+type BudgetReportRow struct {
+	Month      string `json:"month"`
+	CategoryID int64  `json:"category_id"`
+	Name       string `json:"name"`
+	FullName   string `json:"full_name"`
+	ParentID   *int64 `json:"parent_id"`
+	// Depth is 0 for top-level categories.
+	Depth    int    `json:"depth"`
+	BudgetID *int64 `json:"budget_id"`
+	Period   string `json:"period"`
+	// PeriodStart is the first month (YYYY-MM) of the budget period the month
+	// falls in: the month itself for monthly budgets.
+	PeriodStart string `json:"period_start"`
+	// Budget is the limit for the whole period.
+	Budget int64 `json:"budget"`
+	// Carry is what rolled over from earlier periods.
+	Carry     int64 `json:"carry"`
+	Available int64 `json:"available"`
+	// Actual is the spending from PeriodStart through Month, so quarterly and
+	// yearly budgets are judged against their period to date.
+	Actual    int64 `json:"actual"`
+	Remaining int64 `json:"remaining"`
+	Overspent bool  `json:"overspent"`
+	// Unconverted counts transactions left out because they have no amount in
+	// the main currency.
+	Unconverted int `json:"unconverted"`
+}
+
+// BudgetMonthTotal sums the top-level rows of a month.
+type BudgetMonthTotal struct {
+	Month     string `json:"month"`
+	Budget    int64  `json:"budget"`
+	Carry     int64  `json:"carry"`
+	Available int64  `json:"available"`
+	Actual    int64  `json:"actual"`
+	Remaining int64  `json:"remaining"`
+	Overspent bool   `json:"overspent"`
+}
+
+type BudgetReport struct {
+	MainCurrency string             `json:"main_currency"`
+	StartMonth   string             `json:"start_month"`
+	EndMonth     string             `json:"end_month"`
+	Rows         []BudgetReportRow  `json:"rows"`
+	Totals       []BudgetMonthTotal `json:"totals"`
+}
+
+func isValidBudgetPeriod(period string) bool {
+	switch period {
+	case BudgetPeriodMonthly, BudgetPeriodQuarterly, BudgetPeriodYearly:
+		return true
+	}
+	return false
+}
+
+// periodMonths is the number of months a budget amount covers.
+func (b Budget) periodMonths() int {
+	switch b.Period {
+	case BudgetPeriodQuarterly:
+		return 3
+	case BudgetPeriodYearly:
+		return 12
+	default:
+		return 1
+	}
+}
+
+// monthsBetween counts the months from from to to, both first-of-month dates.
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
+
+func parseBudgetMonth(month string) (time.Time, error) {
+	m, err := time.Parse("2006-01", strings.TrimSpace(month))
+	if err != nil {
+		return time.Time{}, ErrInvalidBudgetMonth
+	}
+	return m, nil
+}
+
+// validateBudget normalizes and checks a budget before it is written.
+func (s *Store) validateBudget(b *Budget) error {
+	if b.Period == "" {
+		b.Period = BudgetPeriodMonthly
+	}
+	if !isValidBudgetPeriod(b.Period) {
+		return ErrInvalidBudgetPeriod
+	}
+	if b.Amount <= 0 {
+		return ErrInvalidBudgetAmount
+	}
+	if b.StartMonth == "" {
+		b.StartMonth = time.Now().Format("2006-01")
+	}
+	m, err := parseBudgetMonth(b.StartMonth)
+	if err != nil {
+		return err
+	}
+	b.StartMonth = m.Format("2006-01")
+
+	categories, err := s.loadCategories()
+	if err != nil {
+		return err
+	}
+	category, ok := categoriesByID(categories)[b.CategoryID]
+	if !ok {
+		return fmt.Errorf("category %d not found: %w", b.CategoryID, sql.ErrNoRows)
+	}
+	if category.Kind != CategoryKindExpense {
+		return ErrBudgetCategoryKind
+	}
+	return nil
+}
+
+// CreateBudget adds a budget for a category that does not have one yet. An
+// empty period means monthly and an empty start month the current month.
+func (s *Store) CreateBudget(b Budget) (Budget, error) {
+	if err := s.validateBudget(&b); err != nil {
+		return Budget{}, err
+	}
+
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM budgets WHERE category_id = ?", b.CategoryID).Scan(&exists)
+	if err == nil {
+		return Budget{}, ErrBudgetExists
+	}
+	if err != sql.ErrNoRows {
+		return Budget{}, err
+	}
+
+	res, err := s.db.Exec(
+		"INSERT INTO budgets (category_id, period, amount, rollover, start_month) VALUES (?, ?, ?, ?, ?)",
+		b.CategoryID, b.Period, b.Amount, b.Rollover, b.StartMonth,
+	)
+	if err != nil {
+		return Budget{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Budget{}, err
+	}
+	return s.GetBudget(id)
+}
+
+// UpdateBudget changes the given fields of a budget.
+func (s *Store) UpdateBudget(id int64, u BudgetUpdate) (Budget, error) {
+	b, err := s.GetBudget(id)
+	if err != nil {
+		return Budget{}, err
+	}
+	if u.Period != nil {
+		b.Period = *u.Period
+		if b.Period == "" {
+			return Budget{}, ErrInvalidBudgetPeriod
+		}
+	}
+	if u.Amount != nil {
+		b.Amount = *u.Amount
+	}
+	if u.Rollover != nil {
+		b.Rollover = *u.Rollover
+	}
+	if u.StartMonth != nil {
+		b.StartMonth = *u.StartMonth
+		if b.StartMonth == "" {
+			return Budget{}, ErrInvalidBudgetMonth
+		}
+	}
+	if err := s.validateBudget(&b); err != nil {
+		return Budget{}, err
+	}
+
+	if _, err := s.db.Exec(
+		"UPDATE budgets SET period = ?, amount = ?, rollover = ?, start_month = ? WHERE id = ?",
+		b.Period, b.Amount, b.Rollover, b.StartMonth, id,
+	); err != nil {
+		return Budget{}, err
+	}
+	return s.GetBudget(id)
+}
+
+func (s *Store) DeleteBudget(id int64) error {
+	res, err := s.db.Exec("DELETE FROM budgets WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("budget %d not found: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (s *Store) GetBudget(id int64) (Budget, error) {
+	budgets, err := s.queryBudgets("WHERE id = ?", id)
+	if err != nil {
+		return Budget{}, err
+	}
+	if len(budgets) == 0 {
+		return Budget{}, fmt.Errorf("budget %d not found: %w", id, sql.ErrNoRows)
+	}
+	return budgets[0], nil
+}
+
+// GetBudgets returns every budget, ordered by category.
+func (s *Store) GetBudgets() ([]Budget, error) {
+	return s.queryBudgets("")
+}
+
+func (s *Store) queryBudgets(where string, args ...any) ([]Budget, error) {
+	categories, err := s.loadCategories()
+	if err != nil {
+		return nil, err
+	}
+	byID := categoriesByID(categories)
+
+	rows, err := s.db.Query(
+		"SELECT id, category_id, period, amount, rollover, start_month, COALESCE(created_at, '') FROM budgets "+where,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byCategory := map[int64]Budget{}
+	for rows.Next() {
+		var b Budget
+		if err := rows.Scan(&b.ID, &b.CategoryID, &b.Period, &b.Amount, &b.Rollover, &b.StartMonth, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		b.CategoryName = byID[b.CategoryID].FullName
+		byCategory[b.CategoryID] = b
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// categories is sorted by full name, so subcategories follow their parent.
+	budgets := make([]Budget, 0, len(byCategory))
+	for _, c := range categories {
+		if b, ok := byCategory[c.ID]; ok {
+			budgets = append(budgets, b)
+		}
+	}
+	return budgets, nil
+}
+
+// GetBudgetReport compares each budget with the spending in its category and
+// subcategories for every month from startMonth to endMonth (YYYY-MM).
+// Spending is the negated sum of converted amounts, so refunds reduce it;
+// transfers and excluded categories do not count. Categories without a budget
+// of their own get a row rolling up the budgets below them, stopping at the
+// nearest budgeted ancestor so nothing is counted twice.
+func (s *Store) GetBudgetReport(startMonth, endMonth string) (BudgetReport, error) {
+	start, err := parseBudgetMonth(startMonth)
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	end, err := parseBudgetMonth(endMonth)
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	if end.Before(start) {
+		return BudgetReport{}, ErrInvalidBudgetMonth
+	}
+
+	settings, err := s.GetCurrencySettings()
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	report := BudgetReport{
+		MainCurrency: settings.MainCurrency,
+		StartMonth:   start.Format("2006-01"),
+		EndMonth:     end.Format("2006-01"),
+		Rows:         []BudgetReportRow{},
+		Totals:       []BudgetMonthTotal{},
+	}
+
+	budgets, err := s.GetBudgets()
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	categories, err := s.loadCategories()
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	byID := categoriesByID(categories)
+
+	// Rollover needs the spending since the budget started and quarterly or
+	// yearly budgets the spending since their period started, so history can
+	// reach back before the report.
+	first := start
+	for _, b := range budgets {
+		m, err := parseBudgetMonth(b.StartMonth)
+		if err != nil || !m.Before(start) {
+			continue
+		}
+		if !b.Rollover {
+			m = start.AddDate(0, -(monthsBetween(m, start) % b.periodMonths()), 0)
+		}
+		if m.Before(first) {
+			first = m
+		}
+	}
+	var months []string
+	for m := first; !m.After(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+
+	txs, err := s.GetAnalysisTransactions(first.Format("2006-01-02"), end.AddDate(0, 1, -1).Format("2006-01-02"), nil, nil, nil, nil)
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	type cell struct {
+		category int64
+		month    string
+	}
+	spent := map[cell]int64{}
+	unconverted := map[cell]int{}
+	for _, tx := range txs {
+		if tx.CategoryID == nil || !CountsTowardsTotals(tx.CategoryKind) {
+			continue
+		}
+		month := tx.Date[:7]
+		for _, id := range append([]int64{*tx.CategoryID}, categoryAncestors(byID, *tx.CategoryID)...) {
+			if tx.AmountInMainCurrency == nil {
+				unconverted[cell{id, month}]++
+			} else {
+				spent[cell{id, month}] -= *tx.AmountInMainCurrency
+			}
+		}
+	}
+
+	budgetByCategory := make(map[int64]Budget, len(budgets))
+	for _, b := range budgets {
+		budgetByCategory[b.CategoryID] = b
+	}
+	// Periods start at a budget's start month and run back to back.
+	carries := make(map[int64]int64, len(budgets))
+	periodSpent := make(map[int64]int64, len(budgets))
+	periodUnconverted := make(map[int64]int, len(budgets))
+	for i, month := range months {
+		inReport := month >= report.StartMonth
+		rows := map[int64]*BudgetReportRow{}
+		row := func(id int64) *BudgetReportRow {
+			r, ok := rows[id]
+			if !ok {
+				c := byID[id]
+				r = &BudgetReportRow{
+					Month:      month,
+					CategoryID: id,
+					Name:       c.Name,
+					FullName:   c.FullName,
+					ParentID:   c.ParentID,
+					Depth:      len(categoryAncestors(byID, id)),
+				}
+				rows[id] = r
+			}
+			return r
+		}
+
+		for _, b := range budgets {
+			if month < b.StartMonth {
+				continue
+			}
+			key := cell{b.CategoryID, month}
+			startMonth, _ := parseBudgetMonth(b.StartMonth)
+			elapsed := monthsBetween(startMonth, first.AddDate(0, i, 0))
+			pos := elapsed % b.periodMonths()
+			if pos == 0 {
+				periodSpent[b.ID] = 0
+				periodUnconverted[b.ID] = 0
+			}
+			periodSpent[b.ID] += spent[key]
+			periodUnconverted[b.ID] += unconverted[key]
+			carry := carries[b.ID]
+			if b.Rollover && pos == b.periodMonths()-1 {
+				carries[b.ID] = carry + b.Amount - periodSpent[b.ID]
+			}
+			if !inReport {
+				continue
+			}
+
+			r := row(b.CategoryID)
+			id := b.ID
+			r.BudgetID = &id
+			r.Period = b.Period
+			r.PeriodStart = first.AddDate(0, i-pos, 0).Format("2006-01")
+			r.Budget = b.Amount
+			r.Carry = carry
+			r.Available = b.Amount + carry
+			r.Actual = periodSpent[b.ID]
+			r.Remaining = r.Available - r.Actual
+			r.Unconverted = periodUnconverted[b.ID]
+
+			for _, ancestorID := range categoryAncestors(byID, b.CategoryID) {
+				if _, budgeted := budgetByCategory[ancestorID]; budgeted {
+					break
+				}
+				parent := row(ancestorID)
+				parent.Budget += r.Budget
+				parent.Carry += r.Carry
+				parent.Available += r.Available
+				parent.Actual += r.Actual
+				parent.Remaining += r.Remaining
+				parent.Unconverted += r.Unconverted
+			}
+		}
+		if !inReport {
+			continue
+		}
+
+		total := BudgetMonthTotal{Month: month}
+		for _, c := range categories {
+			r, ok := rows[c.ID]
+			if !ok {
+				continue
+			}
+			r.Overspent = r.Remaining < 0
+			report.Rows = append(report.Rows, *r)
+			if r.Depth == 0 {
+				total.Budget += r.Budget
+				total.Carry += r.Carry
+				total.Available += r.Available
+				total.Actual += r.Actual
+				total.Remaining += r.Remaining
+			}
+		}
+		total.Overspent = total.Remaining < 0
+		report.Totals = append(report.Totals, total)
+	}
+	return report, nil
+}
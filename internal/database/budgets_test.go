@@ -0,0 +1,151 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestBudgets(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	food, _ := store.GetOrCreateCategory("Food")
+	groceries, _ := store.GetOrCreateCategory("Groceries")
+	restaurants, _ := store.GetOrCreateCategory("Restaurants")
+	salary, _ := store.GetOrCreateCategory("Salary")
+	for _, id := range []int64{groceries, restaurants} {
+		if err := store.MoveCategory(id, &food); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetCategoryKind(salary, CategoryKindIncome); err != nil {
+		t.Fatal(err)
+	}
+
+	groceriesBudget, err := store.CreateBudget(Budget{CategoryID: groceries, Amount: 50000, Rollover: true, StartMonth: "2025-01"})
+	if err != nil {
+		t.Fatalf("CreateBudget failed: %v", err)
+	}
+	if groceriesBudget.Period != BudgetPeriodMonthly || groceriesBudget.CategoryName != "Food > Groceries" {
+		t.Errorf("unexpected budget: %+v", groceriesBudget)
+	}
+	if _, err := store.CreateBudget(Budget{CategoryID: restaurants, Period: BudgetPeriodQuarterly, Amount: 30000, StartMonth: "2025-01"}); err != nil {
+		t.Fatalf("CreateBudget failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		budget Budget
+		want   error
+	}{
+		{Budget{CategoryID: groceries, Amount: 100}, ErrBudgetExists},
+		{Budget{CategoryID: salary, Amount: 100}, ErrBudgetCategoryKind},
+		{Budget{CategoryID: food, Amount: 0}, ErrInvalidBudgetAmount},
+		{Budget{CategoryID: food, Amount: 100, Period: "weekly"}, ErrInvalidBudgetPeriod},
+		{Budget{CategoryID: food, Amount: 100, StartMonth: "2025-13"}, ErrInvalidBudgetMonth},
+		{Budget{CategoryID: 999, Amount: 100}, sql.ErrNoRows},
+	} {
+		if _, err := store.CreateBudget(tc.budget); !errors.Is(err, tc.want) {
+			t.Errorf("CreateBudget(%+v): expected %v, got %v", tc.budget, tc.want, err)
+		}
+	}
+
+	acct, _ := store.GetOrCreateAccount("Card")
+	for _, tx := range []TransactionModel{
+		{AccountID: acct, Date: "2025-01-05", Description: "Costco", Amount: -40000, CategoryID: &groceries},
+		{AccountID: acct, Date: "2025-01-20", Description: "Steakhouse", Amount: -20000, CategoryID: &restaurants},
+		{AccountID: acct, Date: "2025-02-05", Description: "Costco", Amount: -70000, CategoryID: &groceries},
+		{AccountID: acct, Date: "2025-02-09", Description: "Costco refund", Amount: 5000, CategoryID: &groceries},
+		{AccountID: acct, Date: "2025-02-12", Description: "Sushi", Amount: -12000, CategoryID: &restaurants},
+		{AccountID: acct, Date: "2025-02-15", Description: "Payroll", Amount: 300000, CategoryID: &salary},
+		{AccountID: acct, Date: "2025-02-20", Description: "Bakery", Amount: -800},
+	} {
+		if _, err := store.CreateTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := store.GetBudgetReport("2025-02", "2025-02")
+	if err != nil {
+		t.Fatalf("GetBudgetReport failed: %v", err)
+	}
+	if len(report.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", report.Rows)
+	}
+	rollup, groceriesRow, restaurantsRow := report.Rows[0], report.Rows[1], report.Rows[2]
+	if groceriesRow.FullName != "Food > Groceries" || groceriesRow.Budget != 50000 || groceriesRow.Carry != 10000 ||
+		groceriesRow.Actual != 65000 || groceriesRow.Remaining != -5000 || !groceriesRow.Overspent || groceriesRow.Depth != 1 {
+		t.Errorf("unexpected groceries row: %+v", groceriesRow)
+	}
+	// The quarterly budget is judged on January and February together, even
+	// though the report starts in February.
+	if restaurantsRow.Budget != 30000 || restaurantsRow.PeriodStart != "2025-01" || restaurantsRow.Carry != 0 ||
+		restaurantsRow.Actual != 32000 || restaurantsRow.Remaining != -2000 || !restaurantsRow.Overspent {
+		t.Errorf("unexpected restaurants row: %+v", restaurantsRow)
+	}
+	if rollup.FullName != "Food" || rollup.BudgetID != nil || rollup.Budget != 80000 || rollup.Available != 90000 ||
+		rollup.Actual != 97000 || rollup.Remaining != -7000 || !rollup.Overspent {
+		t.Errorf("unexpected rollup row: %+v", rollup)
+	}
+	want := BudgetMonthTotal{Month: "2025-02", Budget: 80000, Carry: 10000, Available: 90000, Actual: 97000, Remaining: -7000, Overspent: true}
+	if len(report.Totals) != 1 || report.Totals[0] != want {
+		t.Errorf("expected totals %+v, got %+v", want, report.Totals)
+	}
+
+	// A new quarter starts from zero.
+	report, err = store.GetBudgetReport("2025-04", "2025-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := report.Rows[2]; r.PeriodStart != "2025-04" || r.Actual != 0 || r.Remaining != 30000 {
+		t.Errorf("unexpected restaurants row for the next quarter: %+v", r)
+	}
+
+	// A budget on the parent covers its whole subtree and stops the rollup.
+	if _, err := store.CreateBudget(Budget{CategoryID: food, Amount: 100000, StartMonth: "2025-02"}); err != nil {
+		t.Fatal(err)
+	}
+	report, err = store.GetBudgetReport("2025-02", "2025-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows[0].BudgetID == nil || report.Rows[0].Budget != 100000 || report.Rows[0].Actual != 77000 || report.Rows[0].Overspent {
+		t.Errorf("unexpected parent budget row: %+v", report.Rows[0])
+	}
+	if report.Totals[0].Budget != 100000 {
+		t.Errorf("expected totals to use the parent budget, got %+v", report.Totals[0])
+	}
+
+	rollover := false
+	amount := int64(80000)
+	updated, err := store.UpdateBudget(groceriesBudget.ID, BudgetUpdate{Amount: &amount, Rollover: &rollover})
+	if err != nil {
+		t.Fatalf("UpdateBudget failed: %v", err)
+	}
+	if updated.Amount != 80000 || updated.Rollover || updated.StartMonth != "2025-01" {
+		t.Errorf("unexpected updated budget: %+v", updated)
+	}
+
+	if _, err := store.GetBudgetReport("2025-03", "2025-02"); !errors.Is(err, ErrInvalidBudgetMonth) {
+		t.Errorf("expected invalid month range error, got %v", err)
+	}
+
+	// Deleting a category deletes its budget.
+	if _, err := store.DeleteCategory(restaurants); err != nil {
+		t.Fatal(err)
+	}
+	budgets, err := store.GetBudgets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(budgets) != 2 || budgets[0].CategoryID != food || budgets[1].CategoryID != groceries {
+		t.Errorf("unexpected budgets after deleting a category: %+v", budgets)
+	}
+
+	if err := store.DeleteBudget(groceriesBudget.ID); err != nil {
+		t.Fatalf("DeleteBudget failed: %v", err)
+	}
+	if err := store.DeleteBudget(groceriesBudget.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected not found deleting twice, got %v", err)
+	}
+}
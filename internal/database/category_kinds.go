@@ -0,0 +1,128 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+)
+
+const (
+	CategoryKindIncome  = "income"
+	CategoryKindExpense = "expense"
+	// CategoryKindTransfer is for money moving between your own accounts, such
+	// as credit card payments.
+	CategoryKindTransfer = "transfer"
+	// CategoryKindExcluded is for anything else that should not count, such as
+	// reimbursed work expenses.
+	CategoryKindExcluded = "excluded"
+
+	DefaultCategoryKind = CategoryKindExpense
+)
+
+// ErrInvalidCategoryKind is returned for a kind that is not one of the
+// CategoryKind constants.
+var ErrInvalidCategoryKind = errors.New("category kind must be income, expense, transfer, or excluded")
+
+func CategoryKinds() []string {
+	return []string{CategoryKindIncome, CategoryKindExpense, CategoryKindTransfer, CategoryKindExcluded}
+}
+
+func IsValidCategoryKind(kind string) bool {
+	switch kind {
+	case CategoryKindIncome, CategoryKindExpense, CategoryKindTransfer, CategoryKindExcluded:
+		return true
+	}
+	return false
+}
+
+// CountsTowardsTotals reports whether transactions of this kind belong in
+// income, spending and net totals. Uncategorized transactions (empty kind) do.
+func CountsTowardsTotals(kind string) bool {
+	return kind != CategoryKindTransfer && kind != CategoryKindExcluded
+}
+
+// KindTotals splits amounts in the main currency by category kind. Net is
+// Income plus Expenses; Transfers and Excluded are reported but not part of it.
+// Uncategorized transactions count as income or expenses by their sign.
+type KindTotals struct {
+	Income    int64 `json:"income"`
+	Expenses  int64 `json:"expenses"`
+	Net       int64 `json:"net"`
+	Transfers int64 `json:"transfers"`
+	Excluded  int64 `json:"excluded"`
+	// Unconverted counts transactions left out because they have no amount in
+	// the main currency.
+	Unconverted int `json:"unconverted"`
+}
+
+// Add counts one transaction of the given category kind.
+func (t *KindTotals) Add(kind string, amount *int64) {
+	if amount == nil {
+		t.Unconverted++
+		return
+	}
+	switch kind {
+	case CategoryKindIncome:
+		t.Income += *amount
+	case CategoryKindExpense:
+		t.Expenses += *amount
+	case CategoryKindTransfer:
+		t.Transfers += *amount
+	case CategoryKindExcluded:
+		t.Excluded += *amount
+	default:
+		if *amount > 0 {
+			t.Income += *amount
+		} else {
+			t.Expenses += *amount
+		}
+	}
+	t.Net = t.Income + t.Expenses
+}
+
+// BuildKindTotals totals txs by the kind of their category using
+// AmountInMainCurrency. Linked transfers count as transfers.
+func BuildKindTotals(categories []Category, txs []TransactionModel) KindTotals {
+	kinds := make(map[int64]string, len(categories))
+	for _, c := range categories {
+		kinds[c.ID] = c.Kind
+	}
+	var totals KindTotals
+	for _, tx := range txs {
+		kind := ""
+		if tx.TransferLinkID != nil {
+			kind = CategoryKindTransfer
+		} else if tx.CategoryID != nil {
+			kind = kinds[*tx.CategoryID]
+		}
+		totals.Add(kind, tx.AmountInMainCurrency)
+	}
+	return totals
+}
+
+// GetKindTotals totals txs by the kind of their category.
+func (s *Store) GetKindTotals(txs []TransactionModel) (KindTotals, error) {
+	categories, err := s.loadCategories()
+	if err != nil {
+		return KindTotals{}, err
+	}
+	return BuildKindTotals(categories, txs), nil
+}
+
+func (s *Store) SetCategoryKind(id int64, kind string) error {
+	if !IsValidCategoryKind(kind) {
+		return ErrInvalidCategoryKind
+	}
+	res, err := s.db.Exec("UPDATE categories SET kind = ? WHERE id = ?", kind, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	s.invalidateCategoryCache()
+	return nil
+}
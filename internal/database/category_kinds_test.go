@@ -0,0 +1,71 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestKindTotals(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, _ := store.GetOrCreateAccount("Chequing")
+	salaryID, _ := store.GetOrCreateCategory("Salary")
+	groceriesID, _ := store.GetOrCreateCategory("Groceries")
+	cardPaymentID, _ := store.GetOrCreateCategory("Card Payment")
+	workID, _ := store.GetOrCreateCategory("Work Expenses")
+
+	if err := store.SetCategoryKind(salaryID, CategoryKindIncome); err != nil {
+		t.Fatalf("SetCategoryKind failed: %v", err)
+	}
+	if err := store.SetCategoryKind(cardPaymentID, CategoryKindTransfer); err != nil {
+		t.Fatalf("SetCategoryKind failed: %v", err)
+	}
+	if err := store.SetCategoryKind(workID, CategoryKindExcluded); err != nil {
+		t.Fatalf("SetCategoryKind failed: %v", err)
+	}
+	if err := store.SetCategoryKind(groceriesID, "savings"); !errors.Is(err, ErrInvalidCategoryKind) {
+		t.Errorf("expected invalid kind error, got %v", err)
+	}
+	if err := store.SetCategoryKind(9999, CategoryKindIncome); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing category, got %v", err)
+	}
+
+	if err := store.BatchInsertTransactions([]TransactionModel{
+		{AccountID: accID, Date: "2025-01-01", Description: "PAYROLL", Amount: 500000, Currency: defaultMainCurrency, CategoryID: &salaryID},
+		{AccountID: accID, Date: "2025-01-02", Description: "COSTCO", Amount: -8000, Currency: defaultMainCurrency, CategoryID: &groceriesID},
+		{AccountID: accID, Date: "2025-01-03", Description: "COSTCO REFUND", Amount: 1000, Currency: defaultMainCurrency, CategoryID: &groceriesID},
+		{AccountID: accID, Date: "2025-01-04", Description: "VISA PAYMENT", Amount: -120000, Currency: defaultMainCurrency, CategoryID: &cardPaymentID},
+		{AccountID: accID, Date: "2025-01-05", Description: "CONFERENCE", Amount: -40000, Currency: defaultMainCurrency, CategoryID: &workID},
+		{AccountID: accID, Date: "2025-01-06", Description: "E-TRANSFER IN", Amount: 2500, Currency: defaultMainCurrency},
+		{AccountID: accID, Date: "2025-01-07", Description: "MYSTERY", Amount: -300, Currency: defaultMainCurrency},
+	}); err != nil {
+		t.Fatalf("BatchInsertTransactions failed: %v", err)
+	}
+
+	txs, err := store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	for _, tx := range txs {
+		if tx.Description == "VISA PAYMENT" && tx.CategoryKind != CategoryKindTransfer {
+			t.Errorf("expected transfer kind on card payment, got %q", tx.CategoryKind)
+		}
+	}
+
+	totals, err := store.GetKindTotals(txs)
+	if err != nil {
+		t.Fatalf("GetKindTotals failed: %v", err)
+	}
+	want := KindTotals{
+		Income:    502500,
+		Expenses:  -7300,
+		Net:       495200,
+		Transfers: -120000,
+		Excluded:  -40000,
+	}
+	if totals != want {
+		t.Errorf("expected %+v, got %+v", want, totals)
+	}
+}
@@ -0,0 +1,243 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCategoryMerge is returned when the target is also a source or no
+// sources are given.
+var ErrInvalidCategoryMerge = errors.New("merge needs at least one source category different from the target")
+
+type CategoryMergeResult struct {
+	TargetID   int64  `json:"target_id"`
+	TargetName string `json:"target_name"`
+	// MergedNames lists the deleted source categories.
+	MergedNames       []string `json:"merged_names"`
+	MovedTransactions int64    `json:"moved_transactions"`
+	MovedRules        int64    `json:"moved_rules"`
+	// RemovedRules counts moved rules deleted as duplicates of a target rule.
+	RemovedRules       int64 `json:"removed_rules"`
+	MovedSubcategories int64 `json:"moved_subcategories"`
+}
+
+// MergeCategories moves the transactions, rules and subcategories of the
+// source categories to the target and deletes the sources, all in one
+// transaction. Budgets of the sources are deleted. Rules that end up identical
+// to another rule of the target are removed and their hit stats folded into
+// the rule that is kept.
+func (s *Store) MergeCategories(sourceIDs []int64, targetID int64) (CategoryMergeResult, error) {
+	result := CategoryMergeResult{TargetID: targetID, MergedNames: []string{}}
+
+	sources := make(map[int64]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		sources[id] = true
+	}
+	if len(sources) == 0 || sources[targetID] {
+		return result, ErrInvalidCategoryMerge
+	}
+
+	categories, err := s.loadCategories()
+	if err != nil {
+		return result, err
+	}
+	byID := categoriesByID(categories)
+	target, ok := byID[targetID]
+	if !ok {
+		return result, fmt.Errorf("category %d not found: %w", targetID, sql.ErrNoRows)
+	}
+	result.TargetName = target.Name
+
+	ids := make([]int64, 0, len(sources))
+	for _, c := range categories {
+		if sources[c.ID] {
+			ids = append(ids, c.ID)
+			result.MergedNames = append(result.MergedNames, c.Name)
+		}
+	}
+	for id := range sources {
+		if _, ok := byID[id]; !ok {
+			return result, fmt.Errorf("category %d not found: %w", id, sql.ErrNoRows)
+		}
+	}
+
+	// If the target sits below a source, it moves up to the parent of the
+	// highest such source. Categories between it and that source then hang
+	// below the target instead of above it, so no cycle forms.
+	targetParent := target.ParentID
+	ancestors := categoryAncestors(byID, targetID)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if sources[ancestors[i]] {
+			targetParent = nil
+			if i+1 < len(ancestors) {
+				targetParent = &ancestors[i+1]
+			}
+			break
+		}
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	in := "(" + strings.Join(placeholders, ",") + ")"
+	withTarget := append([]any{targetID}, args...)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE transactions SET category_id = ? WHERE category_id IN "+in, withTarget...)
+	if err != nil {
+		return result, err
+	}
+	if result.MovedTransactions, err = res.RowsAffected(); err != nil {
+		return result, err
+	}
+
+	if _, err := tx.Exec("UPDATE transaction_splits SET category_id = ? WHERE category_id IN "+in, withTarget...); err != nil {
+		return result, err
+	}
+
+	res, err = tx.Exec("UPDATE categorization_rules SET category_id = ? WHERE category_id IN "+in, withTarget...)
+	if err != nil {
+		return result, err
+	}
+	if result.MovedRules, err = res.RowsAffected(); err != nil {
+		return result, err
+	}
+
+	// Keep undo batches from rules apply pointing at a category that exists.
+	for _, column := range []string{"old_category_id", "new_category_id"} {
+		if _, err := tx.Exec("UPDATE rule_apply_changes SET "+column+" = ? WHERE "+column+" IN "+in, withTarget...); err != nil {
+			return result, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE categories SET parent_id = ? WHERE id = ?", targetParent, targetID); err != nil {
+		return result, err
+	}
+	res, err = tx.Exec("UPDATE categories SET parent_id = ? WHERE id != ? AND parent_id IN "+in, append([]any{targetID}, withTarget...)...)
+	if err != nil {
+		return result, err
+	}
+	if result.MovedSubcategories, err = res.RowsAffected(); err != nil {
+		return result, err
+	}
+
+	if result.RemovedRules, err = removeDuplicateRules(tx, targetID); err != nil {
+		return result, err
+	}
+
+	// The target keeps its own budget; the sources' budgets go with them.
+	if _, err := tx.Exec("DELETE FROM budgets WHERE category_id IN "+in, args...); err != nil {
+		return result, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM categories WHERE id IN "+in, args...); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	s.invalidateCategoryCache()
+	return result, nil
+}
+
+// removeDuplicateRules deletes rules of a category that repeat the match and
+// amount bounds of an older rule of the same category. Hits and transactions
+// attributed to a deleted rule move to the older one.
+func removeDuplicateRules(tx *sql.Tx, categoryID int64) (int64, error) {
+	rows, err := tx.Query(`
+		SELECT r.id, k.id
+		FROM categorization_rules r
+		JOIN categorization_rules k
+			ON k.category_id = r.category_id
+			AND k.match_type = r.match_type
+			AND k.match_value = r.match_value
+			AND k.amount_min IS r.amount_min
+			AND k.amount_max IS r.amount_max
+			AND k.id < r.id
+		WHERE r.category_id = ?
+			AND NOT EXISTS (
+				SELECT 1 FROM categorization_rules o
+				WHERE o.category_id = r.category_id
+					AND o.match_type = r.match_type
+					AND o.match_value = r.match_value
+					AND o.amount_min IS r.amount_min
+					AND o.amount_max IS r.amount_max
+					AND o.id < k.id
+			)
+	`, categoryID)
+	if err != nil {
+		return 0, err
+	}
+	duplicates := map[int64]int64{}
+	for rows.Next() {
+		var dupID, keepID int64
+		if err := rows.Scan(&dupID, &keepID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		duplicates[dupID] = keepID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for dupID, keepID := range duplicates {
+		if _, err := tx.Exec(`
+			UPDATE categorization_rules
+			SET hit_count = hit_count + (SELECT hit_count FROM categorization_rules WHERE id = ?),
+				last_matched_date = (
+					SELECT MAX(d) FROM (
+						SELECT last_matched_date AS d FROM categorization_rules WHERE id IN (?, ?)
+					)
+				)
+			WHERE id = ?
+		`, dupID, dupID, keepID, keepID); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("UPDATE transactions SET category_rule_id = ? WHERE category_rule_id = ?", keepID, dupID); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("UPDATE rule_apply_changes SET new_category_rule_id = ? WHERE new_category_rule_id = ?", keepID, dupID); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("UPDATE rule_apply_changes SET old_category_rule_id = ? WHERE old_category_rule_id = ?", keepID, dupID); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("DELETE FROM categorization_rules WHERE id = ?", dupID); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(duplicates)), nil
+}
+
+// DeleteCategoryReassign deletes a category after moving its transactions,
+// rules and subcategories to targetID. Rules that duplicate one of the
+// target's are counted in DeletedRuleCount.
+func (s *Store) DeleteCategoryReassign(id int64, targetID int64) (CategoryDeleteStats, error) {
+	result := CategoryDeleteStats{CategoryID: id}
+	merged, err := s.MergeCategories([]int64{id}, targetID)
+	if err != nil {
+		return result, err
+	}
+	if len(merged.MergedNames) > 0 {
+		result.CategoryName = merged.MergedNames[0]
+	}
+	result.ReassignedToID = &merged.TargetID
+	result.ReassignedToName = merged.TargetName
+	result.ReassignedCount = merged.MovedTransactions
+	result.MovedRuleCount = merged.MovedRules - merged.RemovedRules
+	result.DeletedRuleCount = merged.RemovedRules
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestMergeCategories(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, _ := store.GetOrCreateAccount("Visa")
+	groceriesID, _ := store.GetOrCreateCategory("Groceries")
+	groceryID, _ := store.GetOrCreateCategory("Grocery")
+	foodID, _ := store.GetOrCreateCategory("Food Shopping")
+	produceID, _ := store.GetOrCreateCategory("Produce")
+	if err := store.MoveCategory(produceID, &groceryID); err != nil {
+		t.Fatalf("MoveCategory failed: %v", err)
+	}
+
+	if err := store.BatchInsertTransactions([]TransactionModel{
+		{AccountID: accID, Date: "2025-01-02", Description: "COSTCO", Amount: -8000, Currency: defaultMainCurrency, CategoryID: &groceriesID},
+		{AccountID: accID, Date: "2025-01-03", Description: "SAFEWAY", Amount: -3000, Currency: defaultMainCurrency},
+		{AccountID: accID, Date: "2025-01-04", Description: "LOBLAWS", Amount: -2000, Currency: defaultMainCurrency, CategoryID: &foodID},
+	}); err != nil {
+		t.Fatalf("BatchInsertTransactions failed: %v", err)
+	}
+
+	keepID, _ := store.SaveRule(CategorizationRule{MatchType: "contains", MatchValue: "SAFEWAY", CategoryID: groceriesID})
+	dupID, _ := store.SaveRule(CategorizationRule{MatchType: "contains", MatchValue: "SAFEWAY", CategoryID: groceryID})
+	store.SaveRule(CategorizationRule{MatchType: "contains", MatchValue: "LOBLAWS", CategoryID: foodID})
+	if _, err := store.ApplyRule(dupID); err != nil {
+		t.Fatalf("ApplyRule failed: %v", err)
+	}
+
+	if _, err := store.MergeCategories([]int64{groceriesID}, groceriesID); !errors.Is(err, ErrInvalidCategoryMerge) {
+		t.Errorf("expected invalid merge error, got %v", err)
+	}
+	if _, err := store.MergeCategories([]int64{9999}, groceriesID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing source, got %v", err)
+	}
+
+	res, err := store.MergeCategories([]int64{groceryID, foodID}, groceriesID)
+	if err != nil {
+		t.Fatalf("MergeCategories failed: %v", err)
+	}
+	if res.MovedTransactions != 2 || res.MovedRules != 2 || res.RemovedRules != 1 || res.MovedSubcategories != 1 {
+		t.Errorf("unexpected counts: %+v", res)
+	}
+	if len(res.MergedNames) != 2 || res.TargetName != "Groceries" {
+		t.Errorf("unexpected names: %+v", res)
+	}
+
+	categories, _ := store.GetAllCategories()
+	names := map[string]bool{}
+	for _, c := range categories {
+		names[c.FullName] = true
+	}
+	if len(categories) != 2 || !names["Groceries"] || !names["Groceries > Produce"] {
+		t.Errorf("unexpected categories after merge: %v", names)
+	}
+
+	var count int
+	store.db.QueryRow("SELECT COUNT(*) FROM transactions WHERE category_id = ?", groceriesID).Scan(&count)
+	if count != 3 {
+		t.Errorf("expected 3 transactions in Groceries, got %d", count)
+	}
+
+	keep, err := store.GetRuleByID(keepID)
+	if err != nil {
+		t.Fatalf("expected the older rule to be kept: %v", err)
+	}
+	if keep.HitCount != 1 {
+		t.Errorf("expected hits of the duplicate to move to the kept rule, got %d", keep.HitCount)
+	}
+	if _, err := store.GetRuleByID(dupID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected duplicate rule to be removed, got %v", err)
+	}
+	var ruleID int64
+	store.db.QueryRow("SELECT category_rule_id FROM transactions WHERE description = 'SAFEWAY'").Scan(&ruleID)
+	if ruleID != keepID {
+		t.Errorf("expected SAFEWAY to be attributed to rule %d, got %d", keepID, ruleID)
+	}
+}
+
+func TestMergeCategoriesIntoGrandchild(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	// Home > Shopping > Groceries > Produce; merging Shopping into Produce
+	// must not leave Groceries and Produce as each other's parent.
+	homeID, _ := store.GetOrCreateCategory("Home")
+	shoppingID, _ := store.GetOrCreateCategory("Shopping")
+	groceriesID, _ := store.GetOrCreateCategory("Groceries")
+	produceID, _ := store.GetOrCreateCategory("Produce")
+	for _, move := range [][2]int64{{shoppingID, homeID}, {groceriesID, shoppingID}, {produceID, groceriesID}} {
+		if err := store.MoveCategory(move[0], &move[1]); err != nil {
+			t.Fatalf("MoveCategory failed: %v", err)
+		}
+	}
+
+	res, err := store.MergeCategories([]int64{shoppingID}, produceID)
+	if err != nil {
+		t.Fatalf("MergeCategories failed: %v", err)
+	}
+	if res.MovedSubcategories != 1 {
+		t.Errorf("expected Groceries to move under Produce, got %+v", res)
+	}
+
+	categories, err := store.GetAllCategories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := map[string]string{}
+	for _, c := range categories {
+		paths[c.Name] = c.FullName
+	}
+	if paths["Produce"] != "Home > Produce" || paths["Groceries"] != "Home > Produce > Groceries" {
+		t.Errorf("unexpected tree after merge: %v", paths)
+	}
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	AppSettingCategoryModelVersion        = "category_model_version"
+	AppSettingCategoryModelTrainedVersion = "category_model_trained_version"
+	AppSettingCategoryModelTrainedAt      = "category_model_trained_at"
+)
+
+type CategoryPrediction struct {
+	CategoryID   int64   `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Confidence   float64 `json:"confidence"`
+}
+
+type CategoryModelClass struct {
+	DocCount     int64
+	FeatureCount int64
+}
+
+// CategoryModel holds the per-category feature counts of the local prediction model.
+type CategoryModel struct {
+	Classes   map[int64]CategoryModelClass
+	Features  map[string]map[int64]int64
+	Version   int64
+	TrainedAt string
+}
+
+// CategoryModelVersion returns the counter that database triggers bump on
+// every write to categorized transactions. A stored model trained at an older
+// version is stale.
+func (s *Store) CategoryModelVersion() (int64, error) {
+	return s.categoryModelSetting(AppSettingCategoryModelVersion)
+}
+
+func (s *Store) categoryModelSetting(key string) (int64, error) {
+	value, err := s.GetAppSetting(key)
+	if err != nil || value == "" {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func (s *Store) SaveCategoryModel(model CategoryModel) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM category_model_features"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM category_model_classes"); err != nil {
+		return err
+	}
+
+	classStmt, err := tx.Prepare("INSERT INTO category_model_classes (category_id, doc_count, feature_count) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer classStmt.Close()
+	for id, c := range model.Classes {
+		if _, err := classStmt.Exec(id, c.DocCount, c.FeatureCount); err != nil {
+			return err
+		}
+	}
+
+	featureStmt, err := tx.Prepare("INSERT INTO category_model_features (feature, category_id, count) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer featureStmt.Close()
+	for feature, counts := range model.Features {
+		for id, n := range counts {
+			if _, err := featureStmt.Exec(feature, id, n); err != nil {
+				return err
+			}
+		}
+	}
+
+	trainedAt := model.TrainedAt
+	if trainedAt == "" {
+		trainedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	for key, value := range map[string]string{
+		AppSettingCategoryModelTrainedVersion: strconv.FormatInt(model.Version, 10),
+		AppSettingCategoryModelTrainedAt:      trainedAt,
+	} {
+		if _, err := tx.Exec(`
+			INSERT INTO app_settings (key, value)
+			VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value=excluded.value
+		`, key, value); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadCategoryModel returns the stored model, or nil when none has been trained yet.
+func (s *Store) LoadCategoryModel() (*CategoryModel, error) {
+	version, err := s.categoryModelSetting(AppSettingCategoryModelTrainedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, nil
+	}
+	trainedAt, err := s.GetAppSetting(AppSettingCategoryModelTrainedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &CategoryModel{
+		Classes:   make(map[int64]CategoryModelClass),
+		Features:  make(map[string]map[int64]int64),
+		Version:   version,
+		TrainedAt: trainedAt,
+	}
+
+	classRows, err := s.db.Query("SELECT category_id, doc_count, feature_count FROM category_model_classes")
+	if err != nil {
+		return nil, err
+	}
+	defer classRows.Close()
+	for classRows.Next() {
+		var id int64
+		var c CategoryModelClass
+		if err := classRows.Scan(&id, &c.DocCount, &c.FeatureCount); err != nil {
+			return nil, err
+		}
+		model.Classes[id] = c
+	}
+	if err := classRows.Err(); err != nil {
+		return nil, err
+	}
+
+	featureRows, err := s.db.Query("SELECT feature, category_id, count FROM category_model_features")
+	if err != nil {
+		return nil, err
+	}
+	defer featureRows.Close()
+	for featureRows.Next() {
+		var feature string
+		var id, n int64
+		if err := featureRows.Scan(&feature, &id, &n); err != nil {
+			return nil, err
+		}
+		counts, ok := model.Features[feature]
+		if !ok {
+			counts = make(map[int64]int64)
+			model.Features[feature] = counts
+		}
+		counts[id] = n
+	}
+	if err := featureRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
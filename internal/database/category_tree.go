@@ -0,0 +1,239 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// CategoryPathSeparator joins category names in FullName.
+const CategoryPathSeparator = " > "
+
+// ErrCategoryCycle is returned when a category would become its own ancestor.
+var ErrCategoryCycle = errors.New("a category cannot be moved under itself or one of its subcategories")
+
+// CategoryRollup totals the transactions of a category and all of its
+// subcategories. Amounts are in the main currency; transactions that could not
+// be converted are counted but not summed.
+type CategoryRollup struct {
+	CategoryID int64  `json:"category_id"`
+	Name       string `json:"name"`
+	FullName   string `json:"full_name"`
+	ParentID   *int64 `json:"parent_id"`
+	Kind       string `json:"kind"`
+	// Depth is 0 for top-level categories.
+	Depth int `json:"depth"`
+	// OwnCount and OwnTotal cover transactions assigned to this category only.
+	OwnCount int   `json:"own_count"`
+	OwnTotal int64 `json:"own_total"`
+	Count    int   `json:"count"`
+	Total    int64 `json:"total"`
+}
+
+func categoriesByID(categories []Category) map[int64]Category {
+	byID := make(map[int64]Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	return byID
+}
+
+// categoryAncestors returns the IDs from the category's parent up to its
+// top-level category. A broken or cyclic chain stops at the last valid link.
+func categoryAncestors(byID map[int64]Category, id int64) []int64 {
+	var ancestors []int64
+	seen := map[int64]bool{id: true}
+	current, ok := byID[id]
+	for ok && current.ParentID != nil && !seen[*current.ParentID] {
+		parentID := *current.ParentID
+		if _, exists := byID[parentID]; !exists {
+			break
+		}
+		seen[parentID] = true
+		ancestors = append(ancestors, parentID)
+		current = byID[parentID]
+	}
+	return ancestors
+}
+
+// fillCategoryFullNames sets FullName on each category and sorts them by it,
+// so subcategories follow their parent.
+func fillCategoryFullNames(categories []Category) {
+	byID := categoriesByID(categories)
+	for i, c := range categories {
+		ancestors := categoryAncestors(byID, c.ID)
+		parts := make([]string, 0, len(ancestors)+1)
+		for j := len(ancestors) - 1; j >= 0; j-- {
+			parts = append(parts, byID[ancestors[j]].Name)
+		}
+		categories[i].FullName = strings.Join(append(parts, c.Name), CategoryPathSeparator)
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].FullName < categories[j].FullName
+	})
+}
+
+// categoryTopID returns the top-level ancestor of a category, or the category
+// itself when it has no parent.
+func categoryTopID(byID map[int64]Category, id int64) int64 {
+	ancestors := categoryAncestors(byID, id)
+	if len(ancestors) == 0 {
+		return id
+	}
+	return ancestors[len(ancestors)-1]
+}
+
+// withCategoryDescendants expands ids with every subcategory of each of them.
+func withCategoryDescendants(categories []Category, ids []int64) []int64 {
+	children := make(map[int64][]int64)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	queue := append([]int64{}, ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+	return result
+}
+
+// MoveCategory sets the parent of a category; a nil parentID makes it a
+// top-level category.
+func (s *Store) MoveCategory(id int64, parentID *int64) error {
+	categories, err := s.loadCategories()
+	if err != nil {
+		return err
+	}
+	byID := categoriesByID(categories)
+	if _, ok := byID[id]; !ok {
+		return sql.ErrNoRows
+	}
+	if parentID != nil {
+		if _, ok := byID[*parentID]; !ok {
+			return sql.ErrNoRows
+		}
+		if *parentID == id {
+			return ErrCategoryCycle
+		}
+		for _, ancestor := range categoryAncestors(byID, *parentID) {
+			if ancestor == id {
+				return ErrCategoryCycle
+			}
+		}
+	}
+
+	if _, err := s.db.Exec("UPDATE categories SET parent_id = ? WHERE id = ?", parentID, id); err != nil {
+		return err
+	}
+	s.invalidateCategoryCache()
+	return nil
+}
+
+// fillTransactionCategoryPaths sets the full and top-level category names and
+// the category kind on categorized transactions.
+func (s *Store) fillTransactionCategoryPaths(txs []TransactionModel) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	categories, err := s.loadCategories()
+	if err != nil {
+		return err
+	}
+	byID := categoriesByID(categories)
+	for i := range txs {
+		if txs[i].CategoryID == nil {
+			continue
+		}
+		c, ok := byID[*txs[i].CategoryID]
+		if !ok {
+			continue
+		}
+		topID := categoryTopID(byID, c.ID)
+		txs[i].CategoryFullName = c.FullName
+		txs[i].TopCategoryID = &topID
+		txs[i].TopCategoryName = byID[topID].Name
+		txs[i].CategoryKind = c.Kind
+	}
+	return nil
+}
+
+// GetCategoryRollups totals txs per category, including subcategories.
+func (s *Store) GetCategoryRollups(txs []TransactionModel) ([]CategoryRollup, error) {
+	categories, err := s.loadCategories()
+	if err != nil {
+		return nil, err
+	}
+	return BuildCategoryRollups(categories, txs), nil
+}
+
+// BuildCategoryRollups totals txs per category using AmountInMainCurrency. Only
+// categories with transactions in their subtree are returned, ordered by
+// FullName. Uncategorized transactions are reported under MissingFilterID.
+func BuildCategoryRollups(categories []Category, txs []TransactionModel) []CategoryRollup {
+	byID := categoriesByID(categories)
+	rollups := make(map[int64]*CategoryRollup)
+	get := func(id int64) *CategoryRollup {
+		r, ok := rollups[id]
+		if !ok {
+			c := byID[id]
+			r = &CategoryRollup{CategoryID: id, Name: c.Name, FullName: c.FullName, ParentID: c.ParentID, Kind: c.Kind}
+			rollups[id] = r
+		}
+		return r
+	}
+
+	var uncategorized *CategoryRollup
+	for _, tx := range txs {
+		var amount int64
+		if tx.AmountInMainCurrency != nil {
+			amount = *tx.AmountInMainCurrency
+		}
+		if tx.CategoryID == nil {
+			if uncategorized == nil {
+				uncategorized = &CategoryRollup{CategoryID: MissingFilterID, Name: "Uncategorized", FullName: "Uncategorized"}
+			}
+			uncategorized.OwnCount++
+			uncategorized.OwnTotal += amount
+			uncategorized.Count++
+			uncategorized.Total += amount
+			continue
+		}
+		id := *tx.CategoryID
+		if _, ok := byID[id]; !ok {
+			continue
+		}
+		own := get(id)
+		own.OwnCount++
+		own.OwnTotal += amount
+		for _, node := range append([]int64{id}, categoryAncestors(byID, id)...) {
+			r := get(node)
+			r.Count++
+			r.Total += amount
+		}
+	}
+
+	result := make([]CategoryRollup, 0, len(rollups)+1)
+	for _, r := range rollups {
+		r.Depth = len(categoryAncestors(byID, r.CategoryID))
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].FullName < result[j].FullName
+	})
+	if uncategorized != nil {
+		result = append(result, *uncategorized)
+	}
+	return result
+}
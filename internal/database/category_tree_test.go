@@ -0,0 +1,154 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoveCategory(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	foodID, _ := store.GetOrCreateCategory("Food")
+	groceriesID, _ := store.GetOrCreateCategory("Groceries")
+	produceID, _ := store.GetOrCreateCategory("Produce")
+
+	if err := store.MoveCategory(groceriesID, &foodID); err != nil {
+		t.Fatalf("MoveCategory failed: %v", err)
+	}
+	if err := store.MoveCategory(produceID, &groceriesID); err != nil {
+		t.Fatalf("MoveCategory failed: %v", err)
+	}
+
+	categories, err := store.GetAllCategories()
+	if err != nil {
+		t.Fatalf("GetAllCategories failed: %v", err)
+	}
+	want := []string{"Food", "Food > Groceries", "Food > Groceries > Produce"}
+	if len(categories) != len(want) {
+		t.Fatalf("expected %d categories, got %d", len(want), len(categories))
+	}
+	for i, c := range categories {
+		if c.FullName != want[i] {
+			t.Errorf("category %d: expected %q, got %q", i, want[i], c.FullName)
+		}
+	}
+
+	if err := store.MoveCategory(foodID, &produceID); !errors.Is(err, ErrCategoryCycle) {
+		t.Errorf("expected cycle error moving a category under its descendant, got %v", err)
+	}
+	if err := store.MoveCategory(foodID, &foodID); !errors.Is(err, ErrCategoryCycle) {
+		t.Errorf("expected cycle error moving a category under itself, got %v", err)
+	}
+
+	results, err := store.SearchCategories("food prod")
+	if err != nil {
+		t.Fatalf("SearchCategories failed: %v", err)
+	}
+	if len(results) == 0 || results[0].ID != produceID {
+		t.Errorf("expected full-path search to find Produce first, got %+v", results)
+	}
+
+	// Deleting a category moves its subcategories up to its parent.
+	if _, err := store.DeleteCategory(groceriesID); err != nil {
+		t.Fatalf("DeleteCategory failed: %v", err)
+	}
+	categories, _ = store.GetAllCategories()
+	for _, c := range categories {
+		if c.ID == produceID && c.FullName != "Food > Produce" {
+			t.Errorf("expected Produce to move under Food, got %q", c.FullName)
+		}
+	}
+
+	if err := store.MoveCategory(produceID, nil); err != nil {
+		t.Fatalf("MoveCategory to root failed: %v", err)
+	}
+	categories, _ = store.GetAllCategories()
+	for _, c := range categories {
+		if c.ID == produceID && (c.ParentID != nil || c.FullName != "Produce") {
+			t.Errorf("expected Produce to be top-level, got %+v", c)
+		}
+	}
+}
+
+func TestAnalysisCategoryRollups(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, _ := store.GetOrCreateAccount("Visa")
+	foodID, _ := store.GetOrCreateCategory("Food")
+	groceriesID, _ := store.GetOrCreateCategory("Groceries")
+	restaurantsID, _ := store.GetOrCreateCategory("Restaurants")
+	rentID, _ := store.GetOrCreateCategory("Rent")
+	for _, id := range []int64{groceriesID, restaurantsID} {
+		if err := store.MoveCategory(id, &foodID); err != nil {
+			t.Fatalf("MoveCategory failed: %v", err)
+		}
+	}
+
+	if err := store.BatchInsertTransactions([]TransactionModel{
+		{AccountID: accID, Date: "2025-01-02", Description: "COSTCO", Amount: -8000, Currency: defaultMainCurrency, CategoryID: &groceriesID},
+		{AccountID: accID, Date: "2025-01-03", Description: "SUSHI", Amount: -3000, Currency: defaultMainCurrency, CategoryID: &restaurantsID},
+		{AccountID: accID, Date: "2025-01-04", Description: "MARKET", Amount: -500, Currency: defaultMainCurrency, CategoryID: &foodID},
+		{AccountID: accID, Date: "2025-01-05", Description: "LANDLORD", Amount: -150000, Currency: defaultMainCurrency, CategoryID: &rentID},
+		{AccountID: accID, Date: "2025-01-06", Description: "MYSTERY", Amount: -100, Currency: defaultMainCurrency},
+	}); err != nil {
+		t.Fatalf("BatchInsertTransactions failed: %v", err)
+	}
+
+	// Filtering by a parent includes its subcategories.
+	txs, err := store.GetAnalysisTransactions("2025-01-01", "2025-01-31", []int64{foodID}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	if len(txs) != 3 {
+		t.Fatalf("expected 3 Food transactions, got %d", len(txs))
+	}
+	for _, tx := range txs {
+		if tx.TopCategoryID == nil || *tx.TopCategoryID != foodID || tx.TopCategoryName != "Food" {
+			t.Errorf("expected top category Food for %q, got %v %q", tx.Description, tx.TopCategoryID, tx.TopCategoryName)
+		}
+		if tx.Description == "COSTCO" && tx.CategoryFullName != "Food > Groceries" {
+			t.Errorf("expected full name Food > Groceries, got %q", tx.CategoryFullName)
+		}
+	}
+
+	txs, err = store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	rollups, err := store.GetCategoryRollups(txs)
+	if err != nil {
+		t.Fatalf("GetCategoryRollups failed: %v", err)
+	}
+
+	type want struct {
+		depth           int
+		count, ownCount int
+		total, ownTotal int64
+	}
+	expected := map[string]want{
+		"Food":               {depth: 0, count: 3, ownCount: 1, total: -11500, ownTotal: -500},
+		"Food > Groceries":   {depth: 1, count: 1, ownCount: 1, total: -8000, ownTotal: -8000},
+		"Food > Restaurants": {depth: 1, count: 1, ownCount: 1, total: -3000, ownTotal: -3000},
+		"Rent":               {depth: 0, count: 1, ownCount: 1, total: -150000, ownTotal: -150000},
+		"Uncategorized":      {depth: 0, count: 1, ownCount: 1, total: -100, ownTotal: -100},
+	}
+	if len(rollups) != len(expected) {
+		t.Fatalf("expected %d rollups, got %d: %+v", len(expected), len(rollups), rollups)
+	}
+	for _, r := range rollups {
+		w, ok := expected[r.FullName]
+		if !ok {
+			t.Errorf("unexpected rollup %q", r.FullName)
+			continue
+		}
+		got := want{depth: r.Depth, count: r.Count, ownCount: r.OwnCount, total: r.Total, ownTotal: r.OwnTotal}
+		if got != w {
+			t.Errorf("%s: expected %+v, got %+v", r.FullName, w, got)
+		}
+	}
+	if last := rollups[len(rollups)-1]; last.CategoryID != MissingFilterID {
+		t.Errorf("expected Uncategorized to be listed last, got %q", last.FullName)
+	}
+}
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidMerchantName  = errors.New("merchant name cannot be empty")
+	ErrMerchantExists       = errors.New("a merchant with this name already exists")
+	ErrInvalidMerchantAlias = errors.New("merchant aliases need a match type of starts_with, ends_with, contains, or exact and a non-empty pattern")
+	ErrMerchantAliasExists  = errors.New("the alias already exists")
+)
+
+// MerchantAlias is a description pattern of a merchant. Patterns match like
+// categorization rules: LIKE-based and case-insensitive for ASCII, except
+// exact ones.
+type MerchantAlias struct {
+	ID         int64  `json:"id"`
+	MerchantID int64  `json:"merchant_id"`
+	MatchType  string `json:"match_type"`
+	Pattern    string `json:"pattern"`
+}
+
+// Merchant groups the description variants of one payee. A transaction
+// belongs to the merchant of its best matching alias: exact aliases first,
+// then starts_with and ends_with, then contains, longer patterns first.
+type Merchant struct {
+	ID      int64           `json:"id"`
+	Name    string          `json:"name"`
+	Aliases []MerchantAlias `json:"aliases"`
+	// TransactionCount is the number of transactions assigned to the merchant.
+	TransactionCount int    `json:"transaction_count"`
+	CreatedAt        string `json:"created_at"`
+}
+
+func normalizeMerchantAlias(a MerchantAlias) (MerchantAlias, error) {
+	a.MatchType = strings.ToLower(strings.TrimSpace(a.MatchType))
+	a.Pattern = strings.TrimSpace(a.Pattern)
+	switch a.MatchType {
+	case "starts_with", "ends_with", "contains", "exact":
+	default:
+		return a, ErrInvalidMerchantAlias
+	}
+	if a.Pattern == "" {
+		return a, ErrInvalidMerchantAlias
+	}
+	return a, nil
+}
+
+// merchantAliasMatchSQL picks the best alias for the transaction in scope as
+// "transactions"; see Merchant.
+const merchantAliasMatchSQL = `
+	SELECT a.merchant_id FROM merchant_aliases a
+	WHERE (a.match_type = 'exact' AND transactions.description = a.pattern)
+		OR (a.match_type = 'starts_with' AND transactions.description LIKE a.pattern || '%')
+		OR (a.match_type = 'ends_with' AND transactions.description LIKE '%' || a.pattern)
+		OR (a.match_type = 'contains' AND transactions.description LIKE '%' || a.pattern || '%')
+	ORDER BY
+		CASE a.match_type WHEN 'exact' THEN 1 WHEN 'contains' THEN 3 ELSE 2 END,
+		LENGTH(a.pattern) DESC,
+		a.id
+	LIMIT 1
+`
+
+// assignMerchants sets merchant_id from the aliases on the transactions
+// matching where, or on all of them when where is empty.
+func assignMerchants(q execer, where string, args ...any) error {
+	query := "UPDATE transactions SET merchant_id = (" + merchantAliasMatchSQL + ")"
+	if where != "" {
+		query += " WHERE " + where
+	}
+	_, err := q.Exec(query, args...)
+	return err
+}
+
+// checkMerchantName returns ErrMerchantExists when another merchant than id
+// has the name.
+func checkMerchantName(q interface {
+	QueryRow(string, ...any) *sql.Row
+}, name string, id int64) error {
+	var exists int
+	err := q.QueryRow("SELECT 1 FROM merchants WHERE name = ? AND id != ?", name, id).Scan(&exists)
+	if err == nil {
+		return ErrMerchantExists
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+func insertMerchantAlias(tx *sql.Tx, merchantID int64, a MerchantAlias) error {
+	var exists int
+	err := tx.QueryRow("SELECT 1 FROM merchant_aliases WHERE match_type = ? AND pattern = ?", a.MatchType, a.Pattern).Scan(&exists)
+	if err == nil {
+		return ErrMerchantAliasExists
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO merchant_aliases (merchant_id, match_type, pattern) VALUES (?, ?, ?)", merchantID, a.MatchType, a.Pattern)
+	return err
+}
+
+// GetMerchants returns all merchants with their aliases, ordered by name.
+func (s *Store) GetMerchants() ([]Merchant, error) {
+	rows, err := s.db.Query(`
+		SELECT m.id, m.name, COALESCE(m.created_at, ''),
+			(SELECT COUNT(*) FROM transactions t WHERE t.merchant_id = m.id)
+		FROM merchants m
+		ORDER BY LOWER(m.name) ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	merchants := []Merchant{}
+	byID := map[int64]int{}
+	for rows.Next() {
+		m := Merchant{Aliases: []MerchantAlias{}}
+		if err := rows.Scan(&m.ID, &m.Name, &m.CreatedAt, &m.TransactionCount); err != nil {
+			return nil, err
+		}
+		byID[m.ID] = len(merchants)
+		merchants = append(merchants, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	aliasRows, err := s.db.Query(`SELECT id, merchant_id, match_type, pattern FROM merchant_aliases ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer aliasRows.Close()
+	for aliasRows.Next() {
+		var a MerchantAlias
+		if err := aliasRows.Scan(&a.ID, &a.MerchantID, &a.MatchType, &a.Pattern); err != nil {
+			return nil, err
+		}
+		if i, ok := byID[a.MerchantID]; ok {
+			merchants[i].Aliases = append(merchants[i].Aliases, a)
+		}
+	}
+	return merchants, aliasRows.Err()
+}
+
+// GetMerchant returns one merchant with its aliases.
+func (s *Store) GetMerchant(id int64) (Merchant, error) {
+	merchants, err := s.GetMerchants()
+	if err != nil {
+		return Merchant{}, err
+	}
+	for _, m := range merchants {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return Merchant{}, fmt.Errorf("merchant %d not found: %w", id, sql.ErrNoRows)
+}
+
+// CreateMerchant adds a merchant with its aliases and assigns the
+// transactions they match.
+func (s *Store) CreateMerchant(name string, aliases []MerchantAlias) (Merchant, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Merchant{}, ErrInvalidMerchantName
+	}
+	for i := range aliases {
+		a, err := normalizeMerchantAlias(aliases[i])
+		if err != nil {
+			return Merchant{}, err
+		}
+		aliases[i] = a
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Merchant{}, err
+	}
+	defer tx.Rollback()
+
+	if err := checkMerchantName(tx, name, 0); err != nil {
+		return Merchant{}, err
+	}
+	res, err := tx.Exec("INSERT INTO merchants (name) VALUES (?)", name)
+	if err != nil {
+		return Merchant{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Merchant{}, err
+	}
+	for _, a := range aliases {
+		if err := insertMerchantAlias(tx, id, a); err != nil {
+			return Merchant{}, err
+		}
+	}
+	if len(aliases) > 0 {
+		if err := assignMerchants(tx, ""); err != nil {
+			return Merchant{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return Merchant{}, err
+	}
+	return s.GetMerchant(id)
+}
+
+// RenameMerchant changes the name of a merchant.
+func (s *Store) RenameMerchant(id int64, name string) (Merchant, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Merchant{}, ErrInvalidMerchantName
+	}
+	if err := checkMerchantName(s.db, name, id); err != nil {
+		return Merchant{}, err
+	}
+	res, err := s.db.Exec("UPDATE merchants SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return Merchant{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return Merchant{}, err
+	} else if n == 0 {
+		return Merchant{}, fmt.Errorf("merchant %d not found: %w", id, sql.ErrNoRows)
+	}
+	return s.GetMerchant(id)
+}
+
+// DeleteMerchant removes a merchant and its aliases. Its transactions go to
+// the next best matching merchant, if any.
+func (s *Store) DeleteMerchant(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT 1 FROM merchants WHERE id = ?", id).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("merchant %d not found: %w", id, sql.ErrNoRows)
+		}
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM merchant_aliases WHERE merchant_id = ?", id); err != nil {
+		return err
+	}
+	if err := assignMerchants(tx, "merchant_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM merchants WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddMerchantAlias adds an alias to a merchant and reassigns transactions,
+// since the alias may beat another merchant's.
+func (s *Store) AddMerchantAlias(merchantID int64, alias MerchantAlias) (Merchant, error) {
+	alias, err := normalizeMerchantAlias(alias)
+	if err != nil {
+		return Merchant{}, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Merchant{}, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT 1 FROM merchants WHERE id = ?", merchantID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return Merchant{}, fmt.Errorf("merchant %d not found: %w", merchantID, sql.ErrNoRows)
+		}
+		return Merchant{}, err
+	}
+	if err := insertMerchantAlias(tx, merchantID, alias); err != nil {
+		return Merchant{}, err
+	}
+	if err := assignMerchants(tx, ""); err != nil {
+		return Merchant{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Merchant{}, err
+	}
+	return s.GetMerchant(merchantID)
+}
+
+// DeleteMerchantAlias removes an alias and reassigns the transactions it
+// matched.
+func (s *Store) DeleteMerchantAlias(id int64) (Merchant, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Merchant{}, err
+	}
+	defer tx.Rollback()
+
+	var merchantID int64
+	if err := tx.QueryRow("SELECT merchant_id FROM merchant_aliases WHERE id = ?", id).Scan(&merchantID); err != nil {
+		if err == sql.ErrNoRows {
+			return Merchant{}, fmt.Errorf("merchant alias %d not found: %w", id, sql.ErrNoRows)
+		}
+		return Merchant{}, err
+	}
+	if _, err := tx.Exec("DELETE FROM merchant_aliases WHERE id = ?", id); err != nil {
+		return Merchant{}, err
+	}
+	if err := assignMerchants(tx, "merchant_id = ?", merchantID); err != nil {
+		return Merchant{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Merchant{}, err
+	}
+	return s.GetMerchant(merchantID)
+}
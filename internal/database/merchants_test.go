@@ -0,0 +1,143 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestMerchants(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	card, _ := store.GetOrCreateAccount("Card")
+	var ids []int64
+	for _, tx := range []TransactionModel{
+		{AccountID: card, Date: "2025-01-02", Description: "AMZN MKTP CA*1A2B3", Amount: -2500},
+		{AccountID: card, Date: "2025-01-05", Description: "AMAZON.CA", Amount: -4000},
+		{AccountID: card, Date: "2025-01-07", Description: "AMZN PRIME", Amount: -999},
+		{AccountID: card, Date: "2025-01-09", Description: "Corner Store", Amount: -500},
+	} {
+		created, err := store.CreateTransaction(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	merchantOf := func(id int64) *int64 {
+		t.Helper()
+		tx, err := store.GetTransaction(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx.MerchantID
+	}
+
+	amazon, err := store.CreateMerchant(" Amazon ", []MerchantAlias{
+		{MatchType: "starts_with", Pattern: "amzn"},
+		{MatchType: "exact", Pattern: "AMAZON.CA"},
+	})
+	if err != nil {
+		t.Fatalf("CreateMerchant failed: %v", err)
+	}
+	if amazon.Name != "Amazon" || len(amazon.Aliases) != 2 || amazon.TransactionCount != 3 {
+		t.Errorf("unexpected merchant: %+v", amazon)
+	}
+
+	// The longer prefix of Prime Video beats Amazon's.
+	prime, err := store.CreateMerchant("Prime Video", []MerchantAlias{{MatchType: "starts_with", Pattern: "AMZN PRIME"}})
+	if err != nil {
+		t.Fatalf("CreateMerchant failed: %v", err)
+	}
+	if got := merchantOf(ids[2]); got == nil || *got != prime.ID {
+		t.Errorf("expected Prime Video, got %v", got)
+	}
+	tx, _ := store.GetTransaction(ids[1])
+	if tx.MerchantName != "Amazon" {
+		t.Errorf("expected merchant name Amazon, got %q", tx.MerchantName)
+	}
+	if got := merchantOf(ids[3]); got != nil {
+		t.Errorf("expected no merchant, got %v", *got)
+	}
+
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{"", ErrInvalidMerchantName},
+		{"amazon", ErrMerchantExists},
+	} {
+		if _, err := store.CreateMerchant(tc.name, nil); !errors.Is(err, tc.err) {
+			t.Errorf("CreateMerchant(%q): expected %v, got %v", tc.name, tc.err, err)
+		}
+	}
+	if _, err := store.RenameMerchant(prime.ID, "AMAZON"); !errors.Is(err, ErrMerchantExists) {
+		t.Errorf("expected ErrMerchantExists, got %v", err)
+	}
+	if _, err := store.AddMerchantAlias(prime.ID, MerchantAlias{MatchType: "starts_with", Pattern: "amzn"}); !errors.Is(err, ErrMerchantAliasExists) {
+		t.Errorf("expected ErrMerchantAliasExists, got %v", err)
+	}
+	if _, err := store.AddMerchantAlias(prime.ID, MerchantAlias{MatchType: "regex", Pattern: "x"}); !errors.Is(err, ErrInvalidMerchantAlias) {
+		t.Errorf("expected ErrInvalidMerchantAlias, got %v", err)
+	}
+	if _, err := store.AddMerchantAlias(999, MerchantAlias{MatchType: "exact", Pattern: "x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	// Edits and imports are assigned as they come in.
+	description := "Corner Store"
+	if _, err := store.UpdateTransaction(ids[1], TransactionUpdate{Description: &description}); err != nil {
+		t.Fatal(err)
+	}
+	if got := merchantOf(ids[1]); got != nil {
+		t.Errorf("expected no merchant after the edit, got %v", *got)
+	}
+	if err := store.BatchInsertTransactions([]TransactionModel{
+		{AccountID: card, Date: "2025-02-02", Description: "AMZN MKTP CA*9Z8Y7", Amount: -1200},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	txs, err := store.GetAnalysisTransactions("2025-02-01", "2025-02-28", nil, nil, nil, []int64{amazon.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || txs[0].MerchantName != "Amazon" {
+		t.Errorf("expected the imported Amazon transaction, got %+v", txs)
+	}
+	txs, err = store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, []int64{MissingFilterID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 {
+		t.Errorf("expected 2 transactions without a merchant, got %d", len(txs))
+	}
+
+	// Removing Prime Video's alias hands its transaction back to Amazon.
+	prime, err = store.DeleteMerchantAlias(prime.Aliases[0].ID)
+	if err != nil {
+		t.Fatalf("DeleteMerchantAlias failed: %v", err)
+	}
+	if len(prime.Aliases) != 0 || prime.TransactionCount != 0 {
+		t.Errorf("unexpected merchant after removing its alias: %+v", prime)
+	}
+	if got := merchantOf(ids[2]); got == nil || *got != amazon.ID {
+		t.Errorf("expected Amazon, got %v", got)
+	}
+
+	if err := store.DeleteMerchant(amazon.ID); err != nil {
+		t.Fatalf("DeleteMerchant failed: %v", err)
+	}
+	if got := merchantOf(ids[0]); got != nil {
+		t.Errorf("expected no merchant after deleting it, got %v", *got)
+	}
+	if err := store.DeleteMerchant(amazon.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+	merchants, err := store.GetMerchants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merchants) != 1 || merchants[0].Name != "Prime Video" {
+		t.Errorf("unexpected merchants: %+v", merchants)
+	}
+}
@@ -0,0 +1,54 @@
+package database
+
+import "testing"
+
+func TestMigration007_AddCategoryModel(t *testing.T) {
+	h := newMigrationTest(t, 7)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'Visa')`)
+	h.exec(`INSERT INTO categories (id, name) VALUES (1, 'Groceries')`)
+
+	h.run()
+
+	version := func() string {
+		t.Helper()
+		var value string
+		if err := h.db.QueryRow("SELECT value FROM app_settings WHERE key = 'category_model_version'").Scan(&value); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		return value
+	}
+	if got := version(); got != "1" {
+		t.Fatalf("expected initial version 1, got %s", got)
+	}
+
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount) VALUES (1, 1, '2025-01-01', 'Shop', -100)`)
+	h.exec(`UPDATE transactions SET date = '2025-01-03' WHERE id = 1`)
+	if got := version(); got != "1" {
+		t.Errorf("expected uncategorized writes to keep version 1, got %s", got)
+	}
+	h.exec(`UPDATE transactions SET category_id = 1 WHERE id = 1`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount, category_id) VALUES (2, 1, '2025-01-02', 'Shop', -200, 1)`)
+	h.exec(`DELETE FROM transactions WHERE id = 2`)
+	if got := version(); got != "4" {
+		t.Errorf("expected version 4 after three categorized writes, got %s", got)
+	}
+	h.exec(`INSERT INTO category_model_classes (category_id, doc_count, feature_count) VALUES (1, 1, 3)`)
+	h.exec(`INSERT INTO category_model_features (feature, category_id, count) VALUES ('w:shop', 1, 1)`)
+
+	h.runDown()
+
+	for _, table := range []string{"category_model_classes", "category_model_features"} {
+		if _, err := h.db.Exec("SELECT 1 FROM " + table); err == nil {
+			t.Errorf("expected %s table to be dropped", table)
+		}
+	}
+	h.exec(`UPDATE transactions SET category_id = NULL WHERE id = 1`)
+	var count int
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM app_settings WHERE key LIKE 'category_model_%'").Scan(&count); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected category model settings to be removed, got %d", count)
+	}
+}
@@ -0,0 +1,47 @@
+package database
+
+import "testing"
+
+func TestMigration008_AddRuleHitStats(t *testing.T) {
+	h := newMigrationTest(t, 8)
+
+	h.exec(`INSERT INTO categories (id, name) VALUES (1, 'Transport')`)
+	h.exec(`INSERT INTO categories (id, name) VALUES (2, 'Food')`)
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'Visa')`)
+	h.exec(`INSERT INTO categorization_rules (id, match_type, match_value, category_id) VALUES (1, 'contains', 'Uber', 1)`)
+	h.exec(`INSERT INTO categorization_rules (id, match_type, match_value, category_id) VALUES (2, 'exact', 'Taxi', 1)`)
+	h.exec(`INSERT INTO transactions (account_id, date, description, amount, category_id) VALUES
+		(1, '2025-01-05', 'UBER TRIP', -1500, 1),
+		(1, '2025-02-07', 'Uber trip', -2100, 1),
+		(1, '2025-03-01', 'UBER EATS', -3000, 2),
+		(1, '2025-03-02', 'Uber', -900, NULL)`)
+
+	h.run()
+
+	stats := func(id int64) (int64, *string) {
+		t.Helper()
+		var hitCount int64
+		var lastMatched *string
+		if err := h.db.QueryRow("SELECT hit_count, last_matched_date FROM categorization_rules WHERE id = ?", id).Scan(&hitCount, &lastMatched); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		return hitCount, lastMatched
+	}
+	// Only transactions carrying the rule's category count as its matches.
+	if hitCount, lastMatched := stats(1); hitCount != 2 || lastMatched == nil || *lastMatched != "2025-02-07" {
+		t.Errorf("expected 2 hits last matched 2025-02-07, got %d / %v", hitCount, lastMatched)
+	}
+	if hitCount, lastMatched := stats(2); hitCount != 0 || lastMatched != nil {
+		t.Errorf("expected unmatched rule to start with 0 hits and no date, got %d / %v", hitCount, lastMatched)
+	}
+
+	h.runDown()
+
+	var matchValue string
+	if err := h.db.QueryRow("SELECT match_value FROM categorization_rules WHERE id = 1").Scan(&matchValue); err != nil {
+		t.Fatalf("expected rule to survive down migration: %v", err)
+	}
+	if _, err := h.db.Exec("SELECT hit_count FROM categorization_rules"); err == nil {
+		t.Error("expected hit_count column to be dropped")
+	}
+}
@@ -0,0 +1,50 @@
+package database
+
+import "testing"
+
+func TestMigration009_AddCategorySource(t *testing.T) {
+	h := newMigrationTest(t, 9)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'BMO')`)
+	h.exec(`INSERT INTO categories (id, name) VALUES (1, 'Groceries'), (2, 'Transport')`)
+	h.exec(`INSERT INTO categorization_rules (id, match_type, match_value, category_id) VALUES
+		(1, 'starts_with', 'Costco', 1),
+		(2, 'contains', 'Lyft', 1)`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount, category_id) VALUES
+		(1, 1, '2024-01-01', 'Costco', -5000, 1),
+		(2, 1, '2024-01-02', 'Lyft', -1500, NULL),
+		(3, 1, '2024-01-03', 'Lyft ride', -1800, 2)`)
+
+	h.run()
+
+	source := func(id int64) (*string, *int64) {
+		t.Helper()
+		var src *string
+		var ruleID *int64
+		if err := h.db.QueryRow("SELECT category_source, category_rule_id FROM transactions WHERE id = ?", id).Scan(&src, &ruleID); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		return src, ruleID
+	}
+	if src, ruleID := source(1); src == nil || *src != CategorySourceRule || ruleID == nil || *ruleID != 1 {
+		t.Errorf("expected category matching a rule to be credited to rule 1, got %v / %v", src, ruleID)
+	}
+	if src, ruleID := source(2); src != nil || ruleID != nil {
+		t.Errorf("expected no source for uncategorized transaction, got %v / %v", src, ruleID)
+	}
+	// The Lyft rule points at another category, so this one stays manual.
+	if src, ruleID := source(3); src == nil || *src != CategorySourceManual || ruleID != nil {
+		t.Errorf("expected existing category to be marked manual, got %v / %v", src, ruleID)
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT category_source FROM transactions"); err == nil {
+		t.Error("expected category_source column to be dropped")
+	}
+	for _, table := range []string{"rule_apply_batches", "rule_apply_changes", "rule_apply_hits"} {
+		if _, err := h.db.Exec("SELECT 1 FROM " + table); err == nil {
+			t.Errorf("expected %s table to be dropped", table)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package database
+
+import "testing"
+
+func TestMigration010_AddCategoryParent(t *testing.T) {
+	h := newMigrationTest(t, 10)
+
+	h.exec(`INSERT INTO categories (id, name) VALUES (1, 'Groceries')`)
+
+	h.run()
+
+	var parentID *int64
+	if err := h.db.QueryRow("SELECT parent_id FROM categories WHERE id = 1").Scan(&parentID); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if parentID != nil {
+		t.Errorf("expected existing category to be top-level, got parent %d", *parentID)
+	}
+
+	h.runDown()
+
+	var name string
+	if err := h.db.QueryRow("SELECT name FROM categories WHERE id = 1").Scan(&name); err != nil {
+		t.Fatalf("expected category to survive down migration: %v", err)
+	}
+	if _, err := h.db.Exec("SELECT parent_id FROM categories"); err == nil {
+		t.Error("expected parent_id column to be dropped")
+	}
+}
@@ -0,0 +1,40 @@
+package database
+
+import "testing"
+
+func TestMigration011_AddCategoryKind(t *testing.T) {
+	h := newMigrationTest(t, 11)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'BMO')`)
+	h.exec(`INSERT INTO categories (id, name) VALUES (1, 'Salary'), (2, 'Groceries'), (3, 'Unused')`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount, category_id) VALUES
+		(1, 1, '2024-01-01', 'Payroll', 500000, 1),
+		(2, 1, '2024-01-02', 'Costco', -5000, 2),
+		(3, 1, '2024-01-03', 'Costco refund', 1000, 2)`)
+
+	h.run()
+
+	kinds := map[int64]string{}
+	rows, err := h.db.Query("SELECT id, kind FROM categories")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	for rows.Next() {
+		var id int64
+		var kind string
+		if err := rows.Scan(&id, &kind); err != nil {
+			t.Fatalf("scan failed: %v", err)
+		}
+		kinds[id] = kind
+	}
+	rows.Close()
+	if kinds[1] != CategoryKindIncome || kinds[2] != CategoryKindExpense || kinds[3] != CategoryKindExpense {
+		t.Errorf("unexpected kinds after migration: %v", kinds)
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT kind FROM categories"); err == nil {
+		t.Error("expected kind column to be dropped")
+	}
+}
@@ -0,0 +1,27 @@
+package database
+
+import "testing"
+
+func TestMigration012_AddTags(t *testing.T) {
+	h := newMigrationTest(t, 12)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'BMO')`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount) VALUES (1, 1, '2024-01-01', 'Hotel', -30000)`)
+
+	h.run()
+
+	h.exec(`INSERT INTO tags (id, name) VALUES (1, 'vacation')`)
+	h.exec(`INSERT INTO transaction_tags (transaction_id, tag_id) VALUES (1, 1)`)
+	if _, err := h.db.Exec(`INSERT INTO tags (name) VALUES ('VACATION')`); err == nil {
+		t.Error("expected tag names to be unique regardless of case")
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT 1 FROM transaction_tags"); err == nil {
+		t.Error("expected transaction_tags table to be dropped")
+	}
+	if _, err := h.db.Exec("SELECT 1 FROM tags"); err == nil {
+		t.Error("expected tags table to be dropped")
+	}
+}
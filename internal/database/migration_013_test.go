@@ -0,0 +1,28 @@
+package database
+
+import "testing"
+
+func TestMigration013_AddTransactionSplits(t *testing.T) {
+	h := newMigrationTest(t, 13)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'BMO')`)
+	h.exec(`INSERT INTO categories (id, name) VALUES (1, 'Groceries')`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount) VALUES (1, 1, '2024-01-01', 'Costco', -5000)`)
+
+	h.run()
+
+	h.exec(`INSERT INTO transaction_splits (transaction_id, amount, category_id) VALUES (1, -3000, 1), (1, -2000, 1)`)
+	var note string
+	if err := h.db.QueryRow("SELECT note FROM transaction_splits LIMIT 1").Scan(&note); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if note != "" {
+		t.Errorf("expected empty default note, got %q", note)
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT 1 FROM transaction_splits"); err == nil {
+		t.Error("expected transaction_splits table to be dropped")
+	}
+}
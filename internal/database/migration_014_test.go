@@ -0,0 +1,26 @@
+package database
+
+import "testing"
+
+func TestMigration014_AddTransactionNote(t *testing.T) {
+	h := newMigrationTest(t, 14)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'BMO')`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount) VALUES (1, 1, '2024-01-01', 'Costco', -5000)`)
+
+	h.run()
+
+	var note string
+	if err := h.db.QueryRow("SELECT note FROM transactions WHERE id = 1").Scan(&note); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if note != "" {
+		t.Errorf("expected empty note on existing transactions, got %q", note)
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT note FROM transactions"); err == nil {
+		t.Error("expected note column to be dropped")
+	}
+}
@@ -0,0 +1,23 @@
+package database
+
+import "testing"
+
+func TestMigration015_AddTransactionAttachments(t *testing.T) {
+	h := newMigrationTest(t, 15)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'BMO')`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount) VALUES (1, 1, '2024-01-01', 'Staples', -4200)`)
+
+	h.run()
+
+	h.exec(`INSERT INTO transaction_attachments (transaction_id, sha256, file_name, size) VALUES (1, 'abc', 'receipt.pdf', 10)`)
+	if _, err := h.db.Exec(`INSERT INTO transaction_attachments (transaction_id, sha256, file_name, size) VALUES (1, 'abc', 'copy.pdf', 10)`); err == nil {
+		t.Error("expected the same file to be linked to a transaction only once")
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT 1 FROM transaction_attachments"); err == nil {
+		t.Error("expected transaction_attachments table to be dropped")
+	}
+}
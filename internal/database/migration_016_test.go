@@ -0,0 +1,20 @@
+package database
+
+import "testing"
+
+func TestMigration016_AddKnownSubscriptions(t *testing.T) {
+	h := newMigrationTest(t, 16)
+
+	h.run()
+
+	h.exec(`INSERT INTO known_subscriptions (series_key) VALUES ('1|-|netflix com|1599')`)
+	if _, err := h.db.Exec(`INSERT INTO known_subscriptions (series_key) VALUES ('1|-|netflix com|1599')`); err == nil {
+		t.Error("expected a series to be marked only once")
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT 1 FROM known_subscriptions"); err == nil {
+		t.Error("expected known_subscriptions table to be dropped")
+	}
+}
@@ -0,0 +1,30 @@
+package database
+
+import "testing"
+
+func TestMigration017_AddBudgets(t *testing.T) {
+	h := newMigrationTest(t, 17)
+
+	h.exec(`INSERT INTO categories (id, name) VALUES (1, 'Groceries')`)
+
+	h.run()
+
+	h.exec(`INSERT INTO budgets (category_id, amount, start_month) VALUES (1, 50000, '2025-01')`)
+	var period string
+	var rollover bool
+	if err := h.db.QueryRow(`SELECT period, rollover FROM budgets WHERE category_id = 1`).Scan(&period, &rollover); err != nil {
+		t.Fatal(err)
+	}
+	if period != "monthly" || rollover {
+		t.Errorf("unexpected defaults: period=%q rollover=%v", period, rollover)
+	}
+	if _, err := h.db.Exec(`INSERT INTO budgets (category_id, amount, start_month) VALUES (1, 100, '2025-01')`); err == nil {
+		t.Error("expected one budget per category")
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT 1 FROM budgets"); err == nil {
+		t.Error("expected budgets table to be dropped")
+	}
+}
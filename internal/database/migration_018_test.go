@@ -0,0 +1,29 @@
+package database
+
+import "testing"
+
+func TestMigration018_AddAccountBalances(t *testing.T) {
+	h := newMigrationTest(t, 18)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'Checking')`)
+
+	h.run()
+
+	h.exec(`INSERT INTO account_balances (account_id, date, balance, currency) VALUES (1, '2025-01-31', 123456, 'CAD')`)
+	var source string
+	if err := h.db.QueryRow(`SELECT source FROM account_balances WHERE account_id = 1`).Scan(&source); err != nil {
+		t.Fatal(err)
+	}
+	if source != "manual" {
+		t.Errorf("expected manual source by default, got %q", source)
+	}
+	if _, err := h.db.Exec(`INSERT INTO account_balances (account_id, date, balance, currency) VALUES (1, '2025-01-31', 1, 'CAD')`); err == nil {
+		t.Error("expected one balance per account and date")
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT 1 FROM account_balances"); err == nil {
+		t.Error("expected account_balances table to be dropped")
+	}
+}
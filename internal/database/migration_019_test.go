@@ -0,0 +1,23 @@
+package database
+
+import "testing"
+
+func TestMigration019_AddTransferLinks(t *testing.T) {
+	h := newMigrationTest(t, 19)
+
+	h.run()
+
+	h.exec(`INSERT INTO transfer_links (out_transaction_id, in_transaction_id) VALUES (1, 2)`)
+	if _, err := h.db.Exec(`INSERT INTO transfer_links (out_transaction_id, in_transaction_id) VALUES (1, 3)`); err == nil {
+		t.Error("expected a transaction to be linked only once")
+	}
+	h.exec(`INSERT INTO transfer_dismissals (out_transaction_id, in_transaction_id) VALUES (1, 3)`)
+
+	h.runDown()
+
+	for _, table := range []string{"transfer_links", "transfer_dismissals"} {
+		if _, err := h.db.Exec("SELECT 1 FROM " + table); err == nil {
+			t.Errorf("expected %s table to be dropped", table)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package database
+
+import "testing"
+
+func TestMigration020_AddMerchants(t *testing.T) {
+	h := newMigrationTest(t, 20)
+
+	h.exec(`INSERT INTO accounts (id, name) VALUES (1, 'BMO')`)
+	h.exec(`INSERT INTO transactions (id, account_id, date, description, amount) VALUES (1, 1, '2024-01-01', 'Costco', -5000)`)
+
+	h.run()
+
+	var merchantID *int64
+	if err := h.db.QueryRow("SELECT merchant_id FROM transactions WHERE id = 1").Scan(&merchantID); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if merchantID != nil {
+		t.Errorf("expected no merchant on existing transactions, got %d", *merchantID)
+	}
+	h.exec(`INSERT INTO merchants (id, name) VALUES (1, 'Costco')`)
+	if _, err := h.db.Exec(`INSERT INTO merchants (name) VALUES ('COSTCO')`); err == nil {
+		t.Error("expected merchant names to be unique regardless of case")
+	}
+	h.exec(`INSERT INTO merchant_aliases (merchant_id, match_type, pattern) VALUES (1, 'contains', 'costco')`)
+	if _, err := h.db.Exec(`INSERT INTO merchant_aliases (merchant_id, match_type, pattern) VALUES (1, 'contains', 'costco')`); err == nil {
+		t.Error("expected alias patterns to be unique")
+	}
+
+	h.runDown()
+
+	if _, err := h.db.Exec("SELECT merchant_id FROM transactions"); err == nil {
+		t.Error("expected merchant_id column to be dropped")
+	}
+	for _, table := range []string{"merchants", "merchant_aliases"} {
+		if _, err := h.db.Exec("SELECT 1 FROM " + table); err == nil {
+			t.Errorf("expected %s table to be dropped", table)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS category_model_classes (
+    category_id INTEGER PRIMARY KEY,
+    doc_count INTEGER NOT NULL,
+    feature_count INTEGER NOT NULL,
+    FOREIGN KEY(category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS category_model_features (
+    feature TEXT NOT NULL,
+    category_id INTEGER NOT NULL,
+    count INTEGER NOT NULL,
+    PRIMARY KEY (feature, category_id),
+    FOREIGN KEY(category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+-- category_model_version is bumped on every write that changes what the
+-- model would learn from, so a stored model can be detected as stale.
+INSERT OR IGNORE INTO app_settings (key, value) VALUES ('category_model_version', '1');
+
+CREATE TRIGGER IF NOT EXISTS category_model_version_insert
+AFTER INSERT ON transactions
+WHEN NEW.category_id IS NOT NULL
+BEGIN
+    UPDATE app_settings SET value = CAST(value AS INTEGER) + 1 WHERE key = 'category_model_version';
+END;
+
+CREATE TRIGGER IF NOT EXISTS category_model_version_update
+AFTER UPDATE OF category_id, description, amount, account_id, currency ON transactions
+WHEN OLD.category_id IS NOT NULL OR NEW.category_id IS NOT NULL
+BEGIN
+    UPDATE app_settings SET value = CAST(value AS INTEGER) + 1 WHERE key = 'category_model_version';
+END;
+
+CREATE TRIGGER IF NOT EXISTS category_model_version_delete
+AFTER DELETE ON transactions
+WHEN OLD.category_id IS NOT NULL
+BEGIN
+    UPDATE app_settings SET value = CAST(value AS INTEGER) + 1 WHERE key = 'category_model_version';
+END;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS category_model_version_delete;
+DROP TRIGGER IF EXISTS category_model_version_update;
+DROP TRIGGER IF EXISTS category_model_version_insert;
+DROP TABLE IF EXISTS category_model_features;
+DROP TABLE IF EXISTS category_model_classes;
+DELETE FROM app_settings WHERE key IN ('category_model_version', 'category_model_trained_version', 'category_model_trained_at');
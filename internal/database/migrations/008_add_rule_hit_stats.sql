@@ -0,0 +1,26 @@
+ALTER TABLE categorization_rules ADD COLUMN hit_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE categorization_rules ADD COLUMN last_matched_date TEXT;
+
+-- Seed the stats from the transactions each rule currently matches with its
+-- category, so existing rules don't all look unused after the upgrade. Amount
+-- bounds are compared against the transaction's own amount.
+WITH matches AS (
+    SELECT r.id AS rule_id, COUNT(t.id) AS hits, MAX(t.date) AS last_date
+    FROM categorization_rules r
+    JOIN transactions t ON t.category_id = r.category_id
+    WHERE CASE r.match_type
+            WHEN 'contains' THEN t.description LIKE '%' || r.match_value || '%'
+            WHEN 'starts_with' THEN t.description LIKE r.match_value || '%'
+            WHEN 'ends_with' THEN t.description LIKE '%' || r.match_value
+            WHEN 'exact' THEN t.description = r.match_value
+            ELSE 0
+        END
+        AND (r.amount_min IS NULL OR t.amount >= r.amount_min)
+        AND (r.amount_max IS NULL OR t.amount <= r.amount_max)
+    GROUP BY r.id
+)
+UPDATE categorization_rules
+SET hit_count = matches.hits,
+    last_matched_date = matches.last_date
+FROM matches
+WHERE categorization_rules.id = matches.rule_id;
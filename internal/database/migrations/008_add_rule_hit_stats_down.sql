@@ -0,0 +1,2 @@
+ALTER TABLE categorization_rules DROP COLUMN last_matched_date;
+ALTER TABLE categorization_rules DROP COLUMN hit_count;
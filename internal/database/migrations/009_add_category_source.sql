@@ -0,0 +1,58 @@
+ALTER TABLE transactions ADD COLUMN category_source TEXT;
+ALTER TABLE transactions ADD COLUMN category_rule_id INTEGER;
+
+-- Existing categories that a rule for the same category matches are credited
+-- to that rule so `rules apply --overwrite rule-only` can still replace them;
+-- everything else is treated as manual. Amount bounds are compared against the
+-- transaction's own amount.
+UPDATE transactions SET category_source = 'manual' WHERE category_id IS NOT NULL;
+
+WITH matches AS (
+    SELECT t.id AS transaction_id, MIN(r.id) AS rule_id
+    FROM transactions t
+    JOIN categorization_rules r ON r.category_id = t.category_id
+    WHERE CASE r.match_type
+            WHEN 'contains' THEN t.description LIKE '%' || r.match_value || '%'
+            WHEN 'starts_with' THEN t.description LIKE r.match_value || '%'
+            WHEN 'ends_with' THEN t.description LIKE '%' || r.match_value
+            WHEN 'exact' THEN t.description = r.match_value
+            ELSE 0
+        END
+        AND (r.amount_min IS NULL OR t.amount >= r.amount_min)
+        AND (r.amount_max IS NULL OR t.amount <= r.amount_max)
+    GROUP BY t.id
+)
+UPDATE transactions
+SET category_source = 'rule',
+    category_rule_id = matches.rule_id
+FROM matches
+WHERE transactions.id = matches.transaction_id;
+
+CREATE TABLE IF NOT EXISTS rule_apply_batches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    overwrite TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS rule_apply_changes (
+    batch_id INTEGER NOT NULL,
+    transaction_id INTEGER NOT NULL,
+    old_category_id INTEGER,
+    old_category_source TEXT,
+    old_category_rule_id INTEGER,
+    new_category_id INTEGER NOT NULL,
+    new_category_rule_id INTEGER NOT NULL,
+    PRIMARY KEY (batch_id, transaction_id),
+    FOREIGN KEY(batch_id) REFERENCES rule_apply_batches(id) ON DELETE CASCADE
+);
+
+-- Rule hit stats recorded by each batch, so undo can roll them back.
+CREATE TABLE IF NOT EXISTS rule_apply_hits (
+    batch_id INTEGER NOT NULL,
+    rule_id INTEGER NOT NULL,
+    hits INTEGER NOT NULL,
+    old_last_matched_date TEXT,
+    new_last_matched_date TEXT NOT NULL,
+    PRIMARY KEY (batch_id, rule_id),
+    FOREIGN KEY(batch_id) REFERENCES rule_apply_batches(id) ON DELETE CASCADE
+);
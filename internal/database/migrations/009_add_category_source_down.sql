@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS rule_apply_hits;
+DROP TABLE IF EXISTS rule_apply_changes;
+DROP TABLE IF EXISTS rule_apply_batches;
+
+ALTER TABLE transactions DROP COLUMN category_rule_id;
+ALTER TABLE transactions DROP COLUMN category_source;
@@ -0,0 +1,5 @@
+-- Categories form a tree; top-level categories have no parent. Cycles are
+-- prevented in code when a category is moved.
+ALTER TABLE categories ADD COLUMN parent_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
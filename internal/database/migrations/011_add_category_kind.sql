@@ -0,0 +1,12 @@
+-- kind decides how a category counts in totals: income and expense categories
+-- add up to net, transfer and excluded categories are left out.
+ALTER TABLE categories ADD COLUMN kind TEXT NOT NULL DEFAULT 'expense';
+
+-- Categories that have only ever received money are most likely income.
+UPDATE categories SET kind = 'income'
+WHERE id IN (
+    SELECT category_id FROM transactions
+    WHERE category_id IS NOT NULL
+    GROUP BY category_id
+    HAVING MIN(amount) > 0
+);
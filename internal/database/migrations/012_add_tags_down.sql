@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transaction_tags_tag;
+DROP TABLE IF EXISTS transaction_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    category_id INTEGER,
+    owner_id INTEGER,
+    note TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY(transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY(category_id) REFERENCES categories(id),
+    FOREIGN KEY(owner_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction ON transaction_splits(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_category ON transaction_splits(category_id);
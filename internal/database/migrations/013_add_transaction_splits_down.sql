@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transaction_splits_category;
+DROP INDEX IF EXISTS idx_transaction_splits_transaction;
+DROP TABLE IF EXISTS transaction_splits;
@@ -0,0 +1,3 @@
+-- note is a free-text memo kept with the transaction, e.g. "reimbursed by
+-- employer". It is not part of the duplicate-detection key.
+ALTER TABLE transactions ADD COLUMN note TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+-- Attachment files live next to the database, named by the SHA-256 of their
+-- content; rows link them to transactions. The same file attached to several
+-- transactions is stored once.
+CREATE TABLE IF NOT EXISTS transaction_attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL,
+    sha256 TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    mime_type TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(transaction_id, sha256),
+    FOREIGN KEY(transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_attachments_sha256 ON transaction_attachments(sha256);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transaction_attachments_sha256;
+DROP TABLE IF EXISTS transaction_attachments;
@@ -0,0 +1,6 @@
+-- Recurring series the user has confirmed as known subscriptions. Series are
+-- detected on the fly, so they are referenced by their detection key.
+CREATE TABLE IF NOT EXISTS known_subscriptions (
+    series_key TEXT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,13 @@
+-- One spending budget per category. amount is in the main currency per
+-- period; with rollover, what is left of (or overspent in) a month carries
+-- into the next, counting from start_month.
+CREATE TABLE IF NOT EXISTS budgets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category_id INTEGER NOT NULL UNIQUE,
+    period TEXT NOT NULL DEFAULT 'monthly',
+    amount INTEGER NOT NULL,
+    rollover INTEGER NOT NULL DEFAULT 0,
+    start_month TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY(category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
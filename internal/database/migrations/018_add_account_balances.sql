@@ -0,0 +1,16 @@
+-- Known balances of an account at the end of a day, entered manually or taken
+-- from a statement's closing balance. balance is in cents of currency.
+CREATE TABLE IF NOT EXISTS account_balances (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    date TEXT NOT NULL,
+    balance INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'manual',
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    UNIQUE(account_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_balances_account_date ON account_balances(account_id, date);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_account_balances_account_date;
+DROP TABLE IF EXISTS account_balances;
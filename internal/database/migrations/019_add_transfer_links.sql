@@ -0,0 +1,18 @@
+-- Pairs of transactions that move money between two of your own accounts:
+-- the outgoing side in one account and the incoming side in another.
+CREATE TABLE IF NOT EXISTS transfer_links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    out_transaction_id INTEGER NOT NULL UNIQUE,
+    in_transaction_id INTEGER NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY(out_transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY(in_transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+-- Pairs that were unlinked by hand, so detection does not link them again.
+CREATE TABLE IF NOT EXISTS transfer_dismissals (
+    out_transaction_id INTEGER NOT NULL,
+    in_transaction_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY(out_transaction_id, in_transaction_id)
+);
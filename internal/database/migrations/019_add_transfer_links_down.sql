@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS transfer_dismissals;
+DROP TABLE IF EXISTS transfer_links;
@@ -0,0 +1,24 @@
+-- Merchants group the description variants of one payee.
+CREATE TABLE IF NOT EXISTS merchants (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Alias patterns match descriptions like categorization rules do; a
+-- transaction belongs to the merchant of its best matching alias.
+CREATE TABLE IF NOT EXISTS merchant_aliases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    merchant_id INTEGER NOT NULL,
+    match_type TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(match_type, pattern),
+    FOREIGN KEY(merchant_id) REFERENCES merchants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_aliases_merchant ON merchant_aliases(merchant_id);
+
+ALTER TABLE transactions ADD COLUMN merchant_id INTEGER REFERENCES merchants(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_merchant ON transactions(merchant_id);
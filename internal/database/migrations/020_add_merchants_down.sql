@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_transactions_merchant;
+ALTER TABLE transactions DROP COLUMN merchant_id;
+
+DROP TABLE IF EXISTS merchant_aliases;
+DROP TABLE IF EXISTS merchants;
@@ -0,0 +1,281 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+const (
+	// RuleOverwriteNone only categorizes uncategorized transactions.
+	RuleOverwriteNone = "none"
+	// RuleOverwriteRuleOnly also replaces categories previously set by rules.
+	RuleOverwriteRuleOnly = "rule-only"
+	// RuleOverwriteAll also replaces manual and predicted categories.
+	RuleOverwriteAll = "all"
+)
+
+type RuleApplyChange struct {
+	TransactionID   int64  `json:"transaction_id"`
+	Date            string `json:"date"`
+	Description     string `json:"description"`
+	OldCategoryID   *int64 `json:"old_category_id"`
+	OldCategoryName string `json:"old_category_name"`
+	OldSource       string `json:"old_source"`
+	NewCategoryID   int64  `json:"new_category_id"`
+	NewCategoryName string `json:"new_category_name"`
+	RuleID          int64  `json:"rule_id"`
+
+	oldRuleID *int64
+}
+
+type RuleApplyResult struct {
+	// BatchID identifies the change set for undo; nil on dry runs or when nothing changed.
+	BatchID   *int64            `json:"batch_id"`
+	Overwrite string            `json:"overwrite"`
+	DryRun    bool              `json:"dry_run"`
+	Changes   []RuleApplyChange `json:"changes"`
+}
+
+type RuleApplyUndoResult struct {
+	BatchID int64 `json:"batch_id"`
+	// Restored counts transactions set back to their previous category.
+	Restored int `json:"restored"`
+	// Skipped counts transactions changed again since the batch, or whose previous
+	// category no longer exists; they are left as they are.
+	Skipped int `json:"skipped"`
+}
+
+func ruleOverwriteScope(overwrite string) (string, error) {
+	switch overwrite {
+	case "", RuleOverwriteNone:
+		return "category_id IS NULL", nil
+	case RuleOverwriteRuleOnly:
+		return "(category_id IS NULL OR category_source = '" + CategorySourceRule + "')", nil
+	case RuleOverwriteAll:
+		return "1 = 1", nil
+	}
+	return "", fmt.Errorf("unknown overwrite policy %q", overwrite)
+}
+
+// ReapplyRules runs rules in priority order over the transactions allowed by the
+// overwrite policy; the first matching rule decides each transaction's category.
+// When ruleIDs is empty all rules are used. Unless dryRun is set, changes are
+// written together with an undo batch.
+func (s *Store) ReapplyRules(ruleIDs []int64, overwrite string, dryRun bool) (RuleApplyResult, error) {
+	if overwrite == "" {
+		overwrite = RuleOverwriteNone
+	}
+	result := RuleApplyResult{Overwrite: overwrite, DryRun: dryRun, Changes: []RuleApplyChange{}}
+
+	scope, err := ruleOverwriteScope(overwrite)
+	if err != nil {
+		return result, err
+	}
+
+	rules, err := s.GetRules()
+	if err != nil {
+		return result, err
+	}
+	if len(ruleIDs) > 0 {
+		selected := make(map[int64]bool, len(ruleIDs))
+		for _, id := range ruleIDs {
+			selected[id] = true
+		}
+		filtered := rules[:0]
+		for _, r := range rules {
+			if selected[r.ID] {
+				filtered = append(filtered, r)
+				delete(selected, r.ID)
+			}
+		}
+		for id := range selected {
+			return result, fmt.Errorf("rule %d not found: %w", id, sql.ErrNoRows)
+		}
+		rules = filtered
+	}
+
+	categories, err := s.loadCategories()
+	if err != nil {
+		return result, err
+	}
+	categoryNames := make(map[int64]string, len(categories))
+	for _, c := range categories {
+		categoryNames[c.ID] = c.Name
+	}
+
+	decided := make(map[int64]bool)
+	for _, r := range rules {
+		matches, err := s.matchRuleTransactions(r, scope)
+		if err != nil {
+			return result, err
+		}
+		for _, m := range matches {
+			if decided[m.id] {
+				continue
+			}
+			decided[m.id] = true
+			if m.categoryID != nil && *m.categoryID == r.CategoryID {
+				continue
+			}
+			change := RuleApplyChange{
+				TransactionID:   m.id,
+				Date:            m.date,
+				Description:     m.description,
+				OldCategoryID:   m.categoryID,
+				OldSource:       m.categorySource,
+				NewCategoryID:   r.CategoryID,
+				NewCategoryName: categoryNames[r.CategoryID],
+				RuleID:          r.ID,
+				oldRuleID:       m.categoryRuleID,
+			}
+			if m.categoryID != nil {
+				change.OldCategoryName = categoryNames[*m.categoryID]
+			}
+			result.Changes = append(result.Changes, change)
+		}
+	}
+	sort.Slice(result.Changes, func(i, j int) bool {
+		return result.Changes[i].TransactionID < result.Changes[j].TransactionID
+	})
+
+	if dryRun || len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO rule_apply_batches (overwrite) VALUES (?)", overwrite)
+	if err != nil {
+		return result, err
+	}
+	batchID, err := res.LastInsertId()
+	if err != nil {
+		return result, err
+	}
+
+	hits := make(map[int64]int64)
+	lastMatched := make(map[int64]string)
+	for _, c := range result.Changes {
+		if _, err := tx.Exec(`
+			INSERT INTO rule_apply_changes
+			(batch_id, transaction_id, old_category_id, old_category_source, old_category_rule_id, new_category_id, new_category_rule_id)
+			VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?)
+		`, batchID, c.TransactionID, c.OldCategoryID, c.OldSource, c.oldRuleID, c.NewCategoryID, c.RuleID); err != nil {
+			return result, err
+		}
+		if _, err := tx.Exec(
+			"UPDATE transactions SET category_id = ?, category_source = ?, category_rule_id = ? WHERE id = ?",
+			c.NewCategoryID, CategorySourceRule, c.RuleID, c.TransactionID,
+		); err != nil {
+			return result, err
+		}
+		hits[c.RuleID]++
+		if c.Date > lastMatched[c.RuleID] {
+			lastMatched[c.RuleID] = c.Date
+		}
+	}
+	for ruleID, n := range hits {
+		var oldLastMatched sql.NullString
+		if err := tx.QueryRow("SELECT last_matched_date FROM categorization_rules WHERE id = ?", ruleID).Scan(&oldLastMatched); err != nil {
+			return result, err
+		}
+		newLastMatched := lastMatched[ruleID]
+		if oldLastMatched.String > newLastMatched {
+			newLastMatched = oldLastMatched.String
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO rule_apply_hits (batch_id, rule_id, hits, old_last_matched_date, new_last_matched_date)
+			VALUES (?, ?, ?, ?, ?)
+		`, batchID, ruleID, n, oldLastMatched, newLastMatched); err != nil {
+			return result, err
+		}
+		if err := recordRuleHits(tx, ruleID, n, lastMatched[ruleID]); err != nil {
+			return result, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	result.BatchID = &batchID
+	return result, nil
+}
+
+// UndoRuleApply restores the categories a ReapplyRules batch replaced, rolls
+// back the rule hit stats it recorded and deletes the batch.
+func (s *Store) UndoRuleApply(batchID int64) (RuleApplyUndoResult, error) {
+	result := RuleApplyUndoResult{BatchID: batchID}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT 1 FROM rule_apply_batches WHERE id = ?", batchID).Scan(&exists); err != nil {
+		return result, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE transactions
+		SET category_id = c.old_category_id,
+			category_source = c.old_category_source,
+			category_rule_id = c.old_category_rule_id
+		FROM rule_apply_changes c
+		WHERE c.batch_id = ?
+			AND transactions.id = c.transaction_id
+			AND transactions.category_id = c.new_category_id
+			AND transactions.category_rule_id = c.new_category_rule_id
+			AND (c.old_category_id IS NULL OR EXISTS (SELECT 1 FROM categories WHERE id = c.old_category_id))
+	`, batchID)
+	if err != nil {
+		return result, err
+	}
+	restored, err := res.RowsAffected()
+	if err != nil {
+		return result, err
+	}
+
+	var total int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM rule_apply_changes WHERE batch_id = ?", batchID).Scan(&total); err != nil {
+		return result, err
+	}
+
+	// Roll back the batch's hits. The last matched date is only restored while
+	// no later match has moved it.
+	if _, err := tx.Exec(`
+		UPDATE categorization_rules
+		SET hit_count = MAX(hit_count - h.hits, 0),
+			last_matched_date = CASE
+				WHEN last_matched_date = h.new_last_matched_date THEN h.old_last_matched_date
+				ELSE last_matched_date
+			END
+		FROM rule_apply_hits h
+		WHERE h.batch_id = ? AND categorization_rules.id = h.rule_id
+	`, batchID); err != nil {
+		return result, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM rule_apply_hits WHERE batch_id = ?", batchID); err != nil {
+		return result, err
+	}
+	if _, err := tx.Exec("DELETE FROM rule_apply_changes WHERE batch_id = ?", batchID); err != nil {
+		return result, err
+	}
+	if _, err := tx.Exec("DELETE FROM rule_apply_batches WHERE id = ?", batchID); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	result.Restored = int(restored)
+	result.Skipped = total - int(restored)
+	return result, nil
+}
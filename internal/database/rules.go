@@ -2,19 +2,39 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"strings"
 
 	"github.com/default-anton/cashmop/internal/fuzzy"
 )
 
+const (
+	CategorySourceManual     = "manual"
+	CategorySourceRule       = "rule"
+	CategorySourcePrediction = "prediction"
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
 type Category struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id"`
+	// FullName is the path from the top-level category, e.g. "Food > Groceries".
+	FullName string `json:"full_name"`
+	// Kind is one of the CategoryKind constants.
+	Kind string `json:"kind"`
 }
 
 type CategorySummary struct {
 	ID               int64  `json:"id"`
 	Name             string `json:"name"`
+	ParentID         *int64 `json:"parent_id"`
+	FullName         string `json:"full_name"`
+	Kind             string `json:"kind"`
 	TransactionCount int64  `json:"transaction_count"`
 	RuleCount        int64  `json:"rule_count"`
 	LastUsedDate     string `json:"last_used_date"`
@@ -25,6 +45,12 @@ type CategoryDeleteStats struct {
 	CategoryName       string `json:"category_name"`
 	UncategorizedCount int64  `json:"uncategorized_count"`
 	DeletedRuleCount   int64  `json:"deleted_rule_count"`
+	// ReassignedToID is set when transactions and rules moved to another
+	// category instead of being uncategorized and deleted.
+	ReassignedToID   *int64 `json:"reassigned_to_id"`
+	ReassignedToName string `json:"reassigned_to_name,omitempty"`
+	ReassignedCount  int64  `json:"reassigned_count"`
+	MovedRuleCount   int64  `json:"moved_rule_count"`
 }
 
 type CategorizationRule struct {
@@ -36,6 +62,10 @@ type CategorizationRule struct {
 	AmountMin    *int64 `json:"amount_min"`
 	AmountMax    *int64 `json:"amount_max"`
 	CreatedAt    string `json:"created_at"`
+	// HitCount is the number of transactions the rule has categorized.
+	HitCount int64 `json:"hit_count"`
+	// LastMatchedDate is the latest transaction date the rule has categorized.
+	LastMatchedDate string `json:"last_matched_date"`
 }
 
 func (s *Store) invalidateCategoryCache() {
@@ -58,7 +88,7 @@ func (s *Store) loadCategories() ([]Category, error) {
 		return s.categoryCache, nil
 	}
 
-	rows, err := s.db.Query("SELECT id, name FROM categories ORDER BY name ASC")
+	rows, err := s.db.Query("SELECT id, name, parent_id, kind FROM categories ORDER BY name ASC")
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +97,7 @@ func (s *Store) loadCategories() ([]Category, error) {
 	categories := []Category{}
 	for rows.Next() {
 		var c Category
-		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+		if err := rows.Scan(&c.ID, &c.Name, &c.ParentID, &c.Kind); err != nil {
 			return nil, err
 		}
 		categories = append(categories, c)
@@ -75,6 +105,7 @@ func (s *Store) loadCategories() ([]Category, error) {
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	fillCategoryFullNames(categories)
 
 	s.categoryCache = categories
 	return cloneCategories(categories), nil
@@ -131,13 +162,13 @@ func (s *Store) RenameCategory(id int64, newName string) error {
 }
 
 func (s *Store) GetCategorySummaries() ([]CategorySummary, error) {
-	rows, err := s.db.Query(`
+	rows, err := s.db.Query(expandedTransactionsCTE + `
 		SELECT
 			c.id,
 			c.name,
-			(SELECT COUNT(*) FROM transactions t WHERE t.category_id = c.id) AS transaction_count,
+			(SELECT COUNT(*) FROM expanded t WHERE t.category_id = c.id) AS transaction_count,
 			(SELECT COUNT(*) FROM categorization_rules r WHERE r.category_id = c.id) AS rule_count,
-			(SELECT MAX(t.date) FROM transactions t WHERE t.category_id = c.id) AS last_used_date
+			(SELECT MAX(t.date) FROM expanded t WHERE t.category_id = c.id) AS last_used_date
 		FROM categories c
 		ORDER BY LOWER(c.name) ASC
 	`)
@@ -162,6 +193,21 @@ func (s *Store) GetCategorySummaries() ([]CategorySummary, error) {
 		return nil, err
 	}
 
+	categories, err := s.loadCategories()
+	if err != nil {
+		return nil, err
+	}
+	byID := categoriesByID(categories)
+	for i := range summaries {
+		if c, ok := byID[summaries[i].ID]; ok {
+			summaries[i].ParentID = c.ParentID
+			summaries[i].FullName = c.FullName
+			summaries[i].Kind = c.Kind
+		} else {
+			summaries[i].FullName = summaries[i].Name
+		}
+	}
+
 	return summaries, nil
 }
 
@@ -186,13 +232,30 @@ func (s *Store) DeleteCategory(id int64) (CategoryDeleteStats, error) {
 		return result, err
 	}
 
-	if _, err := tx.Exec("UPDATE transactions SET category_id = NULL WHERE category_id = ?", id); err != nil {
+	var splitCount int64
+	if err := tx.QueryRow("SELECT COUNT(*) FROM transaction_splits WHERE category_id = ?", id).Scan(&splitCount); err != nil {
+		return result, err
+	}
+	result.UncategorizedCount += splitCount
+
+	if _, err := tx.Exec("UPDATE transaction_splits SET category_id = NULL WHERE category_id = ?", id); err != nil {
+		return result, err
+	}
+	if _, err := tx.Exec("UPDATE transactions SET category_id = NULL, category_source = NULL, category_rule_id = NULL WHERE category_id = ?", id); err != nil {
 		return result, err
 	}
 
 	if _, err := tx.Exec("DELETE FROM categorization_rules WHERE category_id = ?", id); err != nil {
 		return result, err
 	}
+	if _, err := tx.Exec("DELETE FROM budgets WHERE category_id = ?", id); err != nil {
+		return result, err
+	}
+
+	// Subcategories move up to the deleted category's parent.
+	if _, err := tx.Exec("UPDATE categories SET parent_id = (SELECT parent_id FROM categories WHERE id = ?) WHERE parent_id = ?", id, id); err != nil {
+		return result, err
+	}
 
 	deleteRes, err := tx.Exec("DELETE FROM categories WHERE id = ?", id)
 	if err != nil {
@@ -228,7 +291,8 @@ func (s *Store) SaveRule(rule CategorizationRule) (int64, error) {
 
 func (s *Store) GetRules() ([]CategorizationRule, error) {
 	rows, err := s.db.Query(`
-        SELECT r.id, r.match_type, r.match_value, r.category_id, COALESCE(c.name, ''), r.amount_min, r.amount_max, r.created_at
+        SELECT r.id, r.match_type, r.match_value, r.category_id, COALESCE(c.name, ''), r.amount_min, r.amount_max, r.created_at,
+            r.hit_count, COALESCE(r.last_matched_date, '')
         FROM categorization_rules r
         LEFT JOIN categories c ON r.category_id = c.id
         ORDER BY 
@@ -257,7 +321,7 @@ func (s *Store) GetRules() ([]CategorizationRule, error) {
 	rules := []CategorizationRule{}
 	for rows.Next() {
 		var r CategorizationRule
-		if err := rows.Scan(&r.ID, &r.MatchType, &r.MatchValue, &r.CategoryID, &r.CategoryName, &r.AmountMin, &r.AmountMax, &r.CreatedAt); err != nil {
+		if err := rows.Scan(&r.ID, &r.MatchType, &r.MatchValue, &r.CategoryID, &r.CategoryName, &r.AmountMin, &r.AmountMax, &r.CreatedAt, &r.HitCount, &r.LastMatchedDate); err != nil {
 			return nil, err
 		}
 		rules = append(rules, r)
@@ -268,11 +332,12 @@ func (s *Store) GetRules() ([]CategorizationRule, error) {
 func (s *Store) GetRuleByID(id int64) (CategorizationRule, error) {
 	var r CategorizationRule
 	err := s.db.QueryRow(`
-        SELECT r.id, r.match_type, r.match_value, r.category_id, COALESCE(c.name, ''), r.amount_min, r.amount_max, r.created_at
+        SELECT r.id, r.match_type, r.match_value, r.category_id, COALESCE(c.name, ''), r.amount_min, r.amount_max, r.created_at,
+            r.hit_count, COALESCE(r.last_matched_date, '')
         FROM categorization_rules r
         LEFT JOIN categories c ON r.category_id = c.id
         WHERE r.id = ?
-    `, id).Scan(&r.ID, &r.MatchType, &r.MatchValue, &r.CategoryID, &r.CategoryName, &r.AmountMin, &r.AmountMax, &r.CreatedAt)
+    `, id).Scan(&r.ID, &r.MatchType, &r.MatchValue, &r.CategoryID, &r.CategoryName, &r.AmountMin, &r.AmountMax, &r.CreatedAt, &r.HitCount, &r.LastMatchedDate)
 	if err != nil {
 		return CategorizationRule{}, err
 	}
@@ -315,25 +380,65 @@ func (s *Store) ApplyRuleWithIds(ruleID int64) (int64, []int64, error) {
 		return 0, nil, err
 	}
 
-	selectQuery := "SELECT id, amount, currency, date FROM transactions WHERE category_id IS NULL"
-	var matchClause string
-	selectArgs := []interface{}{}
+	matches, err := s.matchRuleTransactions(r, "category_id IS NULL")
+	if err != nil {
+		return 0, nil, err
+	}
 
-	switch r.MatchType {
-	case "contains":
-		matchClause = "description LIKE ?"
-		selectArgs = append(selectArgs, "%"+r.MatchValue+"%")
-	case "starts_with":
-		matchClause = "description LIKE ?"
-		selectArgs = append(selectArgs, r.MatchValue+"%")
-	case "ends_with":
-		matchClause = "description LIKE ?"
-		selectArgs = append(selectArgs, "%"+r.MatchValue)
-	case "exact":
-		matchClause = "description = ?"
-		selectArgs = append(selectArgs, r.MatchValue)
+	var affectedIds []int64
+	var lastMatchedDate string
+	for _, m := range matches {
+		affectedIds = append(affectedIds, m.id)
+		if m.date > lastMatchedDate {
+			lastMatchedDate = m.date
+		}
+	}
+
+	if len(affectedIds) == 0 {
+		return 0, affectedIds, nil
 	}
 
+	placeholders := make([]string, len(affectedIds))
+	updateArgs := make([]interface{}, 0, len(affectedIds)+3)
+	updateArgs = append(updateArgs, r.CategoryID, CategorySourceRule, ruleID)
+	for i, id := range affectedIds {
+		placeholders[i] = "?"
+		updateArgs = append(updateArgs, id)
+	}
+
+	updateQuery := "UPDATE transactions SET category_id = ?, category_source = ?, category_rule_id = ? WHERE id IN (" + strings.Join(placeholders, ",") + ")"
+	res, err := s.db.Exec(updateQuery, updateArgs...)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	affectedCount, _ := res.RowsAffected()
+
+	if err := recordRuleHits(s.db, ruleID, affectedCount, lastMatchedDate); err != nil {
+		return 0, nil, err
+	}
+
+	return affectedCount, affectedIds, nil
+}
+
+type ruleTransactionMatch struct {
+	id             int64
+	date           string
+	description    string
+	categoryID     *int64
+	categorySource string
+	categoryRuleID *int64
+}
+
+// matchRuleTransactions returns the transactions satisfying scope (a SQL
+// condition on the transactions table) that the rule matches, applying the
+// description condition in SQL and amount bounds on the main-currency amount.
+func (s *Store) matchRuleTransactions(r CategorizationRule, scope string) ([]ruleTransactionMatch, error) {
+	selectQuery := `
+		SELECT id, amount, currency, date, COALESCE(description, ''), category_id, COALESCE(category_source, ''), category_rule_id
+		FROM transactions
+		WHERE ` + scope
+	matchClause, selectArgs := ruleMatchClause(r.MatchType, r.MatchValue)
 	if matchClause != "" {
 		selectQuery += " AND " + matchClause
 	}
@@ -341,70 +446,150 @@ func (s *Store) ApplyRuleWithIds(ruleID int64) (int64, []int64, error) {
 	var baseCurrency string
 	needsAmountFilter := r.AmountMin != nil || r.AmountMax != nil
 	if needsAmountFilter {
-		settings, err := s.GetCurrencySettings()
+		var err error
+		baseCurrency, err = s.ruleBaseCurrency()
 		if err != nil {
-			return 0, nil, err
-		}
-		baseCurrency = strings.TrimSpace(settings.MainCurrency)
-		if baseCurrency == "" {
-			baseCurrency = defaultMainCurrency
+			return nil, err
 		}
 	}
 
 	rows, err := s.db.Query(selectQuery, selectArgs...)
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var affectedIds []int64
+	var matches []ruleTransactionMatch
 	for rows.Next() {
-		var id int64
+		var m ruleTransactionMatch
 		var amount int64
 		var currency string
-		var date string
-		if err := rows.Scan(&id, &amount, &currency, &date); err != nil {
-			return 0, nil, err
+		if err := rows.Scan(&m.id, &amount, &currency, &m.date, &m.description, &m.categoryID, &m.categorySource, &m.categoryRuleID); err != nil {
+			return nil, err
 		}
 		if needsAmountFilter {
-			converted, err := s.ConvertAmount(amount, baseCurrency, currency, date)
+			converted, err := s.ConvertAmount(amount, baseCurrency, currency, m.date)
 			if err != nil {
-				return 0, nil, err
-			}
-			if converted == nil {
-				continue
-			}
-			if r.AmountMin != nil && *converted < *r.AmountMin {
-				continue
+				return nil, err
 			}
-			if r.AmountMax != nil && *converted > *r.AmountMax {
+			if !ruleAmountMatches(r, converted) {
 				continue
 			}
 		}
-		affectedIds = append(affectedIds, id)
+		matches = append(matches, m)
 	}
+	return matches, rows.Err()
+}
 
-	if len(affectedIds) == 0 {
-		return 0, affectedIds, nil
+// recordRuleHits adds to the rule's hit count and moves its last matched date
+// forward when the newly categorized transactions are more recent.
+func recordRuleHits(db execer, ruleID int64, hits int64, lastMatchedDate string) error {
+	if hits == 0 {
+		return nil
 	}
+	_, err := db.Exec(`
+		UPDATE categorization_rules
+		SET hit_count = hit_count + ?,
+			last_matched_date = CASE
+				WHEN last_matched_date IS NULL OR last_matched_date < ? THEN ?
+				ELSE last_matched_date
+			END
+		WHERE id = ?
+	`, hits, lastMatchedDate, lastMatchedDate, ruleID)
+	return err
+}
 
-	placeholders := make([]string, len(affectedIds))
-	updateArgs := make([]interface{}, 0, len(affectedIds)+1)
-	updateArgs = append(updateArgs, r.CategoryID)
-	for i, id := range affectedIds {
-		placeholders[i] = "?"
-		updateArgs = append(updateArgs, id)
+// ruleMatchClause returns the SQL condition on the description column used to
+// match a rule. Unknown match types yield no condition.
+func ruleMatchClause(matchType, matchValue string) (string, []interface{}) {
+	switch matchType {
+	case "contains":
+		return "description LIKE ?", []interface{}{"%" + matchValue + "%"}
+	case "starts_with":
+		return "description LIKE ?", []interface{}{matchValue + "%"}
+	case "ends_with":
+		return "description LIKE ?", []interface{}{"%" + matchValue}
+	case "exact":
+		return "description = ?", []interface{}{matchValue}
 	}
+	return "", []interface{}{}
+}
 
-	updateQuery := "UPDATE transactions SET category_id = ? WHERE id IN (" + strings.Join(placeholders, ",") + ")"
-	res, err := s.db.Exec(updateQuery, updateArgs...)
+// ruleAmountMatches checks a main-currency amount against the rule's bounds.
+// A nil amount (no FX rate) never matches.
+func ruleAmountMatches(r CategorizationRule, amount *int64) bool {
+	if amount == nil {
+		return false
+	}
+	if r.AmountMin != nil && *amount < *r.AmountMin {
+		return false
+	}
+	if r.AmountMax != nil && *amount > *r.AmountMax {
+		return false
+	}
+	return true
+}
+
+func (s *Store) ruleBaseCurrency() (string, error) {
+	settings, err := s.GetCurrencySettings()
 	if err != nil {
-		return 0, nil, err
+		return "", err
+	}
+	baseCurrency := strings.TrimSpace(settings.MainCurrency)
+	if baseCurrency == "" {
+		baseCurrency = defaultMainCurrency
 	}
+	return baseCurrency, nil
+}
 
-	affectedCount, _ := res.RowsAffected()
+// MatchRule returns the rule that would categorize a transaction with the given
+// description and amount, or nil when none matches. Rules are tried in priority
+// order with the same conditions ApplyRuleWithIds uses. An empty currency means
+// the main currency.
+func (s *Store) MatchRule(description string, amount int64, currency, date string) (*CategorizationRule, error) {
+	rules, err := s.GetRules()
+	if err != nil {
+		return nil, err
+	}
 
-	return affectedCount, affectedIds, nil
+	var baseCurrency string
+	for _, r := range rules {
+		matchClause, args := ruleMatchClause(r.MatchType, r.MatchValue)
+		if matchClause != "" {
+			var matched bool
+			args = append([]interface{}{description}, args...)
+			query := "SELECT EXISTS(SELECT 1 FROM (SELECT ? AS description) WHERE " + matchClause + ")"
+			if err := s.db.QueryRow(query, args...).Scan(&matched); err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		if r.AmountMin != nil || r.AmountMax != nil {
+			if baseCurrency == "" {
+				if baseCurrency, err = s.ruleBaseCurrency(); err != nil {
+					return nil, err
+				}
+			}
+			quote := currency
+			if quote == "" {
+				quote = baseCurrency
+			}
+			converted, err := s.ConvertAmount(amount, baseCurrency, quote, date)
+			if err != nil {
+				return nil, err
+			}
+			if !ruleAmountMatches(r, converted) {
+				continue
+			}
+		}
+
+		match := r
+		return &match, nil
+	}
+	return nil, nil
 }
 
 func (s *Store) UndoRule(ruleID int64, affectedTxIds []int64) error {
@@ -424,7 +609,7 @@ func (s *Store) UndoRule(ruleID int64, affectedTxIds []int64) error {
 		args[i] = id
 	}
 
-	query := "UPDATE transactions SET category_id = NULL WHERE id IN (" + strings.Join(placeholders, ",") + ")"
+	query := "UPDATE transactions SET category_id = NULL, category_source = NULL, category_rule_id = NULL WHERE id IN (" + strings.Join(placeholders, ",") + ")"
 	_, err = s.db.Exec(query, args...)
 	return err
 }
@@ -442,11 +627,12 @@ func (s *Store) SearchCategories(query string) ([]Category, error) {
 		return cloneCategories(all), nil
 	}
 
+	// Match on the full path so "food groc" finds "Food > Groceries".
 	names := make([]string, len(all))
 	nameToCat := make(map[string]Category)
 	for i, c := range all {
-		names[i] = c.Name
-		nameToCat[c.Name] = c
+		names[i] = c.FullName
+		nameToCat[c.FullName] = c
 	}
 
 	rankedNames := fuzzy.Match(query, names)
@@ -486,3 +672,92 @@ func (s *Store) ApplyAllRules() (int, error) {
 	}
 	return totalAffected, nil
 }
+
+// ImportedRule is a rule from a ruleset. CategoryPath names its category and
+// the category's ancestors from the top level, e.g. ["Food", "Groceries"];
+// CategoryKind, when set, is the kind a newly created category gets.
+type ImportedRule struct {
+	CategorizationRule
+	CategoryPath []string
+	CategoryKind string
+}
+
+// ImportRules inserts rules in a single transaction, resolving CategoryPath to a
+// category for rules without a CategoryID. Categories along the path that are
+// missing are created under the previous one; existing categories are used
+// where they are. When replace is set, all existing rules are deleted first;
+// transaction categories are kept.
+func (s *Store) ImportRules(rules []ImportedRule, replace bool) ([]int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if replace {
+		if _, err := tx.Exec("DELETE FROM categorization_rules"); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int64, 0, len(rules))
+	for _, r := range rules {
+		categoryID := r.CategoryID
+		if categoryID == 0 {
+			if categoryID, err = importRuleCategory(tx, r.CategoryPath, r.CategoryKind); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := tx.Exec(`
+			INSERT INTO categorization_rules (match_type, match_value, category_id, amount_min, amount_max)
+			VALUES (?, ?, ?, ?, ?)
+		`, r.MatchType, r.MatchValue, categoryID, r.AmountMin, r.AmountMax)
+		if err != nil {
+			return nil, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.invalidateCategoryCache()
+	return ids, nil
+}
+
+// importRuleCategory returns the category at the end of path, creating the
+// missing ones along it. Only a newly created last category gets kind.
+func importRuleCategory(tx *sql.Tx, path []string, kind string) (int64, error) {
+	if len(path) == 0 {
+		return 0, errors.New("rule category path is empty")
+	}
+	var parentID *int64
+	var id int64
+	for i, name := range path {
+		err := tx.QueryRow("SELECT id FROM categories WHERE name = ?", name).Scan(&id)
+		if err == sql.ErrNoRows {
+			categoryKind := DefaultCategoryKind
+			if i == len(path)-1 && kind != "" {
+				categoryKind = kind
+			}
+			res, err := tx.Exec("INSERT INTO categories (name, parent_id, kind) VALUES (?, ?, ?)", name, parentID, categoryKind)
+			if err != nil {
+				return 0, err
+			}
+			if id, err = res.LastInsertId(); err != nil {
+				return 0, err
+			}
+		} else if err != nil {
+			return 0, err
+		}
+		parent := id
+		parentID = &parent
+	}
+	return id, nil
+}
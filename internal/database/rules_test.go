@@ -0,0 +1,191 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestApplyRuleRecordsHits(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, err := store.GetOrCreateAccount("TestAccount")
+	if err != nil {
+		t.Fatalf("Failed to create test account: %v", err)
+	}
+	catID, err := store.GetOrCreateCategory("Transport")
+	if err != nil {
+		t.Fatalf("Failed to create test category: %v", err)
+	}
+
+	insert := func(txs ...TransactionModel) {
+		t.Helper()
+		if err := store.BatchInsertTransactions(txs); err != nil {
+			t.Fatalf("Failed to insert test transactions: %v", err)
+		}
+	}
+	insert(
+		TransactionModel{AccountID: accID, Date: "2024-03-01", Description: "UBER TRIP", Amount: -1200, Currency: defaultMainCurrency},
+		TransactionModel{AccountID: accID, Date: "2024-01-15", Description: "UBER TRIP", Amount: -900, Currency: defaultMainCurrency},
+		TransactionModel{AccountID: accID, Date: "2024-02-01", Description: "LYFT", Amount: -800, Currency: defaultMainCurrency},
+	)
+
+	ruleID, err := store.SaveRule(CategorizationRule{MatchType: "contains", MatchValue: "uber", CategoryID: catID})
+	if err != nil {
+		t.Fatalf("Failed to save rule: %v", err)
+	}
+	rule, err := store.GetRuleByID(ruleID)
+	if err != nil {
+		t.Fatalf("Failed to load rule: %v", err)
+	}
+	if rule.HitCount != 0 || rule.LastMatchedDate != "" {
+		t.Fatalf("Expected no hits for a new rule, got %d / %q", rule.HitCount, rule.LastMatchedDate)
+	}
+
+	if _, err := store.ApplyRule(ruleID); err != nil {
+		t.Fatalf("Failed to apply rule: %v", err)
+	}
+	rule, _ = store.GetRuleByID(ruleID)
+	if rule.HitCount != 2 || rule.LastMatchedDate != "2024-03-01" {
+		t.Fatalf("Expected 2 hits last matched 2024-03-01, got %d / %q", rule.HitCount, rule.LastMatchedDate)
+	}
+
+	// Older matches add hits without moving the last matched date back.
+	insert(TransactionModel{AccountID: accID, Date: "2023-12-20", Description: "UBER EATS", Amount: -2500, Currency: defaultMainCurrency})
+	if _, err := store.ApplyRule(ruleID); err != nil {
+		t.Fatalf("Failed to apply rule: %v", err)
+	}
+	rule, _ = store.GetRuleByID(ruleID)
+	if rule.HitCount != 3 || rule.LastMatchedDate != "2024-03-01" {
+		t.Fatalf("Expected 3 hits last matched 2024-03-01, got %d / %q", rule.HitCount, rule.LastMatchedDate)
+	}
+
+	// Re-applying with nothing left to categorize changes nothing.
+	if _, err := store.ApplyRule(ruleID); err != nil {
+		t.Fatalf("Failed to apply rule: %v", err)
+	}
+	rule, _ = store.GetRuleByID(ruleID)
+	if rule.HitCount != 3 {
+		t.Fatalf("Expected hit count to stay at 3, got %d", rule.HitCount)
+	}
+}
+
+func TestReapplyRulesOverwritePolicies(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, err := store.GetOrCreateAccount("TestAccount")
+	if err != nil {
+		t.Fatalf("Failed to create test account: %v", err)
+	}
+	shopping, _ := store.GetOrCreateCategory("Shopping")
+	groceries, _ := store.GetOrCreateCategory("Groceries")
+	household, _ := store.GetOrCreateCategory("Household")
+
+	if err := store.BatchInsertTransactions([]TransactionModel{
+		{AccountID: accID, Date: "2024-01-01", Description: "COSTCO #1", Amount: -5000, Currency: defaultMainCurrency},
+		{AccountID: accID, Date: "2024-01-02", Description: "COSTCO #2", Amount: -6000, Currency: defaultMainCurrency},
+		{AccountID: accID, Date: "2024-01-03", Description: "COSTCO #3", Amount: -7000, Currency: defaultMainCurrency},
+	}); err != nil {
+		t.Fatalf("Failed to insert test transactions: %v", err)
+	}
+	txs, err := store.GetUncategorizedTransactions()
+	if err != nil || len(txs) != 3 {
+		t.Fatalf("Expected 3 uncategorized transactions, got %d (%v)", len(txs), err)
+	}
+	ids := map[string]int64{}
+	for _, tx := range txs {
+		ids[tx.Description] = tx.ID
+	}
+
+	// #1 and #2 categorized by an old rule, #3 categorized manually.
+	oldRule, _ := store.SaveRule(CategorizationRule{MatchType: "contains", MatchValue: "costco #", CategoryID: shopping})
+	if err := store.UpdateTransactionCategory(ids["COSTCO #3"], household); err != nil {
+		t.Fatalf("Failed to categorize: %v", err)
+	}
+	if _, err := store.ApplyRule(oldRule); err != nil {
+		t.Fatalf("Failed to apply rule: %v", err)
+	}
+
+	// A more specific rule now points COSTCO at Groceries.
+	newRule, _ := store.SaveRule(CategorizationRule{MatchType: "starts_with", MatchValue: "COSTCO", CategoryID: groceries})
+
+	none, err := store.ReapplyRules(nil, RuleOverwriteNone, false)
+	if err != nil {
+		t.Fatalf("ReapplyRules none failed: %v", err)
+	}
+	if len(none.Changes) != 0 || none.BatchID != nil {
+		t.Fatalf("Expected no changes without overwrite, got %+v", none)
+	}
+
+	dry, err := store.ReapplyRules(nil, RuleOverwriteRuleOnly, true)
+	if err != nil {
+		t.Fatalf("ReapplyRules dry run failed: %v", err)
+	}
+	if len(dry.Changes) != 2 || dry.BatchID != nil {
+		t.Fatalf("Expected 2 dry-run changes, got %+v", dry)
+	}
+	if c := dry.Changes[0]; c.OldCategoryName != "Shopping" || c.OldSource != CategorySourceRule || c.NewCategoryName != "Groceries" || c.RuleID != newRule {
+		t.Errorf("Unexpected change: %+v", c)
+	}
+
+	ruleOnly, err := store.ReapplyRules(nil, RuleOverwriteRuleOnly, false)
+	if err != nil {
+		t.Fatalf("ReapplyRules rule-only failed: %v", err)
+	}
+	if len(ruleOnly.Changes) != 2 || ruleOnly.BatchID == nil {
+		t.Fatalf("Expected 2 changes with a batch, got %+v", ruleOnly)
+	}
+	assertCategory := func(desc string, want int64) {
+		t.Helper()
+		var got int64
+		if err := store.db.QueryRow("SELECT category_id FROM transactions WHERE id = ?", ids[desc]).Scan(&got); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("%s: expected category %d, got %d", desc, want, got)
+		}
+	}
+	assertCategory("COSTCO #1", groceries)
+	assertCategory("COSTCO #2", groceries)
+	assertCategory("COSTCO #3", household)
+
+	all, err := store.ReapplyRules(nil, RuleOverwriteAll, false)
+	if err != nil {
+		t.Fatalf("ReapplyRules all failed: %v", err)
+	}
+	if len(all.Changes) != 1 || all.Changes[0].OldSource != CategorySourceManual {
+		t.Fatalf("Expected the manual category to change, got %+v", all.Changes)
+	}
+	assertCategory("COSTCO #3", groceries)
+
+	// Manual edit after the rule-only batch: undo must leave it alone.
+	if err := store.UpdateTransactionCategory(ids["COSTCO #2"], household); err != nil {
+		t.Fatalf("Failed to categorize: %v", err)
+	}
+	undo, err := store.UndoRuleApply(*ruleOnly.BatchID)
+	if err != nil {
+		t.Fatalf("UndoRuleApply failed: %v", err)
+	}
+	if undo.Restored != 1 || undo.Skipped != 1 {
+		t.Errorf("Expected 1 restored and 1 skipped, got %+v", undo)
+	}
+	assertCategory("COSTCO #1", shopping)
+	assertCategory("COSTCO #2", household)
+
+	// The batch's 2 hits are rolled back; the later batch still holds the date.
+	rule, _ := store.GetRuleByID(newRule)
+	if rule.HitCount != 1 || rule.LastMatchedDate != "2024-01-03" {
+		t.Errorf("Expected 1 hit last matched 2024-01-03 after undo, got %d / %q", rule.HitCount, rule.LastMatchedDate)
+	}
+	if _, err := store.UndoRuleApply(*all.BatchID); err != nil {
+		t.Fatalf("UndoRuleApply failed: %v", err)
+	}
+	if rule, _ = store.GetRuleByID(newRule); rule.HitCount != 0 {
+		t.Errorf("Expected no hits after undoing both batches, got %d", rule.HitCount)
+	}
+
+	if _, err := store.UndoRuleApply(*ruleOnly.BatchID); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows undoing a batch twice, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSplitTooFewLines  = errors.New("a split needs at least two lines")
+	ErrSplitZeroAmount   = errors.New("split line amounts cannot be zero")
+	ErrSplitNoCategory   = errors.New("every split line needs a category")
+	ErrSplitSumMismatch  = errors.New("split lines must sum to the transaction amount")
+	ErrSplitUnknownOwner = errors.New("split line owner does not exist")
+)
+
+// expandedTransactionsCTE defines "expanded": one row per transaction, except
+// that split transactions are replaced by their split lines. Lines carry their
+// own amount, category and owner (falling back to the transaction's owner);
+// split_id is NULL for unsplit transactions.
+const expandedTransactionsCTE = `
+	WITH expanded AS (
+		SELECT
+			t.id, NULL AS split_id, t.account_id, t.owner_id, t.date, t.description, t.note,
+			t.amount, t.category_id, t.currency, '' AS split_note, t.merchant_id
+		FROM transactions t
+		WHERE NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+		UNION ALL
+		SELECT
+			t.id, s.id, t.account_id, COALESCE(s.owner_id, t.owner_id), t.date, t.description, t.note,
+			s.amount, s.category_id, t.currency, s.note, t.merchant_id
+		FROM transaction_splits s
+		JOIN transactions t ON t.id = s.transaction_id
+	)
+`
+
+// SplitLine is one part of a split transaction. Amount is in the
+// transaction's currency.
+type SplitLine struct {
+	ID            int64  `json:"id"`
+	TransactionID int64  `json:"transaction_id"`
+	Amount        int64  `json:"amount"`
+	CategoryID    *int64 `json:"category_id"`
+	CategoryName  string `json:"category_name"`
+	// OwnerID overrides the transaction's owner when set.
+	OwnerID   *int64 `json:"owner_id"`
+	OwnerName string `json:"owner_name"`
+	Note      string `json:"note"`
+}
+
+// GetTransaction returns a single transaction with its main-currency amount.
+func (s *Store) GetTransaction(id int64) (TransactionModel, error) {
+	var t TransactionModel
+	err := s.db.QueryRow(`
+		SELECT
+			t.id, t.account_id, a.name, t.owner_id, COALESCE(u.name, ''),
+			t.date, t.description, t.note, t.amount, t.category_id, COALESCE(c.name, ''), t.currency,
+			t.merchant_id, COALESCE(m.name, '')
+		FROM transactions t
+		JOIN accounts a ON t.account_id = a.id
+		LEFT JOIN users u ON t.owner_id = u.id
+		LEFT JOIN categories c ON t.category_id = c.id
+		LEFT JOIN merchants m ON t.merchant_id = m.id
+		WHERE t.id = ?
+	`, id).Scan(
+		&t.ID, &t.AccountID, &t.AccountName, &t.OwnerID, &t.OwnerName,
+		&t.Date, &t.Description, &t.Note, &t.Amount, &t.CategoryID, &t.CategoryName, &t.Currency,
+		&t.MerchantID, &t.MerchantName,
+	)
+	if err == sql.ErrNoRows {
+		return t, fmt.Errorf("transaction %d not found: %w", id, sql.ErrNoRows)
+	}
+	if err != nil {
+		return t, err
+	}
+	txs, err := s.convertTransactionAmounts([]TransactionModel{t})
+	if err != nil {
+		return t, err
+	}
+	return txs[0], nil
+}
+
+// GetTransactionSplits returns the split lines of a transaction in the order
+// they were given; an unsplit transaction has none.
+func (s *Store) GetTransactionSplits(transactionID int64) ([]SplitLine, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			s.id, s.transaction_id, s.amount, s.category_id, COALESCE(c.name, ''),
+			s.owner_id, COALESCE(u.name, ''), s.note
+		FROM transaction_splits s
+		LEFT JOIN categories c ON s.category_id = c.id
+		LEFT JOIN users u ON s.owner_id = u.id
+		WHERE s.transaction_id = ?
+		ORDER BY s.id ASC
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []SplitLine{}
+	for rows.Next() {
+		var l SplitLine
+		if err := rows.Scan(&l.ID, &l.TransactionID, &l.Amount, &l.CategoryID, &l.CategoryName, &l.OwnerID, &l.OwnerName, &l.Note); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// SplitTransaction replaces the split lines of a transaction. Lines must have a
+// category and a non-zero amount, and sum to the transaction amount. The
+// transaction itself is categorized as its largest line, so it stays out of
+// the uncategorized queue and rules; analysis, exports and category summaries
+// use the lines.
+func (s *Store) SplitTransaction(transactionID int64, lines []SplitLine) ([]SplitLine, error) {
+	if len(lines) < 2 {
+		return nil, ErrSplitTooFewLines
+	}
+
+	categories, err := s.loadCategories()
+	if err != nil {
+		return nil, err
+	}
+	byID := categoriesByID(categories)
+
+	var sum int64
+	primary := lines[0]
+	for _, l := range lines {
+		if l.Amount == 0 {
+			return nil, ErrSplitZeroAmount
+		}
+		if l.CategoryID == nil {
+			return nil, ErrSplitNoCategory
+		}
+		if _, ok := byID[*l.CategoryID]; !ok {
+			return nil, fmt.Errorf("category %d not found: %w", *l.CategoryID, sql.ErrNoRows)
+		}
+		if abs64(l.Amount) > abs64(primary.Amount) {
+			primary = l
+		}
+		sum += l.Amount
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var amount int64
+	if err := tx.QueryRow("SELECT amount FROM transactions WHERE id = ?", transactionID).Scan(&amount); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("transaction %d not found: %w", transactionID, sql.ErrNoRows)
+		}
+		return nil, err
+	}
+	if sum != amount {
+		return nil, fmt.Errorf("%w: lines sum to %d cents, transaction is %d cents", ErrSplitSumMismatch, sum, amount)
+	}
+
+	for _, l := range lines {
+		if l.OwnerID == nil {
+			continue
+		}
+		var exists int
+		if err := tx.QueryRow("SELECT 1 FROM users WHERE id = ?", *l.OwnerID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrSplitUnknownOwner
+			}
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM transaction_splits WHERE transaction_id = ?", transactionID); err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		if _, err := tx.Exec(
+			"INSERT INTO transaction_splits (transaction_id, amount, category_id, owner_id, note) VALUES (?, ?, ?, ?, ?)",
+			transactionID, l.Amount, l.CategoryID, l.OwnerID, l.Note,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(
+		"UPDATE transactions SET category_id = ?, category_source = ?, category_rule_id = NULL WHERE id = ?",
+		primary.CategoryID, CategorySourceManual, transactionID,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetTransactionSplits(transactionID)
+}
+
+// UnsplitTransaction removes the split lines of a transaction and returns how
+// many were removed. The transaction keeps the category of its largest line.
+func (s *Store) UnsplitTransaction(transactionID int64) (int64, error) {
+	var exists int
+	if err := s.db.QueryRow("SELECT 1 FROM transactions WHERE id = ?", transactionID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("transaction %d not found: %w", transactionID, sql.ErrNoRows)
+		}
+		return 0, err
+	}
+	res, err := s.db.Exec("DELETE FROM transaction_splits WHERE transaction_id = ?", transactionID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
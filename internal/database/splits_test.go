@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestSplitTransaction(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, _ := store.GetOrCreateAccount("Visa")
+	ownerID, _ := store.GetOrCreateUser("Sam")
+	groceriesID, _ := store.GetOrCreateCategory("Groceries")
+	householdID, _ := store.GetOrCreateCategory("Household")
+	clothingID, _ := store.GetOrCreateCategory("Clothing")
+	if err := store.BatchInsertTransactions([]TransactionModel{
+		{AccountID: accID, Date: "2025-01-05", Description: "COSTCO", Amount: -15000, Currency: defaultMainCurrency},
+		{AccountID: accID, Date: "2025-01-06", Description: "LOBLAWS", Amount: -4000, Currency: defaultMainCurrency, CategoryID: &groceriesID},
+	}); err != nil {
+		t.Fatalf("BatchInsertTransactions failed: %v", err)
+	}
+	all, err := store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	var costcoID int64
+	for _, tx := range all {
+		if tx.Description == "COSTCO" {
+			costcoID = tx.ID
+		}
+	}
+
+	cases := []struct {
+		name  string
+		lines []SplitLine
+		want  error
+	}{
+		{"one line", []SplitLine{{Amount: -15000, CategoryID: &groceriesID}}, ErrSplitTooFewLines},
+		{"zero amount", []SplitLine{{Amount: -15000, CategoryID: &groceriesID}, {Amount: 0, CategoryID: &householdID}}, ErrSplitZeroAmount},
+		{"no category", []SplitLine{{Amount: -10000, CategoryID: &groceriesID}, {Amount: -5000}}, ErrSplitNoCategory},
+		{"wrong sum", []SplitLine{{Amount: -10000, CategoryID: &groceriesID}, {Amount: -4000, CategoryID: &householdID}}, ErrSplitSumMismatch},
+	}
+	for _, tc := range cases {
+		if _, err := store.SplitTransaction(costcoID, tc.lines); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	if _, err := store.SplitTransaction(9999, []SplitLine{{Amount: -1, CategoryID: &groceriesID}, {Amount: -1, CategoryID: &groceriesID}}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing transaction, got %v", err)
+	}
+
+	lines, err := store.SplitTransaction(costcoID, []SplitLine{
+		{Amount: -8000, CategoryID: &groceriesID},
+		{Amount: -4500, CategoryID: &householdID, Note: "paper towels"},
+		{Amount: -2500, CategoryID: &clothingID, OwnerID: ownerID},
+	})
+	if err != nil {
+		t.Fatalf("SplitTransaction failed: %v", err)
+	}
+	if len(lines) != 3 || lines[1].Note != "paper towels" || lines[2].OwnerName != "Sam" {
+		t.Fatalf("unexpected split lines: %+v", lines)
+	}
+
+	parent, err := store.GetTransaction(costcoID)
+	if err != nil {
+		t.Fatalf("GetTransaction failed: %v", err)
+	}
+	if parent.CategoryID == nil || *parent.CategoryID != groceriesID {
+		t.Errorf("expected the transaction to take its largest line's category, got %v", parent.CategoryID)
+	}
+
+	txs, err := store.GetAnalysisTransactions("2025-01-01", "2025-01-31", []int64{householdID}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	if len(txs) != 1 || txs[0].ID != costcoID || txs[0].Amount != -4500 || txs[0].SplitID == nil || txs[0].SplitNote != "paper towels" {
+		t.Errorf("expected the household split line, got %+v", txs)
+	}
+
+	txs, err = store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	totals, err := store.GetKindTotals(txs)
+	if err != nil {
+		t.Fatalf("GetKindTotals failed: %v", err)
+	}
+	if len(txs) != 4 || totals.Expenses != -19000 {
+		t.Errorf("expected 4 rows totalling -190.00, got %d rows and %+v", len(txs), totals)
+	}
+	for _, r := range BuildCategoryRollups(mustCategories(t, store), txs) {
+		if r.CategoryID == groceriesID && r.Total != -12000 {
+			t.Errorf("expected groceries total -120.00 from the line and Loblaws, got %d", r.Total)
+		}
+	}
+
+	summaries, err := store.GetCategorySummaries()
+	if err != nil {
+		t.Fatalf("GetCategorySummaries failed: %v", err)
+	}
+	counts := map[int64]int64{}
+	for _, s := range summaries {
+		counts[s.ID] = s.TransactionCount
+	}
+	if counts[groceriesID] != 2 || counts[householdID] != 1 || counts[clothingID] != 1 {
+		t.Errorf("expected summaries to count split lines, got %v", counts)
+	}
+
+	removed, err := store.UnsplitTransaction(costcoID)
+	if err != nil {
+		t.Fatalf("UnsplitTransaction failed: %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("expected 3 lines removed, got %d", removed)
+	}
+	txs, err = store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Errorf("expected the transaction back as one row, got %d rows", len(txs))
+	}
+}
+
+func mustCategories(t *testing.T, store *Store) []Category {
+	t.Helper()
+	categories, err := store.GetAllCategories()
+	if err != nil {
+		t.Fatalf("GetAllCategories failed: %v", err)
+	}
+	return categories
+}
@@ -0,0 +1,32 @@
+package database
+
+// GetKnownSubscriptions returns the keys of recurring series marked as known
+// subscriptions.
+func (s *Store) GetKnownSubscriptions() (map[string]bool, error) {
+	rows, err := s.db.Query("SELECT series_key FROM known_subscriptions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	known := map[string]bool{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		known[key] = true
+	}
+	return known, rows.Err()
+}
+
+// SetKnownSubscription marks or unmarks a recurring series as a known
+// subscription.
+func (s *Store) SetKnownSubscription(key string, known bool) error {
+	if known {
+		_, err := s.db.Exec("INSERT OR IGNORE INTO known_subscriptions (series_key) VALUES (?)", key)
+		return err
+	}
+	_, err := s.db.Exec("DELETE FROM known_subscriptions WHERE series_key = ?", key)
+	return err
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	SummaryPeriodMonth   = "month"
+	SummaryPeriodQuarter = "quarter"
+	SummaryPeriodYear    = "year"
+
+	SummaryGroupCategory = "category"
+	SummaryGroupOwner    = "owner"
+	SummaryGroupAccount  = "account"
+	SummaryGroupTag      = "tag"
+	SummaryGroupMerchant = "merchant"
+)
+
+var (
+	ErrInvalidSummaryPeriod = errors.New("summary period must be month, quarter, or year")
+	ErrInvalidSummaryGroup  = errors.New("summary grouping must be category, owner, account, tag, or merchant")
+)
+
+// SummaryOptions selects the transactions to summarize and how to bucket them.
+// The filters work like those of GetAnalysisTransactions.
+type SummaryOptions struct {
+	StartDate   string  `json:"start_date"`
+	EndDate     string  `json:"end_date"`
+	Period      string  `json:"period"`
+	GroupBy     string  `json:"group_by"`
+	CategoryIDs []int64 `json:"category_ids"`
+	OwnerIDs    []int64 `json:"owner_ids"`
+	TagIDs      []int64 `json:"tag_ids"`
+	MerchantIDs []int64 `json:"merchant_ids"`
+	// IncludeTransfers counts linked transfers by the kind of their own
+	// category instead of as transfers.
+	IncludeTransfers bool `json:"include_transfers"`
+}
+
+// SummaryRow totals one group within one period, in the main currency.
+type SummaryRow struct {
+	// Period is "2025-01" for months, "2025-Q1" for quarters and "2025" for
+	// years.
+	Period string `json:"period"`
+	// GroupID is nil for the uncategorized, no-owner, untagged and
+	// no-merchant groups.
+	GroupID *int64 `json:"group_id"`
+	Group   string `json:"group"`
+	Count   int    `json:"count"`
+	KindTotals
+}
+
+type Summary struct {
+	MainCurrency string       `json:"main_currency"`
+	Period       string       `json:"period"`
+	GroupBy      string       `json:"group_by"`
+	Rows         []SummaryRow `json:"rows"`
+	// Totals covers every transaction once, so with tag grouping it can be less
+	// than the sum of the rows.
+	Totals KindTotals `json:"totals"`
+}
+
+// summaryPeriodKey returns the period a YYYY-MM-DD date falls in.
+func summaryPeriodKey(date, period string) (string, error) {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", err
+	}
+	switch period {
+	case SummaryPeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", d.Year(), (int(d.Month())-1)/3+1), nil
+	case SummaryPeriodYear:
+		return fmt.Sprintf("%d", d.Year()), nil
+	default:
+		return d.Format("2006-01"), nil
+	}
+}
+
+type summaryGroup struct {
+	id   *int64
+	name string
+}
+
+// summaryGroups returns the groups a transaction counts towards; with tag
+// grouping that is one per tag.
+func summaryGroups(tx TransactionModel, groupBy string, tagIDs map[string]int64) []summaryGroup {
+	switch groupBy {
+	case SummaryGroupOwner:
+		if tx.OwnerID == nil {
+			return []summaryGroup{{name: "No owner"}}
+		}
+		return []summaryGroup{{id: tx.OwnerID, name: tx.OwnerName}}
+	case SummaryGroupAccount:
+		id := tx.AccountID
+		return []summaryGroup{{id: &id, name: tx.AccountName}}
+	case SummaryGroupTag:
+		if len(tx.Tags) == 0 {
+			return []summaryGroup{{name: "Untagged"}}
+		}
+		groups := make([]summaryGroup, 0, len(tx.Tags))
+		for _, tag := range tx.Tags {
+			id := tagIDs[tag]
+			groups = append(groups, summaryGroup{id: &id, name: tag})
+		}
+		return groups
+	case SummaryGroupMerchant:
+		if tx.MerchantID == nil {
+			return []summaryGroup{{name: "No merchant"}}
+		}
+		return []summaryGroup{{id: tx.MerchantID, name: tx.MerchantName}}
+	default:
+		if tx.CategoryID == nil {
+			return []summaryGroup{{name: "Uncategorized"}}
+		}
+		name := tx.CategoryFullName
+		if name == "" {
+			name = tx.CategoryName
+		}
+		return []summaryGroup{{id: tx.CategoryID, name: name}}
+	}
+}
+
+// GetSummary totals transactions per period and group in the main currency,
+// split by category kind. Split transactions count once per line.
+func (s *Store) GetSummary(opts SummaryOptions) (Summary, error) {
+	if opts.Period == "" {
+		opts.Period = SummaryPeriodMonth
+	}
+	if opts.GroupBy == "" {
+		opts.GroupBy = SummaryGroupCategory
+	}
+	switch opts.Period {
+	case SummaryPeriodMonth, SummaryPeriodQuarter, SummaryPeriodYear:
+	default:
+		return Summary{}, ErrInvalidSummaryPeriod
+	}
+	switch opts.GroupBy {
+	case SummaryGroupCategory, SummaryGroupOwner, SummaryGroupAccount, SummaryGroupTag, SummaryGroupMerchant:
+	default:
+		return Summary{}, ErrInvalidSummaryGroup
+	}
+
+	settings, err := s.GetCurrencySettings()
+	if err != nil {
+		return Summary{}, err
+	}
+	txs, err := s.GetAnalysisTransactions(opts.StartDate, opts.EndDate, opts.CategoryIDs, opts.OwnerIDs, opts.TagIDs, opts.MerchantIDs)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var kinds map[int64]string
+	if opts.IncludeTransfers {
+		categories, err := s.loadCategories()
+		if err != nil {
+			return Summary{}, err
+		}
+		kinds = make(map[int64]string, len(categories))
+		for _, c := range categories {
+			kinds[c.ID] = c.Kind
+		}
+	}
+
+	var tagIDs map[string]int64
+	if opts.GroupBy == SummaryGroupTag {
+		tags, err := s.GetTags()
+		if err != nil {
+			return Summary{}, err
+		}
+		tagIDs = make(map[string]int64, len(tags))
+		for _, tag := range tags {
+			tagIDs[tag.Name] = tag.ID
+		}
+	}
+
+	type rowKey struct {
+		period string
+		group  string
+	}
+	rows := map[rowKey]*SummaryRow{}
+	summary := Summary{MainCurrency: settings.MainCurrency, Period: opts.Period, GroupBy: opts.GroupBy}
+	for _, tx := range txs {
+		period, err := summaryPeriodKey(tx.Date, opts.Period)
+		if err != nil {
+			return Summary{}, err
+		}
+		kind := tx.CategoryKind
+		if opts.IncludeTransfers && tx.TransferLinkID != nil {
+			kind = ""
+			if tx.CategoryID != nil {
+				kind = kinds[*tx.CategoryID]
+			}
+		}
+		summary.Totals.Add(kind, tx.AmountInMainCurrency)
+		for _, g := range summaryGroups(tx, opts.GroupBy, tagIDs) {
+			key := rowKey{period: period, group: g.name}
+			row, ok := rows[key]
+			if !ok {
+				row = &SummaryRow{Period: period, GroupID: g.id, Group: g.name}
+				rows[key] = row
+			}
+			row.Count++
+			row.Add(kind, tx.AmountInMainCurrency)
+		}
+	}
+
+	summary.Rows = make([]SummaryRow, 0, len(rows))
+	for _, row := range rows {
+		summary.Rows = append(summary.Rows, *row)
+	}
+	sort.Slice(summary.Rows, func(i, j int) bool {
+		a, b := summary.Rows[i], summary.Rows[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		return a.Group < b.Group
+	})
+	return summary, nil
+}
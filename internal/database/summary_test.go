@@ -0,0 +1,137 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetSummary(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	checking, err := store.GetOrCreateAccount("Checking")
+	if err != nil {
+		t.Fatal(err)
+	}
+	card, err := store.GetOrCreateAccount("Card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	salary, err := store.GetOrCreateCategory("Salary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetCategoryKind(salary, CategoryKindIncome); err != nil {
+		t.Fatal(err)
+	}
+	groceries, err := store.GetOrCreateCategory("Groceries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment, err := store.GetOrCreateCategory("Card Payment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetCategoryKind(payment, CategoryKindTransfer); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tx := range []TransactionModel{
+		{AccountID: checking, Date: "2025-01-01", Description: "Payroll", Amount: 300000, CategoryID: &salary},
+		{AccountID: card, Date: "2025-01-10", Description: "Costco", Amount: -8000, CategoryID: &groceries},
+		{AccountID: card, Date: "2025-02-10", Description: "Safeway", Amount: -3000, CategoryID: &groceries},
+		{AccountID: checking, Date: "2025-02-15", Description: "Card payment", Amount: -11000, CategoryID: &payment},
+		{AccountID: card, Date: "2025-04-02", Description: "Bakery", Amount: -500},
+	} {
+		if _, err := store.CreateTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary, err := store.GetSummary(SummaryOptions{StartDate: "2025-01-01", EndDate: "2025-12-31"})
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	if summary.Period != SummaryPeriodMonth || summary.GroupBy != SummaryGroupCategory || len(summary.Rows) != 5 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	first := summary.Rows[0]
+	if first.Period != "2025-01" || first.Group != "Groceries" || first.Expenses != -8000 || first.Count != 1 {
+		t.Errorf("unexpected first row: %+v", first)
+	}
+	last := summary.Rows[4]
+	if last.Period != "2025-04" || last.Group != "Uncategorized" || last.GroupID != nil || last.Expenses != -500 {
+		t.Errorf("unexpected uncategorized row: %+v", last)
+	}
+	want := KindTotals{Income: 300000, Expenses: -11500, Net: 288500, Transfers: -11000}
+	if summary.Totals != want {
+		t.Errorf("expected totals %+v, got %+v", want, summary.Totals)
+	}
+
+	quarters, err := store.GetSummary(SummaryOptions{StartDate: "2025-01-01", EndDate: "2025-12-31", Period: SummaryPeriodQuarter, GroupBy: SummaryGroupAccount})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byKey := map[string]SummaryRow{}
+	for _, row := range quarters.Rows {
+		byKey[row.Period+"/"+row.Group] = row
+	}
+	if len(quarters.Rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", quarters.Rows)
+	}
+	if r := byKey["2025-Q1/Card"]; r.Count != 2 || r.Expenses != -11000 {
+		t.Errorf("unexpected Q1 card row: %+v", r)
+	}
+	if r := byKey["2025-Q1/Checking"]; r.Income != 300000 || r.Transfers != -11000 || r.Net != 300000 {
+		t.Errorf("unexpected Q1 checking row: %+v", r)
+	}
+	if r := byKey["2025-Q2/Card"]; r.Count != 1 {
+		t.Errorf("unexpected Q2 card row: %+v", r)
+	}
+
+	if _, err := store.GetSummary(SummaryOptions{StartDate: "2025-01-01", EndDate: "2025-12-31", Period: "week"}); !errors.Is(err, ErrInvalidSummaryPeriod) {
+		t.Errorf("expected ErrInvalidSummaryPeriod, got %v", err)
+	}
+	if _, err := store.GetSummary(SummaryOptions{StartDate: "2025-01-01", EndDate: "2025-12-31", GroupBy: "payee"}); !errors.Is(err, ErrInvalidSummaryGroup) {
+		t.Errorf("expected ErrInvalidSummaryGroup, got %v", err)
+	}
+}
+
+func TestGetSummaryByTag(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, err := store.GetOrCreateAccount("Card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotel, err := store.CreateTransaction(TransactionModel{AccountID: accID, Date: "2025-03-01", Description: "Hotel", Amount: -20000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateTransaction(TransactionModel{AccountID: accID, Date: "2025-03-02", Description: "Coffee", Amount: -500}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddTransactionTags([]int64{hotel.ID}, []string{"vacation", "reimbursable"}); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := store.GetSummary(SummaryOptions{StartDate: "2025-03-01", EndDate: "2025-03-31", Period: SummaryPeriodYear, GroupBy: SummaryGroupTag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := map[string]int64{}
+	for _, row := range summary.Rows {
+		if row.Period != "2025" {
+			t.Errorf("expected year period, got %q", row.Period)
+		}
+		groups[row.Group] = row.Expenses
+	}
+	if len(groups) != 3 || groups["vacation"] != -20000 || groups["reimbursable"] != -20000 || groups["Untagged"] != -500 {
+		t.Errorf("unexpected tag groups: %v", groups)
+	}
+	// A transaction with two tags counts once in the totals.
+	if summary.Totals.Expenses != -20500 {
+		t.Errorf("expected expenses -20500, got %d", summary.Totals.Expenses)
+	}
+}
@@ -0,0 +1,246 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidTagName is returned for a tag name that is empty or contains a
+// comma, which is used to separate tags in CLI flags and exports.
+var ErrInvalidTagName = errors.New("tag names must be non-empty and cannot contain commas")
+
+// TagSeparator joins tag names in exports and table output.
+const TagSeparator = ", "
+
+type Tag struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// TransactionCount is the number of transactions carrying the tag.
+	TransactionCount int `json:"transaction_count"`
+}
+
+type TagChangeResult struct {
+	TransactionIDs []int64  `json:"transaction_ids"`
+	Tags           []string `json:"tags"`
+	// Changed counts transaction-tag links added or removed.
+	Changed int64 `json:"changed"`
+}
+
+// NormalizeTagNames trims names, drops case-insensitive duplicates and rejects
+// invalid ones. The first spelling of a name wins.
+func NormalizeTagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.Contains(name, ",") {
+			return nil, ErrInvalidTagName
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+// GetTags returns all tags ordered by name.
+func (s *Store) GetTags() ([]Tag, error) {
+	rows, err := s.db.Query(`
+		SELECT t.id, t.name, COUNT(tt.transaction_id)
+		FROM tags t
+		LEFT JOIN transaction_tags tt ON tt.tag_id = t.id
+		GROUP BY t.id
+		ORDER BY LOWER(t.name) ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.TransactionCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetTagIDsByName resolves tag names case-insensitively. Unknown names are
+// returned in missing.
+func (s *Store) GetTagIDsByName(names []string) (ids []int64, missing []string, err error) {
+	for _, name := range names {
+		var id int64
+		err := s.db.QueryRow("SELECT id FROM tags WHERE name = ?", strings.TrimSpace(name)).Scan(&id)
+		if err == sql.ErrNoRows {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, missing, nil
+}
+
+func getOrCreateTag(tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRow("SELECT id FROM tags WHERE name = ?", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		res, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", name)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+	return id, err
+}
+
+// checkTransactionsExist returns sql.ErrNoRows naming the first missing ID.
+func checkTransactionsExist(tx *sql.Tx, ids []int64) error {
+	for _, id := range ids {
+		var exists int
+		if err := tx.QueryRow("SELECT 1 FROM transactions WHERE id = ?", id).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("transaction %d not found: %w", id, sql.ErrNoRows)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// AddTransactionTags tags each transaction with each name, creating tags that
+// do not exist yet.
+func (s *Store) AddTransactionTags(transactionIDs []int64, names []string) (TagChangeResult, error) {
+	result := TagChangeResult{TransactionIDs: transactionIDs}
+	names, err := NormalizeTagNames(names)
+	if err != nil {
+		return result, err
+	}
+	result.Tags = names
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	if err := checkTransactionsExist(tx, transactionIDs); err != nil {
+		return result, err
+	}
+	for _, name := range names {
+		tagID, err := getOrCreateTag(tx, name)
+		if err != nil {
+			return result, err
+		}
+		for _, id := range transactionIDs {
+			res, err := tx.Exec("INSERT OR IGNORE INTO transaction_tags (transaction_id, tag_id) VALUES (?, ?)", id, tagID)
+			if err != nil {
+				return result, err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return result, err
+			}
+			result.Changed += n
+		}
+	}
+	return result, tx.Commit()
+}
+
+// RemoveTransactionTags removes the named tags from each transaction. Tags no
+// longer used by any transaction are deleted.
+func (s *Store) RemoveTransactionTags(transactionIDs []int64, names []string) (TagChangeResult, error) {
+	result := TagChangeResult{TransactionIDs: transactionIDs}
+	names, err := NormalizeTagNames(names)
+	if err != nil {
+		return result, err
+	}
+	result.Tags = names
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	if err := checkTransactionsExist(tx, transactionIDs); err != nil {
+		return result, err
+	}
+	for _, name := range names {
+		for _, id := range transactionIDs {
+			res, err := tx.Exec(`
+				DELETE FROM transaction_tags
+				WHERE transaction_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)
+			`, id, name)
+			if err != nil {
+				return result, err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return result, err
+			}
+			result.Changed += n
+		}
+	}
+	if err := deleteUnusedTags(tx); err != nil {
+		return result, err
+	}
+	return result, tx.Commit()
+}
+
+func deleteUnusedTags(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM transaction_tags)")
+	return err
+}
+
+// fillTransactionTags sets Tags on each transaction, sorted by name.
+func (s *Store) fillTransactionTags(txs []TransactionModel) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	index := make(map[int64][]int, len(txs))
+	for i := range txs {
+		index[txs[i].ID] = append(index[txs[i].ID], i)
+		txs[i].Tags = []string{}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT tt.transaction_id, t.name
+		FROM transaction_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		for _, i := range index[id] {
+			txs[i].Tags = append(txs[i].Tags, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range txs {
+		sort.Slice(txs[i].Tags, func(a, b int) bool {
+			return strings.ToLower(txs[i].Tags[a]) < strings.ToLower(txs[i].Tags[b])
+		})
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTransactionTags(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, _ := store.GetOrCreateAccount("Chequing")
+	if err := store.BatchInsertTransactions([]TransactionModel{
+		{AccountID: accID, Date: "2025-01-01", Description: "HOTEL", Amount: -30000, Currency: defaultMainCurrency},
+		{AccountID: accID, Date: "2025-01-02", Description: "FLIGHT", Amount: -50000, Currency: defaultMainCurrency},
+		{AccountID: accID, Date: "2025-01-03", Description: "COFFEE", Amount: -500, Currency: defaultMainCurrency},
+	}); err != nil {
+		t.Fatalf("BatchInsertTransactions failed: %v", err)
+	}
+	all, err := store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	ids := map[string]int64{}
+	for _, tx := range all {
+		ids[tx.Description] = tx.ID
+	}
+
+	res, err := store.AddTransactionTags([]int64{ids["HOTEL"], ids["FLIGHT"]}, []string{"vacation-2025", " Reimbursable ", "VACATION-2025"})
+	if err != nil {
+		t.Fatalf("AddTransactionTags failed: %v", err)
+	}
+	if res.Changed != 4 || !reflect.DeepEqual(res.Tags, []string{"vacation-2025", "Reimbursable"}) {
+		t.Errorf("unexpected add result: %+v", res)
+	}
+	if res, err := store.AddTransactionTags([]int64{ids["HOTEL"]}, []string{"Vacation-2025"}); err != nil || res.Changed != 0 {
+		t.Errorf("expected re-adding a tag in another case to be a no-op, got %+v, %v", res, err)
+	}
+	if _, err := store.AddTransactionTags([]int64{ids["HOTEL"]}, []string{"a,b"}); !errors.Is(err, ErrInvalidTagName) {
+		t.Errorf("expected ErrInvalidTagName, got %v", err)
+	}
+	if _, err := store.AddTransactionTags([]int64{9999}, []string{"x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing transaction, got %v", err)
+	}
+
+	tags, err := store.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Name != "Reimbursable" || tags[0].TransactionCount != 2 {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+	reimbursableID, vacationID := tags[0].ID, tags[1].ID
+
+	txs, err := store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, []int64{vacationID}, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	if len(txs) != 2 || !reflect.DeepEqual(txs[0].Tags, []string{"Reimbursable", "vacation-2025"}) {
+		t.Errorf("expected two tagged transactions with sorted tags, got %+v", txs)
+	}
+
+	txs, err = store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, []int64{MissingFilterID}, nil)
+	if err != nil {
+		t.Fatalf("GetAnalysisTransactions failed: %v", err)
+	}
+	if len(txs) != 1 || txs[0].Description != "COFFEE" || len(txs[0].Tags) != 0 {
+		t.Errorf("expected only the untagged transaction, got %+v", txs)
+	}
+
+	facets, err := store.GetAnalysisFacets("2025-01-01", "2025-01-31")
+	if err != nil {
+		t.Fatalf("GetAnalysisFacets failed: %v", err)
+	}
+	if len(facets.Tags) != 2 || !facets.HasUntagged {
+		t.Errorf("unexpected tag facets: %+v", facets)
+	}
+
+	res, err = store.RemoveTransactionTags([]int64{ids["HOTEL"], ids["FLIGHT"]}, []string{"reimbursable"})
+	if err != nil {
+		t.Fatalf("RemoveTransactionTags failed: %v", err)
+	}
+	if res.Changed != 2 {
+		t.Errorf("expected 2 links removed, got %d", res.Changed)
+	}
+	if ids, missing, err := store.GetTagIDsByName([]string{"Reimbursable", "VACATION-2025"}); err != nil || len(ids) != 1 || ids[0] != vacationID || !reflect.DeepEqual(missing, []string{"Reimbursable"}) {
+		t.Errorf("expected unused tag %d to be deleted, got ids=%v missing=%v err=%v", reimbursableID, ids, missing, err)
+	}
+
+	if _, err := store.DeleteTransactions([]int64{ids["HOTEL"], ids["FLIGHT"]}); err != nil {
+		t.Fatalf("DeleteTransactions failed: %v", err)
+	}
+	tags, err = store.GetTags()
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("expected tags of deleted transactions to be removed, got %+v", tags)
+	}
+}
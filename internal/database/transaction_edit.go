@@ -0,0 +1,229 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTransactionDate = errors.New("transaction date must be a valid YYYY-MM-DD date")
+	ErrInvalidCurrency        = errors.New("currency must be a three-letter code like CAD")
+	ErrEmptyDescription       = errors.New("transaction description cannot be empty")
+	ErrUnknownAccount         = errors.New("account does not exist")
+	ErrUnknownOwner           = errors.New("owner does not exist")
+	// ErrDuplicateTransaction is returned when another transaction already has
+	// the same account, date, description and amount, which imports rely on to
+	// skip rows seen before.
+	ErrDuplicateTransaction = errors.New("a transaction with the same account, date, description and amount already exists")
+	// ErrSplitAmountChange is returned when editing the amount of a split
+	// transaction, whose lines must keep summing to it.
+	ErrSplitAmountChange = errors.New("the amount of a split transaction cannot change")
+)
+
+// TransactionUpdate lists the fields to change; nil fields are kept.
+type TransactionUpdate struct {
+	AccountID   *int64  `json:"account_id"`
+	Date        *string `json:"date"`
+	Description *string `json:"description"`
+	Amount      *int64  `json:"amount"`
+	Currency    *string `json:"currency"`
+	// OwnerID sets the owner; ClearOwner removes it.
+	OwnerID    *int64 `json:"owner_id"`
+	ClearOwner bool   `json:"clear_owner"`
+}
+
+// normalizeCurrency upper-cases a currency code and checks it has three
+// letters.
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return currency, nil
+}
+
+// validateTransactionFields normalizes and checks the fields that make up a
+// transaction before it is written.
+func (s *Store) validateTransactionFields(q interface {
+	QueryRow(string, ...any) *sql.Row
+}, t *TransactionModel) error {
+	if _, err := time.Parse("2006-01-02", t.Date); err != nil {
+		return ErrInvalidTransactionDate
+	}
+	t.Description = strings.TrimSpace(t.Description)
+	if t.Description == "" {
+		return ErrEmptyDescription
+	}
+	currency, err := normalizeCurrency(t.Currency)
+	if err != nil {
+		return err
+	}
+	t.Currency = currency
+
+	var exists int
+	if err := q.QueryRow("SELECT 1 FROM accounts WHERE id = ?", t.AccountID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUnknownAccount
+		}
+		return err
+	}
+	if t.OwnerID != nil {
+		if err := q.QueryRow("SELECT 1 FROM users WHERE id = ?", *t.OwnerID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUnknownOwner
+			}
+			return err
+		}
+	}
+	if t.CategoryID != nil {
+		if err := q.QueryRow("SELECT 1 FROM categories WHERE id = ?", *t.CategoryID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("category %d not found: %w", *t.CategoryID, sql.ErrNoRows)
+			}
+			return err
+		}
+	}
+
+	// Mirror UNIQUE(account_id, date, description, amount) with a clear error.
+	err = q.QueryRow(
+		"SELECT 1 FROM transactions WHERE account_id = ? AND date = ? AND description = ? AND amount = ? AND id != ?",
+		t.AccountID, t.Date, t.Description, t.Amount, t.ID,
+	).Scan(&exists)
+	if err == nil {
+		return ErrDuplicateTransaction
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+// CreateTransaction inserts a manually entered transaction. A category, if
+// set, is recorded as chosen manually. An empty currency means the main
+// currency.
+func (s *Store) CreateTransaction(t TransactionModel) (TransactionModel, error) {
+	if strings.TrimSpace(t.Currency) == "" {
+		settings, err := s.GetCurrencySettings()
+		if err != nil {
+			return TransactionModel{}, err
+		}
+		t.Currency = settings.MainCurrency
+	}
+	t.ID = 0
+	t.Note = strings.TrimSpace(t.Note)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return TransactionModel{}, err
+	}
+	defer tx.Rollback()
+
+	if err := s.validateTransactionFields(tx, &t); err != nil {
+		return TransactionModel{}, err
+	}
+
+	var source any
+	if t.CategoryID != nil {
+		source = CategorySourceManual
+	}
+	res, err := tx.Exec(`
+		INSERT INTO transactions (account_id, owner_id, date, description, note, amount, category_id, category_source, currency, raw_metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, t.AccountID, t.OwnerID, t.Date, t.Description, t.Note, t.Amount, t.CategoryID, source, t.Currency, t.RawMetadata)
+	if err != nil {
+		return TransactionModel{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return TransactionModel{}, err
+	}
+	if err := assignMerchants(tx, "id = ?", id); err != nil {
+		return TransactionModel{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return TransactionModel{}, err
+	}
+	return s.GetTransaction(id)
+}
+
+// UpdateTransaction changes the given fields of a transaction. The result must
+// pass the same checks as CreateTransaction, including not colliding with
+// another transaction. An edit that leaves a linked transfer unbalanced
+// removes the link.
+func (s *Store) UpdateTransaction(id int64, u TransactionUpdate) (TransactionModel, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return TransactionModel{}, err
+	}
+	defer tx.Rollback()
+
+	t := TransactionModel{ID: id}
+	if err := tx.QueryRow(
+		"SELECT account_id, owner_id, date, description, amount, currency FROM transactions WHERE id = ?", id,
+	).Scan(&t.AccountID, &t.OwnerID, &t.Date, &t.Description, &t.Amount, &t.Currency); err != nil {
+		if err == sql.ErrNoRows {
+			return TransactionModel{}, fmt.Errorf("transaction %d not found: %w", id, sql.ErrNoRows)
+		}
+		return TransactionModel{}, err
+	}
+
+	if u.Amount != nil && *u.Amount != t.Amount {
+		var splits int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM transaction_splits WHERE transaction_id = ?", id).Scan(&splits); err != nil {
+			return TransactionModel{}, err
+		}
+		if splits > 0 {
+			return TransactionModel{}, ErrSplitAmountChange
+		}
+		t.Amount = *u.Amount
+	}
+	if u.AccountID != nil {
+		t.AccountID = *u.AccountID
+	}
+	if u.Date != nil {
+		t.Date = *u.Date
+	}
+	if u.Description != nil {
+		t.Description = *u.Description
+	}
+	if u.Currency != nil {
+		t.Currency = *u.Currency
+	}
+	if u.OwnerID != nil {
+		t.OwnerID = u.OwnerID
+	}
+	if u.ClearOwner {
+		t.OwnerID = nil
+	}
+
+	if err := s.validateTransactionFields(tx, &t); err != nil {
+		return TransactionModel{}, err
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE transactions SET account_id = ?, owner_id = ?, date = ?, description = ?, amount = ?, currency = ? WHERE id = ?",
+		t.AccountID, t.OwnerID, t.Date, t.Description, t.Amount, t.Currency, id,
+	); err != nil {
+		return TransactionModel{}, err
+	}
+	if err := assignMerchants(tx, "id = ?", id); err != nil {
+		return TransactionModel{}, err
+	}
+	if u.Amount != nil || u.AccountID != nil || u.Date != nil || u.Currency != nil {
+		if err := s.unlinkBrokenTransfer(tx, t); err != nil {
+			return TransactionModel{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return TransactionModel{}, err
+	}
+	return s.GetTransaction(id)
+}
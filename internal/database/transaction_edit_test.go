@@ -0,0 +1,130 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestCreateTransaction(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, err := store.GetOrCreateAccount("Cash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	catID, err := store.GetOrCreateCategory("Groceries")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := store.CreateTransaction(TransactionModel{
+		AccountID:   accID,
+		Date:        "2025-02-03",
+		Description: "  Farmers market ",
+		Amount:      -2350,
+		CategoryID:  &catID,
+		Currency:    "usd",
+		Note:        "cash only",
+	})
+	if err != nil {
+		t.Fatalf("CreateTransaction failed: %v", err)
+	}
+	if tx.ID == 0 || tx.Description != "Farmers market" || tx.Currency != "USD" || tx.CategoryName != "Groceries" || tx.Note != "cash only" {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+	var source string
+	if err := store.DB().QueryRow("SELECT category_source FROM transactions WHERE id = ?", tx.ID).Scan(&source); err != nil {
+		t.Fatal(err)
+	}
+	if source != CategorySourceManual {
+		t.Errorf("expected manual category source, got %q", source)
+	}
+
+	defaulted, err := store.CreateTransaction(TransactionModel{AccountID: accID, Date: "2025-02-04", Description: "Coffee", Amount: -450})
+	if err != nil {
+		t.Fatalf("CreateTransaction without currency failed: %v", err)
+	}
+	if defaulted.Currency != DefaultCurrency() {
+		t.Errorf("expected main currency, got %q", defaulted.Currency)
+	}
+
+	tests := []struct {
+		name string
+		tx   TransactionModel
+		want error
+	}{
+		{"bad date", TransactionModel{AccountID: accID, Date: "2025-02-30", Description: "X", Amount: 1, Currency: "CAD"}, ErrInvalidTransactionDate},
+		{"empty description", TransactionModel{AccountID: accID, Date: "2025-02-03", Description: " ", Amount: 1, Currency: "CAD"}, ErrEmptyDescription},
+		{"bad currency", TransactionModel{AccountID: accID, Date: "2025-02-03", Description: "X", Amount: 1, Currency: "DOLLARS"}, ErrInvalidCurrency},
+		{"unknown account", TransactionModel{AccountID: 999, Date: "2025-02-03", Description: "X", Amount: 1, Currency: "CAD"}, ErrUnknownAccount},
+		{"duplicate", TransactionModel{AccountID: accID, Date: "2025-02-03", Description: "Farmers market", Amount: -2350, Currency: "CAD"}, ErrDuplicateTransaction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.CreateTransaction(tt.tx); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, err := store.GetOrCreateAccount("Cash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ownerID, err := store.GetOrCreateUser("Alex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := store.CreateTransaction(TransactionModel{AccountID: accID, OwnerID: ownerID, Date: "2025-02-03", Description: "Lunch", Amount: -1500, Currency: "CAD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.CreateTransaction(TransactionModel{AccountID: accID, Date: "2025-02-04", Description: "Lunch", Amount: -1500, Currency: "CAD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := "2025-02-05"
+	amount := int64(-1750)
+	currency := "eur"
+	updated, err := store.UpdateTransaction(first.ID, TransactionUpdate{Date: &date, Amount: &amount, Currency: &currency, ClearOwner: true})
+	if err != nil {
+		t.Fatalf("UpdateTransaction failed: %v", err)
+	}
+	if updated.Date != date || updated.Amount != amount || updated.Currency != "EUR" || updated.OwnerID != nil || updated.Description != "Lunch" {
+		t.Errorf("unexpected transaction: %+v", updated)
+	}
+
+	// Moving the second transaction onto the first one's key must fail.
+	secondAmount := int64(-1750)
+	if _, err := store.UpdateTransaction(second.ID, TransactionUpdate{Date: &date, Amount: &secondAmount}); !errors.Is(err, ErrDuplicateTransaction) {
+		t.Errorf("expected ErrDuplicateTransaction, got %v", err)
+	}
+	// Saving a transaction unchanged does not collide with itself.
+	if _, err := store.UpdateTransaction(first.ID, TransactionUpdate{Date: &date}); err != nil {
+		t.Errorf("expected unchanged update to succeed, got %v", err)
+	}
+
+	if _, err := store.UpdateTransaction(999, TransactionUpdate{Date: &date}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	catID, err := store.GetOrCreateCategory("Dining")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SplitTransaction(first.ID, []SplitLine{{Amount: -1000, CategoryID: &catID}, {Amount: -750, CategoryID: &catID}}); err != nil {
+		t.Fatal(err)
+	}
+	newAmount := int64(-2000)
+	if _, err := store.UpdateTransaction(first.ID, TransactionUpdate{Amount: &newAmount}); !errors.Is(err, ErrSplitAmountChange) {
+		t.Errorf("expected ErrSplitAmountChange, got %v", err)
+	}
+}
@@ -7,20 +7,45 @@ import (
 )
 
 type TransactionModel struct {
-	ID                   int64  `json:"id"`
-	AccountID            int64  `json:"account_id"`
-	AccountName          string `json:"account_name"`
-	OwnerID              *int64 `json:"owner_id"`
-	OwnerName            string `json:"owner_name"`
-	Date                 string `json:"date"`
-	Description          string `json:"description"`
-	Amount               int64  `json:"amount"`
-	CategoryID           *int64 `json:"category_id"`
-	CategoryName         string `json:"category_name"`
+	ID           int64  `json:"id"`
+	AccountID    int64  `json:"account_id"`
+	AccountName  string `json:"account_name"`
+	OwnerID      *int64 `json:"owner_id"`
+	OwnerName    string `json:"owner_name"`
+	Date         string `json:"date"`
+	Description  string `json:"description"`
+	Note         string `json:"note"`
+	Amount       int64  `json:"amount"`
+	CategoryID   *int64 `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	// CategoryFullName, TopCategoryID, TopCategoryName and CategoryKind are only
+	// filled by GetAnalysisTransactions.
+	CategoryFullName string `json:"category_full_name,omitempty"`
+	TopCategoryID    *int64 `json:"top_category_id,omitempty"`
+	TopCategoryName  string `json:"top_category_name,omitempty"`
+	CategoryKind     string `json:"category_kind,omitempty"`
+	// CategorySource is only filled by GetCategorizedTransactions.
+	CategorySource       string `json:"category_source,omitempty"`
 	Currency             string `json:"currency"`
 	RawMetadata          string `json:"raw_metadata"`
 	AmountInMainCurrency *int64 `json:"amount_in_main_currency"`
 	MainCurrency         string `json:"main_currency"`
+	// Tags is only filled by GetAnalysisTransactions.
+	Tags []string `json:"tags,omitempty"`
+	// MerchantID and MerchantName are filled by GetTransaction and
+	// GetAnalysisTransactions.
+	MerchantID   *int64 `json:"merchant_id,omitempty"`
+	MerchantName string `json:"merchant_name,omitempty"`
+	// SplitID is set when GetAnalysisTransactions returns one split line of
+	// the transaction: Amount, CategoryID and OwnerID are the line's, and ID
+	// is still the transaction's.
+	SplitID   *int64 `json:"split_id,omitempty"`
+	SplitNote string `json:"split_note,omitempty"`
+	// TransferLinkID is set by GetAnalysisTransactions when the transaction
+	// is one side of a linked transfer; CategoryKind is then a transfer.
+	TransferLinkID *int64 `json:"transfer_link_id,omitempty"`
+
+	Predictions []CategoryPrediction `json:"predictions,omitempty"`
 }
 
 func (s *Store) convertTransactionAmounts(txs []TransactionModel) ([]TransactionModel, error) {
@@ -198,98 +223,75 @@ func (s *Store) GetUsers() ([]string, error) {
 }
 
 func (s *Store) BatchInsertTransactions(txs []TransactionModel) error {
-	if len(txs) == 0 {
-		return nil
-	}
-
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	stmt, err := tx.Prepare(`
-		INSERT OR IGNORE INTO transactions
-		(account_id, owner_id, date, description, amount, category_id, currency, raw_metadata)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-
-	for _, t := range txs {
-		if _, err := stmt.Exec(
-			t.AccountID,
-			t.OwnerID,
-			t.Date,
-			t.Description,
-			t.Amount,
-			t.CategoryID,
-			t.Currency,
-			t.RawMetadata,
-		); err != nil {
-			return err
-		}
-	}
-
-	return tx.Commit()
+	_, err := s.BatchInsertTransactionsWithIDs(txs)
+	return err
 }
 
-func (s *Store) BatchInsertTransactionsWithCount(txs []TransactionModel) (int, int, error) {
+// BatchInsertTransactionsWithIDs inserts txs, skipping duplicates, and returns
+// the IDs of the rows that were actually inserted.
+func (s *Store) BatchInsertTransactionsWithIDs(txs []TransactionModel) ([]int64, error) {
+	ids := []int64{}
 	if len(txs) == 0 {
-		return 0, 0, nil
+		return ids, nil
 	}
 
 	tx, err := s.db.Begin()
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(`
 		INSERT OR IGNORE INTO transactions
-		(account_id, owner_id, date, description, amount, category_id, currency, raw_metadata)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		(account_id, owner_id, date, description, note, amount, category_id, category_source, currency, raw_metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CASE WHEN ? IS NULL THEN NULL ELSE 'manual' END, ?, ?)
 	`)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 	defer stmt.Close()
 
-	inserted := 0
 	for _, t := range txs {
 		res, err := stmt.Exec(
 			t.AccountID,
 			t.OwnerID,
 			t.Date,
 			t.Description,
+			t.Note,
 			t.Amount,
 			t.CategoryID,
+			t.CategoryID,
 			t.Currency,
 			t.RawMetadata,
 		)
 		if err != nil {
-			return 0, 0, err
+			return nil, err
 		}
-		rows, err := res.RowsAffected()
-		if err == nil && rows > 0 {
-			inserted++
+		if rows, err := res.RowsAffected(); err != nil || rows == 0 {
+			continue
 		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		if err := assignMerchants(tx, "id = ?", id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 
-	return inserted, len(txs) - inserted, nil
+	return ids, nil
 }
 
 func (s *Store) GetUncategorizedTransactions() ([]TransactionModel, error) {
 	rows, err := s.db.Query(`
 		SELECT
 			t.id, t.account_id, a.name, t.owner_id, COALESCE(u.name, ''),
-			t.date, t.description, t.amount, t.category_id, t.currency
+			t.date, t.description, t.note, t.amount, t.category_id, t.currency
 		FROM transactions t
 		JOIN accounts a ON t.account_id = a.id
 		LEFT JOIN users u ON t.owner_id = u.id
@@ -306,7 +308,7 @@ func (s *Store) GetUncategorizedTransactions() ([]TransactionModel, error) {
 		var t TransactionModel
 		if err := rows.Scan(
 			&t.ID, &t.AccountID, &t.AccountName, &t.OwnerID, &t.OwnerName,
-			&t.Date, &t.Description, &t.Amount, &t.CategoryID, &t.Currency,
+			&t.Date, &t.Description, &t.Note, &t.Amount, &t.CategoryID, &t.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -315,15 +317,71 @@ func (s *Store) GetUncategorizedTransactions() ([]TransactionModel, error) {
 	return s.convertTransactionAmounts(txs)
 }
 
+func (s *Store) GetCategorizedTransactions() ([]TransactionModel, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			t.id, t.account_id, a.name, t.owner_id, COALESCE(u.name, ''),
+			t.date, t.description, t.amount, t.category_id, COALESCE(c.name, ''), COALESCE(t.category_source, ''), t.currency
+		FROM transactions t
+		JOIN accounts a ON t.account_id = a.id
+		LEFT JOIN users u ON t.owner_id = u.id
+		JOIN categories c ON t.category_id = c.id
+		ORDER BY t.date DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := []TransactionModel{}
+	for rows.Next() {
+		var t TransactionModel
+		if err := rows.Scan(
+			&t.ID, &t.AccountID, &t.AccountName, &t.OwnerID, &t.OwnerName,
+			&t.Date, &t.Description, &t.Amount, &t.CategoryID, &t.CategoryName, &t.CategorySource, &t.Currency,
+		); err != nil {
+			return nil, err
+		}
+		txs = append(txs, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return s.convertTransactionAmounts(txs)
+}
+
+// UpdateTransactionCategory sets a manually chosen category; 0 clears it.
 func (s *Store) UpdateTransactionCategory(id int64, categoryID int64) error {
-	var cid interface{} = categoryID
+	return s.SetTransactionCategory(id, categoryID, CategorySourceManual)
+}
+
+// SetTransactionCategory sets the category and records where it came from; 0 clears it.
+func (s *Store) SetTransactionCategory(id int64, categoryID int64, source string) error {
+	var cid, src interface{} = categoryID, source
 	if categoryID == 0 {
-		cid = nil
+		cid, src = nil, nil
 	}
-	_, err := s.db.Exec("UPDATE transactions SET category_id = ? WHERE id = ?", cid, id)
+	_, err := s.db.Exec("UPDATE transactions SET category_id = ?, category_source = ?, category_rule_id = NULL WHERE id = ?", cid, src, id)
 	return err
 }
 
+// SetTransactionNote replaces the note of a transaction; an empty note clears
+// it.
+func (s *Store) SetTransactionNote(id int64, note string) error {
+	res, err := s.db.Exec("UPDATE transactions SET note = ? WHERE id = ?", note, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("transaction %d not found: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
 func (s *Store) ClearTransactionCategories(ids []int64) error {
 	if len(ids) == 0 {
 		return nil
@@ -334,7 +392,7 @@ func (s *Store) ClearTransactionCategories(ids []int64) error {
 		placeholders[i] = "?"
 		args[i] = id
 	}
-	query := "UPDATE transactions SET category_id = NULL WHERE id IN (" + strings.Join(placeholders, ",") + ")"
+	query := "UPDATE transactions SET category_id = NULL, category_source = NULL, category_rule_id = NULL WHERE id IN (" + strings.Join(placeholders, ",") + ")"
 	_, err := s.db.Exec(query, args...)
 	return err
 }
@@ -721,15 +779,21 @@ func (s *Store) GetMonthList() ([]string, error) {
 	return months, nil
 }
 
-func (s *Store) GetAnalysisTransactions(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64) ([]TransactionModel, error) {
-	query := `
+// GetAnalysisTransactions returns transactions in the date range matching any
+// of the given categories, owners, tags and merchants. MissingFilterID selects
+// uncategorized, ownerless, untagged or merchantless transactions. Split transactions are
+// returned as one row per split line, and filters apply to the lines.
+func (s *Store) GetAnalysisTransactions(startDate string, endDate string, categoryIDs []int64, ownerIDs []int64, tagIDs []int64, merchantIDs []int64) ([]TransactionModel, error) {
+	query := expandedTransactionsCTE + `
 		SELECT
-			t.id, t.account_id, a.name, t.owner_id, COALESCE(u.name, ''),
-			t.date, t.description, t.amount, t.category_id, COALESCE(c.name, ''), t.currency
-		FROM transactions t
+			t.id, t.split_id, t.split_note, t.account_id, a.name, t.owner_id, COALESCE(u.name, ''),
+			t.date, t.description, t.note, t.amount, t.category_id, COALESCE(c.name, ''), t.currency,
+			t.merchant_id, COALESCE(m.name, '')
+		FROM expanded t
 		JOIN accounts a ON t.account_id = a.id
 		LEFT JOIN users u ON t.owner_id = u.id
 		LEFT JOIN categories c ON t.category_id = c.id
+		LEFT JOIN merchants m ON t.merchant_id = m.id
 		WHERE t.date >= ? AND t.date <= ?
 	`
 	args := []any{startDate, endDate}
@@ -746,6 +810,15 @@ func (s *Store) GetAnalysisTransactions(startDate string, endDate string, catego
 			}
 		}
 
+		// Selecting a category includes its subcategories.
+		if len(realIDs) > 0 {
+			categories, err := s.loadCategories()
+			if err != nil {
+				return nil, err
+			}
+			realIDs = withCategoryDescendants(categories, realIDs)
+		}
+
 		if hasUncategorized {
 			query += " AND (t.category_id IS NULL"
 			if len(realIDs) > 0 {
@@ -799,6 +872,58 @@ func (s *Store) GetAnalysisTransactions(startDate string, endDate string, catego
 		}
 	}
 
+	if len(tagIDs) > 0 {
+		hasUntagged := false
+		var realTagIDs []int64
+		for _, id := range tagIDs {
+			if id == MissingFilterID {
+				hasUntagged = true
+			} else {
+				realTagIDs = append(realTagIDs, id)
+			}
+		}
+
+		var conditions []string
+		if hasUntagged {
+			conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id)")
+		}
+		if len(realTagIDs) > 0 {
+			placeholders := make([]string, len(realTagIDs))
+			for i, id := range realTagIDs {
+				placeholders[i] = "?"
+				args = append(args, id)
+			}
+			conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = t.id AND tt.tag_id IN (%s))", strings.Join(placeholders, ",")))
+		}
+		query += " AND (" + strings.Join(conditions, " OR ") + ")"
+	}
+
+	if len(merchantIDs) > 0 {
+		hasNoMerchant := false
+		var realMerchantIDs []int64
+		for _, id := range merchantIDs {
+			if id == MissingFilterID {
+				hasNoMerchant = true
+			} else {
+				realMerchantIDs = append(realMerchantIDs, id)
+			}
+		}
+
+		var conditions []string
+		if hasNoMerchant {
+			conditions = append(conditions, "t.merchant_id IS NULL")
+		}
+		if len(realMerchantIDs) > 0 {
+			placeholders := make([]string, len(realMerchantIDs))
+			for i, id := range realMerchantIDs {
+				placeholders[i] = "?"
+				args = append(args, id)
+			}
+			conditions = append(conditions, fmt.Sprintf("t.merchant_id IN (%s)", strings.Join(placeholders, ",")))
+		}
+		query += " AND (" + strings.Join(conditions, " OR ") + ")"
+	}
+
 	query += " ORDER BY t.date DESC"
 
 	rows, err := s.db.Query(query, args...)
@@ -811,13 +936,23 @@ func (s *Store) GetAnalysisTransactions(startDate string, endDate string, catego
 	for rows.Next() {
 		var t TransactionModel
 		if err := rows.Scan(
-			&t.ID, &t.AccountID, &t.AccountName, &t.OwnerID, &t.OwnerName,
-			&t.Date, &t.Description, &t.Amount, &t.CategoryID, &t.CategoryName, &t.Currency,
+			&t.ID, &t.SplitID, &t.SplitNote, &t.AccountID, &t.AccountName, &t.OwnerID, &t.OwnerName,
+			&t.Date, &t.Description, &t.Note, &t.Amount, &t.CategoryID, &t.CategoryName, &t.Currency,
+			&t.MerchantID, &t.MerchantName,
 		); err != nil {
 			return nil, err
 		}
 		txs = append(txs, t)
 	}
+	if err := s.fillTransactionCategoryPaths(txs); err != nil {
+		return nil, err
+	}
+	if err := s.fillTransactionTransferLinks(txs); err != nil {
+		return nil, err
+	}
+	if err := s.fillTransactionTags(txs); err != nil {
+		return nil, err
+	}
 	return s.convertTransactionAmounts(txs)
 }
 
@@ -833,8 +968,33 @@ func (s *Store) DeleteTransactions(ids []int64) (int, error) {
 		args[i] = id
 	}
 
-	query := "DELETE FROM transactions WHERE id IN (" + strings.Join(placeholders, ",") + ")"
-	result, err := s.db.Exec(query, args...)
+	in := "(" + strings.Join(placeholders, ",") + ")"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var hashes []string
+	rows, err := tx.Query("SELECT DISTINCT sha256 FROM transaction_attachments WHERE transaction_id IN "+in, args...)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var sha string
+		if err := rows.Scan(&sha); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		hashes = append(hashes, sha)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec("DELETE FROM transactions WHERE id IN "+in, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -844,5 +1004,33 @@ func (s *Store) DeleteTransactions(ids []int64) (int, error) {
 		return 0, err
 	}
 
+	if _, err := tx.Exec("DELETE FROM transaction_splits WHERE transaction_id IN "+in, args...); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id IN "+in, args...); err != nil {
+		return 0, err
+	}
+	if err := deleteUnusedTags(tx); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM transaction_attachments WHERE transaction_id IN "+in, args...); err != nil {
+		return 0, err
+	}
+	bothArgs := append(append([]interface{}{}, args...), args...)
+	if _, err := tx.Exec("DELETE FROM transfer_links WHERE out_transaction_id IN "+in+" OR in_transaction_id IN "+in, bothArgs...); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM transfer_dismissals WHERE out_transaction_id IN "+in+" OR in_transaction_id IN "+in, bothArgs...); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// The rows are gone either way; a file left behind only costs disk space.
+	if err := s.removeUnusedAttachmentFiles(hashes); err != nil {
+		s.logger.Error("failed to remove attachment files", "error", err)
+	}
+
 	return int(rowsAffected), nil
 }
@@ -1,6 +1,10 @@
 package database
 
-import "testing"
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
 
 func TestDeleteTransactions(t *testing.T) {
 	store := newTestStore(t)
@@ -26,7 +30,7 @@ func TestDeleteTransactions(t *testing.T) {
 		t.Fatalf("Failed to insert test transactions: %v", err)
 	}
 
-	fetched, err := store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil)
+	fetched, err := store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to fetch transactions: %v", err)
 	}
@@ -47,7 +51,7 @@ func TestDeleteTransactions(t *testing.T) {
 		t.Fatalf("Expected 1 row affected, got %d", count)
 	}
 
-	fetched, err = store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil)
+	fetched, err = store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to fetch transactions after delete: %v", err)
 	}
@@ -63,7 +67,7 @@ func TestDeleteTransactions(t *testing.T) {
 		t.Fatalf("Expected 2 rows affected, got %d", count)
 	}
 
-	fetched, err = store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil)
+	fetched, err = store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to fetch transactions after multi delete: %v", err)
 	}
@@ -97,3 +101,43 @@ func TestDeleteTransactionsNonExistent(t *testing.T) {
 		t.Fatalf("Expected 0 rows affected for non-existent IDs, got %d", count)
 	}
 }
+
+func TestSetTransactionNote(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	accID, err := store.GetOrCreateAccount("TestAccount")
+	if err != nil {
+		t.Fatalf("Failed to create test account: %v", err)
+	}
+	txs := []TransactionModel{
+		{AccountID: accID, Date: "2024-01-01", Description: "Flowers", Note: "gift for mom", Amount: -4500, Currency: defaultMainCurrency},
+	}
+	if err := store.BatchInsertTransactions(txs); err != nil {
+		t.Fatalf("Failed to insert test transactions: %v", err)
+	}
+
+	fetched, err := store.GetAnalysisTransactions("2024-01-01", "2024-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to fetch transactions: %v", err)
+	}
+	if len(fetched) != 1 || fetched[0].Note != "gift for mom" {
+		t.Fatalf("Expected imported note, got %+v", fetched)
+	}
+
+	id := fetched[0].ID
+	if err := store.SetTransactionNote(id, "reimbursed"); err != nil {
+		t.Fatalf("SetTransactionNote failed: %v", err)
+	}
+	tx, err := store.GetTransaction(id)
+	if err != nil {
+		t.Fatalf("GetTransaction failed: %v", err)
+	}
+	if tx.Note != "reimbursed" {
+		t.Errorf("Expected updated note, got %q", tx.Note)
+	}
+
+	if err := store.SetTransactionNote(99999, "x"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows for a missing transaction, got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultTransferWindowDays = 3
+	MaxTransferWindowDays     = 31
+
+	// transferFXTolerance is how far apart, as a fraction of the incoming
+	// amount, the two sides of a transfer in different currencies may be once
+	// converted to the main currency. Banks rarely use the day's reference rate.
+	transferFXTolerance = 0.02
+)
+
+var ErrInvalidTransferWindow = fmt.Errorf("transfer window must be between 0 and %d days", MaxTransferWindowDays)
+
+// TransferSide is one of the two transactions of a transfer.
+type TransferSide struct {
+	TransactionID int64  `json:"transaction_id"`
+	AccountID     int64  `json:"account_id"`
+	AccountName   string `json:"account_name"`
+	Date          string `json:"date"`
+	Description   string `json:"description"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+// TransferLink pairs money leaving one account with the same money arriving
+// in another. Linked transactions count as transfers in every report,
+// whatever their category.
+type TransferLink struct {
+	// ID is 0 for a pair that was detected but not linked.
+	ID        int64        `json:"id"`
+	Out       TransferSide `json:"out"`
+	In        TransferSide `json:"in"`
+	DaysApart int          `json:"days_apart"`
+	CreatedAt string       `json:"created_at"`
+}
+
+// TransferDetectOptions limits DetectTransfers to a date range; empty dates
+// leave that end open.
+type TransferDetectOptions struct {
+	StartDate string
+	EndDate   string
+	// WindowDays is how many days apart the two sides may be.
+	WindowDays int
+	// DryRun returns the pairs without linking them.
+	DryRun bool
+}
+
+func newTransferSide(tx TransactionModel) TransferSide {
+	return TransferSide{
+		TransactionID: tx.ID,
+		AccountID:     tx.AccountID,
+		AccountName:   tx.AccountName,
+		Date:          tx.Date,
+		Description:   tx.Description,
+		Amount:        tx.Amount,
+		Currency:      tx.Currency,
+	}
+}
+
+// transferAmountsMatch reports whether out (negative) and in (positive) move
+// the same money: exactly when both are in one currency, within
+// transferFXTolerance after conversion otherwise. It returns how far apart
+// the amounts are, for ranking.
+func transferAmountsMatch(out, in TransactionModel) (int64, bool) {
+	if out.Currency == in.Currency {
+		return 0, out.Amount+in.Amount == 0
+	}
+	if out.AmountInMainCurrency == nil || in.AmountInMainCurrency == nil {
+		return 0, false
+	}
+	diff := *out.AmountInMainCurrency + *in.AmountInMainCurrency
+	if diff < 0 {
+		diff = -diff
+	}
+	limit := int64(math.Round(float64(*in.AmountInMainCurrency) * transferFXTolerance))
+	return diff, diff <= max(limit, 1)
+}
+
+// DetectTransfers pairs outgoing and incoming transactions of different
+// accounts that move the same amount within WindowDays of each other, and
+// links them unless DryRun is set. Split, already linked and dismissed
+// transactions are skipped, as are transactions put in an income or expense
+// category by hand. When a transaction has several candidates, the closest in
+// date (then in amount) wins.
+func (s *Store) DetectTransfers(opts TransferDetectOptions) ([]TransferLink, error) {
+	if opts.WindowDays < 0 || opts.WindowDays > MaxTransferWindowDays {
+		return nil, ErrInvalidTransferWindow
+	}
+	start, end := opts.StartDate, opts.EndDate
+	if start == "" {
+		start = "0000-01-01"
+	}
+	if end == "" {
+		end = "9999-12-31"
+	}
+	txs, err := s.GetAnalysisTransactions(start, end, nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	dismissed, err := s.loadTransferDismissals()
+	if err != nil {
+		return nil, err
+	}
+	manual, err := s.loadManuallyCategorized()
+	if err != nil {
+		return nil, err
+	}
+
+	type dated struct {
+		tx   TransactionModel
+		date time.Time
+	}
+	var outs, ins []dated
+	for _, tx := range txs {
+		if tx.SplitID != nil || tx.TransferLinkID != nil || tx.Amount == 0 {
+			continue
+		}
+		if manual[tx.ID] && (tx.CategoryKind == CategoryKindIncome || tx.CategoryKind == CategoryKindExpense) {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+		if tx.Amount < 0 {
+			outs = append(outs, dated{tx, date})
+		} else {
+			ins = append(ins, dated{tx, date})
+		}
+	}
+	sort.Slice(ins, func(i, j int) bool { return ins[i].date.Before(ins[j].date) })
+
+	type candidate struct {
+		out, in dated
+		days    int
+		diff    int64
+	}
+	var candidates []candidate
+	window := time.Duration(opts.WindowDays) * 24 * time.Hour
+	for _, out := range outs {
+		from := sort.Search(len(ins), func(i int) bool { return !ins[i].date.Before(out.date.Add(-window)) })
+		for _, in := range ins[from:] {
+			if in.date.After(out.date.Add(window)) {
+				break
+			}
+			if in.tx.AccountID == out.tx.AccountID || dismissed[[2]int64{out.tx.ID, in.tx.ID}] {
+				continue
+			}
+			diff, ok := transferAmountsMatch(out.tx, in.tx)
+			if !ok {
+				continue
+			}
+			days := int(math.Abs(in.date.Sub(out.date).Hours()) / 24)
+			candidates = append(candidates, candidate{out: out, in: in, days: days, diff: diff})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.days != b.days {
+			return a.days < b.days
+		}
+		if a.diff != b.diff {
+			return a.diff < b.diff
+		}
+		if a.out.tx.ID != b.out.tx.ID {
+			return a.out.tx.ID < b.out.tx.ID
+		}
+		return a.in.tx.ID < b.in.tx.ID
+	})
+
+	used := map[int64]bool{}
+	links := []TransferLink{}
+	for _, c := range candidates {
+		if used[c.out.tx.ID] || used[c.in.tx.ID] {
+			continue
+		}
+		used[c.out.tx.ID] = true
+		used[c.in.tx.ID] = true
+		links = append(links, TransferLink{Out: newTransferSide(c.out.tx), In: newTransferSide(c.in.tx), DaysApart: c.days})
+	}
+	sort.SliceStable(links, func(i, j int) bool { return links[i].Out.Date < links[j].Out.Date })
+
+	if opts.DryRun || len(links) == 0 {
+		return links, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for i := range links {
+		res, err := tx.Exec(`INSERT INTO transfer_links (out_transaction_id, in_transaction_id) VALUES (?, ?)`,
+			links[i].Out.TransactionID, links[i].In.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if links[i].ID, err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (s *Store) loadTransferDismissals() (map[[2]int64]bool, error) {
+	rows, err := s.db.Query(`SELECT out_transaction_id, in_transaction_id FROM transfer_dismissals`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	dismissed := map[[2]int64]bool{}
+	for rows.Next() {
+		var out, in int64
+		if err := rows.Scan(&out, &in); err != nil {
+			return nil, err
+		}
+		dismissed[[2]int64{out, in}] = true
+	}
+	return dismissed, rows.Err()
+}
+
+// loadManuallyCategorized returns the IDs of transactions whose category was
+// set by hand rather than by a rule or a prediction.
+func (s *Store) loadManuallyCategorized() (map[int64]bool, error) {
+	rows, err := s.db.Query(`SELECT id FROM transactions WHERE category_id IS NOT NULL AND category_source = ?`, CategorySourceManual)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	manual := map[int64]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		manual[id] = true
+	}
+	return manual, rows.Err()
+}
+
+// GetTransferLinks returns every linked transfer, oldest first.
+func (s *Store) GetTransferLinks() ([]TransferLink, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			l.id, COALESCE(l.created_at, ''),
+			o.id, o.account_id, oa.name, o.date, o.description, o.amount, o.currency,
+			i.id, i.account_id, ia.name, i.date, i.description, i.amount, i.currency
+		FROM transfer_links l
+		JOIN transactions o ON o.id = l.out_transaction_id
+		JOIN accounts oa ON oa.id = o.account_id
+		JOIN transactions i ON i.id = l.in_transaction_id
+		JOIN accounts ia ON ia.id = i.account_id
+		ORDER BY o.date, l.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []TransferLink{}
+	for rows.Next() {
+		var l TransferLink
+		if err := rows.Scan(
+			&l.ID, &l.CreatedAt,
+			&l.Out.TransactionID, &l.Out.AccountID, &l.Out.AccountName, &l.Out.Date, &l.Out.Description, &l.Out.Amount, &l.Out.Currency,
+			&l.In.TransactionID, &l.In.AccountID, &l.In.AccountName, &l.In.Date, &l.In.Description, &l.In.Amount, &l.In.Currency,
+		); err != nil {
+			return nil, err
+		}
+		outDate, errOut := time.Parse("2006-01-02", l.Out.Date)
+		inDate, errIn := time.Parse("2006-01-02", l.In.Date)
+		if errOut == nil && errIn == nil {
+			l.DaysApart = int(math.Abs(inDate.Sub(outDate).Hours()) / 24)
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// UnlinkTransfer removes a transfer link and remembers the pair so that
+// DetectTransfers does not link it again.
+func (s *Store) UnlinkTransfer(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var out, in int64
+	err = tx.QueryRow(`SELECT out_transaction_id, in_transaction_id FROM transfer_links WHERE id = ?`, id).Scan(&out, &in)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("transfer link %d not found: %w", id, sql.ErrNoRows)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM transfer_links WHERE id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO transfer_dismissals (out_transaction_id, in_transaction_id) VALUES (?, ?)`, out, in); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// unlinkBrokenTransfer removes the transfer link of t, as just edited in tx,
+// when t no longer pairs with the other side: both in one account, amounts
+// that are not opposite, or more than MaxTransferWindowDays apart. The pair
+// is not dismissed, so detection links it again once it matches.
+func (s *Store) unlinkBrokenTransfer(tx *sql.Tx, t TransactionModel) error {
+	var linkID int64
+	other := TransactionModel{}
+	err := tx.QueryRow(`
+		SELECT l.id, o.id, o.account_id, o.date, o.amount, o.currency
+		FROM transfer_links l
+		JOIN transactions o ON o.id = CASE WHEN l.out_transaction_id = ? THEN l.in_transaction_id ELSE l.out_transaction_id END
+		WHERE l.out_transaction_id = ? OR l.in_transaction_id = ?
+	`, t.ID, t.ID, t.ID).Scan(&linkID, &other.ID, &other.AccountID, &other.Date, &other.Amount, &other.Currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	out, in := t, other
+	if t.Amount > 0 {
+		out, in = other, t
+	}
+	paired := out.AccountID != in.AccountID && out.Amount < 0 && in.Amount > 0
+	if paired {
+		outDate, errOut := time.Parse("2006-01-02", out.Date)
+		inDate, errIn := time.Parse("2006-01-02", in.Date)
+		paired = errOut == nil && errIn == nil &&
+			math.Abs(inDate.Sub(outDate).Hours())/24 <= MaxTransferWindowDays
+	}
+	if paired {
+		converted, err := s.convertTransactionAmounts([]TransactionModel{out, in})
+		if err != nil {
+			return err
+		}
+		_, paired = transferAmountsMatch(converted[0], converted[1])
+	}
+	if paired {
+		return nil
+	}
+	_, err = tx.Exec(`DELETE FROM transfer_links WHERE id = ?`, linkID)
+	return err
+}
+
+// fillTransactionTransferLinks sets TransferLinkID on linked transactions and
+// makes their CategoryKind a transfer, so that totals leave them out.
+func (s *Store) fillTransactionTransferLinks(txs []TransactionModel) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	rows, err := s.db.Query(`SELECT id, out_transaction_id, in_transaction_id FROM transfer_links`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	links := map[int64]int64{}
+	for rows.Next() {
+		var id, out, in int64
+		if err := rows.Scan(&id, &out, &in); err != nil {
+			return err
+		}
+		links[out] = id
+		links[in] = id
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range txs {
+		if id, ok := links[txs[i].ID]; ok {
+			txs[i].TransferLinkID = &id
+			txs[i].CategoryKind = CategoryKindTransfer
+		}
+	}
+	return nil
+}
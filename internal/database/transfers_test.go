@@ -0,0 +1,168 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestDetectTransfers(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+
+	checking, _ := store.GetOrCreateAccount("Checking")
+	card, _ := store.GetOrCreateAccount("Card")
+	savings, _ := store.GetOrCreateAccount("Savings")
+	brokerage, _ := store.GetOrCreateAccount("Brokerage")
+	groceries, _ := store.GetOrCreateCategory("Groceries")
+
+	var ids []int64
+	for _, tx := range []TransactionModel{
+		{AccountID: checking, Date: "2025-01-10", Description: "Card payment", Amount: -20000},
+		{AccountID: card, Date: "2025-01-12", Description: "Payment received", Amount: 20000},
+		// Too late to pair with the card payment.
+		{AccountID: savings, Date: "2025-01-20", Description: "Deposit", Amount: 20000},
+		// Same account as the card payment.
+		{AccountID: checking, Date: "2025-01-10", Description: "Refund", Amount: 20000},
+		{AccountID: checking, Date: "2025-03-02", Description: "Wire out", Amount: -10000},
+		{AccountID: brokerage, Date: "2025-03-03", Description: "Wire in", Amount: 7520, Currency: "USD"},
+		// Categorized by hand as an expense, so never a transfer.
+		{AccountID: checking, Date: "2025-02-05", Description: "Groceries", Amount: -3000, CategoryID: &groceries},
+		{AccountID: card, Date: "2025-02-05", Description: "Cashback", Amount: 3000},
+	} {
+		created, err := store.CreateTransaction(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	// A rule's category does not keep the card payment from being linked.
+	if err := store.SetTransactionCategory(ids[0], groceries, CategorySourceRule); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertFxRates([]FxRate{
+		{BaseCurrency: "CAD", QuoteCurrency: "USD", RateDate: "2025-01-01", Rate: 1.333333333333, Source: "test"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.DetectTransfers(TransferDetectOptions{WindowDays: MaxTransferWindowDays + 1}); !errors.Is(err, ErrInvalidTransferWindow) {
+		t.Errorf("expected ErrInvalidTransferWindow, got %v", err)
+	}
+
+	pairs, err := store.DetectTransfers(TransferDetectOptions{WindowDays: DefaultTransferWindowDays, DryRun: true})
+	if err != nil {
+		t.Fatalf("DetectTransfers failed: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("expected 2 pairs, got %+v", pairs)
+	}
+	if pairs[0].ID != 0 || pairs[0].Out.TransactionID != ids[0] || pairs[0].In.TransactionID != ids[1] || pairs[0].DaysApart != 2 {
+		t.Errorf("unexpected card payment pair: %+v", pairs[0])
+	}
+	if pairs[1].Out.TransactionID != ids[4] || pairs[1].In.TransactionID != ids[5] || pairs[1].In.Currency != "USD" {
+		t.Errorf("unexpected wire pair: %+v", pairs[1])
+	}
+	if links, _ := store.GetTransferLinks(); len(links) != 0 {
+		t.Fatalf("dry run should not link, got %+v", links)
+	}
+
+	linked, err := store.DetectTransfers(TransferDetectOptions{WindowDays: DefaultTransferWindowDays})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(linked) != 2 || linked[0].ID == 0 {
+		t.Fatalf("expected 2 links, got %+v", linked)
+	}
+	again, err := store.DetectTransfers(TransferDetectOptions{WindowDays: DefaultTransferWindowDays})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Errorf("linked transactions should not be detected again, got %+v", again)
+	}
+
+	txs, err := store.GetAnalysisTransactions("2025-01-01", "2025-01-31", nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range txs {
+		if tx.ID == ids[0] && (tx.TransferLinkID == nil || *tx.TransferLinkID != linked[0].ID || tx.CategoryKind != CategoryKindTransfer) {
+			t.Errorf("expected the card payment to be a linked transfer, got %+v", tx)
+		}
+	}
+	totals, err := store.GetKindTotals(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.Expenses != 0 || totals.Income != 40000 || totals.Transfers != 0 {
+		t.Errorf("unexpected totals: %+v", totals)
+	}
+
+	links, err := store.GetTransferLinks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].Out.AccountName != "Checking" || links[0].In.AccountName != "Card" || links[0].DaysApart != 2 {
+		t.Fatalf("unexpected links: %+v", links)
+	}
+
+	if err := store.UnlinkTransfer(links[0].ID); err != nil {
+		t.Fatalf("UnlinkTransfer failed: %v", err)
+	}
+	if err := store.UnlinkTransfer(links[0].ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+	again, err = store.DetectTransfers(TransferDetectOptions{WindowDays: DefaultTransferWindowDays})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Errorf("unlinked pairs should not be detected again, got %+v", again)
+	}
+
+	wireOut := int64(-5000)
+	if _, err := store.UpdateTransaction(ids[4], TransactionUpdate{Amount: &wireOut}); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := store.GetTransferLinks(); len(links) != 0 {
+		t.Fatalf("expected an unbalancing edit to remove the link, got %+v", links)
+	}
+	wireOut = -10000
+	if _, err := store.UpdateTransaction(ids[4], TransactionUpdate{Amount: &wireOut}); err != nil {
+		t.Fatal(err)
+	}
+	relinked, err := store.DetectTransfers(TransferDetectOptions{WindowDays: DefaultTransferWindowDays})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relinked) != 1 || relinked[0].Out.TransactionID != ids[4] {
+		t.Fatalf("expected the fixed wire to be linked again, got %+v", relinked)
+	}
+	wireDate := "2025-03-04"
+	if _, err := store.UpdateTransaction(ids[4], TransactionUpdate{Date: &wireDate}); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := store.GetTransferLinks(); len(links) != 1 {
+		t.Fatalf("expected a date edit that still pairs to keep the link, got %+v", links)
+	}
+	if _, err := store.UpdateTransaction(ids[4], TransactionUpdate{AccountID: &brokerage}); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := store.GetTransferLinks(); len(links) != 0 {
+		t.Fatalf("expected moving both sides into one account to remove the link, got %+v", links)
+	}
+	if _, err := store.UpdateTransaction(ids[4], TransactionUpdate{AccountID: &checking}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.DetectTransfers(TransferDetectOptions{WindowDays: DefaultTransferWindowDays}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.DeleteTransactions([]int64{ids[5]}); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := store.GetTransferLinks(); len(links) != 0 {
+		t.Errorf("expected deleting a side to remove its link, got %+v", links)
+	}
+}
@@ -19,6 +19,8 @@ type ImportMapping struct {
 		AmountMapping AmountMapping `json:"amountMapping"`
 		Account       string        `json:"account,omitempty"`
 		Currency      string        `json:"currency,omitempty"`
+		// Memo is imported as the transaction note.
+		Memo string `json:"memo,omitempty"`
 	} `json:"csv"`
 	Account         string `json:"account"`
 	Owner           string `json:"owner,omitempty"`
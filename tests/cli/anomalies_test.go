@@ -0,0 +1,45 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReportAnomalies(t *testing.T) {
+	db := setupDB(t)
+
+	add := func(date, description, amount string) {
+		t.Helper()
+		res, _ := run(db, "tx", "add", "--date", date, "--description", description, "--amount", amount, "--account", "Visa", "--category", "Groceries")
+		assertGlobal(t, res, 0)
+	}
+	for m := 1; m <= 12; m++ {
+		add(fmt.Sprintf("2024-%02d-03", m), "Grocer", fmt.Sprintf("-%d.00", 400+10*(m%3)))
+	}
+	add("2025-01-03", "Grocer", "-1200.00")
+
+	res, _ := run(db, "report", "anomalies", "--month", "2025-13")
+	assertGlobal(t, res, 2)
+
+	res, _ = run(db, "report", "anomalies", "--month", "2025-01")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(2) {
+		t.Fatalf("expected a category and a merchant anomaly, got %v", res.JSON)
+	}
+	first := res.JSON["anomalies"].([]interface{})[0].(map[string]interface{})
+	if first["category"] != "Groceries" || first["amount"] != "1200.00" || first["median"] != "410.00" || first["direction"] != "high" {
+		t.Errorf("unexpected anomaly: %v", first)
+	}
+
+	res, _ = run(db, "report", "anomalies", "--month", "2024-12")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(0) {
+		t.Errorf("expected no anomalies, got %v", res.JSON)
+	}
+
+	res, _ = run(db, "--format", "table", "report", "anomalies", "--month", "2025-01")
+	if res.ExitCode != 0 || !strings.Contains(res.Stdout, "merchant") {
+		t.Errorf("unexpected table output: %s", res.Stdout)
+	}
+}
@@ -0,0 +1,120 @@
+package cli_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBalances(t *testing.T) {
+	db := setupDB(t)
+
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"}},"account":"Checking","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	os.WriteFile(mappingPath, []byte(mappingJSON), 0644)
+	csvData := `Date,Description,Amount
+2025-01-05,Payroll,1000.00
+2025-01-20,Rent,-300.00
+`
+	csvPath := filepath.Join(t.TempDir(), "january.csv")
+	os.WriteFile(csvPath, []byte(csvData), 0644)
+
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath, "--closing-balance", "abc")
+	assertGlobal(t, res, 2)
+
+	res, _ = run(db, "import", "--file", csvPath, "--mapping", mappingPath, "--closing-balance", "1500.00")
+	assertGlobal(t, res, 0)
+	closing := res.JSON["closing_balance"].(map[string]interface{})
+	if closing["account"] != "Checking" || closing["date"] != "2025-01-20" || closing["balance"] != "1500.00" || closing["source"] != "statement" {
+		t.Fatalf("unexpected closing balance: %v", closing)
+	}
+
+	res, _ = run(db, "tx", "add", "--date", "2025-02-10", "--description", "Hydro", "--amount", "-200.00", "--account", "Checking")
+	assertGlobal(t, res, 0)
+
+	t.Run("Closing date", func(t *testing.T) {
+		statementDB := setupDB(t)
+		statement := filepath.Join(t.TempDir(), "statement.csv")
+		os.WriteFile(statement, []byte("Date,Description,Amount\n2025-03-03,Coffee,-5.00\n2025-04-02,Tea,-4.00\n"), 0644)
+
+		res, _ := run(statementDB, "import", "--file", statement, "--mapping", mappingPath, "--month", "2025-03", "--closing-balance", "100.00", "--dry-run")
+		assertGlobal(t, res, 2)
+		res, _ = run(statementDB, "import", "--file", statement, "--mapping", mappingPath, "--month", "2025-03", "--month", "2025-04", "--closing-date", "2025-04-05", "--dry-run")
+		assertGlobal(t, res, 2)
+		res, _ = run(statementDB, "import", "--file", statement, "--mapping", mappingPath, "--month", "2025-03", "--month", "2025-04", "--closing-balance", "100.00", "--closing-date", "2025-04-01", "--dry-run")
+		assertGlobal(t, res, 2)
+		res, _ = run(statementDB, "import", "--file", statement, "--mapping", mappingPath, "--month", "2025-03", "--month", "2025-04", "--closing-balance", "100.00", "--closing-date", "2025-04-05")
+		assertGlobal(t, res, 0)
+		if closing := res.JSON["closing_balance"].(map[string]interface{}); closing["date"] != "2025-04-05" {
+			t.Errorf("expected the closing balance on 2025-04-05, got %v", closing)
+		}
+
+		// With no rows to import there is no account to put the balance on.
+		empty := filepath.Join(t.TempDir(), "empty.csv")
+		os.WriteFile(empty, []byte("Date,Description,Amount\n"), 0644)
+		res, _ = run(statementDB, "import", "--file", empty, "--mapping", mappingPath, "--month", "2025-05", "--closing-balance", "100.00")
+		assertGlobal(t, res, 2)
+		if detail := res.JSON["errors"].([]interface{})[0].(map[string]interface{}); detail["field"] != "closing-balance" {
+			t.Errorf("expected a closing-balance error, got %v", detail)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "balances", "set", "--account", "Checking", "--date", "2025-02-30", "--balance", "1")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "balances", "set", "--account", "Checking", "--date", "2025-02-28", "--balance", "1", "--source", "bank")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "balances", "set", "--account", "Nope", "--date", "2025-02-28", "--balance", "1")
+		assertGlobal(t, res, 1)
+		res, _ = run(db, "report", "net-worth", "--start", "2025-01")
+		assertGlobal(t, res, 2)
+	})
+
+	res, _ = run(db, "balances", "set", "--account", "Checking", "--date", "2025-02-28", "--balance", "1250.00")
+	assertGlobal(t, res, 0)
+	cp := res.JSON["checkpoint"].(map[string]interface{})
+	if cp["currency"] != "CAD" || cp["source"] != "manual" {
+		t.Errorf("unexpected checkpoint: %v", cp)
+	}
+	febID := fmt.Sprint(cp["id"])
+
+	res, _ = run(db, "balances", "reconcile", "--account", "Checking")
+	assertGlobal(t, res, 0)
+	if res.JSON["reconciled"] != false {
+		t.Errorf("expected a gap, got %v", res.JSON)
+	}
+	account := res.JSON["accounts"].([]interface{})[0].(map[string]interface{})
+	if account["opening_balance"] != "800.00" || account["current_balance"] != "1250.00" {
+		t.Errorf("unexpected account reconciliation: %v", account)
+	}
+	feb := account["checkpoints"].([]interface{})[1].(map[string]interface{})
+	if feb["expected"] != "1300.00" || feb["gap"] != "-50.00" || feb["transaction_count"] != float64(1) {
+		t.Errorf("unexpected february entry: %v", feb)
+	}
+
+	res, _ = run(db, "report", "net-worth", "--start", "2025-01", "--end", "2025-02")
+	assertGlobal(t, res, 0)
+	points := res.JSON["points"].([]interface{})
+	if len(points) != 2 || points[0].(map[string]interface{})["total"] != "1500.00" || points[1].(map[string]interface{})["total"] != "1250.00" {
+		t.Errorf("unexpected net worth: %v", points)
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		res, _ := run(db, "--format", "table", "balances", "reconcile")
+		if res.ExitCode != 0 || !strings.Contains(res.Stdout, "-50.00") {
+			t.Errorf("unexpected table output: %s", res.Stdout)
+		}
+	})
+
+	res, _ = run(db, "balances", "delete", "--id", febID)
+	assertGlobal(t, res, 0)
+	res, _ = run(db, "balances", "delete", "--id", febID)
+	assertGlobal(t, res, 1)
+	res, _ = run(db, "balances", "list", "--account", "Checking")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(1) {
+		t.Errorf("expected one checkpoint left, got %v", res.JSON)
+	}
+}
@@ -0,0 +1,94 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBudgets(t *testing.T) {
+	db := setupDB(t)
+
+	res, _ := run(db, "categories", "create", "--name", "Food")
+	assertGlobal(t, res, 0)
+	foodID := fmt.Sprint(res.JSON["id"])
+	res, _ = run(db, "categories", "create", "--name", "Groceries", "--parent", foodID)
+	assertGlobal(t, res, 0)
+	groceriesID := fmt.Sprint(res.JSON["id"])
+	res, _ = run(db, "categories", "create", "--name", "Salary", "--kind", "income")
+	assertGlobal(t, res, 0)
+	salaryID := fmt.Sprint(res.JSON["id"])
+
+	for _, args := range [][]string{
+		{"--date", "2025-01-05", "--description", "Costco", "--amount", "-400.00"},
+		{"--date", "2025-02-05", "--description", "Costco", "--amount", "-650.00"},
+	} {
+		res, _ := run(db, append([]string{"tx", "add", "--account", "Visa", "--category", "Groceries"}, args...)...)
+		assertGlobal(t, res, 0)
+	}
+
+	res, _ = run(db, "budgets", "create", "--category-id", groceriesID, "--amount", "500", "--rollover", "--start-month", "2025-01")
+	assertGlobal(t, res, 0)
+	b := res.JSON["budget"].(map[string]interface{})
+	if b["category"] != "Food > Groceries" || b["amount"] != "500.00" || b["period"] != "monthly" || b["rollover"] != true {
+		t.Fatalf("unexpected budget: %v", b)
+	}
+	budgetID := fmt.Sprint(b["id"])
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "budgets", "create", "--category-id", groceriesID, "--amount", "100")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "budgets", "create", "--category-id", salaryID, "--amount", "100")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "budgets", "create", "--category-id", foodID, "--amount", "-5")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "budgets", "create", "--category-id", "999", "--amount", "100")
+		assertGlobal(t, res, 1)
+		res, _ = run(db, "budgets", "update", "--id", budgetID)
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "budgets", "report", "--month", "2025-02", "--start", "2025-01")
+		assertGlobal(t, res, 2)
+	})
+
+	res, _ = run(db, "budgets", "report", "--month", "2025-02")
+	assertGlobal(t, res, 0)
+	rows := res.JSON["rows"].([]interface{})
+	if len(rows) != 2 {
+		t.Fatalf("expected a rollup and a budget row, got %v", rows)
+	}
+	rollup := rows[0].(map[string]interface{})
+	groceries := rows[1].(map[string]interface{})
+	if rollup["category"] != "Food" || rollup["budget_id"] != nil || rollup["actual"] != "650.00" {
+		t.Errorf("unexpected rollup row: %v", rollup)
+	}
+	if groceries["carry"] != "100.00" || groceries["available"] != "600.00" || groceries["remaining"] != "-50.00" || groceries["overspent"] != true {
+		t.Errorf("unexpected groceries row: %v", groceries)
+	}
+	totals := res.JSON["totals"].([]interface{})
+	if len(totals) != 1 || totals[0].(map[string]interface{})["overspent"] != true {
+		t.Errorf("unexpected totals: %v", totals)
+	}
+
+	res, _ = run(db, "budgets", "update", "--id", budgetID, "--amount", "700", "--no-rollover")
+	assertGlobal(t, res, 0)
+	if b := res.JSON["budget"].(map[string]interface{}); b["amount"] != "700.00" || b["rollover"] != false {
+		t.Errorf("unexpected updated budget: %v", b)
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		res, _ := run(db, "--format", "table", "budgets", "report", "--start", "2025-01", "--end", "2025-02")
+		if res.ExitCode != 0 || !strings.Contains(res.Stdout, "Groceries") || !strings.Contains(res.Stdout, "rollup") {
+			t.Errorf("unexpected table output: %s", res.Stdout)
+		}
+	})
+
+	res, _ = run(db, "budgets", "delete", "--id", budgetID)
+	assertGlobal(t, res, 0)
+	res, _ = run(db, "budgets", "delete", "--id", budgetID)
+	assertGlobal(t, res, 1)
+	res, _ = run(db, "budgets", "list")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(0) {
+		t.Errorf("expected no budgets, got %v", res.JSON)
+	}
+}
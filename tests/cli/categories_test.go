@@ -0,0 +1,247 @@
+package cli_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCategoriesHierarchy(t *testing.T) {
+	db := setupDB(t)
+
+	res, _ := run(db, "categories", "create", "--name", "Food")
+	assertGlobal(t, res, 0)
+	foodID := fmt.Sprint(res.JSON["id"])
+
+	res, _ = run(db, "categories", "create", "--name", "Groceries", "--parent", foodID)
+	assertGlobal(t, res, 0)
+	groceriesID := fmt.Sprint(res.JSON["id"])
+
+	res, _ = run(db, "categories", "create", "--name", "Orphan", "--parent", "999")
+	assertGlobal(t, res, 1)
+
+	t.Run("Create reuses a name only under the same parent", func(t *testing.T) {
+		res, _ := run(db, "categories", "create", "--name", "Groceries", "--parent", foodID)
+		assertGlobal(t, res, 0)
+		if got := fmt.Sprint(res.JSON["id"]); got != groceriesID {
+			t.Errorf("expected existing Groceries %s, got %s", groceriesID, got)
+		}
+
+		res, _ = run(db, "categories", "create", "--name", "Groceries")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "categories", "create", "--name", "Food", "--parent", groceriesID)
+		assertGlobal(t, res, 2)
+	})
+
+	t.Run("List shows full paths", func(t *testing.T) {
+		res, _ := run(db, "categories", "list")
+		assertGlobal(t, res, 0)
+		paths := map[string]string{}
+		for _, item := range res.JSON["items"].([]interface{}) {
+			c := item.(map[string]interface{})
+			paths[c["name"].(string)] = c["full_name"].(string)
+		}
+		if paths["Groceries"] != "Food > Groceries" {
+			t.Errorf("expected Groceries under Food, got %v", paths)
+		}
+		if _, ok := paths["Orphan"]; ok {
+			t.Error("expected no category to be created for a missing parent")
+		}
+	})
+
+	t.Run("Move rejects cycles", func(t *testing.T) {
+		res, _ := run(db, "categories", "move", "--id", foodID, "--parent", groceriesID)
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "categories", "move", "--id", foodID)
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "categories", "move", "--id", "999", "--root")
+		assertGlobal(t, res, 1)
+	})
+
+	t.Run("Filter and export roll up to parent", func(t *testing.T) {
+		mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"},"account":"Account","owner":"Owner"},"account":"BMO","currencyDefault":"CAD"}`
+		mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+		os.WriteFile(mappingPath, []byte(mappingJSON), 0644)
+
+		csvData := `Date,Description,Amount,Account,Owner
+2025-01-10,Costco,-80.00,BMO,Alex
+2025-01-12,Landlord,-1500.00,BMO,Alex
+`
+		csvPath := filepath.Join(t.TempDir(), "data.csv")
+		os.WriteFile(csvPath, []byte(csvData), 0644)
+		res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+		assertGlobal(t, res, 0)
+		run(db, "tx", "categorize", "--id", "1", "--category", "Groceries")
+		run(db, "tx", "categorize", "--id", "2", "--category", "Rent")
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--category-ids", foodID)
+		assertGlobal(t, res, 0)
+		txs := res.JSON["transactions"].([]interface{})
+		if len(txs) != 1 || txs[0].(map[string]interface{})["category"] != "Food > Groceries" {
+			t.Fatalf("expected only the Groceries transaction under Food, got %v", txs)
+		}
+
+		outPath := filepath.Join(t.TempDir(), "out.csv")
+		res, _ = run(db, "export", "--start", "2025-01-01", "--end", "2025-01-31", "--format", "csv", "--out", outPath)
+		assertGlobal(t, res, 0)
+		data, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(data)
+		if !strings.Contains(content, "Category,Top Category,Account") {
+			t.Errorf("expected Top Category column in export header, got:\n%s", content)
+		}
+		if !strings.Contains(content, "Food > Groceries,Food,BMO") {
+			t.Errorf("expected full path and top category in export, got:\n%s", content)
+		}
+	})
+
+	t.Run("Move to root", func(t *testing.T) {
+		res, _ := run(db, "categories", "move", "--id", groceriesID, "--root")
+		assertGlobal(t, res, 0)
+		if res.JSON["full_name"] != "Groceries" || res.JSON["parent_id"] != nil {
+			t.Errorf("expected Groceries to be top-level, got %v", res.JSON)
+		}
+	})
+}
+
+func TestCategoriesSetKind(t *testing.T) {
+	db := setupDB(t)
+
+	res, _ := run(db, "categories", "create", "--name", "Salary", "--kind", "income")
+	assertGlobal(t, res, 0)
+	if res.JSON["kind"] != "income" {
+		t.Errorf("expected income kind on create, got %v", res.JSON["kind"])
+	}
+
+	res, _ = run(db, "categories", "create", "--name", "Card Payment")
+	assertGlobal(t, res, 0)
+	paymentID := fmt.Sprint(res.JSON["id"])
+
+	res, _ = run(db, "categories", "set-kind", "--id", paymentID, "--kind", "Transfer")
+	assertGlobal(t, res, 0)
+	if res.JSON["name"] != "Card Payment" || res.JSON["kind"] != "transfer" {
+		t.Errorf("unexpected set-kind response: %v", res.JSON)
+	}
+
+	res, _ = run(db, "categories", "set-kind", "--id", paymentID, "--kind", "savings")
+	assertGlobal(t, res, 2)
+
+	res, _ = run(db, "categories", "set-kind", "--id", "999", "--kind", "income")
+	assertGlobal(t, res, 1)
+
+	res, _ = run(db, "categories", "list")
+	assertGlobal(t, res, 0)
+	kinds := map[string]string{}
+	for _, item := range res.JSON["items"].([]interface{}) {
+		c := item.(map[string]interface{})
+		kinds[c["name"].(string)] = c["kind"].(string)
+	}
+	if kinds["Salary"] != "income" || kinds["Card Payment"] != "transfer" {
+		t.Errorf("unexpected kinds in list: %v", kinds)
+	}
+}
+
+func TestCategoriesMerge(t *testing.T) {
+	db := setupDB(t)
+
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"},"account":"Account","owner":"Owner"},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	os.WriteFile(mappingPath, []byte(mappingJSON), 0644)
+	csvData := `Date,Description,Amount,Account,Owner
+2025-01-10,Costco,-80.00,BMO,Alex
+2025-01-12,Safeway,-30.00,BMO,Alex
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	os.WriteFile(csvPath, []byte(csvData), 0644)
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+	run(db, "tx", "categorize", "--id", "1", "--category", "Groceries")
+	run(db, "tx", "categorize", "--id", "2", "--category", "Grocery")
+
+	ids := map[string]string{}
+	res, _ = run(db, "categories", "list")
+	for _, item := range res.JSON["items"].([]interface{}) {
+		c := item.(map[string]interface{})
+		ids[c["name"].(string)] = fmt.Sprint(c["id"])
+	}
+
+	res, _ = run(db, "categories", "merge", "--source-ids", ids["Groceries"], "--target-id", ids["Groceries"])
+	assertGlobal(t, res, 2)
+
+	res, _ = run(db, "categories", "merge", "--source-ids", "999", "--target-id", ids["Groceries"])
+	assertGlobal(t, res, 1)
+
+	res, _ = run(db, "categories", "merge", "--source-ids", ids["Grocery"], "--target-id", ids["Groceries"])
+	assertGlobal(t, res, 0)
+	if res.JSON["moved_transactions"].(float64) != 1 || res.JSON["target_name"] != "Groceries" {
+		t.Errorf("unexpected merge response: %v", res.JSON)
+	}
+
+	res, _ = run(db, "categories", "list")
+	if items := res.JSON["items"].([]interface{}); len(items) != 1 {
+		t.Errorf("expected only Groceries to remain, got %v", items)
+	}
+}
+
+func TestCategoriesDelete(t *testing.T) {
+	db := setupDB(t)
+
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"},"account":"Account","owner":"Owner"},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	os.WriteFile(mappingPath, []byte(mappingJSON), 0644)
+	csvData := `Date,Description,Amount,Account,Owner
+2025-01-10,Netflix,-15.99,BMO,Alex
+2025-01-12,Spotify,-9.99,BMO,Alex
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	os.WriteFile(csvPath, []byte(csvData), 0644)
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+	run(db, "tx", "categorize", "--id", "1", "--category", "Streaming")
+	run(db, "tx", "categorize", "--id", "2", "--category", "Music")
+	run(db, "categories", "create", "--name", "Subscriptions")
+
+	ids := map[string]string{}
+	res, _ = run(db, "categories", "list")
+	for _, item := range res.JSON["items"].([]interface{}) {
+		c := item.(map[string]interface{})
+		ids[c["name"].(string)] = fmt.Sprint(c["id"])
+	}
+
+	res, _ = run(db, "categories", "delete")
+	assertGlobal(t, res, 2)
+
+	res, _ = run(db, "categories", "delete", "--id", ids["Streaming"], "--reassign-to", ids["Streaming"])
+	assertGlobal(t, res, 2)
+
+	res, _ = run(db, "categories", "delete", "--id", "999")
+	assertGlobal(t, res, 1)
+
+	res, _ = run(db, "categories", "delete", "--id", ids["Streaming"], "--reassign-to", ids["Subscriptions"])
+	assertGlobal(t, res, 0)
+	if res.JSON["reassigned_count"].(float64) != 1 || res.JSON["reassigned_to_name"] != "Subscriptions" {
+		t.Errorf("unexpected reassign response: %v", res.JSON)
+	}
+
+	res, _ = run(db, "categories", "delete", "--id", ids["Music"])
+	assertGlobal(t, res, 0)
+	if res.JSON["uncategorized_count"].(float64) != 1 || res.JSON["reassigned_to_id"] != nil {
+		t.Errorf("unexpected delete response: %v", res.JSON)
+	}
+
+	res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+	categories := map[string]string{}
+	for _, item := range res.JSON["transactions"].([]interface{}) {
+		tx := item.(map[string]interface{})
+		categories[tx["description"].(string)] = tx["category"].(string)
+	}
+	if categories["Netflix"] != "Subscriptions" || categories["Spotify"] != "Uncategorized" {
+		t.Errorf("unexpected categories after delete: %v", categories)
+	}
+}
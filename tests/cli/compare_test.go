@@ -0,0 +1,82 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportCompare(t *testing.T) {
+	db := setupDB(t)
+
+	add := func(date, description, amount, category string) {
+		t.Helper()
+		res, _ := run(db, "tx", "add", "--date", date, "--description", description, "--amount", amount, "--account", "Visa", "--category", category)
+		assertGlobal(t, res, 0)
+	}
+	add("2024-02-10", "Grocer", "-300.00", "Groceries")
+	add("2025-01-10", "Grocer", "-400.00", "Groceries")
+	add("2025-01-15", "Gym Club", "-50.00", "Fitness")
+	add("2025-02-10", "Grocer", "-500.00", "Groceries")
+	add("2025-02-12", "Streaming Plus", "-15.00", "Subscriptions")
+
+	res, _ := run(db, "report", "compare", "--start", "2025-02-01", "--end", "2025-02-28")
+	assertGlobal(t, res, 0)
+	previous := res.JSON["previous"].(map[string]interface{})
+	if previous["start"] != "2025-01-01" || previous["end"] != "2025-01-31" {
+		t.Fatalf("expected the previous month, got %v", previous)
+	}
+	rows := res.JSON["rows"].([]interface{})
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %v", rows)
+	}
+	first := rows[0].(map[string]interface{})
+	if first["group"] != "Groceries" || first["delta"] != "-100.00" || first["delta_percent"] != float64(-25) {
+		t.Errorf("unexpected first row: %v", first)
+	}
+	newMerchants := res.JSON["new_merchants"].([]interface{})
+	if len(newMerchants) != 1 || newMerchants[0].(map[string]interface{})["amount"] != "15.00" {
+		t.Errorf("unexpected new merchants: %v", newMerchants)
+	}
+	gone := res.JSON["disappeared_merchants"].([]interface{})
+	if len(gone) != 1 || gone[0].(map[string]interface{})["description"] != "Gym Club" {
+		t.Errorf("unexpected disappeared merchants: %v", gone)
+	}
+
+	res, _ = run(db, "report", "compare", "--start", "2025-02-01", "--end", "2025-02-28", "--vs", "year")
+	assertGlobal(t, res, 0)
+	previous = res.JSON["previous"].(map[string]interface{})
+	if previous["start"] != "2024-02-01" || previous["end"] != "2024-02-29" {
+		t.Errorf("expected the same month last year, got %v", previous)
+	}
+
+	res, _ = run(db, "--format", "table", "report", "compare", "--start", "2025-02-01", "--end", "2025-02-28")
+	if res.ExitCode != 0 || !strings.Contains(res.Stdout, "Groceries") {
+		t.Errorf("unexpected table output: %s", res.Stdout)
+	}
+
+	for _, args := range [][]string{
+		{"report", "compare", "--start", "2025-02-01"},
+		{"report", "compare", "--vs", "week"},
+		{"report", "compare", "--previous-start", "2025-01-01"},
+		{"report", "compare", "--vs", "year", "--previous-start", "2025-01-01", "--previous-end", "2025-01-31"},
+		{"report", "compare", "--start", "2025-02-28", "--end", "2025-02-01"},
+	} {
+		res, _ = run(db, args...)
+		assertGlobal(t, res, 2)
+	}
+
+	dir := t.TempDir()
+	res, _ = run(db, "export", "--start", "2025-02-01", "--end", "2025-02-28", "--format", "csv", "--out", filepath.Join(dir, "out.csv"),
+		"--previous-start", "2025-01-01", "--previous-end", "2025-01-31")
+	assertGlobal(t, res, 2)
+
+	outPath := filepath.Join(dir, "out.xlsx")
+	res, _ = run(db, "export", "--start", "2025-02-01", "--end", "2025-02-28", "--format", "xlsx", "--out", outPath,
+		"--previous-start", "2025-01-01", "--previous-end", "2025-01-31")
+	assertGlobal(t, res, 0)
+	if _, err := os.Stat(outPath); err != nil {
+		t.Errorf("expected xlsx export: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestForecast(t *testing.T) {
+	db := setupDB(t)
+
+	thisMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 4; i >= 1; i-- {
+		date := thisMonth.AddDate(0, -i, 4).Format("2006-01-02")
+		res, _ := run(db, "tx", "add", "--date", date, "--description", fmt.Sprintf("NETFLIX.COM %d", i), "--amount", "-15.99", "--account", "Visa", "--category", "Subscriptions")
+		assertGlobal(t, res, 0)
+	}
+
+	res, _ := run(db, "forecast", "--months", "2")
+	assertGlobal(t, res, 0)
+	months := res.JSON["months"].([]interface{})
+	if len(months) != 2 {
+		t.Fatalf("expected 2 months, got %v", months)
+	}
+	first := months[0].(map[string]interface{})
+	if first["month"] != thisMonth.AddDate(0, 1, 0).Format("2006-01") || first["expenses"] != "-15.99" || first["balance"] != "-79.95" {
+		t.Errorf("unexpected first month: %v", first)
+	}
+	// Without a checkpoint the balance starts from the sum of transactions.
+	if res.JSON["opening_balance"] != "-63.96" {
+		t.Errorf("expected opening balance -63.96, got %v", res.JSON["opening_balance"])
+	}
+	second := months[1].(map[string]interface{})
+	if second["balance"] != "-95.94" {
+		t.Errorf("expected balance to accumulate, got %v", second)
+	}
+	balances := res.JSON["balances"].([]interface{})
+	if len(balances) != 1 || balances[0].(map[string]interface{})["name"] != "Visa" {
+		t.Errorf("expected a Visa balance, got %v", balances)
+	}
+	recurring := res.JSON["recurring"].([]interface{})
+	if len(recurring) != 1 || recurring[0].(map[string]interface{})["cadence"] != "monthly" {
+		t.Errorf("expected one monthly series, got %v", recurring)
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		res, _ := run(db, "--format", "table", "forecast")
+		if res.ExitCode != 0 {
+			t.Fatalf("expected exit 0, got %d: %s", res.ExitCode, res.Stdout)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "forecast", "--months", "0")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "forecast", "--months", "25")
+		assertGlobal(t, res, 2)
+	})
+}
@@ -0,0 +1,123 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMerchants(t *testing.T) {
+	db := setupDB(t)
+
+	add := func(date, description, amount string) {
+		t.Helper()
+		res, _ := run(db, "tx", "add", "--date", date, "--description", description, "--amount", amount, "--account", "Visa", "--category", "Shopping")
+		assertGlobal(t, res, 0)
+	}
+	add("2025-01-02", "AMZN MKTP CA*1A2B3", "-25.00")
+	add("2025-01-05", "AMAZON.CA", "-40.00")
+	add("2025-01-07", "TIM HORTONS #1234", "-2.50")
+	add("2025-01-08", "TIM HORTONS #0871", "-3.10")
+
+	res, _ := run(db, "merchants", "create", "--name", "Amazon", "--alias", "starts_with:AMZN", "--alias", "exact:AMAZON.CA")
+	assertGlobal(t, res, 0)
+	merchant := res.JSON["merchant"].(map[string]interface{})
+	if merchant["transaction_count"] != float64(2) || len(merchant["aliases"].([]interface{})) != 2 {
+		t.Fatalf("unexpected merchant: %v", merchant)
+	}
+	amazonID := fmt.Sprint(merchant["id"])
+
+	res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--merchant-ids", amazonID)
+	assertGlobal(t, res, 0)
+	txs := res.JSON["transactions"].([]interface{})
+	if len(txs) != 2 || txs[0].(map[string]interface{})["merchant"] != "Amazon" {
+		t.Errorf("expected the Amazon transactions, got %v", txs)
+	}
+	res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--no-merchant")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(2) {
+		t.Errorf("expected 2 transactions without a merchant, got %v", res.JSON["count"])
+	}
+
+	res, _ = run(db, "merchants", "suggest")
+	assertGlobal(t, res, 0)
+	suggestions := res.JSON["suggestions"].([]interface{})
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %v", suggestions)
+	}
+	sg := suggestions[0].(map[string]interface{})
+	alias := sg["aliases"].([]interface{})[0].(map[string]interface{})
+	if sg["name"] != "Tim Hortons" || alias["match_type"] != "starts_with" || alias["pattern"] != "TIM HORTONS" {
+		t.Errorf("unexpected suggestion: %v", sg)
+	}
+
+	res, _ = run(db, "merchants", "create", "--name", "Tim Hortons")
+	assertGlobal(t, res, 0)
+	timID := fmt.Sprint(res.JSON["merchant"].(map[string]interface{})["id"])
+	res, _ = run(db, "merchants", "add-alias", "--id", timID, "--match-type", "starts_with", "--pattern", "TIM HORTONS")
+	assertGlobal(t, res, 0)
+	merchant = res.JSON["merchant"].(map[string]interface{})
+	if merchant["transaction_count"] != float64(2) {
+		t.Errorf("expected the alias to assign 2 transactions, got %v", merchant)
+	}
+	aliasID := fmt.Sprint(merchant["aliases"].([]interface{})[0].(map[string]interface{})["id"])
+
+	res, _ = run(db, "report", "merchants", "--start", "2025-01-01", "--end", "2025-01-31")
+	assertGlobal(t, res, 0)
+	merchants := res.JSON["merchants"].([]interface{})
+	first := merchants[0].(map[string]interface{})
+	if len(merchants) != 2 || first["merchant"] != "Amazon" || first["spent"] != "65.00" || first["average_ticket"] != "32.50" {
+		t.Errorf("unexpected report: %v", merchants)
+	}
+
+	res, _ = run(db, "report", "summary", "--start", "2025-01-01", "--end", "2025-01-31", "--by", "merchant")
+	assertGlobal(t, res, 0)
+	if rows := res.JSON["rows"].([]interface{}); len(rows) != 2 {
+		t.Errorf("expected a row per merchant, got %v", rows)
+	}
+
+	res, _ = run(db, "--format", "table", "merchants", "list")
+	if res.ExitCode != 0 || !strings.Contains(res.Stdout, "starts_with:AMZN") {
+		t.Errorf("unexpected table output: %s", res.Stdout)
+	}
+	res, _ = run(db, "--format", "table", "report", "merchants", "--start", "2025-01-01", "--end", "2025-01-31", "--sort", "visits")
+	if res.ExitCode != 0 || !strings.Contains(res.Stdout, "Tim Hortons") {
+		t.Errorf("unexpected table output: %s", res.Stdout)
+	}
+
+	res, _ = run(db, "merchants", "remove-alias", "--alias-id", aliasID)
+	assertGlobal(t, res, 0)
+	if res.JSON["merchant"].(map[string]interface{})["transaction_count"] != float64(0) {
+		t.Errorf("expected no transactions after removing the alias, got %v", res.JSON)
+	}
+	res, _ = run(db, "merchants", "rename", "--id", timID, "--name", "Tims")
+	assertGlobal(t, res, 0)
+	res, _ = run(db, "merchants", "delete", "--id", amazonID)
+	assertGlobal(t, res, 0)
+	res, _ = run(db, "merchants", "list")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(1) {
+		t.Errorf("expected 1 merchant, got %v", res.JSON["merchants"])
+	}
+
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"merchants"}, 2},
+		{[]string{"merchants", "bogus"}, 2},
+		{[]string{"merchants", "create"}, 2},
+		{[]string{"merchants", "create", "--name", "tims"}, 2},
+		{[]string{"merchants", "create", "--name", "Costco", "--alias", "costco"}, 2},
+		{[]string{"merchants", "create", "--name", "Costco", "--alias", "regex:costco"}, 2},
+		{[]string{"merchants", "add-alias", "--id", timID}, 2},
+		{[]string{"merchants", "delete", "--id", amazonID}, 1},
+		{[]string{"merchants", "remove-alias", "--alias-id", aliasID}, 1},
+		{[]string{"merchants", "suggest", "--limit", "0"}, 2},
+		{[]string{"report", "merchants", "--sort", "name"}, 2},
+		{[]string{"tx", "list", "--merchant-ids", "abc"}, 2},
+	} {
+		res, _ = run(db, tc.args...)
+		assertGlobal(t, res, tc.code)
+	}
+}
@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurring(t *testing.T) {
+	db := setupDB(t)
+
+	thisMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i, amount := range []string{"-15.99", "-15.99", "-17.99", "-17.99"} {
+		date := thisMonth.AddDate(0, i-4, 4).Format("2006-01-02")
+		res, _ := run(db, "tx", "add", "--date", date, "--description", "NETFLIX.COM", "--amount", amount, "--account", "Visa", "--category", "Subscriptions")
+		assertGlobal(t, res, 0)
+	}
+
+	res, _ := run(db, "recurring", "list")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(1) {
+		t.Fatalf("expected one series, got %v", res.JSON)
+	}
+	series := res.JSON["series"].([]interface{})[0].(map[string]interface{})
+	if series["cadence"] != "monthly" || series["amount"] != "-17.99" || series["average_amount"] != "-16.99" || series["known"] != false {
+		t.Errorf("unexpected series: %v", series)
+	}
+	changes := series["price_changes"].([]interface{})
+	if len(changes) != 1 || changes[0].(map[string]interface{})["to"] != "-17.99" {
+		t.Errorf("expected a price change to -17.99, got %v", changes)
+	}
+	key := series["key"].(string)
+
+	res, _ = run(db, "recurring", "mark", "--key", key)
+	assertGlobal(t, res, 0)
+	if res.JSON["known"] != true {
+		t.Errorf("unexpected mark response: %v", res.JSON)
+	}
+
+	res, _ = run(db, "recurring", "list")
+	assertGlobal(t, res, 0)
+	if series := res.JSON["series"].([]interface{})[0].(map[string]interface{}); series["known"] != true {
+		t.Errorf("expected series to be known, got %v", series)
+	}
+	res, _ = run(db, "recurring", "list", "--unknown")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(0) {
+		t.Errorf("expected known series hidden, got %v", res.JSON)
+	}
+
+	res, _ = run(db, "recurring", "unmark", "--key", key)
+	assertGlobal(t, res, 0)
+	res, _ = run(db, "recurring", "list", "--unknown")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(1) {
+		t.Errorf("expected unmarked series listed, got %v", res.JSON)
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		res, _ := run(db, "--format", "table", "recurring", "list")
+		if res.ExitCode != 0 {
+			t.Fatalf("expected exit 0, got %d: %s", res.ExitCode, res.Stdout)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "recurring", "mark")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "recurring", "mark", "--key", "1|-|nothing|monthly")
+		assertGlobal(t, res, 1)
+
+		res, _ = run(db, "recurring", "bogus")
+		assertGlobal(t, res, 2)
+	})
+}
@@ -0,0 +1,84 @@
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReportSummary(t *testing.T) {
+	db := setupDB(t)
+
+	res, _ := run(db, "categories", "create", "--name", "Salary", "--kind", "income")
+	assertGlobal(t, res, 0)
+	for _, args := range [][]string{
+		{"--date", "2024-12-15", "--description", "Payroll", "--amount", "3000", "--account", "Checking", "--category", "Salary", "--owner", "Alex"},
+		{"--date", "2025-01-10", "--description", "Costco", "--amount", "-80", "--account", "Card", "--category", "Groceries", "--owner", "Alex"},
+		{"--date", "2025-01-12", "--description", "Safeway", "--amount", "-30", "--account", "Card", "--category", "Groceries", "--owner", "Sam"},
+		{"--date", "2025-02-01", "--description", "Bakery", "--amount", "-5", "--account", "Card"},
+	} {
+		res, _ := run(db, append([]string{"tx", "add"}, args...)...)
+		assertGlobal(t, res, 0)
+	}
+
+	res, _ = run(db, "report", "summary", "--start", "2024-12-01", "--end", "2025-02-28")
+	assertGlobal(t, res, 0)
+	if res.JSON["period"] != "month" || res.JSON["by"] != "category" || res.JSON["main_currency"] != "CAD" {
+		t.Fatalf("unexpected summary header: %v", res.JSON)
+	}
+	rows := map[string]map[string]interface{}{}
+	for _, item := range res.JSON["rows"].([]interface{}) {
+		row := item.(map[string]interface{})
+		rows[fmt.Sprintf("%s/%s", row["period"], row["group"])] = row
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %v", rows)
+	}
+	if r := rows["2025-01/Groceries"]; r["expenses"] != "-110.00" || r["count"] != float64(2) {
+		t.Errorf("unexpected groceries row: %v", r)
+	}
+	if r := rows["2024-12/Salary"]; r["income"] != "3000.00" || r["net"] != "3000.00" {
+		t.Errorf("unexpected salary row: %v", r)
+	}
+	if r := rows["2025-02/Uncategorized"]; r["group_id"] != nil || r["expenses"] != "-5.00" {
+		t.Errorf("unexpected uncategorized row: %v", r)
+	}
+	totals := res.JSON["totals"].(map[string]interface{})
+	if totals["income"] != "3000.00" || totals["expenses"] != "-115.00" || totals["net"] != "2885.00" {
+		t.Errorf("unexpected totals: %v", totals)
+	}
+
+	t.Run("ByOwnerPerYear", func(t *testing.T) {
+		res, _ := run(db, "report", "summary", "--start", "2024-12-01", "--end", "2025-02-28", "--period", "year", "--by", "owner")
+		assertGlobal(t, res, 0)
+		groups := map[string]string{}
+		for _, item := range res.JSON["rows"].([]interface{}) {
+			row := item.(map[string]interface{})
+			groups[fmt.Sprintf("%s/%s", row["period"], row["group"])] = row["net"].(string)
+		}
+		want := map[string]string{"2024/Alex": "3000.00", "2025/Alex": "-80.00", "2025/Sam": "-30.00", "2025/No owner": "-5.00"}
+		if fmt.Sprint(groups) != fmt.Sprint(want) {
+			t.Errorf("expected %v, got %v", want, groups)
+		}
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		res, _ := run(db, "--format", "table", "report", "summary", "--start", "2025-01-01", "--end", "2025-01-31")
+		if res.ExitCode != 0 {
+			t.Fatalf("expected exit 0, got %d: %s", res.ExitCode, res.Stdout)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "report", "summary", "--start", "2025-01-01")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "report", "summary", "--period", "week")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "report", "summary", "--by", "payee")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "report", "bogus")
+		assertGlobal(t, res, 2)
+	})
+}
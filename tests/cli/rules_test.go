@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -95,3 +96,368 @@ func TestRulesDetailed(t *testing.T) {
 		}
 	})
 }
+
+func TestRulesSuggest(t *testing.T) {
+	db := setupDB(t)
+
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"}},"account":"Visa","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(mappingPath, []byte(mappingJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	csvData := `Date,Description,Amount
+2025-01-02,COSTCO WHOLESALE #123,-84.10
+2025-01-09,COSTCO WHOLESALE #123,-51.20
+2025-01-16,COSTCO WHOLESALE #456,-99.00
+2025-01-20,COSTCO WHOLESALE #789,-20.00
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+
+	listRes, _ := run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--order", "asc")
+	assertGlobal(t, listRes, 0)
+	for _, item := range listRes.JSON["transactions"].([]interface{})[:3] {
+		id := item.(map[string]interface{})["id"]
+		res, _ := run(db, "tx", "categorize", "--id", fmt.Sprintf("%v", id), "--category", "Groceries")
+		assertGlobal(t, res, 0)
+	}
+
+	res, _ = run(db, "rules", "suggest")
+	assertGlobal(t, res, 0)
+	items := res.JSON["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d: %v", len(items), items)
+	}
+	item := items[0].(map[string]interface{})
+	if item["match_type"] != "starts_with" || item["match_value"] != "COSTCO" || item["category_name"] != "Groceries" {
+		t.Errorf("unexpected suggestion: %v", item)
+	}
+	if item["support"].(float64) != 3 || item["precision"].(float64) != 1 {
+		t.Errorf("unexpected stats: %v", item)
+	}
+	preview := item["preview"].(map[string]interface{})
+	if preview["count"].(float64) != 1 {
+		t.Errorf("expected 1 uncategorized preview match, got %v", preview["count"])
+	}
+
+	res, _ = run(db, "rules", "suggest", "--min-precision", "1.5")
+	assertGlobal(t, res, 2)
+}
+
+func TestRulesExportImport(t *testing.T) {
+	src := setupDB(t)
+	res, _ := run(src, "categories", "create", "--name", "Food")
+	assertGlobal(t, res, 0)
+	res, _ = run(src, "categories", "create", "--name", "Groceries", "--parent", fmt.Sprint(res.JSON["id"]))
+	assertGlobal(t, res, 0)
+	res, _ = run(src, "categories", "create", "--name", "Transport", "--kind", "transfer")
+	assertGlobal(t, res, 0)
+	run(src, "rules", "create", "--match-value", "Uber", "--match-type", "contains", "--category", "Transport")
+	run(src, "rules", "create", "--match-value", "Costco", "--match-type", "starts_with", "--amount-max", "-50.00", "--category", "Groceries")
+
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "rules.yaml")
+	res, _ = run(src, "rules", "export", "--file", yamlPath)
+	assertGlobal(t, res, 0)
+	if res.JSON["count"].(float64) != 2 {
+		t.Fatalf("expected 2 exported rules, got %v", res.JSON["count"])
+	}
+	data, err := os.ReadFile(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "category: Food > Groceries") || !strings.Contains(string(data), "category_kind: transfer") || !strings.Contains(string(data), `amount_max: "-50.00"`) {
+		t.Errorf("unexpected export contents:\n%s", data)
+	}
+
+	jsonPath := filepath.Join(dir, "rules.json")
+	res, _ = run(src, "rules", "export", "--file", jsonPath)
+	assertGlobal(t, res, 0)
+	data, _ = os.ReadFile(jsonPath)
+	if !strings.Contains(string(data), `"match_value": "Uber"`) {
+		t.Errorf("expected JSON export, got:\n%s", data)
+	}
+
+	t.Run("Dry run makes no changes", func(t *testing.T) {
+		dst := setupDB(t)
+		res, _ := run(dst, "rules", "import", "--file", yamlPath, "--dry-run")
+		assertGlobal(t, res, 0)
+		if res.JSON["created_count"].(float64) != 2 {
+			t.Errorf("expected 2 rules to create, got %v", res.JSON["created_count"])
+		}
+		if len(res.JSON["created_categories"].([]interface{})) != 3 {
+			t.Errorf("expected 3 categories to create, got %v", res.JSON["created_categories"])
+		}
+		list, _ := run(dst, "rules", "list")
+		if len(list.JSON["items"].([]interface{})) != 0 {
+			t.Error("dry run should not create rules")
+		}
+	})
+
+	t.Run("Import recreates category paths and kinds", func(t *testing.T) {
+		dst := setupDB(t)
+		res, _ := run(dst, "rules", "import", "--file", yamlPath)
+		assertGlobal(t, res, 0)
+
+		res, _ = run(dst, "categories", "list")
+		assertGlobal(t, res, 0)
+		categories := map[string]map[string]interface{}{}
+		for _, item := range res.JSON["items"].([]interface{}) {
+			c := item.(map[string]interface{})
+			categories[c["name"].(string)] = c
+		}
+		if categories["Groceries"]["full_name"] != "Food > Groceries" || categories["Groceries"]["kind"] != "expense" {
+			t.Errorf("expected Groceries under Food, got %v", categories["Groceries"])
+		}
+		if categories["Transport"]["kind"] != "transfer" {
+			t.Errorf("expected Transport to keep its transfer kind, got %v", categories["Transport"])
+		}
+	})
+
+	t.Run("Merge reports duplicates and conflicts", func(t *testing.T) {
+		dst := setupDB(t)
+		run(dst, "rules", "create", "--match-value", "Uber", "--match-type", "contains", "--category", "Transport")
+		run(dst, "rules", "create", "--match-value", "Costco", "--match-type", "starts_with", "--amount-max", "-50.00", "--category", "Shopping")
+		run(dst, "rules", "create", "--match-value", "Netflix", "--match-type", "contains", "--category", "Subscriptions")
+
+		res, _ := run(dst, "rules", "import", "--file", jsonPath)
+		assertGlobal(t, res, 0)
+		if res.JSON["created_count"].(float64) != 0 {
+			t.Errorf("expected 0 created, got %v", res.JSON["created_count"])
+		}
+		duplicates := res.JSON["duplicates"].([]interface{})
+		conflicts := res.JSON["conflicts"].([]interface{})
+		if len(duplicates) != 1 || len(conflicts) != 1 {
+			t.Fatalf("expected 1 duplicate and 1 conflict, got %v / %v", duplicates, conflicts)
+		}
+		if conflicts[0].(map[string]interface{})["existing_category"] != "Shopping" {
+			t.Errorf("expected conflict with Shopping, got %v", conflicts[0])
+		}
+	})
+
+	t.Run("Replace removes existing rules", func(t *testing.T) {
+		dst := setupDB(t)
+		run(dst, "rules", "create", "--match-value", "Netflix", "--match-type", "contains", "--category", "Subscriptions")
+
+		res, _ := run(dst, "rules", "import", "--file", yamlPath, "--mode", "replace")
+		assertGlobal(t, res, 0)
+		if res.JSON["deleted_count"].(float64) != 1 || res.JSON["created_count"].(float64) != 2 {
+			t.Errorf("unexpected replace result: %v", res.JSON)
+		}
+		list, _ := run(dst, "rules", "list")
+		items := list.JSON["items"].([]interface{})
+		if len(items) != 2 {
+			t.Fatalf("expected 2 rules after replace, got %d", len(items))
+		}
+		for _, item := range items {
+			if item.(map[string]interface{})["match_value"] == "Netflix" {
+				t.Error("expected Netflix rule to be replaced")
+			}
+		}
+	})
+
+	t.Run("Invalid ruleset", func(t *testing.T) {
+		dst := setupDB(t)
+		bad := filepath.Join(dir, "bad.yaml")
+		os.WriteFile(bad, []byte("version: 1\nrules:\n  - match_type: fuzzy\n    match_value: x\n    category: y\n"), 0644)
+		res, _ := run(dst, "rules", "import", "--file", bad)
+		assertGlobal(t, res, 2)
+
+		res, _ = run(dst, "rules", "import", "--file", yamlPath, "--mode", "upsert")
+		assertGlobal(t, res, 2)
+	})
+}
+
+func TestRulesTest(t *testing.T) {
+	db := setupDB(t)
+	run(db, "rules", "create", "--match-value", "Uber", "--match-type", "contains", "--category", "Transport")
+	run(db, "rules", "create", "--match-value", "Uber Eats", "--match-type", "starts_with", "--category", "Dining")
+	run(db, "rules", "create", "--match-value", "Costco", "--match-type", "contains", "--amount-max", "-100.00", "--category", "Groceries")
+
+	dir := t.TempDir()
+
+	t.Run("Passing cases", func(t *testing.T) {
+		cases := filepath.Join(dir, "pass.yaml")
+		os.WriteFile(cases, []byte(`cases:
+  - description: "UBER EATS TORONTO"
+    expected: Dining
+  - description: "Pending UBER trip"
+    expected: Transport
+  - description: "COSTCO WHOLESALE"
+    amount: -150.00
+    expected: Groceries
+  - description: "COSTCO GAS"
+    amount: "-40.00"
+    expected: ""
+`), 0644)
+		res, _ := run(db, "rules", "test", "--file", cases)
+		assertGlobal(t, res, 0)
+		if res.JSON["passed"].(float64) != 4 || res.JSON["failed"].(float64) != 0 {
+			t.Fatalf("expected 4 passing cases, got %v", res.JSON)
+		}
+		first := res.JSON["results"].([]interface{})[0].(map[string]interface{})
+		if first["match_type"] != "starts_with" || first["match_value"] != "Uber Eats" {
+			t.Errorf("expected the starts_with rule to fire first, got %v", first)
+		}
+	})
+
+	t.Run("Failing cases exit non-zero", func(t *testing.T) {
+		cases := filepath.Join(dir, "fail.json")
+		os.WriteFile(cases, []byte(`{"cases":[
+  {"description":"UBER EATS","expected":"Dining"},
+  {"description":"Uber trip","expected":"Taxi"},
+  {"description":"Netflix","expected":"Subscriptions"}
+]}`), 0644)
+		res, _ := run(db, "rules", "test", "--file", cases)
+		assertGlobal(t, res, 1)
+		errs := res.JSON["errors"].([]interface{})
+		failures := errs[0].(map[string]interface{})["details"].([]interface{})
+		if len(failures) != 2 {
+			t.Fatalf("expected 2 failures, got %v", failures)
+		}
+		taxi := failures[0].(map[string]interface{})
+		if taxi["actual"] != "Transport" || taxi["match_value"] != "Uber" {
+			t.Errorf("expected Transport via the Uber rule, got %v", taxi)
+		}
+		netflix := failures[1].(map[string]interface{})
+		if netflix["actual"] != "" || netflix["rule_id"] != nil {
+			t.Errorf("expected no rule to fire for Netflix, got %v", netflix)
+		}
+	})
+
+	t.Run("Invalid case file", func(t *testing.T) {
+		cases := filepath.Join(dir, "bad.yaml")
+		os.WriteFile(cases, []byte("cases:\n  - expected: Dining\n"), 0644)
+		res, _ := run(db, "rules", "test", "--file", cases)
+		assertGlobal(t, res, 2)
+	})
+}
+
+func TestRulesHitStats(t *testing.T) {
+	db := setupDB(t)
+
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"}},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	os.WriteFile(mappingPath, []byte(mappingJSON), 0644)
+	csvData := `Date,Description,Amount
+2024-06-10,Uber,-12.34
+2025-02-12,Uber,-45.67
+2024-03-15,Blockbuster,-20.00
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	os.WriteFile(csvPath, []byte(csvData), 0644)
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath, "--month", "2024-03", "--month", "2024-06", "--month", "2025-02")
+	assertGlobal(t, res, 0)
+
+	run(db, "rules", "create", "--match-value", "Uber", "--match-type", "contains", "--category", "Transport")
+	run(db, "rules", "create", "--match-value", "Blockbuster", "--match-type", "contains", "--category", "Movies")
+	run(db, "rules", "create", "--match-value", "Netflix", "--match-type", "contains", "--category", "Movies")
+
+	res, _ = run(db, "rules", "list")
+	assertGlobal(t, res, 0)
+	stats := map[string]map[string]interface{}{}
+	for _, item := range res.JSON["items"].([]interface{}) {
+		rule := item.(map[string]interface{})
+		stats[rule["match_value"].(string)] = rule
+	}
+	if stats["Uber"]["hit_count"].(float64) != 2 || stats["Uber"]["last_matched_date"] != "2025-02-12" {
+		t.Errorf("unexpected Uber stats: %v", stats["Uber"])
+	}
+	if stats["Netflix"]["hit_count"].(float64) != 0 || stats["Netflix"]["last_matched_date"] != nil {
+		t.Errorf("unexpected Netflix stats: %v", stats["Netflix"])
+	}
+
+	res, _ = run(db, "rules", "stale", "--since", "2025-01-01")
+	assertGlobal(t, res, 0)
+	items := res.JSON["items"].([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 stale rules, got %v", items)
+	}
+	for _, item := range items {
+		if item.(map[string]interface{})["match_value"] == "Uber" {
+			t.Error("Uber rule matched in 2025 and should not be stale")
+		}
+	}
+
+	res, _ = run(db, "rules", "stale")
+	assertGlobal(t, res, 2)
+	res, _ = run(db, "rules", "stale", "--since", "2025-13-01")
+	assertGlobal(t, res, 2)
+}
+
+func TestRulesApplyOverwrite(t *testing.T) {
+	db := setupDB(t)
+
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"}},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	os.WriteFile(mappingPath, []byte(mappingJSON), 0644)
+	csvData := `Date,Description,Amount
+2025-01-10,AMAZON MKTP,-12.34
+2025-01-12,AMAZON PRIME,-9.99
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	os.WriteFile(csvPath, []byte(csvData), 0644)
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+
+	run(db, "rules", "create", "--match-value", "AMAZON", "--match-type", "contains", "--category", "Shopping")
+	list, _ := run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--query", "PRIME")
+	primeID := fmt.Sprint(list.JSON["transactions"].([]interface{})[0].(map[string]interface{})["id"])
+	run(db, "tx", "categorize", "--id", primeID, "--category", "Subscriptions")
+	run(db, "rules", "create", "--match-value", "AMAZON", "--match-type", "starts_with", "--category", "Online")
+
+	res, _ = run(db, "rules", "apply", "--all", "--overwrite", "rule-only", "--dry-run")
+	assertGlobal(t, res, 0)
+	if res.JSON["changed_count"].(float64) != 1 || res.JSON["batch_id"] != nil {
+		t.Fatalf("unexpected dry run result: %v", res.JSON)
+	}
+	change := res.JSON["changes"].([]interface{})[0].(map[string]interface{})
+	if change["old_category_name"] != "Shopping" || change["old_source"] != "rule" || change["new_category_name"] != "Online" {
+		t.Errorf("unexpected change: %v", change)
+	}
+
+	res, _ = run(db, "rules", "apply", "--all", "--overwrite", "rule-only")
+	assertGlobal(t, res, 0)
+	batchID := fmt.Sprint(res.JSON["batch_id"])
+
+	categories := func() map[string]interface{} {
+		out := map[string]interface{}{}
+		list, _ := run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+		for _, item := range list.JSON["transactions"].([]interface{}) {
+			tx := item.(map[string]interface{})
+			out[tx["description"].(string)] = tx["category"]
+		}
+		return out
+	}
+	got := categories()
+	if got["AMAZON MKTP"] != "Online" || got["AMAZON PRIME"] != "Subscriptions" {
+		t.Errorf("unexpected categories after rule-only apply: %v", got)
+	}
+
+	res, _ = run(db, "rules", "apply", "--undo", batchID)
+	assertGlobal(t, res, 0)
+	if res.JSON["restored"].(float64) != 1 {
+		t.Errorf("expected 1 restored transaction, got %v", res.JSON)
+	}
+	if got := categories(); got["AMAZON MKTP"] != "Shopping" {
+		t.Errorf("expected undo to restore Shopping, got %v", got)
+	}
+
+	res, _ = run(db, "rules", "apply", "--all", "--overwrite", "all")
+	assertGlobal(t, res, 0)
+	if res.JSON["changed_count"].(float64) != 2 {
+		t.Errorf("expected 2 changes with --overwrite all, got %v", res.JSON)
+	}
+
+	res, _ = run(db, "rules", "apply", "--undo", batchID)
+	assertGlobal(t, res, 1)
+	res, _ = run(db, "rules", "apply", "--overwrite", "all")
+	assertGlobal(t, res, 2)
+	res, _ = run(db, "rules", "apply", "--all", "--overwrite", "manual")
+	assertGlobal(t, res, 2)
+}
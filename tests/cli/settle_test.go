@@ -0,0 +1,55 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReportSettle(t *testing.T) {
+	db := setupDB(t)
+
+	res, _ := run(db, "categories", "create", "--name", "Hobbies")
+	assertGlobal(t, res, 0)
+	hobbies := fmt.Sprint(res.JSON["id"])
+
+	for _, args := range [][]string{
+		{"--date", "2025-03-02", "--description", "Grocer", "--amount", "-100.00", "--account", "Visa", "--category", "Groceries", "--owner", "Alex"},
+		{"--date", "2025-03-01", "--description", "Rent", "--amount", "-1000.00", "--account", "Chequing", "--category", "Rent", "--owner", "Sam"},
+		{"--date", "2025-03-05", "--description", "Guitar strings", "--amount", "-50.00", "--account", "Visa", "--category", "Hobbies", "--owner", "Alex"},
+		{"--date", "2025-03-20", "--description", "Pharmacy", "--amount", "-20.00", "--account", "Cash"},
+	} {
+		res, _ := run(db, append([]string{"tx", "add"}, args...)...)
+		assertGlobal(t, res, 0)
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "report", "settle", "--share", "Alex=60")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "report", "settle", "--share", "Nobody=100")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "report", "settle", "--share", "Alex")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "report", "settle", "--category-share", "999:Alex=100")
+		assertGlobal(t, res, 2)
+	})
+
+	res, _ = run(db, "report", "settle", "--start", "2025-03-01", "--end", "2025-03-31", "--category-share", hobbies+":Alex=100")
+	assertGlobal(t, res, 0)
+	if res.JSON["total"] != "1150.00" || res.JSON["unassigned_total"] != "20.00" {
+		t.Errorf("unexpected settlement: %v", res.JSON)
+	}
+	alex := res.JSON["owners"].([]interface{})[0].(map[string]interface{})
+	if alex["owner"] != "Alex" || alex["paid"] != "150.00" || alex["share"] != "600.00" || alex["balance"] != "-450.00" {
+		t.Errorf("unexpected owner: %v", alex)
+	}
+	payment := res.JSON["payments"].([]interface{})[0].(map[string]interface{})
+	if payment["from"] != "Alex" || payment["to"] != "Sam" || payment["amount"] != "450.00" {
+		t.Errorf("unexpected payment: %v", payment)
+	}
+
+	res, _ = run(db, "--format", "table", "report", "settle", "--start", "2025-03-01", "--end", "2025-03-31", "--share", "Alex=60,Sam=40")
+	if res.ExitCode != 0 || !strings.Contains(res.Stdout, "pays Sam") {
+		t.Errorf("unexpected table output: %s", res.Stdout)
+	}
+}
@@ -0,0 +1,82 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTransfers(t *testing.T) {
+	db := setupDB(t)
+
+	for _, args := range [][]string{
+		{"--date", "2025-01-10", "--description", "Card payment", "--amount", "-200.00", "--account", "Checking"},
+		{"--date", "2025-01-12", "--description", "Payment received", "--amount", "200.00", "--account", "Card"},
+		{"--date", "2025-01-15", "--description", "Groceries", "--amount", "-50.00", "--account", "Card"},
+	} {
+		res, _ := run(db, append([]string{"tx", "add"}, args...)...)
+		assertGlobal(t, res, 0)
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "transfers", "detect", "--window-days", "40")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "transfers", "detect", "--start", "2025-01-01")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "transfers", "unlink")
+		assertGlobal(t, res, 2)
+		res, _ = run(db, "transfers", "unlink", "--id", "99")
+		assertGlobal(t, res, 1)
+	})
+
+	res, _ := run(db, "transfers", "detect", "--dry-run")
+	assertGlobal(t, res, 0)
+	if res.JSON["dry_run"] != true || res.JSON["count"] != float64(1) {
+		t.Fatalf("unexpected dry run: %v", res.JSON)
+	}
+	pair := res.JSON["transfers"].([]interface{})[0].(map[string]interface{})
+	if pair["id"] != nil || pair["out"].(map[string]interface{})["account"] != "Checking" || pair["in"].(map[string]interface{})["amount"] != "200.00" {
+		t.Errorf("unexpected pair: %v", pair)
+	}
+
+	res, _ = run(db, "transfers", "detect", "--start", "2025-01-01", "--end", "2025-01-31")
+	assertGlobal(t, res, 0)
+	link := res.JSON["transfers"].([]interface{})[0].(map[string]interface{})
+	if link["id"] == nil || link["days_apart"] != float64(2) {
+		t.Fatalf("unexpected link: %v", link)
+	}
+	id := fmt.Sprint(link["id"])
+
+	res, _ = run(db, "report", "summary", "--start", "2025-01-01", "--end", "2025-01-31")
+	assertGlobal(t, res, 0)
+	totals := res.JSON["totals"].(map[string]interface{})
+	if totals["income"] != "0.00" || totals["expenses"] != "-50.00" || totals["transfers"] != "0.00" {
+		t.Errorf("expected linked transfers out of totals, got %v", totals)
+	}
+	res, _ = run(db, "report", "summary", "--start", "2025-01-01", "--end", "2025-01-31", "--include-transfers")
+	assertGlobal(t, res, 0)
+	totals = res.JSON["totals"].(map[string]interface{})
+	if totals["income"] != "200.00" || totals["expenses"] != "-250.00" || totals["transfers"] != "0.00" {
+		t.Errorf("expected --include-transfers to count linked transfers by sign, got %v", totals)
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		res, _ := run(db, "--format", "table", "transfers", "list")
+		if res.ExitCode != 0 || !strings.Contains(res.Stdout, "Checking") {
+			t.Errorf("unexpected table output: %s", res.Stdout)
+		}
+	})
+
+	res, _ = run(db, "transfers", "unlink", "--id", id)
+	assertGlobal(t, res, 0)
+	res, _ = run(db, "transfers", "list")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(0) {
+		t.Errorf("expected no links, got %v", res.JSON)
+	}
+	res, _ = run(db, "transfers", "detect")
+	assertGlobal(t, res, 0)
+	if res.JSON["count"] != float64(0) {
+		t.Errorf("expected the unlinked pair to stay unlinked, got %v", res.JSON)
+	}
+}
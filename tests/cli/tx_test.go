@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -143,3 +144,471 @@ func TestTxListFilters(t *testing.T) {
 		}
 	})
 }
+
+func TestTxTag(t *testing.T) {
+	db := setupDB(t)
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"},"account":"Account","owner":"Owner"},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(mappingPath, []byte(mappingJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvData := `Date,Description,Amount,Account,Owner
+2025-01-10,Hotel,-300.00,BMO,Alex
+2025-01-12,Flight,-500.00,BMO,Alex
+2025-01-15,Coffee,-5.00,BMO,Alex
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+
+	ids := map[string]string{}
+	res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+	for _, item := range res.JSON["transactions"].([]interface{}) {
+		tx := item.(map[string]interface{})
+		ids[tx["description"].(string)] = fmt.Sprint(tx["id"])
+	}
+
+	res, _ = run(db, "tx", "tag", "--ids", ids["Hotel"]+","+ids["Flight"], "--add", "vacation-2025,reimbursable")
+	assertGlobal(t, res, 0)
+	if res.JSON["changed"].(float64) != 4 {
+		t.Errorf("expected 4 tag links added, got %v", res.JSON["changed"])
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "tx", "tag", "--ids", ids["Hotel"])
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "tag", "--add", "x")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "tag", "--ids", "999", "--add", "x")
+		assertGlobal(t, res, 1)
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--tags", "nope")
+		assertGlobal(t, res, 2)
+	})
+
+	t.Run("Swap and filter", func(t *testing.T) {
+		res, _ := run(db, "tx", "tag", "--ids", ids["Flight"], "--remove", "reimbursable", "--add", "tax-deductible")
+		assertGlobal(t, res, 0)
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--tags", "Reimbursable")
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 1 {
+			t.Fatalf("expected 1 reimbursable transaction, got %v", res.JSON["count"])
+		}
+		tags := res.JSON["transactions"].([]interface{})[0].(map[string]interface{})["tags"].([]interface{})
+		if len(tags) != 2 || tags[0] != "reimbursable" || tags[1] != "vacation-2025" {
+			t.Errorf("unexpected tags on Hotel: %v", tags)
+		}
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--untagged")
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 1 {
+			t.Errorf("expected 1 untagged transaction, got %v", res.JSON["count"])
+		}
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--query", "tax-deductible")
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 1 {
+			t.Errorf("expected query to match the tag, got %v", res.JSON["count"])
+		}
+	})
+
+	t.Run("Export includes tags", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out.csv")
+		res, _ := run(db, "export", "--start", "2025-01-01", "--end", "2025-01-31", "--format", "csv", "--out", out, "--tags", "vacation-2025")
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 2 {
+			t.Errorf("expected 2 exported rows, got %v", res.JSON["count"])
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(data)
+		if !strings.Contains(content, "Owner,Tags") || !strings.Contains(content, "\"reimbursable, vacation-2025\"") {
+			t.Errorf("expected Tags column in export, got:\n%s", content)
+		}
+	})
+}
+
+func TestTxNote(t *testing.T) {
+	db := setupDB(t)
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"},"memo":"Memo"},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(mappingPath, []byte(mappingJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvData := `Date,Description,Amount,Memo
+2025-01-10,Flowers,-45.00, gift for mom 
+2025-01-12,Laptop,-1500.00,
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+
+	notes := map[string]string{}
+	ids := map[string]string{}
+	res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+	for _, item := range res.JSON["transactions"].([]interface{}) {
+		tx := item.(map[string]interface{})
+		ids[tx["description"].(string)] = fmt.Sprint(tx["id"])
+		notes[tx["description"].(string)] = tx["note"].(string)
+	}
+	if notes["Flowers"] != "gift for mom" || notes["Laptop"] != "" {
+		t.Fatalf("expected memo column imported as note, got %v", notes)
+	}
+
+	res, _ = run(db, "tx", "note", "--id", ids["Laptop"], "--note", "reimbursed by employer")
+	assertGlobal(t, res, 0)
+	if res.JSON["note"] != "reimbursed by employer" {
+		t.Errorf("unexpected note in response: %v", res.JSON["note"])
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "tx", "note", "--id", ids["Laptop"])
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "note", "--id", ids["Laptop"], "--note", "x", "--clear")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "note", "--id", "999", "--note", "x")
+		assertGlobal(t, res, 1)
+	})
+
+	t.Run("Search and export", func(t *testing.T) {
+		res, _ := run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--query", "reimbursed employer")
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 1 {
+			t.Fatalf("expected query to match the note, got %v", res.JSON["count"])
+		}
+
+		out := filepath.Join(t.TempDir(), "out.csv")
+		res, _ = run(db, "export", "--start", "2025-01-01", "--end", "2025-01-31", "--format", "csv", "--out", out)
+		assertGlobal(t, res, 0)
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(data)
+		if !strings.Contains(content, "Tags,Notes") || !strings.Contains(content, "reimbursed by employer") {
+			t.Errorf("expected Notes column in export, got:\n%s", content)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		res, _ := run(db, "tx", "note", "--id", ids["Flowers"], "--clear")
+		assertGlobal(t, res, 0)
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31", "--query", "gift for mom")
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 0 {
+			t.Errorf("expected cleared note to no longer match, got %v", res.JSON["count"])
+		}
+	})
+}
+
+func TestTxAttach(t *testing.T) {
+	db := setupDB(t)
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"}},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(mappingPath, []byte(mappingJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte("Date,Description,Amount\n2025-01-10,Staples,-42.00\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+	res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+	id := fmt.Sprint(res.JSON["transactions"].([]interface{})[0].(map[string]interface{})["id"])
+
+	receipt := filepath.Join(t.TempDir(), "receipt.pdf")
+	if err := os.WriteFile(receipt, []byte("%PDF-1.4 receipt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ = run(db, "tx", "attach", "--id", id, "--file", receipt)
+	assertGlobal(t, res, 0)
+	attachment := res.JSON["attachment"].(map[string]interface{})
+	if attachment["file_name"] != "receipt.pdf" || attachment["mime_type"] != "application/pdf" {
+		t.Errorf("unexpected attachment: %v", attachment)
+	}
+	stored := attachment["path"].(string)
+	if data, err := os.ReadFile(stored); err != nil || string(data) != "%PDF-1.4 receipt" {
+		t.Errorf("expected stored copy at %s, got %q (%v)", stored, data, err)
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "tx", "attach", "--id", id)
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "attach", "--id", id, "--file", filepath.Join(t.TempDir(), "missing.pdf"))
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "attach", "--id", "999", "--file", receipt)
+		assertGlobal(t, res, 1)
+
+		res, _ = run(db, "tx", "attachments", "--id", "999")
+		assertGlobal(t, res, 1)
+	})
+
+	t.Run("Backup keeps attachments", func(t *testing.T) {
+		backup := filepath.Join(t.TempDir(), "backup.db")
+		res, _ := run(db, "backup", "create", "--out", backup)
+		assertGlobal(t, res, 0)
+
+		if err := os.RemoveAll(filepath.Dir(filepath.Dir(stored))); err != nil {
+			t.Fatal(err)
+		}
+		res, _ = run(db, "backup", "restore", "--file", backup)
+		assertGlobal(t, res, 0)
+
+		res, _ = run(db, "tx", "attachments", "--id", id)
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 1 {
+			t.Fatalf("expected 1 attachment, got %v", res.JSON["count"])
+		}
+		if data, err := os.ReadFile(stored); err != nil || string(data) != "%PDF-1.4 receipt" {
+			t.Errorf("expected attachment restored from backup, got %q (%v)", data, err)
+		}
+	})
+}
+
+func TestTxSplit(t *testing.T) {
+	db := setupDB(t)
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"},"account":"Account","owner":"Owner"},"account":"BMO","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(mappingPath, []byte(mappingJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvData := `Date,Description,Amount,Account,Owner
+2025-01-10,Costco,-150.00,BMO,Alex
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+
+	res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+	id := fmt.Sprint(res.JSON["transactions"].([]interface{})[0].(map[string]interface{})["id"])
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "tx", "split", "--id", id)
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "split", "--id", id, "--line", "-150.00|Groceries")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "split", "--id", id, "--line", "-100.00|Groceries", "--line", "-40.00|Toys||Alex")
+		assertGlobal(t, res, 2)
+		errs := res.JSON["errors"].([]interface{})
+		if msg := errs[0].(map[string]interface{})["message"].(string); !strings.Contains(msg, "-140.00") || !strings.Contains(msg, "-150.00") {
+			t.Errorf("expected sum mismatch message with both amounts, got %q", msg)
+		}
+		res, _ = run(db, "categories", "list")
+		for _, item := range res.JSON["items"].([]interface{}) {
+			if item.(map[string]interface{})["name"] == "Toys" {
+				t.Error("expected a rejected split to create no categories")
+			}
+		}
+
+		res, _ = run(db, "tx", "split", "--id", id, "--line", "abc|Groceries", "--line", "-150.00|Household")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "split", "--id", "999", "--line", "-1|A", "--line", "-1|B")
+		assertGlobal(t, res, 1)
+	})
+
+	t.Run("Split and list lines", func(t *testing.T) {
+		res, _ := run(db, "tx", "split", "--id", id,
+			"--line", "-80.00|Groceries",
+			"--line", "-45.00|Household|paper towels",
+			"--line", "-25.00|Clothing||Sam")
+		assertGlobal(t, res, 0)
+		lines := res.JSON["lines"].([]interface{})
+		if len(lines) != 3 || lines[2].(map[string]interface{})["owner"] != "Sam" || lines[1].(map[string]interface{})["note"] != "paper towels" {
+			t.Fatalf("unexpected split lines: %v", lines)
+		}
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 3 {
+			t.Fatalf("expected one row per split line, got %v", res.JSON["count"])
+		}
+		categories := map[string]string{}
+		for _, item := range res.JSON["transactions"].([]interface{}) {
+			tx := item.(map[string]interface{})
+			if tx["split_id"] == nil {
+				t.Errorf("expected split_id on split rows, got %v", tx)
+			}
+			categories[tx["category"].(string)] = tx["amount"].(string)
+		}
+		if categories["Household"] != "-45.00" || categories["Clothing"] != "-25.00" {
+			t.Errorf("unexpected split rows: %v", categories)
+		}
+
+		out := filepath.Join(t.TempDir(), "out.csv")
+		res, _ = run(db, "export", "--start", "2025-01-01", "--end", "2025-01-31", "--format", "csv", "--out", out)
+		assertGlobal(t, res, 0)
+		if res.JSON["count"].(float64) != 3 {
+			t.Errorf("expected split lines in export, got %v rows", res.JSON["count"])
+		}
+	})
+
+	t.Run("Unsplit", func(t *testing.T) {
+		res, _ := run(db, "tx", "unsplit", "--id", id)
+		assertGlobal(t, res, 0)
+		if res.JSON["removed_lines"].(float64) != 3 {
+			t.Errorf("expected 3 lines removed, got %v", res.JSON["removed_lines"])
+		}
+
+		res, _ = run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+		assertGlobal(t, res, 0)
+		txs := res.JSON["transactions"].([]interface{})
+		if len(txs) != 1 || txs[0].(map[string]interface{})["category"] != "Groceries" {
+			t.Errorf("expected one transaction categorized as its largest line, got %v", txs)
+		}
+	})
+}
+
+func TestTxPredict(t *testing.T) {
+	db := setupDB(t)
+
+	mappingJSON := `{"csv":{"date":"Date","description":["Description"],"amountMapping":{"type":"single","column":"Amount"}},"account":"Visa","currencyDefault":"CAD"}`
+	mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(mappingPath, []byte(mappingJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	csvData := `Date,Description,Amount
+2025-01-02,NETFLIX.COM,-15.99
+2025-01-05,LOBLAWS 1234,-64.00
+`
+	csvPath := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(csvPath, []byte(csvData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := run(db, "import", "--file", csvPath, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+
+	listRes, _ := run(db, "tx", "list", "--start", "2025-01-01", "--end", "2025-01-31")
+	for _, item := range listRes.JSON["transactions"].([]interface{}) {
+		tx := item.(map[string]interface{})
+		category := "Groceries"
+		if tx["description"] == "NETFLIX.COM" {
+			category = "Subscriptions"
+		}
+		res, _ := run(db, "tx", "categorize", "--id", fmt.Sprintf("%v", tx["id"]), "--category", category)
+		assertGlobal(t, res, 0)
+	}
+
+	nextCSV := filepath.Join(t.TempDir(), "next.csv")
+	if err := os.WriteFile(nextCSV, []byte("Date,Description,Amount\n2025-02-02,NETFLIX.COM,-15.99\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ = run(db, "import", "--file", nextCSV, "--mapping", mappingPath)
+	assertGlobal(t, res, 0)
+
+	res, _ = run(db, "tx", "predict", "--top", "1")
+	assertGlobal(t, res, 0)
+	items := res.JSON["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("expected 1 uncategorized transaction, got %d", len(items))
+	}
+	predictions := items[0].(map[string]interface{})["predictions"].([]interface{})
+	if len(predictions) != 1 || predictions[0].(map[string]interface{})["category_name"] != "Subscriptions" {
+		t.Errorf("expected Subscriptions prediction, got %v", predictions)
+	}
+	if res.JSON["model"].(map[string]interface{})["transaction_count"].(float64) != 2 {
+		t.Errorf("expected model trained on 2 transactions, got %v", res.JSON["model"])
+	}
+
+	t.Run("Import auto-accept", func(t *testing.T) {
+		marchCSV := filepath.Join(t.TempDir(), "march.csv")
+		if err := os.WriteFile(marchCSV, []byte("Date,Description,Amount\n2025-03-02,NETFLIX.COM,-15.99\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		res, _ := run(db, "import", "--file", marchCSV, "--mapping", mappingPath, "--auto-accept", "0.5")
+		assertGlobal(t, res, 0)
+		// Only the March row from this import is accepted; February stays a suggestion.
+		if res.JSON["auto_accepted_count"].(float64) != 1 {
+			t.Errorf("expected 1 auto-accepted transaction, got %v", res.JSON["auto_accepted_count"])
+		}
+		res, _ = run(db, "tx", "predict")
+		assertGlobal(t, res, 0)
+		if items := res.JSON["items"].([]interface{}); len(items) != 1 || items[0].(map[string]interface{})["date"] != "2025-02-02" {
+			t.Errorf("expected the February row to stay uncategorized, got %v", items)
+		}
+
+		res, _ = run(db, "import", "--file", marchCSV, "--mapping", mappingPath, "--auto-accept", "2")
+		assertGlobal(t, res, 2)
+	})
+}
+
+func TestTxAddEdit(t *testing.T) {
+	db := setupDB(t)
+
+	res, _ := run(db, "tx", "add", "--date", "2025-03-01", "--description", "Farmers market", "--amount", "-23.50", "--account", "Wallet", "--category", "Groceries", "--currency", "usd", "--note", "cash")
+	assertGlobal(t, res, 0)
+	tx := res.JSON["transaction"].(map[string]interface{})
+	if tx["amount"] != "-23.50" || tx["currency"] != "USD" || tx["account"] != "Wallet" || tx["category"] != "Groceries" || tx["note"] != "cash" {
+		t.Fatalf("unexpected transaction: %v", tx)
+	}
+	id := fmt.Sprint(tx["id"])
+
+	res, _ = run(db, "tx", "add", "--date", "2025-03-02", "--description", "Coffee", "--amount", "-4.50", "--account", "Wallet")
+	assertGlobal(t, res, 0)
+	otherID := fmt.Sprint(res.JSON["transaction"].(map[string]interface{})["id"])
+
+	res, _ = run(db, "tx", "edit", "--id", id, "--date", "2025-03-03", "--amount", "-25", "--owner", "Sam")
+	assertGlobal(t, res, 0)
+	tx = res.JSON["transaction"].(map[string]interface{})
+	if tx["date"] != "2025-03-03" || tx["amount"] != "-25.00" || tx["owner"] != "Sam" || tx["description"] != "Farmers market" {
+		t.Fatalf("unexpected edited transaction: %v", tx)
+	}
+
+	res, _ = run(db, "tx", "edit", "--id", id, "--owner", "")
+	assertGlobal(t, res, 0)
+	if owner := res.JSON["transaction"].(map[string]interface{})["owner"]; owner != "" {
+		t.Errorf("expected owner cleared, got %v", owner)
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		res, _ := run(db, "tx", "add", "--date", "2025-03-01", "--amount", "-1")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "add", "--date", "2025-13-01", "--description", "X", "--amount", "-1", "--account", "Wallet")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "add", "--date", "2025-03-01", "--description", "X", "--amount", "-1", "--account", "Wallet", "--currency", "dollars")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "edit", "--id", id)
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "edit", "--id", "999", "--amount", "1")
+		assertGlobal(t, res, 1)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		res, _ := run(db, "tx", "add", "--date", "2025-03-02", "--description", "Coffee", "--amount", "-4.50", "--account", "Wallet")
+		assertGlobal(t, res, 2)
+
+		// Editing into another transaction's account, date, description, and amount is rejected.
+		res, _ = run(db, "tx", "edit", "--id", id, "--date", "2025-03-02", "--description", "Coffee", "--amount", "-4.50")
+		assertGlobal(t, res, 2)
+
+		res, _ = run(db, "tx", "edit", "--id", otherID, "--description", "Coffee")
+		assertGlobal(t, res, 0)
+	})
+}